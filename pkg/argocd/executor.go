@@ -0,0 +1,49 @@
+package argocd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// ArgoCDExecutor implements the CommandExecutor interface for argocd commands
+type ArgoCDExecutor struct{}
+
+// This line ensures ArgoCDExecutor implements the CommandExecutor interface
+var _ tools.CommandExecutor = (*ArgoCDExecutor)(nil)
+var _ tools.CommandDescriber = (*ArgoCDExecutor)(nil)
+
+// NewExecutor creates a new ArgoCDExecutor instance
+func NewExecutor() *ArgoCDExecutor {
+	return &ArgoCDExecutor{}
+}
+
+// Execute handles argocd command execution
+func (e *ArgoCDExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	argocdCmd, ok := params["command"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid command parameter")
+	}
+
+	// Validate the command against security settings
+	validator := security.NewValidator(cfg.SecurityConfig)
+	err := validator.ValidateCommand(argocdCmd, security.CommandTypeArgoCD)
+	if err != nil {
+		return "", err
+	}
+
+	// Execute the command
+	process := command.NewShellProcess("argocd", cfg.Timeout)
+	process.Limiter = cfg.ProcessLimiter
+	return process.Run(argocdCmd)
+}
+
+// DescribeCommand returns the argocd command a call would run
+func (e *ArgoCDExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	argocdCmd, ok := params["command"].(string)
+	return security.CommandTypeArgoCD, argocdCmd, ok
+}
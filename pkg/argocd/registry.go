@@ -0,0 +1,25 @@
+package argocd
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterArgoCD registers the argocd tool
+func RegisterArgoCD() mcp.Tool {
+	return mcp.NewTool("call_argocd",
+		mcp.WithDescription("Run Argo CD CLI commands to inspect and sync applications (app list/get/diff/history/logs; app sync, rollback and terminate-op need readwrite)"),
+		mcp.WithString("command",
+			mcp.Required(),
+			mcp.Description("Full argocd command to execute (e.g., 'argocd app get web -N team-a', 'argocd app diff web')"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Call Argo CD",
+			DestructiveHint: boolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,267 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/shlex"
+)
+
+// defaultMaxEntries bounds the number of cached responses
+const defaultMaxEntries = 1000
+
+// Config holds the response cache configuration
+type Config struct {
+	// DefaultTTL applies to read verbs without an explicit TTL (0 disables caching for them)
+	DefaultTTL time.Duration
+	// VerbTTLs overrides the TTL for specific verbs (0 disables caching for that verb)
+	VerbTTLs map[string]time.Duration
+	// MaxEntries bounds the number of cached responses
+	MaxEntries int
+}
+
+// ParseTTLs parses a comma-separated TTL specification. Bare durations set the
+// default TTL and verb=duration entries override it for a verb, e.g.
+// "5s,api-resources=5m,logs=0".
+func ParseTTLs(spec string) (*Config, error) {
+	cfg := &Config{
+		VerbTTLs:   make(map[string]time.Duration),
+		MaxEntries: defaultMaxEntries,
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		verb, value, hasVerb := strings.Cut(entry, "=")
+		if !hasVerb {
+			value = verb
+		}
+
+		ttl, err := parseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache TTL '%s': %w", entry, err)
+		}
+
+		if hasVerb {
+			verb = strings.TrimSpace(verb)
+			if verb == "" {
+				return nil, fmt.Errorf("invalid cache TTL '%s': missing verb", entry)
+			}
+			cfg.VerbTTLs[verb] = ttl
+		} else {
+			cfg.DefaultTTL = ttl
+		}
+	}
+	return cfg, nil
+}
+
+// parseDuration accepts Go durations and bare "0"
+func parseDuration(value string) (time.Duration, error) {
+	if value == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration")
+	}
+	return d, nil
+}
+
+// TTLFor returns the TTL configured for a verb
+func (c *Config) TTLFor(verb string) time.Duration {
+	if ttl, ok := c.VerbTTLs[verb]; ok {
+		return ttl
+	}
+	return c.DefaultTTL
+}
+
+// Key identifies a cached response
+type Key struct {
+	// Cluster identifies the cluster the command runs against
+	Cluster string
+	// CommandType is the CLI the command is run with (kubectl, helm, ...)
+	CommandType string
+	// Command is the normalized command line
+	Command string
+	// Format is the format the tool rendered the output in, for tools
+	// that format the same command's output in several ways
+	Format string
+}
+
+// streamingFlags are flags that make a command stream output until it times
+// out; such output is never a meaningful snapshot to replay
+var streamingFlags = map[string]bool{
+	"-w":           true,
+	"--watch":      true,
+	"--watch-only": true,
+	"--follow":     true,
+}
+
+// NewKey builds the cache key for a command. The command is normalized by
+// tokenizing it the same way the executor does and dropping the leading CLI
+// name, so "kubectl get pods  -n x" and "get pods -n x" share an entry.
+// ok is false for commands that cannot be cached (streaming, unparsable).
+func NewKey(cluster, commandType, command string) (Key, bool) {
+	tokens, err := shlex.Split(command)
+	if err != nil || len(tokens) == 0 {
+		return Key{}, false
+	}
+	if tokens[0] == commandType {
+		tokens = tokens[1:]
+	}
+	verb := ""
+	for _, t := range tokens {
+		if t == "--" {
+			break
+		}
+		if verb == "" && !strings.HasPrefix(t, "-") {
+			verb = t
+		}
+		if streamingFlags[t] || strings.HasPrefix(t, "--follow=") || strings.HasPrefix(t, "--watch=") {
+			return Key{}, false
+		}
+		// "-f" means --follow for logs, but --filename everywhere else
+		if t == "-f" && verb == "logs" {
+			return Key{}, false
+		}
+	}
+	return Key{
+		Cluster:     cluster,
+		CommandType: commandType,
+		Command:     strings.Join(tokens, " "),
+	}, true
+}
+
+// Entry is a cached response
+type Entry struct {
+	Output   string
+	StoredAt time.Time
+	TTL      time.Duration
+	// Namespace is the namespace the command was scoped to ("" for unscoped or all namespaces)
+	Namespace string
+}
+
+// Age returns how long ago the entry was stored
+func (e *Entry) Age(now time.Time) time.Duration {
+	return now.Sub(e.StoredAt)
+}
+
+// Cache is a short-TTL response cache for read-only commands
+type Cache struct {
+	config   *Config
+	identity *IdentityResolver
+	mu       sync.Mutex
+	entries  map[Key]*Entry
+	now      func() time.Time
+}
+
+// New creates a new Cache from the given configuration. The identity
+// resolver scopes entries to the cluster they were fetched from.
+func New(config *Config, identity *IdentityResolver) *Cache {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultMaxEntries
+	}
+	return &Cache{
+		config:   config,
+		identity: identity,
+		entries:  make(map[Key]*Entry),
+		now:      time.Now,
+	}
+}
+
+// Cluster returns the identity of the cluster commands currently run against
+func (c *Cache) Cluster() string {
+	return c.identity.Identity()
+}
+
+// ResetCluster forces the cluster identity to be resolved again, e.g. after
+// the kubeconfig context was changed through the server
+func (c *Cache) ResetCluster() {
+	c.identity.Reset()
+}
+
+// TTLFor returns the TTL for a verb (0 means the verb is not cached)
+func (c *Cache) TTLFor(verb string) time.Duration {
+	return c.config.TTLFor(verb)
+}
+
+// Get returns an unexpired entry for the key
+func (c *Cache) Get(key Key) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if entry.Age(c.now()) >= entry.TTL {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry, true
+}
+
+// Set stores a response for the key
+func (c *Cache) Set(key Key, namespace, output string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= c.config.MaxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = &Entry{
+		Output:    output,
+		StoredAt:  now,
+		TTL:       ttl,
+		Namespace: namespace,
+	}
+}
+
+// Invalidate drops the entries for a cluster that a mutation in the given
+// namespace could have affected. An empty namespace (cluster-scoped,
+// all-namespace or current-context mutations) drops every entry for the
+// cluster; otherwise entries scoped to other namespaces are kept.
+func (c *Cache) Invalidate(cluster, namespace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if key.Cluster != cluster {
+			continue
+		}
+		if namespace == "" || entry.Namespace == "" || entry.Namespace == namespace {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// evict drops expired entries, then the oldest entry if the cache is still full.
+// Callers must hold c.mu.
+func (c *Cache) evict(now time.Time) {
+	var oldestKey Key
+	var oldest *Entry
+	for key, entry := range c.entries {
+		if entry.Age(now) >= entry.TTL {
+			delete(c.entries, key)
+			continue
+		}
+		if oldest == nil || entry.StoredAt.Before(oldest.StoredAt) {
+			oldestKey, oldest = key, entry
+		}
+	}
+	if len(c.entries) >= c.config.MaxEntries && oldest != nil {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T, spec string, now *time.Time) *Cache {
+	t.Helper()
+	cfg, err := ParseTTLs(spec)
+	if err != nil {
+		t.Fatalf("Expected no error parsing %q, got %v", spec, err)
+	}
+	c := New(cfg, NewIdentityResolver(func() string { return "cluster-a" }))
+	c.now = func() time.Time { return *now }
+	return c
+}
+
+func TestParseTTLs(t *testing.T) {
+	cfg, err := ParseTTLs("5s, api-resources=5m,logs=0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.TTLFor("get") != 5*time.Second {
+		t.Errorf("Expected default TTL 5s, got %v", cfg.TTLFor("get"))
+	}
+	if cfg.TTLFor("api-resources") != 5*time.Minute {
+		t.Errorf("Expected api-resources TTL 5m, got %v", cfg.TTLFor("api-resources"))
+	}
+	if cfg.TTLFor("logs") != 0 {
+		t.Errorf("Expected logs caching to be disabled, got %v", cfg.TTLFor("logs"))
+	}
+
+	for _, spec := range []string{"abc", "get=abc", "=5s", "-5s"} {
+		if _, err := ParseTTLs(spec); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	a, ok := NewKey("c", "kubectl", "kubectl get pods  -n x")
+	if !ok {
+		t.Fatal("Expected command to be cacheable")
+	}
+	b, _ := NewKey("c", "kubectl", "get pods -n 'x'")
+	if a != b {
+		t.Errorf("Expected normalized keys to match, got %+v and %+v", a, b)
+	}
+
+	uncacheable := []string{
+		"get pods -w",
+		"get pods --watch",
+		"logs mypod -f",
+		"logs mypod --follow=true",
+		"get pods 'unterminated",
+	}
+	for _, cmd := range uncacheable {
+		if _, ok := NewKey("c", "kubectl", cmd); ok {
+			t.Errorf("Expected %q to be uncacheable", cmd)
+		}
+	}
+
+	if _, ok := NewKey("c", "kubectl", "get -f pod.yaml"); !ok {
+		t.Error("Expected -f outside logs to be treated as --filename")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newTestCache(t, "5s", &now)
+	key, _ := NewKey(c.Cluster(), "kubectl", "get pods -n x")
+
+	c.Set(key, "x", "output", c.TTLFor("get"))
+	if entry, ok := c.Get(key); !ok || entry.Output != "output" {
+		t.Fatalf("Expected cache hit, got %v %v", entry, ok)
+	}
+
+	now = now.Add(5 * time.Second)
+	if _, ok := c.Get(key); ok {
+		t.Error("Expected entry to expire after its TTL")
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newTestCache(t, "1m", &now)
+
+	inX, _ := NewKey("cluster-a", "kubectl", "get pods -n x")
+	inY, _ := NewKey("cluster-a", "kubectl", "get pods -n y")
+	unscoped, _ := NewKey("cluster-a", "kubectl", "get nodes")
+	otherCluster, _ := NewKey("cluster-b", "kubectl", "get pods -n x")
+	c.Set(inX, "x", "x", time.Minute)
+	c.Set(inY, "y", "y", time.Minute)
+	c.Set(unscoped, "", "nodes", time.Minute)
+	c.Set(otherCluster, "x", "x", time.Minute)
+
+	c.Invalidate("cluster-a", "x")
+	if _, ok := c.Get(inX); ok {
+		t.Error("Expected entry in the mutated namespace to be invalidated")
+	}
+	if _, ok := c.Get(unscoped); ok {
+		t.Error("Expected unscoped entry to be invalidated")
+	}
+	if _, ok := c.Get(inY); !ok {
+		t.Error("Expected entry in another namespace to be kept")
+	}
+	if _, ok := c.Get(otherCluster); !ok {
+		t.Error("Expected entry for another cluster to be kept")
+	}
+
+	c.Invalidate("cluster-a", "")
+	if _, ok := c.Get(inY); ok {
+		t.Error("Expected cluster-wide mutation to invalidate every entry for the cluster")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newTestCache(t, "1m", &now)
+	c.config.MaxEntries = 2
+
+	first, _ := NewKey("c", "kubectl", "get pods -n a")
+	second, _ := NewKey("c", "kubectl", "get pods -n b")
+	third, _ := NewKey("c", "kubectl", "get pods -n c")
+	c.Set(first, "a", "a", time.Minute)
+	now = now.Add(time.Second)
+	c.Set(second, "b", "b", time.Minute)
+	now = now.Add(time.Second)
+	c.Set(third, "c", "c", time.Minute)
+
+	if _, ok := c.Get(first); ok {
+		t.Error("Expected oldest entry to be evicted")
+	}
+	if _, ok := c.Get(third); !ok {
+		t.Error("Expected newest entry to be stored")
+	}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+)
+
+// identityRefresh bounds how long a resolved cluster identity is reused, so
+// context switches made outside the server are eventually picked up
+const identityRefresh = 30 * time.Second
+
+// IdentityResolver resolves and memoizes the identity of the cluster that
+// commands run against
+type IdentityResolver struct {
+	resolve    func() string
+	mu         sync.Mutex
+	value      string
+	resolvedAt time.Time
+	now        func() time.Time
+}
+
+// NewIdentityResolver creates a resolver around the given resolve function
+func NewIdentityResolver(resolve func() string) *IdentityResolver {
+	return &IdentityResolver{
+		resolve: resolve,
+		now:     time.Now,
+	}
+}
+
+// NewKubeconfigIdentityResolver creates a resolver that identifies the
+// cluster by kubeconfig path, current context and API server URL
+func NewKubeconfigIdentityResolver(timeout int) *IdentityResolver {
+	return NewIdentityResolver(func() string {
+		process := command.NewShellProcess("kubectl", timeout)
+		process.ReturnErrOutput = false
+		output, err := process.Run(`config view --minify -o 'jsonpath={.current-context}{"|"}{.clusters[0].cluster.server}'`)
+		if err != nil {
+			output = ""
+		}
+		return os.Getenv("KUBECONFIG") + "|" + strings.TrimSpace(output)
+	})
+}
+
+// Identity returns the memoized cluster identity, resolving it when stale
+func (r *IdentityResolver) Identity() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if r.resolvedAt.IsZero() || now.Sub(r.resolvedAt) >= identityRefresh {
+		r.value = r.resolve()
+		r.resolvedAt = now
+	}
+	return r.value
+}
+
+// Reset forces the next Identity call to resolve again
+func (r *IdentityResolver) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolvedAt = time.Time{}
+}
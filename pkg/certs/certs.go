@@ -0,0 +1,365 @@
+package certs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// Certificate sources
+const (
+	SourceSecret            = "Secret"
+	SourceCertManager       = "Certificate"
+	SourceMutatingWebhook   = "MutatingWebhookConfiguration"
+	SourceValidatingWebhook = "ValidatingWebhookConfiguration"
+	SourceAPIService        = "APIService"
+)
+
+// Certificate statuses
+const (
+	StatusExpired     = "expired"
+	StatusExpiring    = "expiring"
+	StatusOK          = "ok"
+	StatusNotYetValid = "not-yet-valid"
+	StatusNotReady    = "not-ready"
+	StatusInvalid     = "invalid"
+)
+
+const (
+	// defaultWarningDays is the default expiry window
+	defaultWarningDays = 30
+	// maxWarningDays caps warning_days
+	maxWarningDays = 3650
+)
+
+// Options are the parameters of cert_expiry
+type Options struct {
+	// Namespace limits Secrets and cert-manager Certificates to one namespace
+	Namespace string
+	// WarningDays is the window in which certificates are expiring
+	WarningDays int
+	// ShowAll lists certificates that are not expiring too
+	ShowAll bool
+	// Now is the reference time for the expiry (default: the current time)
+	Now time.Time
+}
+
+// Report is the result of cert_expiry
+type Report struct {
+	// Namespaces are the scanned namespaces; "*" means all namespaces
+	Namespaces   []string      `json:"namespaces"`
+	WarningDays  int           `json:"warningDays"`
+	Summary      Summary       `json:"summary"`
+	Certificates []Certificate `json:"certificates"`
+	Notes        []string      `json:"notes,omitempty"`
+}
+
+// Summary counts the certificates by status
+type Summary struct {
+	Total    int `json:"total"`
+	Expired  int `json:"expired"`
+	Expiring int `json:"expiring"`
+	NotReady int `json:"notReady"`
+	Invalid  int `json:"invalid"`
+}
+
+// Certificate describes one certificate. It never carries key material or
+// the encoded certificate itself.
+type Certificate struct {
+	Source    string `json:"source"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Field is where the certificate was found, e.g. "tls.crt" or
+	// "webhooks[my-webhook.example.com].caBundle"
+	Field        string   `json:"field,omitempty"`
+	Status       string   `json:"status"`
+	Subject      string   `json:"subject,omitempty"`
+	SANs         []string `json:"sans,omitempty"`
+	Issuer       string   `json:"issuer,omitempty"`
+	IsCA         bool     `json:"isCA,omitempty"`
+	NotBefore    string   `json:"notBefore,omitempty"`
+	NotAfter     string   `json:"notAfter,omitempty"`
+	DaysToExpiry int      `json:"daysToExpiry"`
+	// ChainLength is the number of certificates in the field
+	ChainLength int    `json:"chainLength,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// tlsSecret declares only the certificate keys of a kubernetes.io/tls
+// Secret, so that tls.key is never decoded
+type tlsSecret struct {
+	Metadata k8s.ObjectMeta `json:"metadata"`
+	Data     struct {
+		Certificate string `json:"tls.crt"`
+		CA          string `json:"ca.crt"`
+	} `json:"data"`
+}
+
+// certManagerCertificate is the subset of a cert-manager Certificate used here
+type certManagerCertificate struct {
+	Metadata k8s.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		SecretName string   `json:"secretName"`
+		CommonName string   `json:"commonName"`
+		DNSNames   []string `json:"dnsNames"`
+		IssuerRef  struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"issuerRef"`
+	} `json:"spec"`
+	Status struct {
+		NotBefore   string          `json:"notBefore"`
+		NotAfter    string          `json:"notAfter"`
+		RenewalTime string          `json:"renewalTime"`
+		Conditions  []k8s.Condition `json:"conditions"`
+	} `json:"status"`
+}
+
+// webhookConfiguration is the subset of a webhook configuration used here
+type webhookConfiguration struct {
+	Metadata k8s.ObjectMeta `json:"metadata"`
+	Webhooks []struct {
+		Name         string `json:"name"`
+		ClientConfig struct {
+			CABundle string `json:"caBundle"`
+		} `json:"clientConfig"`
+	} `json:"webhooks"`
+}
+
+// apiService is the subset of an APIService used here
+type apiService struct {
+	Metadata k8s.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		CABundle string `json:"caBundle"`
+	} `json:"spec"`
+}
+
+// Scan reads kubernetes.io/tls Secrets and cert-manager Certificates of the
+// allowed namespaces, and the CA bundles of webhook configurations and
+// APIServices, and reports the expiry of their certificates. Certificate
+// data is only parsed here; the report holds metadata only.
+func Scan(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts Options) (*Report, error) {
+	switch {
+	case opts.WarningDays == 0:
+		opts.WarningDays = defaultWarningDays
+	case opts.WarningDays < 0 || opts.WarningDays > maxWarningDays:
+		return nil, fmt.Errorf("warning_days must be between 1 and %d", maxWarningDays)
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	namespaces, err := k8s.ScanNamespaces(ctx, runner, secConfig, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Namespaces: namespaces, WarningDays: opts.WarningDays}
+	var all []Certificate
+	note := func(format string, args ...interface{}) {
+		report.Notes = append(report.Notes, fmt.Sprintf(format, args...))
+	}
+
+	certManagerInstalled := true
+	for _, namespace := range namespaces {
+		scope := "-n " + namespace
+		if namespace == k8s.AllNamespaces {
+			scope = "-A"
+		}
+		var secrets k8s.List[tlsSecret]
+		if err := k8s.GetSensitiveJSON(ctx, runner, "get secrets "+scope+" --field-selector type=kubernetes.io/tls", &secrets); err != nil {
+			note("TLS Secrets in %s: %v", namespace, err)
+		}
+		for _, secret := range secrets.Items {
+			all = append(all, secretCertificates(secret, opts.Now)...)
+		}
+
+		if !certManagerInstalled {
+			continue
+		}
+		var certificates k8s.List[certManagerCertificate]
+		if err := k8s.GetJSON(ctx, runner, "get certificates.cert-manager.io "+scope, &certificates); err != nil {
+			if strings.Contains(err.Error(), "doesn't have a resource type") {
+				certManagerInstalled = false
+				continue
+			}
+			note("cert-manager Certificates in %s: %v", namespace, err)
+		}
+		for _, certificate := range certificates.Items {
+			all = append(all, certManagerStatus(certificate, opts.Now))
+		}
+	}
+
+	for _, source := range []struct{ kind, resource string }{
+		{SourceMutatingWebhook, "mutatingwebhookconfigurations"},
+		{SourceValidatingWebhook, "validatingwebhookconfigurations"},
+	} {
+		var configurations k8s.List[webhookConfiguration]
+		if err := k8s.GetJSON(ctx, runner, "get "+source.resource, &configurations); err != nil {
+			note("%ss: %v", source.kind, err)
+			continue
+		}
+		for _, configuration := range configurations.Items {
+			for _, webhook := range configuration.Webhooks {
+				if webhook.ClientConfig.CABundle == "" {
+					continue
+				}
+				base := Certificate{Source: source.kind, Name: configuration.Metadata.Name, Field: "webhooks[" + webhook.Name + "].caBundle"}
+				all = append(all, bundleCertificates(base, webhook.ClientConfig.CABundle, opts.Now)...)
+			}
+		}
+	}
+
+	var services k8s.List[apiService]
+	if err := k8s.GetJSON(ctx, runner, "get apiservices", &services); err != nil {
+		note("APIServices: %v", err)
+	}
+	for _, service := range services.Items {
+		if service.Spec.CABundle == "" {
+			continue
+		}
+		base := Certificate{Source: SourceAPIService, Name: service.Metadata.Name, Field: "caBundle"}
+		all = append(all, bundleCertificates(base, service.Spec.CABundle, opts.Now)...)
+	}
+
+	for i := range all {
+		classify(&all[i], opts.WarningDays)
+		countStatus(&report.Summary, all[i].Status)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if (all[i].NotAfter == "") != (all[j].NotAfter == "") {
+			return all[i].NotAfter == ""
+		}
+		if all[i].DaysToExpiry != all[j].DaysToExpiry {
+			return all[i].DaysToExpiry < all[j].DaysToExpiry
+		}
+		return all[i].Source+"/"+all[i].Namespace+"/"+all[i].Name < all[j].Source+"/"+all[j].Namespace+"/"+all[j].Name
+	})
+	report.Certificates = []Certificate{}
+	for _, certificate := range all {
+		if opts.ShowAll || certificate.Status != StatusOK {
+			report.Certificates = append(report.Certificates, certificate)
+		}
+	}
+	if !certManagerInstalled {
+		note("cert-manager is not installed; Certificate resources were not checked")
+	}
+	return report, nil
+}
+
+// secretCertificates reports the leaf certificate of tls.crt and the CA
+// certificate of ca.crt, if present
+func secretCertificates(secret tlsSecret, now time.Time) []Certificate {
+	base := Certificate{Source: SourceSecret, Namespace: secret.Metadata.Namespace, Name: secret.Metadata.Name, Field: "tls.crt"}
+	if secret.Data.Certificate == "" {
+		base.Status, base.Message = StatusInvalid, "tls.crt is empty"
+		return []Certificate{base}
+	}
+	certificates := leafCertificate(base, secret.Data.Certificate, now)
+	if secret.Data.CA != "" {
+		base.Field = "ca.crt"
+		certificates = append(certificates, bundleCertificates(base, secret.Data.CA, now)...)
+	}
+	return certificates
+}
+
+// leafCertificate reports the first certificate of a chain, which is the
+// serving certificate, with the length of the chain
+func leafCertificate(base Certificate, encoded string, now time.Time) []Certificate {
+	chain, err := parseBundle(encoded)
+	if err != nil {
+		base.Status, base.Message = StatusInvalid, err.Error()
+		return []Certificate{base}
+	}
+	base.ChainLength = len(chain)
+	certificate := describe(base, chain[0], now)
+	for _, intermediate := range chain[1:] {
+		if intermediate.NotAfter.Before(chain[0].NotAfter) {
+			certificate.Message = fmt.Sprintf("chain certificate %s expires first, at %s",
+				intermediate.Subject.String(), intermediate.NotAfter.UTC().Format(time.RFC3339))
+			certificate.DaysToExpiry = daysUntil(intermediate.NotAfter, now)
+		}
+	}
+	return []Certificate{certificate}
+}
+
+// bundleCertificates reports every certificate of a CA bundle
+func bundleCertificates(base Certificate, encoded string, now time.Time) []Certificate {
+	bundle, err := parseBundle(encoded)
+	if err != nil {
+		base.Status, base.Message = StatusInvalid, err.Error()
+		return []Certificate{base}
+	}
+	var certificates []Certificate
+	for _, certificate := range bundle {
+		info := describe(base, certificate, now)
+		info.ChainLength = len(bundle)
+		certificates = append(certificates, info)
+	}
+	return certificates
+}
+
+// certManagerStatus reports a cert-manager Certificate from its status
+func certManagerStatus(c certManagerCertificate, now time.Time) Certificate {
+	info := Certificate{
+		Source:    SourceCertManager,
+		Namespace: c.Metadata.Namespace,
+		Name:      c.Metadata.Name,
+		Field:     "secret " + c.Spec.SecretName,
+		Subject:   c.Spec.CommonName,
+		SANs:      c.Spec.DNSNames,
+		Issuer:    strings.TrimPrefix(c.Spec.IssuerRef.Kind+"/"+c.Spec.IssuerRef.Name, "/"),
+		NotBefore: c.Status.NotBefore,
+		NotAfter:  c.Status.NotAfter,
+	}
+	if notAfter, err := time.Parse(time.RFC3339, c.Status.NotAfter); err == nil {
+		info.DaysToExpiry = daysUntil(notAfter, now)
+	}
+	if ready := k8s.FindCondition(c.Status.Conditions, "Ready"); ready == nil || ready.Status != "True" {
+		info.Status = StatusNotReady
+		if ready != nil {
+			info.Message = strings.TrimSpace(ready.Reason + ": " + ready.Message)
+		}
+	}
+	if c.Status.RenewalTime != "" && info.Message == "" {
+		info.Message = "renewal scheduled at " + c.Status.RenewalTime
+	}
+	return info
+}
+
+// classify sets the status of a certificate from its days to expiry,
+// keeping statuses already set by parsing
+func classify(c *Certificate, warningDays int) {
+	if c.Status == StatusInvalid || c.Status == StatusNotYetValid || c.NotAfter == "" && c.Status != "" {
+		return
+	}
+	switch {
+	case c.NotAfter != "" && c.DaysToExpiry < 0:
+		c.Status = StatusExpired
+	case c.Status == StatusNotReady:
+		// not ready takes precedence over expiring
+	case c.NotAfter != "" && c.DaysToExpiry <= warningDays:
+		c.Status = StatusExpiring
+	default:
+		c.Status = StatusOK
+	}
+}
+
+// countStatus adds a certificate to the summary
+func countStatus(summary *Summary, status string) {
+	summary.Total++
+	switch status {
+	case StatusExpired:
+		summary.Expired++
+	case StatusExpiring:
+		summary.Expiring++
+	case StatusNotReady:
+		summary.NotReady++
+	case StatusInvalid:
+		summary.Invalid++
+	}
+}
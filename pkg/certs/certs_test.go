@@ -0,0 +1,169 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// keyMarker is the content of the private keys in the test Secrets
+const keyMarker = "THIS-IS-PRIVATE-KEY-MATERIAL"
+
+// newCertificate returns a base64 PEM self-signed certificate valid until
+// now plus days
+func newCertificate(t *testing.T, commonName string, days int, dnsNames ...string) string {
+	t.Helper()
+	return base64.StdEncoding.EncodeToString(pemCertificate(t, commonName, days, dnsNames...))
+}
+
+func pemCertificate(t *testing.T, commonName string, days int, dnsNames ...string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Example"}},
+		DNSNames:     dnsNames,
+		NotBefore:    now.AddDate(0, 0, -90),
+		NotAfter:     now.Add(time.Duration(days)*24*time.Hour + time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func tlsSecretJSON(namespace, name, crt string) string {
+	key := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte(keyMarker)}))
+	return fmt.Sprintf(`{"metadata": {"name": %q, "namespace": %q}, "type": "kubernetes.io/tls", "data": {"tls.crt": %q, "tls.key": %q}}`,
+		name, namespace, crt, key)
+}
+
+func certRunner(t *testing.T) *k8stest.Runner {
+	chain := base64.StdEncoding.EncodeToString(append(pemCertificate(t, "api.example.com", 300, "api.example.com"), pemCertificate(t, "Example Intermediate", 20)...))
+	secrets := []string{
+		tlsSecretJSON("shop", "web-tls", newCertificate(t, "shop.example.com", 10, "shop.example.com", "www.shop.example.com")),
+		tlsSecretJSON("shop", "old-tls", newCertificate(t, "old.example.com", -3)),
+		tlsSecretJSON("shop", "fresh-tls", newCertificate(t, "fresh.example.com", 200)),
+		tlsSecretJSON("shop", "api-tls", chain),
+		tlsSecretJSON("shop", "broken-tls", base64.StdEncoding.EncodeToString([]byte("not a certificate"))),
+	}
+	return &k8stest.Runner{Outputs: map[string]string{
+		"get secrets -A --field-selector type=kubernetes.io/tls -o json": `{"items": [` + strings.Join(secrets, ",") + `]}`,
+		"get certificates.cert-manager.io -A -o json": `{"items": [{"metadata": {"name": "shop", "namespace": "shop"},
+			"spec": {"secretName": "web-tls", "commonName": "shop.example.com", "dnsNames": ["shop.example.com"], "issuerRef": {"kind": "ClusterIssuer", "name": "letsencrypt"}},
+			"status": {"notAfter": "2026-03-11T12:00:00Z", "conditions": [{"type": "Ready", "status": "False", "reason": "Failed", "message": "ACME challenge failed"}]}}]}`,
+		"get mutatingwebhookconfigurations -o json": fmt.Sprintf(`{"items": [{"metadata": {"name": "injector"},
+			"webhooks": [{"name": "inject.example.com", "clientConfig": {"caBundle": %q}}, {"name": "no-bundle.example.com", "clientConfig": {}}]}]}`,
+			newCertificate(t, "injector-ca", -1)),
+		"get apiservices -o json": fmt.Sprintf(`{"items": [{"metadata": {"name": "v1beta1.metrics.k8s.io"}, "spec": {"caBundle": %q}}, {"metadata": {"name": "v1.apps"}, "spec": {}}]}`,
+			newCertificate(t, "metrics-ca", 3000)),
+	}}
+}
+
+func TestScan(t *testing.T) {
+	report, err := Scan(context.Background(), certRunner(t), nil, Options{Now: now})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := Summary{Total: 8, Expired: 2, Expiring: 2, NotReady: 1, Invalid: 1}
+	if report.Summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, report.Summary)
+	}
+	if len(report.Certificates) != 6 {
+		t.Fatalf("Expected 6 certificates that are not ok, got %+v", report.Certificates)
+	}
+
+	statuses := make(map[string]Certificate)
+	for _, c := range report.Certificates {
+		statuses[c.Source+"/"+c.Name] = c
+	}
+	web := statuses["Secret/web-tls"]
+	if web.Status != StatusExpiring || web.DaysToExpiry != 10 || web.Subject != "CN=shop.example.com,O=Example" ||
+		strings.Join(web.SANs, ",") != "shop.example.com,www.shop.example.com" || web.Field != "tls.crt" {
+		t.Errorf("Unexpected web-tls entry: %+v", web)
+	}
+	if c := statuses["Secret/old-tls"]; c.Status != StatusExpired || c.DaysToExpiry != -3 {
+		t.Errorf("Expected old-tls expired 3 days ago, got %+v", c)
+	}
+	if c := statuses["Secret/api-tls"]; c.Status != StatusExpiring || c.DaysToExpiry != 20 || c.ChainLength != 2 || !strings.Contains(c.Message, "Example Intermediate") {
+		t.Errorf("Expected api-tls to expire with its intermediate in 20 days, got %+v", c)
+	}
+	if c := statuses["Secret/broken-tls"]; c.Status != StatusInvalid || c.Message != "no PEM certificate found" {
+		t.Errorf("Expected broken-tls to be invalid, got %+v", c)
+	}
+	if c := statuses["Certificate/shop"]; c.Status != StatusNotReady || c.Issuer != "ClusterIssuer/letsencrypt" || !strings.Contains(c.Message, "ACME challenge failed") {
+		t.Errorf("Expected the cert-manager Certificate to be not ready, got %+v", c)
+	}
+	if c := statuses["MutatingWebhookConfiguration/injector"]; c.Status != StatusExpired || c.Field != "webhooks[inject.example.com].caBundle" {
+		t.Errorf("Expected the injector CA bundle to be expired, got %+v", c)
+	}
+	if report.Certificates[0].Status != StatusInvalid || report.Certificates[1].Name != "old-tls" {
+		t.Errorf("Expected invalid certificates first, then the soonest expiry, got %+v", report.Certificates[:2])
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Failed to marshal report: %v", err)
+	}
+	for _, leaked := range []string{keyMarker, base64.StdEncoding.EncodeToString([]byte(keyMarker)), "PRIVATE KEY", "BEGIN", "tls.key"} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("Expected the report not to contain %q", leaked)
+		}
+	}
+}
+
+func TestScanShowAllAndWarningDays(t *testing.T) {
+	report, err := Scan(context.Background(), certRunner(t), nil, Options{Now: now, ShowAll: true, WarningDays: 250})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Certificates) != 8 || report.Summary.Expiring != 3 {
+		t.Errorf("Expected all 8 certificates with 3 expiring within 250 days, got %d and %+v", len(report.Certificates), report.Summary)
+	}
+	if _, err := Scan(context.Background(), certRunner(t), nil, Options{Now: now, WarningDays: -1}); err == nil {
+		t.Error("Expected an error for negative warning_days")
+	}
+}
+
+func TestScanWithheldOutputAndMissingCertManager(t *testing.T) {
+	runner := &k8stest.Runner{
+		Outputs: map[string]string{
+			"get secrets -n shop --field-selector type=kubernetes.io/tls -o json": `{"items": [{"data": {"tls.key": "` + keyMarker + `"`,
+		},
+		Errors: map[string]error{
+			"get certificates.cert-manager.io -n shop -o json": fmt.Errorf(`error: the server doesn't have a resource type "certificates"`),
+		},
+	}
+
+	report, err := Scan(context.Background(), runner, nil, Options{Namespace: "shop", Now: now})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	notes := strings.Join(report.Notes, "\n")
+	if strings.Contains(notes, keyMarker) || !strings.Contains(notes, "output withheld") {
+		t.Errorf("Expected the unparseable Secret output to be withheld, got %v", report.Notes)
+	}
+	if !strings.Contains(notes, "cert-manager is not installed") {
+		t.Errorf("Expected a note that cert-manager is not installed, got %v", report.Notes)
+	}
+}
@@ -0,0 +1,72 @@
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"time"
+)
+
+// parseBundle decodes base64 PEM data, as stored in Secret data and
+// caBundle fields, and parses every CERTIFICATE block. Other blocks, such
+// as private keys placed in the wrong key, are skipped without being kept.
+func parseBundle(encoded string) ([]*x509.Certificate, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("data is not valid base64")
+	}
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %v", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+	return certificates, nil
+}
+
+// describe fills the report fields of a parsed certificate
+func describe(info Certificate, certificate *x509.Certificate, now time.Time) Certificate {
+	info.Subject = certificate.Subject.String()
+	info.Issuer = certificate.Issuer.String()
+	info.SANs = sans(certificate)
+	info.IsCA = certificate.IsCA
+	info.NotBefore = certificate.NotBefore.UTC().Format(time.RFC3339)
+	info.NotAfter = certificate.NotAfter.UTC().Format(time.RFC3339)
+	info.DaysToExpiry = daysUntil(certificate.NotAfter, now)
+	if now.Before(certificate.NotBefore) {
+		info.Status = StatusNotYetValid
+	}
+	return info
+}
+
+// sans lists the DNS, IP, URI and e-mail subject alternative names
+func sans(certificate *x509.Certificate) []string {
+	names := append([]string{}, certificate.DNSNames...)
+	for _, ip := range certificate.IPAddresses {
+		names = append(names, ip.String())
+	}
+	for _, uri := range certificate.URIs {
+		names = append(names, uri.String())
+	}
+	return append(names, certificate.EmailAddresses...)
+}
+
+// daysUntil returns the whole days from now until t, negative once t has passed
+func daysUntil(t, now time.Time) int {
+	return int(math.Floor(t.Sub(now).Hours() / 24))
+}
@@ -0,0 +1,40 @@
+package certs
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterCertExpiry registers the cert_expiry tool
+func RegisterCertExpiry() mcp.Tool {
+	return mcp.NewTool("cert_expiry",
+		mcp.WithDescription(`Find expired and soon-to-expire certificates before they cause an outage.
+
+Parses, on the server:
+- kubernetes.io/tls Secrets (tls.crt with its chain, and ca.crt)
+- cert-manager Certificate resources (status, readiness and renewal time), when cert-manager is installed
+- caBundle of MutatingWebhookConfigurations and ValidatingWebhookConfigurations
+- caBundle of APIServices
+
+Reports subject, SANs, issuer, validity and days to expiry, soonest first. Key material and certificate data are never returned.
+Secrets and Certificates are limited to the allowed namespaces.
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("namespace",
+			mcp.Description("Limit Secrets and cert-manager Certificates to this namespace (default: all allowed namespaces)"),
+		),
+		mcp.WithNumber("warning_days",
+			mcp.Description("Report certificates expiring within this many days (default: 30)"),
+		),
+		mcp.WithBoolean("show_all",
+			mcp.Description("Also list certificates that are not expiring (default: false)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Certificate Expiry",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
@@ -15,6 +15,7 @@ type CiliumExecutor struct{}
 
 // This line ensures CiliumExecutor implements the CommandExecutor interface
 var _ tools.CommandExecutor = (*CiliumExecutor)(nil)
+var _ tools.CommandDescriber = (*CiliumExecutor)(nil)
 
 // NewExecutor creates a new CiliumExecutor instance
 func NewExecutor() *CiliumExecutor {
@@ -37,5 +38,12 @@ func (e *CiliumExecutor) Execute(ctx context.Context, params map[string]interfac
 
 	// Execute the command
 	process := command.NewShellProcess("cilium", cfg.Timeout)
+	process.Limiter = cfg.ProcessLimiter
 	return process.Run(ciliumCmd)
 }
+
+// DescribeCommand returns the cilium command a call would run
+func (e *CiliumExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	ciliumCmd, ok := params["command"].(string)
+	return security.CommandTypeCilium, ciliumCmd, ok
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os/exec"
 	"strings"
 	"time"
@@ -10,12 +11,25 @@ import (
 	"github.com/google/shlex"
 )
 
+// Limiter bounds the number of processes running at once. Acquire blocks
+// until a slot is free and returns a function that releases it.
+type Limiter interface {
+	Acquire(ctx context.Context) (func(), error)
+}
+
 // ShellProcess wraps a shell command execution
 type ShellProcess struct {
 	Command         string
 	StripNewlines   bool
 	ReturnErrOutput bool
-	Timeout         int // in seconds
+	// ReturnStdoutOnError returns stdout instead of stderr when a failing
+	// command wrote to stdout, for CLIs that report findings through their
+	// exit code
+	ReturnStdoutOnError bool
+	Timeout             int // in seconds
+	// Limiter, when set, is acquired before the process starts. Time spent
+	// waiting for a slot counts towards Timeout.
+	Limiter Limiter
 }
 
 // NewShellProcess creates a new ShellProcess
@@ -67,6 +81,14 @@ func (s *ShellProcess) Exec(commands string) (string, error) {
 		return "", nil
 	}
 
+	if s.Limiter != nil {
+		release, err := s.Limiter.Acquire(ctx)
+		if err != nil {
+			return "", fmt.Errorf("timed out waiting for a free process slot: %w", err)
+		}
+		defer release()
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -81,6 +103,9 @@ func (s *ShellProcess) Exec(commands string) (string, error) {
 
 	// Handle errors
 	if err != nil {
+		if s.ReturnStdoutOnError && stdout.Len() > 0 {
+			return stdout.String(), nil
+		}
 		if s.ReturnErrOutput && stderr.Len() > 0 {
 			return stderr.String(), nil
 		}
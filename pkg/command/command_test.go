@@ -172,3 +172,21 @@ func TestReturnErrOutput(t *testing.T) {
 		t.Errorf("Expected error when ReturnErrOutput=false, got none")
 	}
 }
+
+func TestReturnStdoutOnError(t *testing.T) {
+	sp := NewShellProcess("sh", 5)
+	sp.ReturnStdoutOnError = true
+	output, err := sp.Exec(`sh -c "echo findings; echo failed >&2; exit 3"`)
+
+	if err != nil {
+		t.Errorf("Expected no error when ReturnStdoutOnError=true, got: %v", err)
+	}
+	if strings.TrimSpace(output) != "findings" {
+		t.Errorf("Expected stdout 'findings', got: %q", output)
+	}
+
+	output, err = sp.Exec(`sh -c "echo failed >&2; exit 3"`)
+	if err != nil || strings.TrimSpace(output) != "failed" {
+		t.Errorf("Expected stderr without stdout, got: %q, %v", output, err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/Azure/mcp-kubernetes/pkg/cache"
+	"github.com/Azure/mcp-kubernetes/pkg/ratelimit"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
 	"github.com/Azure/mcp-kubernetes/pkg/telemetry"
 	"github.com/Azure/mcp-kubernetes/pkg/version"
@@ -17,6 +19,8 @@ import (
 type ConfigData struct {
 	// Map of additional tools enabled
 	AdditionalTools map[string]bool
+	// Plugin descriptor files and directories
+	PluginPaths []string
 	// Command execution timeout in seconds
 	Timeout int
 	// Security configuration
@@ -35,6 +39,15 @@ type ConfigData struct {
 	// Telemetry service
 	TelemetryService telemetry.TelemetryInterface
 
+	// Rate limiting configuration and the limiter built from it (nil when no limits are set)
+	RateLimitConfig *ratelimit.Config
+	RateLimiter     *ratelimit.Limiter
+	// ProcessLimiter caps concurrent CLI subprocesses (nil when --max-processes is not set)
+	ProcessLimiter *ratelimit.ProcessLimiter
+
+	// Response cache for read-only commands (nil when caching is disabled)
+	ResponseCache *cache.Cache
+
 	// UseLegacyTools controls whether to use multiple specialized tools (true) or unified call_kubectl tool (false, default)
 	UseLegacyTools bool
 }
@@ -45,6 +58,7 @@ func NewConfig() *ConfigData {
 		AdditionalTools: make(map[string]bool),
 		Timeout:         60,
 		SecurityConfig:  security.NewSecurityConfig(),
+		RateLimitConfig: ratelimit.NewConfig(),
 		Transport:       "stdio",
 		Port:            8000,
 		AccessLevel:     "readonly",
@@ -63,12 +77,34 @@ func (cfg *ConfigData) ParseFlags() error {
 
 	// Tools configuration
 	additionalTools := flag.String("additional-tools", "",
-		"Comma-separated list of additional tools to support (kubectl is always enabled). Available: helm,cilium,hubble")
+		"Comma-separated list of additional tools to support (kubectl is always enabled). Available: helm,cilium,hubble,flux,argocd,istioctl")
+	plugins := flag.String("plugins", "",
+		"Comma-separated plugin descriptor files or directories of *.yaml descriptors, each registering an additional CLI tool")
 
 	// Security settings
 	flag.StringVar(&cfg.AccessLevel, "access-level", "readonly", "Access level (readonly, readwrite, or admin)")
 	flag.StringVar(&cfg.AllowNamespaces, "allow-namespaces", "",
 		"Comma-separated list of namespaces to allow (empty means all allowed)")
+	redactKeys := flag.String("helm-redact-keys", strings.Join(security.DefaultRedactKeys, ","),
+		"Comma-separated patterns of helm value keys whose values are masked in helm output (empty disables masking)")
+
+	// Rate limiting settings
+	flag.Float64Var(&cfg.RateLimitConfig.Global.Rate, "rate-limit", 0,
+		"Maximum tool calls per second across all clients (0 means unlimited)")
+	flag.IntVar(&cfg.RateLimitConfig.Global.MaxConcurrent, "max-concurrent", 0,
+		"Maximum concurrent tool executions across all clients (0 means unlimited)")
+	flag.Float64Var(&cfg.RateLimitConfig.PerClient.Rate, "client-rate-limit", 0,
+		"Maximum tool calls per second for each client session (0 means unlimited)")
+	flag.IntVar(&cfg.RateLimitConfig.PerClient.MaxConcurrent, "client-max-concurrent", 0,
+		"Maximum concurrent tool executions for each client session (0 means unlimited)")
+	flag.IntVar(&cfg.RateLimitConfig.MaxProcesses, "max-processes", 0,
+		"Maximum concurrent CLI subprocesses across all tool calls, including those started by composite tools (0 means unlimited)")
+	toolLimits := flag.String("tool-limits", "",
+		"Comma-separated per-tool limits as tool=rate[:burst[:concurrency]] (e.g. call_kubectl=5:10:4,call_helm=1)")
+
+	// Response cache settings
+	cacheTTL := flag.String("cache-ttl", "",
+		"Enable the response cache for read-only commands with comma-separated TTLs; a bare duration sets the default and verb=duration overrides it (e.g. 5s,api-resources=5m,logs=0)")
 
 	// OTLP settings
 	flag.StringVar(&cfg.OTLPEndpoint, "otlp-endpoint", "", "OTLP endpoint for OpenTelemetry traces (e.g. localhost:4317, default \"\")")
@@ -99,6 +135,29 @@ func (cfg *ConfigData) ParseFlags() error {
 	if cfg.AllowNamespaces != "" {
 		cfg.SecurityConfig.SetAllowedNamespaces(cfg.AllowNamespaces)
 	}
+	cfg.SecurityConfig.SetRedactKeys(*redactKeys)
+
+	if *toolLimits != "" {
+		limits, err := ratelimit.ParseToolLimits(*toolLimits)
+		if err != nil {
+			return err
+		}
+		cfg.RateLimitConfig.PerTool = limits
+	}
+	if cfg.RateLimitConfig.IsEnabled() {
+		cfg.RateLimiter = ratelimit.NewLimiter(cfg.RateLimitConfig)
+	}
+	if cfg.RateLimitConfig.MaxProcesses > 0 {
+		cfg.ProcessLimiter = ratelimit.NewProcessLimiter(cfg.RateLimitConfig.MaxProcesses)
+	}
+
+	if *cacheTTL != "" {
+		cacheConfig, err := cache.ParseTTLs(*cacheTTL)
+		if err != nil {
+			return err
+		}
+		cfg.ResponseCache = cache.New(cacheConfig, cache.NewKubeconfigIdentityResolver(cfg.Timeout))
+	}
 
 	// Check USE_LEGACY_TOOLS environment variable
 	if os.Getenv("USE_LEGACY_TOOLS") == "true" {
@@ -116,6 +175,13 @@ func (cfg *ConfigData) ParseFlags() error {
 		}
 	}
 
+	// Parse plugin descriptor paths
+	for _, path := range strings.Split(*plugins, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			cfg.PluginPaths = append(cfg.PluginPaths, path)
+		}
+	}
+
 	return nil
 }
 
@@ -140,7 +206,7 @@ func (cfg *ConfigData) InitializeTelemetry(ctx context.Context, serviceName, ser
 	cfg.TelemetryService.TrackServiceStartup(ctx)
 }
 
-var availableTools = []string{"kubectl", "helm", "cilium", "hubble"}
+var availableTools = []string{"kubectl", "helm", "cilium", "hubble", "flux", "argocd", "istioctl"}
 
 // IsToolSupported checks if a tool is supported
 func IsToolSupported(tool string) bool {
@@ -0,0 +1,155 @@
+package diagnose
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// PodExecutor implements the CommandExecutor interface for diagnose_pod
+type PodExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures PodExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*PodExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*PodExecutor)(nil)
+
+// NewPodExecutor creates a new PodExecutor instance
+func NewPodExecutor() *PodExecutor {
+	return &PodExecutor{newRunner: newClientRunner}
+}
+
+// newClientRunner runs commands through the validated kubectl client
+func newClientRunner(cfg *config.ConfigData) k8s.Runner {
+	return k8s.NewClient(cfg)
+}
+
+// Execute collects diagnostics for a pod and returns the report as JSON
+func (e *PodExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	namespace, ok := params["namespace"].(string)
+	if !ok || namespace == "" {
+		return "", fmt.Errorf("namespace parameter is required and must be a string")
+	}
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		return "", fmt.Errorf("name parameter is required and must be a string")
+	}
+	tailLines := defaultLogTailLines
+	if v, ok := params["tail_lines"].(float64); ok && v > 0 {
+		tailLines = int(v)
+	}
+
+	if err := errors.Join(k8s.ValidateNamespace("namespace", namespace), k8s.ValidateName("name", name)); err != nil {
+		return "", err
+	}
+
+	report, err := DiagnosePod(ctx, e.newRunner(cfg), namespace, name, tailLines)
+	if err != nil {
+		return "", err
+	}
+	return marshalReport(report)
+}
+
+// ReturnsStructuredOutput reports that diagnose_pod always returns a JSON report
+func (e *PodExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
+
+// NodeExecutor implements the CommandExecutor interface for diagnose_node
+type NodeExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures NodeExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*NodeExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*NodeExecutor)(nil)
+
+// NewNodeExecutor creates a new NodeExecutor instance
+func NewNodeExecutor() *NodeExecutor {
+	return &NodeExecutor{newRunner: newClientRunner}
+}
+
+// Execute collects diagnostics for a node and returns the report as JSON
+func (e *NodeExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		return "", fmt.Errorf("name parameter is required and must be a string")
+	}
+	opts := NodeOptions{Name: name}
+	opts.CaptureKubeletLogs, _ = params["capture_kubelet_logs"].(bool)
+	opts.DebugNamespace, _ = params["debug_namespace"].(string)
+	if v, ok := params["kubelet_log_lines"].(float64); ok {
+		opts.KubeletLogLines = int(v)
+	}
+	if opts.CaptureKubeletLogs && cfg.SecurityConfig.AccessLevel != security.AccessLevelAdmin {
+		return "", fmt.Errorf("capture_kubelet_logs requires admin access level")
+	}
+
+	if err := errors.Join(k8s.ValidateName("name", name), k8s.ValidateNamespace("debug_namespace", opts.DebugNamespace)); err != nil {
+		return "", err
+	}
+
+	report, err := DiagnoseNode(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	return marshalReport(report)
+}
+
+// ReturnsStructuredOutput reports that diagnose_node always returns a JSON report
+func (e *NodeExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
+
+// StorageExecutor implements the CommandExecutor interface for diagnose_storage
+type StorageExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures StorageExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*StorageExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*StorageExecutor)(nil)
+
+// NewStorageExecutor creates a new StorageExecutor instance
+func NewStorageExecutor() *StorageExecutor {
+	return &StorageExecutor{newRunner: newClientRunner}
+}
+
+// Execute diagnoses the claims of a namespace, or a single claim, and
+// returns the report as JSON
+func (e *StorageExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	var opts StorageOptions
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.PVC, _ = params["pvc"].(string)
+
+	if err := errors.Join(k8s.ValidateNamespace("namespace", opts.Namespace), k8s.ValidateName("pvc", opts.PVC)); err != nil {
+		return "", err
+	}
+
+	report, err := DiagnoseStorage(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	return marshalReport(report)
+}
+
+// ReturnsStructuredOutput reports that diagnose_storage always returns a JSON report
+func (e *StorageExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
+
+// marshalReport renders a report as compact JSON
+func marshalReport(report interface{}) (string, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
+}
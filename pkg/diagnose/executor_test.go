@@ -0,0 +1,41 @@
+package diagnose
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+func TestExecutorsRejectInvalidNames(t *testing.T) {
+	cfg := &config.ConfigData{SecurityConfig: security.NewSecurityConfig()}
+	cfg.SecurityConfig.AccessLevel = security.AccessLevelAdmin
+	newRunner := func(*config.ConfigData) k8s.Runner {
+		return &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: map[string]string{}}
+	}
+
+	tests := []struct {
+		name     string
+		executor tools.CommandExecutor
+		params   map[string]interface{}
+	}{
+		{"pod name", &PodExecutor{newRunner: newRunner}, map[string]interface{}{"namespace": "shop", "name": "web -A"}},
+		{"pod namespace", &PodExecutor{newRunner: newRunner}, map[string]interface{}{"namespace": "shop --raw=/metrics", "name": "web"}},
+		{"node name", &NodeExecutor{newRunner: newRunner}, map[string]interface{}{"name": "node-1 -A"}},
+		{"debug namespace", &NodeExecutor{newRunner: newRunner}, map[string]interface{}{"name": "node-1", "capture_kubelet_logs": true, "debug_namespace": "ops -it"}},
+		{"pvc", &StorageExecutor{newRunner: newRunner}, map[string]interface{}{"namespace": "shop", "pvc": "data -A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.executor.Execute(context.Background(), tt.params, cfg)
+			if err == nil || !strings.Contains(err.Error(), "DNS-1123") {
+				t.Errorf("Expected an invalid name error, got %v", err)
+			}
+		})
+	}
+}
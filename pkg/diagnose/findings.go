@@ -0,0 +1,717 @@
+package diagnose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// Finding severities
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Finding categories
+const (
+	CategoryCrashLoop  = "crash-loop"
+	CategoryOOM        = "oom-killed"
+	CategoryImagePull  = "image-pull"
+	CategoryConfig     = "config"
+	CategoryProbe      = "probe"
+	CategoryScheduling = "scheduling"
+	CategoryStorage    = "storage"
+	CategoryNode       = "node"
+	CategoryEviction   = "eviction"
+	CategoryReadiness  = "readiness"
+	CategoryRollout    = "rollout"
+	CategoryResources  = "resources"
+	CategoryDaemonSet  = "daemonset"
+)
+
+// Finding is a single diagnosed problem
+type Finding struct {
+	Severity  string `json:"severity"`
+	Category  string `json:"category"`
+	Container string `json:"container,omitempty"`
+	Message   string `json:"message"`
+	Evidence  string `json:"evidence,omitempty"`
+}
+
+// severityRank orders findings from most to least severe
+var severityRank = map[string]int{
+	SeverityCritical: 0,
+	SeverityWarning:  1,
+	SeverityInfo:     2,
+}
+
+// imagePullReasons are waiting reasons caused by image pulls
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff":  true,
+	"ErrImagePull":      true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// configReasons are waiting reasons caused by invalid container configuration
+var configReasons = map[string]bool{
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// analyzePod correlates container states, events, node and owner status
+// into findings, most severe first
+func analyzePod(data *podData) []Finding {
+	pod := data.pod
+	var findings []Finding
+	add := func(f Finding) { findings = append(findings, f) }
+
+	if pod.Status.Reason == "Evicted" {
+		add(Finding{Severity: SeverityCritical, Category: CategoryEviction,
+			Message: "Pod was evicted from its node", Evidence: pod.Status.Message})
+	}
+
+	if scheduled := k8s.FindCondition(pod.Status.Conditions, "PodScheduled"); scheduled != nil && scheduled.Status == "False" {
+		add(Finding{Severity: SeverityCritical, Category: CategoryScheduling,
+			Message:  "Pod cannot be scheduled: " + scheduled.Reason,
+			Evidence: firstNonEmpty(scheduled.Message, latestEventMessage(data.events, "FailedScheduling"))})
+	} else if pod.Status.Phase == "Pending" && pod.Spec.NodeName == "" {
+		if message := latestEventMessage(data.events, "FailedScheduling"); message != "" {
+			add(Finding{Severity: SeverityCritical, Category: CategoryScheduling,
+				Message: "Pod cannot be scheduled", Evidence: message})
+		}
+	}
+
+	memoryLimits := make(map[string]string)
+	for _, c := range append(append([]k8s.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		memoryLimits[c.Name] = c.Resources.Limits["memory"]
+	}
+
+	for _, status := range allContainerStatuses(pod) {
+		findings = append(findings, analyzeContainer(status, memoryLimits[status.Name], data)...)
+	}
+
+	findings = append(findings, analyzeProbeEvents(data.events)...)
+
+	if message := latestEventMessage(data.events, "FailedMount", "FailedAttachVolume"); message != "" {
+		add(Finding{Severity: SeverityCritical, Category: CategoryStorage,
+			Message: "Volume could not be mounted", Evidence: message})
+	}
+
+	if data.node != nil {
+		findings = append(findings, analyzeNode(data.node)...)
+	}
+
+	if data.deployment != nil {
+		if progressing := k8s.FindCondition(data.deployment.Status.Conditions, "Progressing"); progressing != nil && progressing.Status == "False" {
+			add(Finding{Severity: SeverityWarning, Category: CategoryRollout,
+				Message:  fmt.Sprintf("Owner %s rollout is stuck: %s", data.owner, progressing.Reason),
+				Evidence: progressing.Message})
+		} else if data.deployment.Status.UnavailableReplicas > 0 {
+			add(Finding{Severity: SeverityInfo, Category: CategoryRollout,
+				Message: fmt.Sprintf("Owner %s has %d unavailable replica(s)", data.owner, data.deployment.Status.UnavailableReplicas)})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return findings
+}
+
+// analyzeContainer diagnoses a single container status
+func analyzeContainer(status k8s.ContainerStatus, memoryLimit string, data *podData) []Finding {
+	var findings []Finding
+	add := func(severity, category, message, evidence string) {
+		findings = append(findings, Finding{Severity: severity, Category: category,
+			Container: status.Name, Message: message, Evidence: evidence})
+	}
+
+	lastTerminated := status.LastState.Terminated
+	if status.State.Terminated != nil {
+		lastTerminated = status.State.Terminated
+	}
+
+	if waiting := status.State.Waiting; waiting != nil {
+		switch {
+		case waiting.Reason == "CrashLoopBackOff":
+			message := fmt.Sprintf("Container is crash-looping (%d restarts)", status.RestartCount)
+			if lastTerminated != nil {
+				message += fmt.Sprintf("; last exit code %d (%s)", lastTerminated.ExitCode, firstNonEmpty(lastTerminated.Reason, "Error"))
+			}
+			evidence := lastLogLine(data.previousLogs[status.Name])
+			add(SeverityCritical, CategoryCrashLoop, message, firstNonEmpty(evidence, waiting.Message))
+		case imagePullReasons[waiting.Reason]:
+			evidence := firstNonEmpty(latestEventMessage(data.events, "Failed"), waiting.Message)
+			add(SeverityCritical, CategoryImagePull,
+				fmt.Sprintf("Image for container cannot be pulled (%s)", waiting.Reason), evidence)
+		case configReasons[waiting.Reason]:
+			add(SeverityCritical, CategoryConfig,
+				fmt.Sprintf("Container cannot be created (%s), often a missing ConfigMap, Secret or key", waiting.Reason), waiting.Message)
+		}
+	}
+
+	if lastTerminated != nil && lastTerminated.Reason == "OOMKilled" {
+		message := "Container was OOMKilled"
+		if memoryLimit != "" {
+			message += "; memory limit is " + memoryLimit
+		} else {
+			message += "; no memory limit is set, so the node ran out of memory"
+		}
+		add(SeverityCritical, CategoryOOM, message, fmt.Sprintf("exit code %d", lastTerminated.ExitCode))
+	} else if status.State.Waiting == nil && lastTerminated != nil && status.RestartCount > 0 && lastTerminated.ExitCode != 0 {
+		add(SeverityWarning, CategoryCrashLoop,
+			fmt.Sprintf("Container restarted %d time(s); last exit code %d (%s)", status.RestartCount, lastTerminated.ExitCode, firstNonEmpty(lastTerminated.Reason, "Error")),
+			lastLogLine(data.previousLogs[status.Name]))
+	}
+
+	if status.State.Running != nil && !status.Ready {
+		add(SeverityWarning, CategoryReadiness, "Container is running but not ready", "")
+	}
+	return findings
+}
+
+// analyzeProbeEvents reports failing liveness, readiness and startup probes
+func analyzeProbeEvents(events []k8s.Event) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event.Reason != "Unhealthy" {
+			continue
+		}
+		probe := "Probe"
+		for _, kind := range []string{"Liveness", "Readiness", "Startup"} {
+			if strings.HasPrefix(event.Message, kind) {
+				probe = kind
+				break
+			}
+		}
+		if seen[probe] {
+			continue
+		}
+		seen[probe] = true
+
+		severity := SeverityWarning
+		if probe == "Liveness" || probe == "Startup" {
+			// Failing liveness and startup probes restart the container
+			severity = SeverityCritical
+		}
+		message := probe + " probe is failing"
+		if event.Count > 1 {
+			message += fmt.Sprintf(" (%d times)", event.Count)
+		}
+		findings = append(findings, Finding{Severity: severity, Category: CategoryProbe,
+			Message: message, Evidence: event.Message})
+	}
+	return findings
+}
+
+// analyzeNode reports node conditions that affect the pod
+func analyzeNode(node *k8s.Node) []Finding {
+	var findings []Finding
+	for _, condition := range node.Status.Conditions {
+		switch {
+		case condition.Type == "Ready" && condition.Status != "True":
+			findings = append(findings, Finding{Severity: SeverityCritical, Category: CategoryNode,
+				Message: fmt.Sprintf("Node %s is not ready", node.Metadata.Name), Evidence: condition.Message})
+		case strings.HasSuffix(condition.Type, "Pressure") && condition.Status == "True":
+			findings = append(findings, Finding{Severity: SeverityWarning, Category: CategoryNode,
+				Message: fmt.Sprintf("Node %s reports %s", node.Metadata.Name, condition.Type), Evidence: condition.Message})
+		case condition.Type == "NetworkUnavailable" && condition.Status == "True":
+			findings = append(findings, Finding{Severity: SeverityCritical, Category: CategoryNode,
+				Message: fmt.Sprintf("Node %s network is unavailable", node.Metadata.Name), Evidence: condition.Message})
+		}
+	}
+	return findings
+}
+
+// nodeEventFindings are node event reasons worth a finding, by severity
+var nodeEventFindings = map[string]string{
+	"SystemOOM":            SeverityCritical,
+	"OOMKilling":           SeverityCritical,
+	"EvictionThresholdMet": SeverityWarning,
+	"FreeDiskSpaceFailed":  SeverityWarning,
+	"ImageGCFailed":        SeverityWarning,
+	"NodeNotReady":         SeverityWarning,
+	"Rebooted":             SeverityWarning,
+}
+
+// analyzeNodeReport correlates node conditions, resource pressure,
+// evictions, DaemonSet gaps and node events into findings, most severe first
+func analyzeNodeReport(report *NodeReport, node *k8s.Node, events []k8s.Event) []Finding {
+	findings := analyzeNode(node)
+	add := func(f Finding) { findings = append(findings, f) }
+
+	if report.Unschedulable {
+		add(Finding{Severity: SeverityInfo, Category: CategoryNode,
+			Message: fmt.Sprintf("Node %s is cordoned", report.Name)})
+	}
+
+	for _, r := range report.Resources {
+		if r.UsagePercent != nil && *r.UsagePercent > 90 {
+			add(Finding{Severity: SeverityWarning, Category: CategoryResources,
+				Message:  fmt.Sprintf("%s usage is at %d%% of allocatable", r.Resource, *r.UsagePercent),
+				Evidence: fmt.Sprintf("usage %s, allocatable %s", r.Usage, r.Allocatable)})
+		}
+		if r.RequestedPercent >= 95 {
+			add(Finding{Severity: SeverityInfo, Category: CategoryResources,
+				Message:  fmt.Sprintf("%s requests are at %d%% of allocatable; new pods may not fit", r.Resource, r.RequestedPercent),
+				Evidence: fmt.Sprintf("requested %s, allocatable %s", r.Requested, r.Allocatable)})
+		}
+		if r.LimitsPercent > 150 {
+			add(Finding{Severity: SeverityWarning, Category: CategoryResources,
+				Message:  fmt.Sprintf("%s limits are overcommitted to %d%% of allocatable", r.Resource, r.LimitsPercent),
+				Evidence: fmt.Sprintf("limits %s, allocatable %s", r.Limits, r.Allocatable)})
+		}
+	}
+	if report.Pods.Allocatable > 0 && report.Pods.Running+report.Pods.Pending >= report.Pods.Allocatable {
+		add(Finding{Severity: SeverityWarning, Category: CategoryResources,
+			Message: fmt.Sprintf("Node runs %d pods, its allocatable maximum", report.Pods.Running+report.Pods.Pending)})
+	}
+
+	if len(report.Evictions) > 0 {
+		add(Finding{Severity: SeverityWarning, Category: CategoryEviction,
+			Message:  fmt.Sprintf("%d pod(s) were evicted from the node", len(report.Evictions)),
+			Evidence: report.Evictions[0].Message})
+	}
+
+	for _, gap := range report.DaemonSetGaps {
+		add(Finding{Severity: SeverityWarning, Category: CategoryDaemonSet,
+			Message: fmt.Sprintf("DaemonSet %s/%s has no ready pod on the node: %s", gap.Namespace, gap.Name, gap.Reason)})
+	}
+
+	seen := make(map[string]bool)
+	for _, event := range summarizeEvents(events) {
+		severity, ok := nodeEventFindings[event.Reason]
+		if !ok || seen[event.Reason] {
+			continue
+		}
+		seen[event.Reason] = true
+		add(Finding{Severity: severity, Category: CategoryNode,
+			Message:  fmt.Sprintf("Node event %s (x%d)", event.Reason, event.Count),
+			Evidence: event.Message})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return findings
+}
+
+// latestEventMessage returns the message of the most recent event with one of the reasons
+func latestEventMessage(events []k8s.Event, reasons ...string) string {
+	var latest *k8s.Event
+	for i := range events {
+		for _, reason := range reasons {
+			if events[i].Reason == reason && (latest == nil || eventTime(events[i]) > eventTime(*latest)) {
+				latest = &events[i]
+			}
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Message
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// analyzeClaim follows a claim to its StorageClass, volume, consumer pods,
+// attachments and CSI driver and explains why it is pending or unusable
+func analyzeClaim(claim k8s.PersistentVolumeClaim, data *storageData) ClaimReport {
+	c := ClaimReport{
+		Namespace:   claim.Metadata.Namespace,
+		Name:        claim.Metadata.Name,
+		Phase:       claim.Status.Phase,
+		Requested:   claim.Spec.Resources.Requests["storage"],
+		Capacity:    claim.Status.Capacity["storage"],
+		AccessModes: claim.Spec.AccessModes,
+	}
+	add := func(severity, category, message, evidence string) {
+		c.Findings = append(c.Findings, Finding{Severity: severity, Category: category, Message: message, Evidence: evidence})
+	}
+
+	// StorageClass: nil uses the default class, "" disables dynamic provisioning
+	var class *k8s.StorageClass
+	c.StorageClass = data.defaultClass
+	if claim.Spec.StorageClassName != nil {
+		c.StorageClass = *claim.Spec.StorageClassName
+	}
+	if c.StorageClass != "" {
+		if sc, ok := data.classes[c.StorageClass]; ok {
+			class = &sc
+			c.Provisioner = sc.Provisioner
+			c.BindingMode = firstNonEmpty(sc.VolumeBindingMode, "Immediate")
+		} else if len(data.classes) > 0 {
+			add(SeverityCritical, CategoryStorage, fmt.Sprintf("StorageClass %s does not exist", c.StorageClass), "")
+		}
+	}
+
+	// Consumers and their events
+	podNames := map[string]bool{}
+	for _, pod := range data.pods {
+		if pod.Metadata.Namespace != claim.Metadata.Namespace || !mountsClaim(pod, claim.Metadata.Name) {
+			continue
+		}
+		podNames[pod.Metadata.Name] = true
+		consumer := ConsumerPod{Name: pod.Metadata.Name, Phase: pod.Status.Phase, Node: pod.Spec.NodeName}
+		if node, ok := data.nodes[pod.Spec.NodeName]; ok {
+			consumer.Zone = nodeZone(node)
+		}
+		c.Consumers = append(c.Consumers, consumer)
+	}
+	var events []k8s.Event
+	for _, event := range data.events {
+		object := event.InvolvedObject
+		if object.Namespace != claim.Metadata.Namespace && event.Metadata.Namespace != claim.Metadata.Namespace {
+			continue
+		}
+		if object.Kind == "PersistentVolumeClaim" && object.Name == claim.Metadata.Name || object.Kind == "Pod" && podNames[object.Name] {
+			events = append(events, event)
+		}
+	}
+	c.Events = summarizeEvents(events)
+
+	if claim.Status.Phase == "Pending" {
+		analyzePendingClaim(&c, claim, class, data, events, add)
+	}
+	if claim.Status.Phase == "Lost" {
+		add(SeverityCritical, CategoryStorage, fmt.Sprintf("Claim lost its volume %s", claim.Spec.VolumeName),
+			"the PersistentVolume was deleted or its claimRef changed; data may be unrecoverable")
+	}
+
+	if pv, ok := data.volumes[claim.Spec.VolumeName]; ok && claim.Spec.VolumeName != "" {
+		c.Volume = &VolumeReport{
+			Name:          pv.Metadata.Name,
+			Phase:         pv.Status.Phase,
+			Capacity:      pv.Spec.Capacity["storage"],
+			AccessModes:   pv.Spec.AccessModes,
+			ReclaimPolicy: pv.Spec.PersistentVolumeReclaimPolicy,
+		}
+		if pv.Spec.CSI != nil {
+			c.Volume.CSIDriver = pv.Spec.CSI.Driver
+		}
+		if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+			c.Volume.Zones = selectorZones(*pv.Spec.NodeAffinity.Required)
+		}
+		analyzeBoundVolume(&c, claim, pv, class, data, add)
+	} else if claim.Spec.VolumeName != "" && claim.Status.Phase != "Lost" && len(data.volumes) > 0 {
+		add(SeverityCritical, CategoryStorage, fmt.Sprintf("Bound volume %s does not exist", claim.Spec.VolumeName), "")
+	}
+
+	if message := latestEventMessage(events, "FailedAttachVolume"); message != "" {
+		add(SeverityCritical, CategoryStorage, "Volume could not be attached", message)
+	}
+	if message := latestEventMessage(events, "FailedMount", "FailedMapVolume"); message != "" {
+		add(SeverityCritical, CategoryStorage, "Volume could not be mounted", message)
+	}
+	if message := latestEventMessage(events, "VolumeResizeFailed", "FileSystemResizeFailed"); message != "" {
+		add(SeverityWarning, CategoryStorage, "Volume expansion failed", message)
+	}
+
+	sort.SliceStable(c.Findings, func(i, j int) bool {
+		return severityRank[c.Findings[i].Severity] < severityRank[c.Findings[j].Severity]
+	})
+	if c.Findings == nil {
+		c.Findings = []Finding{}
+	}
+	return c
+}
+
+// analyzePendingClaim explains why a claim is not bound: the binding mode,
+// provisioning failures, a missing CSI driver, or why no pre-provisioned
+// volume matches
+func analyzePendingClaim(c *ClaimReport, claim k8s.PersistentVolumeClaim, class *k8s.StorageClass, data *storageData, events []k8s.Event, add func(severity, category, message, evidence string)) {
+	if class != nil && class.VolumeBindingMode == "WaitForFirstConsumer" {
+		if len(c.Consumers) == 0 {
+			add(SeverityInfo, CategoryStorage,
+				fmt.Sprintf("StorageClass %s uses WaitForFirstConsumer: the volume is provisioned once a pod using the claim is scheduled, and no pod uses it yet", class.Metadata.Name), "")
+		}
+		for _, consumer := range c.Consumers {
+			if consumer.Node != "" {
+				continue
+			}
+			message := latestEventMessage(events, "FailedScheduling")
+			add(SeverityCritical, CategoryScheduling,
+				fmt.Sprintf("Pod %s using the claim cannot be scheduled, so the volume is not provisioned (WaitForFirstConsumer)", consumer.Name),
+				message)
+			if class.AllowedTopologies != nil && strings.Contains(message, "node(s) didn't match") {
+				add(SeverityWarning, CategoryScheduling, "StorageClass allowedTopologies may exclude the nodes the pod can run on",
+					topologySummary(class.AllowedTopologies))
+			}
+		}
+	}
+
+	if message := latestEventMessage(events, "ProvisioningFailed"); message != "" {
+		add(SeverityCritical, CategoryStorage, "Volume provisioning failed", message)
+	} else if message := latestEventMessage(events, "ExternalProvisioning"); message != "" && class != nil {
+		add(SeverityWarning, CategoryStorage, fmt.Sprintf("Waiting for the external provisioner %s", class.Provisioner), message)
+	}
+	if class != nil && isCSIProvisioner(class.Provisioner) && len(data.csiDrivers) > 0 && !data.csiDrivers[class.Provisioner] {
+		add(SeverityCritical, CategoryStorage, fmt.Sprintf("CSI driver %s of StorageClass %s is not installed", class.Provisioner, class.Metadata.Name),
+			"no CSIDriver object with this name exists")
+	}
+
+	// Without a provisioner the claim can only bind a pre-provisioned volume
+	if class == nil || class.Provisioner == "kubernetes.io/no-provisioner" || claim.Spec.VolumeName != "" {
+		explainStaticBinding(c, claim, data, add)
+	}
+}
+
+// explainStaticBinding explains why no pre-provisioned volume can bind the
+// claim: capacity, access modes, volume mode or StorageClass mismatches
+func explainStaticBinding(c *ClaimReport, claim k8s.PersistentVolumeClaim, data *storageData, add func(severity, category, message, evidence string)) {
+	var reasons []string
+	names := make([]string, 0, len(data.volumes))
+	for name := range data.volumes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pv := data.volumes[name]
+		if claim.Spec.VolumeName != "" && name != claim.Spec.VolumeName {
+			continue
+		}
+		if pv.Status.Phase != "Available" || pv.Spec.StorageClassName != c.StorageClass {
+			if claim.Spec.VolumeName != "" {
+				reasons = append(reasons, fmt.Sprintf("%s: phase %s, storageClassName %q", name, pv.Status.Phase, pv.Spec.StorageClassName))
+			}
+			continue
+		}
+		if mismatch := volumeMismatch(claim, pv); mismatch != "" {
+			reasons = append(reasons, name+": "+mismatch)
+			continue
+		}
+		return
+	}
+	evidence := fmt.Sprintf("no Available PersistentVolume with storageClassName %q", c.StorageClass)
+	if len(reasons) > maxCandidateVolumes {
+		reasons = reasons[:maxCandidateVolumes]
+	}
+	if len(reasons) > 0 {
+		evidence = strings.Join(reasons, "; ")
+	}
+	add(SeverityCritical, CategoryStorage, "No PersistentVolume can bind the claim", evidence)
+}
+
+// volumeMismatch explains why a volume cannot satisfy a claim
+func volumeMismatch(claim k8s.PersistentVolumeClaim, pv k8s.PersistentVolume) string {
+	var problems []string
+	if requested, capacity := k8s.Bytes(claim.Spec.Resources.Requests["storage"]), k8s.Bytes(pv.Spec.Capacity["storage"]); capacity < requested {
+		problems = append(problems, fmt.Sprintf("capacity %s is smaller than the requested %s", pv.Spec.Capacity["storage"], claim.Spec.Resources.Requests["storage"]))
+	}
+	for _, mode := range claim.Spec.AccessModes {
+		if !contains(pv.Spec.AccessModes, mode) {
+			problems = append(problems, fmt.Sprintf("access modes %s do not include %s", strings.Join(pv.Spec.AccessModes, ","), mode))
+		}
+	}
+	if firstNonEmpty(claim.Spec.VolumeMode, "Filesystem") != firstNonEmpty(pv.Spec.VolumeMode, "Filesystem") {
+		problems = append(problems, fmt.Sprintf("volume mode %s does not match %s", firstNonEmpty(pv.Spec.VolumeMode, "Filesystem"), firstNonEmpty(claim.Spec.VolumeMode, "Filesystem")))
+	}
+	return strings.Join(problems, ", ")
+}
+
+// analyzeBoundVolume checks the volume of a bound claim: its phase,
+// capacity, topology against the consumers' nodes, concurrent use of a
+// ReadWriteOnce volume, attachments and the CSI node plugin
+func analyzeBoundVolume(c *ClaimReport, claim k8s.PersistentVolumeClaim, pv k8s.PersistentVolume, class *k8s.StorageClass, data *storageData, add func(severity, category, message, evidence string)) {
+	if pv.Status.Phase == "Failed" {
+		add(SeverityCritical, CategoryStorage, fmt.Sprintf("Volume %s failed", pv.Metadata.Name), pv.Status.Message)
+	}
+	if requested, capacity := k8s.Bytes(c.Requested), k8s.Bytes(c.Capacity); capacity > 0 && requested > capacity {
+		switch {
+		case class != nil && (class.AllowVolumeExpansion == nil || !*class.AllowVolumeExpansion):
+			add(SeverityWarning, CategoryStorage, fmt.Sprintf("Claim requests %s but has %s, and StorageClass %s does not allow volume expansion", c.Requested, c.Capacity, class.Metadata.Name), "")
+		case k8s.FindCondition(claim.Status.Conditions, "FileSystemResizePending") != nil:
+			add(SeverityInfo, CategoryStorage, fmt.Sprintf("Expansion to %s is waiting for a pod to mount the volume", c.Requested), "")
+		default:
+			add(SeverityInfo, CategoryStorage, fmt.Sprintf("Expansion from %s to %s is in progress", c.Capacity, c.Requested), "")
+		}
+	}
+
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		selector := *pv.Spec.NodeAffinity.Required
+		for _, consumer := range c.Consumers {
+			if node, ok := data.nodes[consumer.Node]; ok && !selector.Matches(node) {
+				add(SeverityCritical, CategoryScheduling,
+					fmt.Sprintf("Pod %s runs on node %s (zone %s), outside the volume's node affinity", consumer.Name, consumer.Node, firstNonEmpty(consumer.Zone, "unknown")),
+					"volume zones: "+strings.Join(c.Volume.Zones, ", "))
+			}
+		}
+		eligible := 0
+		for _, node := range data.nodes {
+			if selector.Matches(node) && nodeReady(node) && !node.Spec.Unschedulable {
+				eligible++
+			}
+		}
+		if eligible == 0 && len(data.nodes) > 0 {
+			add(SeverityCritical, CategoryScheduling, "No ready, schedulable node satisfies the volume's node affinity, so its pods cannot be scheduled",
+				"volume zones: "+firstNonEmpty(strings.Join(c.Volume.Zones, ", "), "node affinity "+nodeSelectorSummary(selector)))
+		}
+	}
+
+	nodes := map[string]bool{}
+	for _, consumer := range c.Consumers {
+		if consumer.Node != "" && consumer.Phase != "Succeeded" && consumer.Phase != "Failed" {
+			nodes[consumer.Node] = true
+		}
+	}
+	if len(nodes) > 1 && (contains(pv.Spec.AccessModes, "ReadWriteOnce") || contains(pv.Spec.AccessModes, "ReadWriteOncePod")) &&
+		!contains(pv.Spec.AccessModes, "ReadWriteMany") && !contains(pv.Spec.AccessModes, "ReadOnlyMany") {
+		add(SeverityCritical, CategoryStorage,
+			fmt.Sprintf("%s volume is used by pods on %d nodes; it can only be attached to one node at a time", strings.Join(pv.Spec.AccessModes, ","), len(nodes)),
+			strings.Join(sortedKeys(nodes), ", "))
+	}
+
+	for _, attachment := range data.attachments {
+		if attachment.Spec.Source.PersistentVolumeName != pv.Metadata.Name {
+			continue
+		}
+		report := AttachmentReport{Name: attachment.Metadata.Name, Node: attachment.Spec.NodeName, Attached: attachment.Status.Attached}
+		switch {
+		case attachment.Status.AttachError != nil:
+			report.Error = attachment.Status.AttachError.Message
+			add(SeverityCritical, CategoryStorage, fmt.Sprintf("Attaching the volume to node %s failed", attachment.Spec.NodeName), report.Error)
+		case attachment.Status.DetachError != nil:
+			report.Error = attachment.Status.DetachError.Message
+			add(SeverityWarning, CategoryStorage, fmt.Sprintf("Detaching the volume from node %s failed", attachment.Spec.NodeName), report.Error)
+		case attachment.Status.Attached && len(nodes) > 0 && !nodes[attachment.Spec.NodeName] && !contains(pv.Spec.AccessModes, "ReadWriteMany"):
+			add(SeverityWarning, CategoryStorage,
+				fmt.Sprintf("Volume is still attached to node %s, where no pod uses it; this blocks attaching it elsewhere", attachment.Spec.NodeName), attachment.Metadata.Name)
+		}
+		c.Attachments = append(c.Attachments, report)
+	}
+
+	if pv.Spec.CSI == nil {
+		return
+	}
+	driver := pv.Spec.CSI.Driver
+	if len(data.csiDrivers) > 0 && !data.csiDrivers[driver] {
+		add(SeverityCritical, CategoryStorage, fmt.Sprintf("CSI driver %s of the volume is not installed", driver), "no CSIDriver object with this name exists")
+	}
+	for _, node := range sortedKeys(nodes) {
+		csiNode, ok := data.csiNodes[node]
+		if !ok {
+			continue
+		}
+		registered := false
+		for _, d := range csiNode.Spec.Drivers {
+			registered = registered || d.Name == driver
+		}
+		if !registered {
+			add(SeverityCritical, CategoryStorage, fmt.Sprintf("CSI driver %s is not registered on node %s; its node plugin is not running there", driver, node), "")
+		}
+	}
+}
+
+// mountsClaim reports whether a pod mounts the claim
+func mountsClaim(pod k8s.Pod, claim string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claim {
+			return true
+		}
+	}
+	return false
+}
+
+// isCSIProvisioner reports whether a provisioner is a CSI driver rather
+// than an in-tree or legacy external provisioner
+func isCSIProvisioner(provisioner string) bool {
+	return strings.Contains(provisioner, ".") && !strings.HasPrefix(provisioner, "kubernetes.io/")
+}
+
+// nodeZone returns the zone label of a node
+func nodeZone(node k8s.Node) string {
+	for _, label := range zoneLabels {
+		if zone := node.Metadata.Labels[label]; zone != "" {
+			return zone
+		}
+	}
+	return ""
+}
+
+// nodeReady reports whether a node's Ready condition is True
+func nodeReady(node k8s.Node) bool {
+	ready := k8s.FindCondition(node.Status.Conditions, "Ready")
+	return ready != nil && ready.Status == "True"
+}
+
+// selectorZones lists the zones named by a volume's node affinity,
+// including CSI topology keys such as topology.disk.csi.azure.com/zone
+func selectorZones(selector k8s.NodeSelector) []string {
+	var zones []string
+	for _, term := range selector.NodeSelectorTerms {
+		for _, req := range term.MatchExpressions {
+			if req.Operator == "In" && strings.HasSuffix(req.Key, "/zone") {
+				for _, zone := range req.Values {
+					if !contains(zones, zone) {
+						zones = append(zones, zone)
+					}
+				}
+			}
+		}
+	}
+	return zones
+}
+
+// nodeSelectorSummary renders a node selector, e.g. "kubernetes.io/hostname In node-1"
+func nodeSelectorSummary(selector k8s.NodeSelector) string {
+	var terms []string
+	for _, term := range selector.NodeSelectorTerms {
+		var reqs []string
+		for _, req := range append(append([]k8s.NodeSelectorRequirement{}, term.MatchExpressions...), term.MatchFields...) {
+			reqs = append(reqs, strings.TrimSpace(req.Key+" "+req.Operator+" "+strings.Join(req.Values, ",")))
+		}
+		terms = append(terms, strings.Join(reqs, " and "))
+	}
+	return strings.Join(terms, " or ")
+}
+
+// topologySummary renders StorageClass allowedTopologies
+func topologySummary(terms []k8s.TopologySelectorTerm) string {
+	var parts []string
+	for _, term := range terms {
+		for _, expression := range term.MatchLabelExpressions {
+			parts = append(parts, expression.Key+" in "+strings.Join(expression.Values, ","))
+		}
+	}
+	return "allowedTopologies: " + strings.Join(parts, "; ")
+}
+
+// contains reports whether values includes value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
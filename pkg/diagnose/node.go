@@ -0,0 +1,483 @@
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const (
+	// defaultDebugNamespace is where the node debugging pod is created
+	defaultDebugNamespace = "default"
+	// defaultKubeletLogLines is the number of kubelet journal lines captured
+	defaultKubeletLogLines = 200
+	// maxTolerationsListed caps the tolerating pods listed per taint
+	maxTolerationsListed = 10
+)
+
+// NodeOptions are the parameters of diagnose_node
+type NodeOptions struct {
+	Name string
+	// CaptureKubeletLogs runs "kubectl debug node/<name>" to read the
+	// kubelet journal; the validator only allows it at the admin level
+	CaptureKubeletLogs bool
+	DebugNamespace     string
+	KubeletLogLines    int
+}
+
+// NodeReport is the structured result of diagnose_node
+type NodeReport struct {
+	Name             string          `json:"name"`
+	Ready            bool            `json:"ready"`
+	Unschedulable    bool            `json:"unschedulable,omitempty"`
+	KubeletVersion   string          `json:"kubeletVersion,omitempty"`
+	ContainerRuntime string          `json:"containerRuntime,omitempty"`
+	OSImage          string          `json:"osImage,omitempty"`
+	Conditions       []NodeCondition `json:"conditions"`
+	Resources        []ResourceUsage `json:"resources"`
+	Pods             PodCounts       `json:"pods"`
+	Taints           []TaintReport   `json:"taints,omitempty"`
+	Evictions        []Eviction      `json:"evictions,omitempty"`
+	DaemonSetGaps    []DaemonSetGap  `json:"daemonSetGaps,omitempty"`
+	Findings         []Finding       `json:"findings"`
+	Events           []EventSummary  `json:"events,omitempty"`
+	KubeletLogs      *KubeletLogs    `json:"kubeletLogs,omitempty"`
+	// CollectionErrors lists data that could not be gathered (denied by the
+	// security configuration, metrics-server not installed, ...)
+	CollectionErrors []string `json:"collectionErrors,omitempty"`
+}
+
+// NodeCondition is a node condition
+type NodeCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Since   string `json:"since,omitempty"`
+}
+
+// ResourceUsage compares a node's allocatable CPU or memory with the
+// requests and limits of its pods and the usage reported by metrics-server
+type ResourceUsage struct {
+	Resource         string `json:"resource"`
+	Capacity         string `json:"capacity,omitempty"`
+	Allocatable      string `json:"allocatable"`
+	Requested        string `json:"requested"`
+	RequestedPercent int    `json:"requestedPercent"`
+	Limits           string `json:"limits"`
+	LimitsPercent    int    `json:"limitsPercent"`
+	Usage            string `json:"usage,omitempty"`
+	UsagePercent     *int   `json:"usagePercent,omitempty"`
+}
+
+// PodCounts counts the pods on the node by phase
+type PodCounts struct {
+	Total       int `json:"total"`
+	Allocatable int `json:"allocatable,omitempty"`
+	Running     int `json:"running"`
+	Pending     int `json:"pending"`
+	Failed      int `json:"failed"`
+	Succeeded   int `json:"succeeded"`
+}
+
+// TaintReport is a node taint and the pods on the node that tolerate it
+type TaintReport struct {
+	Key            string   `json:"key"`
+	Value          string   `json:"value,omitempty"`
+	Effect         string   `json:"effect"`
+	ToleratedBy    int      `json:"toleratedBy"`
+	ToleratingPods []string `json:"toleratingPods,omitempty"`
+}
+
+// Eviction is a pod evicted from the node
+type Eviction struct {
+	Pod     string `json:"pod"`
+	Message string `json:"message,omitempty"`
+}
+
+// DaemonSetGap is a DaemonSet that should run on the node but has no
+// ready pod there
+type DaemonSetGap struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Pod is the DaemonSet's pod on the node, if any
+	Pod    string `json:"pod,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// KubeletLogs is the kubelet journal captured through a node debugging pod
+type KubeletLogs struct {
+	DebugPods []string `json:"debugPods,omitempty"`
+	Namespace string   `json:"namespace"`
+	Output    string   `json:"output,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	// Cleanup reports whether the debugging pods were deleted
+	Cleanup string `json:"cleanup,omitempty"`
+}
+
+// daemonSet is the subset of a DaemonSet used to find coverage gaps
+type daemonSet struct {
+	Metadata k8s.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Template struct {
+			Spec k8s.PodSpec `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+// nodeData is everything collected for a node
+type nodeData struct {
+	node        k8s.Node
+	pods        []k8s.Pod
+	daemonSets  []daemonSet
+	events      []k8s.Event
+	usage       map[string]string
+	kubeletLogs *KubeletLogs
+	restricted  bool
+	errors      []string
+}
+
+// DiagnoseNode collects the node, its pods, events, usage and the
+// DaemonSets of the allowed namespaces in parallel, optionally captures
+// the kubelet journal, and correlates them into a findings report
+func DiagnoseNode(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts NodeOptions) (*NodeReport, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if opts.DebugNamespace == "" {
+		opts.DebugNamespace = defaultDebugNamespace
+	}
+	switch {
+	case opts.KubeletLogLines == 0:
+		opts.KubeletLogLines = defaultKubeletLogLines
+	case opts.KubeletLogLines < 0 || opts.KubeletLogLines > security.MaxKubeletLogLines:
+		return nil, fmt.Errorf("kubelet_log_lines must be between 1 and %d", security.MaxKubeletLogLines)
+	}
+
+	data := &nodeData{restricted: secConfig != nil && secConfig.HasNamespaceRestrictions()}
+	if err := k8s.GetJSON(ctx, runner, "get node "+opts.Name, &data.node); err != nil {
+		return nil, err
+	}
+	namespaces, err := k8s.ScanNamespaces(ctx, runner, secConfig, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	addError := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		data.errors = append(data.errors, fmt.Sprintf(format, args...))
+	}
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		for _, namespace := range namespaces {
+			var pods k8s.List[k8s.Pod]
+			command := fmt.Sprintf("get pods %s --field-selector spec.nodeName=%s", namespaceScope(namespace), opts.Name)
+			if err := k8s.GetJSON(ctx, runner, command, &pods); err != nil {
+				addError("pods in %s: %v", namespace, err)
+				continue
+			}
+			data.pods = append(data.pods, pods.Items...)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, namespace := range namespaces {
+			var daemonSets k8s.List[daemonSet]
+			if err := k8s.GetJSON(ctx, runner, "get daemonsets.apps "+namespaceScope(namespace), &daemonSets); err != nil {
+				addError("daemonsets in %s: %v", namespace, err)
+				continue
+			}
+			data.daemonSets = append(data.daemonSets, daemonSets.Items...)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		events, err := k8s.NodeEvents(ctx, runner, secConfig, opts.Name)
+		if err != nil {
+			addError("events: %v", err)
+			return
+		}
+		data.events = events
+	}()
+	go func() {
+		defer wg.Done()
+		usage, err := nodeUsage(ctx, runner, opts.Name)
+		if err != nil {
+			addError("usage (kubectl top node): %v", err)
+			return
+		}
+		data.usage = usage
+	}()
+	if opts.CaptureKubeletLogs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data.kubeletLogs = captureKubeletLogs(ctx, runner, opts)
+		}()
+	}
+	wg.Wait()
+
+	if data.restricted {
+		data.errors = append(data.errors, "Pods and DaemonSets are only included from the allowed namespaces, so requests may be understated")
+	}
+	return buildNodeReport(data), nil
+}
+
+// namespaceScope returns the kubectl flag that selects a scanned namespace
+func namespaceScope(namespace string) string {
+	if namespace == k8s.AllNamespaces {
+		return "-A"
+	}
+	return "-n " + namespace
+}
+
+// nodeUsage reads the CPU and memory usage of a node from metrics-server
+func nodeUsage(ctx context.Context, runner k8s.Runner, name string) (map[string]string, error) {
+	output, err := runner.Run(ctx, "top node "+name+" --no-headers")
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(output)
+	if len(fields) < 4 || fields[0] != name {
+		return nil, fmt.Errorf("unexpected output: %s", strings.TrimSpace(output))
+	}
+	return map[string]string{"cpu": fields[1], "memory": fields[3]}, nil
+}
+
+// captureKubeletLogs reads the kubelet journal from a node debugging pod
+// and deletes the pod afterwards
+func captureKubeletLogs(ctx context.Context, runner k8s.Runner, opts NodeOptions) *KubeletLogs {
+	logs := &KubeletLogs{Namespace: opts.DebugNamespace}
+	capture, ok := runner.(k8s.KubeletLogRunner)
+	if !ok {
+		logs.Error = "kubelet log capture is not supported by this runner"
+		return logs
+	}
+	output, err := capture.RunKubeletLogs(ctx, opts.Name, opts.DebugNamespace, opts.KubeletLogLines)
+	logs.Output = output
+	if err != nil {
+		logs.Error = err.Error()
+	}
+	logs.DebugPods, logs.Cleanup = deleteDebugPods(ctx, runner, opts.Name, opts.DebugNamespace)
+	return logs
+}
+
+// deleteDebugPods deletes the debugging pods kubectl debug created for a
+// node. kubectl names the pod only on stderr, which the runner does not
+// return, so the pods are found by the node-debugger-<node>- name prefix
+// among the pods on the node. This also removes pods left behind by earlier
+// captures that were interrupted.
+func deleteDebugPods(ctx context.Context, runner k8s.Runner, node, namespace string) ([]string, string) {
+	prefix := "node-debugger-" + node + "-"
+	var pods k8s.List[k8s.Pod]
+	if err := k8s.GetJSON(ctx, runner, fmt.Sprintf("get pods -n %s --field-selector spec.nodeName=%s", namespace, node), &pods); err != nil {
+		return nil, fmt.Sprintf("failed to list debugging pods, check for %s* pods in %s: %v", prefix, namespace, err)
+	}
+	var names []string
+	for _, pod := range pods.Items {
+		if strings.HasPrefix(pod.Metadata.Name, prefix) {
+			names = append(names, pod.Metadata.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Sprintf("no %s* pods found in %s", prefix, namespace)
+	}
+	if _, err := runner.Run(ctx, fmt.Sprintf("delete pod %s -n %s --wait=false", strings.Join(names, " "), namespace)); err != nil {
+		return names, fmt.Sprintf("failed to delete %s: %v", strings.Join(names, ", "), err)
+	}
+	return names, "deleted " + strings.Join(names, ", ")
+}
+
+// buildNodeReport assembles the report and runs the analysis
+func buildNodeReport(data *nodeData) *NodeReport {
+	node := data.node
+	report := &NodeReport{
+		Name:             node.Metadata.Name,
+		Unschedulable:    node.Spec.Unschedulable,
+		KubeletVersion:   node.Status.NodeInfo.KubeletVersion,
+		ContainerRuntime: node.Status.NodeInfo.ContainerRuntimeVersion,
+		OSImage:          node.Status.NodeInfo.OSImage,
+		Conditions:       []NodeCondition{},
+		Events:           summarizeEvents(data.events),
+		KubeletLogs:      data.kubeletLogs,
+		CollectionErrors: data.errors,
+	}
+	for _, c := range node.Status.Conditions {
+		report.Conditions = append(report.Conditions, NodeCondition{
+			Type: c.Type, Status: c.Status, Reason: c.Reason, Message: c.Message, Since: c.LastTransitionTime,
+		})
+		if c.Type == "Ready" {
+			report.Ready = c.Status == "True"
+		}
+	}
+
+	sort.Slice(data.pods, func(i, j int) bool { return podKey(data.pods[i]) < podKey(data.pods[j]) })
+	active := make([]k8s.Pod, 0, len(data.pods))
+	for _, pod := range data.pods {
+		report.Pods.Total++
+		switch pod.Status.Phase {
+		case "Running":
+			report.Pods.Running++
+		case "Pending":
+			report.Pods.Pending++
+		case "Failed":
+			report.Pods.Failed++
+		case "Succeeded":
+			report.Pods.Succeeded++
+		}
+		if pod.Status.Phase == "Failed" && pod.Status.Reason == "Evicted" {
+			report.Evictions = append(report.Evictions, Eviction{Pod: podKey(pod), Message: pod.Status.Message})
+		}
+		if pod.Status.Phase != "Succeeded" && pod.Status.Phase != "Failed" {
+			active = append(active, pod)
+		}
+	}
+	if pods, err := k8s.ParseQuantity(node.Status.Allocatable["pods"]); err == nil {
+		report.Pods.Allocatable = int(pods)
+	}
+	report.Resources = resourceUsage(node, active, data.usage)
+	report.Taints = taintReports(node, active)
+	report.DaemonSetGaps = daemonSetGaps(node, data.daemonSets, data.pods)
+	report.Findings = analyzeNodeReport(report, &node, data.events)
+	sort.Strings(report.CollectionErrors)
+	return report
+}
+
+// resourceUsage sums the effective requests and limits of the active pods
+// for CPU and memory. A pod's effective request is the larger of the sum of
+// its containers and its largest init container, as in the scheduler.
+func resourceUsage(node k8s.Node, pods []k8s.Pod, usage map[string]string) []ResourceUsage {
+	var resources []ResourceUsage
+	for _, resource := range []string{"cpu", "memory"} {
+		parse, format := k8s.Bytes, k8s.FormatBytes
+		if resource == "cpu" {
+			parse, format = k8s.MilliCPU, k8s.FormatMilliCPU
+		}
+		var requested, limits int64
+		for _, pod := range pods {
+			requested += effective(pod, func(r k8s.ResourceRequirements) int64 { return parse(r.Requests[resource]) })
+			limits += effective(pod, func(r k8s.ResourceRequirements) int64 { return parse(r.Limits[resource]) })
+		}
+		allocatable := parse(node.Status.Allocatable[resource])
+		r := ResourceUsage{
+			Resource:         resource,
+			Allocatable:      format(allocatable),
+			Requested:        format(requested),
+			RequestedPercent: percent(requested, allocatable),
+			Limits:           format(limits),
+			LimitsPercent:    percent(limits, allocatable),
+		}
+		if capacity := node.Status.Capacity[resource]; capacity != "" {
+			r.Capacity = format(parse(capacity))
+		}
+		if used, ok := usage[resource]; ok {
+			r.Usage = used
+			p := percent(parse(used), allocatable)
+			r.UsagePercent = &p
+		}
+		resources = append(resources, r)
+	}
+	return resources
+}
+
+// effective returns a pod's effective amount of a resource
+func effective(pod k8s.Pod, amount func(k8s.ResourceRequirements) int64) int64 {
+	var sum, init int64
+	for _, c := range pod.Spec.Containers {
+		sum += amount(c.Resources)
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if a := amount(c.Resources); a > init {
+			init = a
+		}
+	}
+	if init > sum {
+		return init
+	}
+	return sum
+}
+
+// percent returns part as a percentage of total, or 0 when total is unknown
+func percent(part, total int64) int {
+	if total <= 0 {
+		return 0
+	}
+	return int(part * 100 / total)
+}
+
+// taintReports lists the node's taints and the active pods tolerating them
+func taintReports(node k8s.Node, pods []k8s.Pod) []TaintReport {
+	var reports []TaintReport
+	for _, taint := range node.Spec.Taints {
+		r := TaintReport{Key: taint.Key, Value: taint.Value, Effect: taint.Effect}
+		for _, pod := range pods {
+			for _, toleration := range pod.Spec.Tolerations {
+				if toleration.Tolerates(taint) {
+					r.ToleratedBy++
+					if len(r.ToleratingPods) < maxTolerationsListed {
+						r.ToleratingPods = append(r.ToleratingPods, podKey(pod))
+					}
+					break
+				}
+			}
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+// daemonSetGaps finds the DaemonSets whose pod template should schedule on
+// the node (node selector, required node affinity and NoSchedule/NoExecute
+// taints) but have no ready pod there
+func daemonSetGaps(node k8s.Node, daemonSets []daemonSet, pods []k8s.Pod) []DaemonSetGap {
+	byOwner := map[string]k8s.Pod{}
+	for _, pod := range pods {
+		if owner := k8s.ControllerOf(pod.Metadata); owner != nil && owner.Kind == "DaemonSet" {
+			byOwner[pod.Metadata.Namespace+"/"+owner.Name] = pod
+		}
+	}
+	var gaps []DaemonSetGap
+	for _, ds := range daemonSets {
+		spec := ds.Spec.Template.Spec
+		if !spec.MatchesNode(node) || !k8s.ToleratesAll(spec.Tolerations, node.Spec.Taints, "NoSchedule", "NoExecute") {
+			continue
+		}
+		gap := DaemonSetGap{Namespace: ds.Metadata.Namespace, Name: ds.Metadata.Name}
+		pod, ok := byOwner[ds.Metadata.Namespace+"/"+ds.Metadata.Name]
+		if !ok {
+			gap.Reason = "no pod on this node"
+			gaps = append(gaps, gap)
+			continue
+		}
+		gap.Pod = pod.Metadata.Name
+		if ready := k8s.FindCondition(pod.Status.Conditions, "Ready"); ready != nil && ready.Status == "True" {
+			continue
+		}
+		gap.Reason = "pod is " + pod.Status.Phase + " and not ready"
+		for _, status := range allContainerStatuses(pod) {
+			if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+				gap.Reason = "pod is not ready: " + status.State.Waiting.Reason
+				break
+			}
+		}
+		gaps = append(gaps, gap)
+	}
+	sort.Slice(gaps, func(i, j int) bool {
+		return gaps[i].Namespace+"/"+gaps[i].Name < gaps[j].Namespace+"/"+gaps[j].Name
+	})
+	return gaps
+}
+
+// podKey names a pod with its namespace
+func podKey(pod k8s.Pod) string {
+	return pod.Metadata.Namespace + "/" + pod.Metadata.Name
+}
@@ -0,0 +1,204 @@
+package diagnose
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const pressuredNode = `{
+  "metadata": {"name": "node-1", "labels": {"kubernetes.io/os": "linux", "pool": "gpu"}},
+  "spec": {"taints": [{"key": "sku", "value": "gpu", "effect": "NoSchedule"}]},
+  "status": {
+    "capacity": {"cpu": "4", "memory": "16Gi", "pods": "110"},
+    "allocatable": {"cpu": "2", "memory": "8Gi", "pods": "110"},
+    "conditions": [
+      {"type": "Ready", "status": "True"},
+      {"type": "MemoryPressure", "status": "True", "reason": "KubeletHasInsufficientMemory", "message": "kubelet has insufficient memory available"},
+      {"type": "DiskPressure", "status": "False"},
+      {"type": "PIDPressure", "status": "False"}
+    ],
+    "nodeInfo": {"kubeletVersion": "v1.30.4", "containerRuntimeVersion": "containerd://1.7.15", "osImage": "Ubuntu 22.04.4 LTS"}
+  }
+}`
+
+const nodePods = `{"items": [
+  {"metadata": {"name": "train-1", "namespace": "ml"},
+   "spec": {"nodeName": "node-1", "tolerations": [{"key": "sku", "operator": "Equal", "value": "gpu", "effect": "NoSchedule"}],
+            "initContainers": [{"name": "fetch", "resources": {"requests": {"cpu": "1500m"}}}],
+            "containers": [{"name": "app", "resources": {"requests": {"cpu": "500m", "memory": "2Gi"}, "limits": {"cpu": "4", "memory": "6Gi"}}},
+                           {"name": "sidecar", "resources": {"requests": {"cpu": "100m", "memory": "256Mi"}}}]},
+   "status": {"phase": "Running"}},
+  {"metadata": {"name": "agent-x1", "namespace": "monitoring", "ownerReferences": [{"kind": "DaemonSet", "name": "agent", "controller": true}]},
+   "spec": {"nodeName": "node-1", "tolerations": [{"operator": "Exists"}], "containers": [{"name": "agent", "resources": {"requests": {"cpu": "100m", "memory": "128Mi"}}}]},
+   "status": {"phase": "Pending", "conditions": [{"type": "Ready", "status": "False"}],
+              "containerStatuses": [{"name": "agent", "state": {"waiting": {"reason": "ImagePullBackOff"}}}]}},
+  {"metadata": {"name": "batch-old", "namespace": "ml"},
+   "spec": {"nodeName": "node-1", "containers": [{"name": "job", "resources": {"requests": {"cpu": "1", "memory": "4Gi"}}}]},
+   "status": {"phase": "Failed", "reason": "Evicted", "message": "The node was low on resource: memory."}}
+]}`
+
+const nodeDaemonSets = `{"items": [
+  {"metadata": {"name": "agent", "namespace": "monitoring"}, "spec": {"template": {"spec": {"tolerations": [{"operator": "Exists"}]}}}},
+  {"metadata": {"name": "csi-node", "namespace": "kube-system"}, "spec": {"template": {"spec": {"nodeSelector": {"kubernetes.io/os": "linux"}, "tolerations": [{"key": "sku", "operator": "Exists"}]}}}},
+  {"metadata": {"name": "no-toleration", "namespace": "kube-system"}, "spec": {"template": {"spec": {}}}},
+  {"metadata": {"name": "windows-only", "namespace": "kube-system"}, "spec": {"template": {"spec": {"nodeSelector": {"kubernetes.io/os": "windows"}, "tolerations": [{"operator": "Exists"}]}}}}
+]}`
+
+func TestDiagnoseNode(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: map[string]string{
+		"get node node-1": pressuredNode,
+		"get pods -A --field-selector spec.nodeName=node-1": nodePods,
+		"get daemonsets.apps -A":                            nodeDaemonSets,
+		"get events -A --field-selector involvedObject.kind=Node,involvedObject.name=node-1": `{"items": [
+			{"type": "Warning", "reason": "SystemOOM", "message": "System OOM encountered, victim process: java", "count": 2, "lastTimestamp": "2026-10-19T10:00:00Z"},
+			{"type": "Normal", "reason": "NodeHasSufficientPID", "message": "Node status is now: NodeHasSufficientPID"}]}`,
+		"top node node-1 --no-headers": "node-1   1900m   95%   7680Mi   93%\n",
+	}}
+
+	report, err := DiagnoseNode(context.Background(), runner, nil, NodeOptions{Name: "node-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !report.Ready || report.KubeletVersion != "v1.30.4" {
+		t.Errorf("Expected ready node with kubelet v1.30.4, got ready=%v version=%q", report.Ready, report.KubeletVersion)
+	}
+	if report.Pods.Total != 3 || report.Pods.Running != 1 || report.Pods.Pending != 1 || report.Pods.Failed != 1 {
+		t.Errorf("Expected 3 pods (1 running, 1 pending, 1 failed), got %+v", report.Pods)
+	}
+
+	// train-1 requests max(500m+100m, 1500m) = 1500m, agent-x1 100m; the evicted pod is not counted
+	expected := map[string]ResourceUsage{
+		"cpu":    {Allocatable: "2", Requested: "1600m", RequestedPercent: 80, Limits: "4", LimitsPercent: 200, Usage: "1900m"},
+		"memory": {Allocatable: "8Gi", Requested: "2.4Gi", RequestedPercent: 29, Limits: "6Gi", LimitsPercent: 75, Usage: "7680Mi"},
+	}
+	for _, r := range report.Resources {
+		want := expected[r.Resource]
+		if r.Allocatable != want.Allocatable || r.Requested != want.Requested || r.RequestedPercent != want.RequestedPercent ||
+			r.Limits != want.Limits || r.LimitsPercent != want.LimitsPercent || r.Usage != want.Usage {
+			t.Errorf("Expected %s %+v, got %+v", r.Resource, want, r)
+		}
+		if r.UsagePercent == nil || *r.UsagePercent < 90 {
+			t.Errorf("Expected %s usage above 90%%, got %v", r.Resource, r.UsagePercent)
+		}
+	}
+
+	if len(report.Taints) != 1 || report.Taints[0].ToleratedBy != 2 {
+		t.Fatalf("Expected taint sku tolerated by 2 pods, got %+v", report.Taints)
+	}
+	if len(report.Evictions) != 1 || report.Evictions[0].Pod != "ml/batch-old" {
+		t.Errorf("Expected eviction of ml/batch-old, got %+v", report.Evictions)
+	}
+
+	gaps := make(map[string]string)
+	for _, gap := range report.DaemonSetGaps {
+		gaps[gap.Namespace+"/"+gap.Name] = gap.Reason
+	}
+	if len(gaps) != 2 || gaps["kube-system/csi-node"] != "no pod on this node" || !strings.Contains(gaps["monitoring/agent"], "ImagePullBackOff") {
+		t.Errorf("Expected gaps for csi-node and agent only, got %v", gaps)
+	}
+
+	categories := make(map[string]bool)
+	for _, f := range report.Findings {
+		categories[f.Category+"/"+f.Severity] = true
+	}
+	for _, want := range []string{"node/critical", "node/warning", "resources/warning", "eviction/warning", "daemonset/warning"} {
+		if !categories[want] {
+			t.Errorf("Expected a %s finding, got %+v", want, report.Findings)
+		}
+	}
+	if report.Findings[0].Severity != SeverityCritical || !strings.Contains(report.Findings[0].Message, "SystemOOM") {
+		t.Errorf("Expected the SystemOOM finding first, got %+v", report.Findings[0])
+	}
+	if len(report.CollectionErrors) != 0 {
+		t.Errorf("Expected no collection errors, got %v", report.CollectionErrors)
+	}
+}
+
+func TestDiagnoseNodeRestrictedNamespaces(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: map[string]string{
+		"get node node-1":             `{"metadata": {"name": "node-1"}, "status": {"conditions": [{"type": "Ready", "status": "False", "message": "Kubelet stopped posting node status."}]}}`,
+		"get namespaces":              `{"items": [{"metadata": {"name": "apps"}}, {"metadata": {"name": "kube-system"}}]}`,
+		"get pods -n apps":            `{"items": []}`,
+		"get daemonsets.apps -n apps": `{"items": []}`,
+	}}
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("apps")
+
+	report, err := DiagnoseNode(context.Background(), runner, secConfig, NodeOptions{Name: "node-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, command := range runner.Commands() {
+		if strings.Contains(command, " -A") || strings.Contains(command, "kube-system") {
+			t.Errorf("Expected only allowed namespaces to be queried, got %q", command)
+		}
+	}
+	if report.Ready || len(report.Findings) == 0 || report.Findings[0].Message != "Node node-1 is not ready" {
+		t.Errorf("Expected a not ready finding, got %+v", report.Findings)
+	}
+	joined := strings.Join(report.CollectionErrors, "\n")
+	for _, want := range []string{"namespace default, which is not allowed", "kubectl top node", "allowed namespaces"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected collection errors to mention %q, got %v", want, report.CollectionErrors)
+		}
+	}
+}
+
+func TestDiagnoseNodeKubeletLogs(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: map[string]string{
+		"get node node-1": `{"metadata": {"name": "node-1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}}`,
+		// kubectl prints "Creating debugging pod ..." on stderr, so only the journal reaches stdout
+		"debug node/node-1 -n ops --image=busybox:1.36 --profile=sysadmin --attach=true -- chroot /host journalctl -u kubelet --no-pager -n 50": "kubelet[123]: E1019 PLEG is not healthy\n",
+		"get pods -n ops --field-selector spec.nodeName=node-1":                                                                                 `{"items": [{"metadata": {"name": "node-debugger-node-1-abcde"}}, {"metadata": {"name": "node-debugger-node-10-xyz12"}}, {"metadata": {"name": "web-0"}}]}`,
+		"delete pod node-debugger-node-1-abcde -n ops --wait=false":                                                                             `pod "node-debugger-node-1-abcde" deleted`,
+	}}
+
+	report, err := DiagnoseNode(context.Background(), runner, nil, NodeOptions{
+		Name: "node-1", CaptureKubeletLogs: true, DebugNamespace: "ops", KubeletLogLines: 50,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	logs := report.KubeletLogs
+	if logs == nil || logs.Output != "kubelet[123]: E1019 PLEG is not healthy\n" {
+		t.Fatalf("Expected captured kubelet logs, got %+v", logs)
+	}
+	if len(logs.DebugPods) != 1 || logs.DebugPods[0] != "node-debugger-node-1-abcde" || logs.Cleanup != "deleted node-debugger-node-1-abcde" {
+		t.Errorf("Expected only the debugging pod of node-1 to be deleted, got %+v", logs)
+	}
+	var deletes []string
+	for _, command := range runner.Commands() {
+		if strings.HasPrefix(command, "delete ") {
+			deletes = append(deletes, command)
+		}
+	}
+	if len(deletes) != 1 {
+		t.Errorf("Expected exactly one delete, got %v", deletes)
+	}
+
+	if _, err := DiagnoseNode(context.Background(), runner, nil, NodeOptions{Name: "node-1", KubeletLogLines: 5000}); err == nil {
+		t.Error("Expected an error for kubelet_log_lines above the maximum")
+	}
+}
+
+func TestNodeExecutorCaptureRequiresAdmin(t *testing.T) {
+	for _, level := range []security.AccessLevel{security.AccessLevelReadOnly, security.AccessLevelReadWrite} {
+		cfg := &config.ConfigData{SecurityConfig: security.NewSecurityConfig()}
+		cfg.SecurityConfig.AccessLevel = level
+		executor := &NodeExecutor{newRunner: func(*config.ConfigData) k8s.Runner {
+			return &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound}
+		}}
+
+		_, err := executor.Execute(context.Background(), map[string]interface{}{"name": "node-1", "capture_kubelet_logs": true}, cfg)
+		if err == nil || !strings.Contains(err.Error(), "requires admin access level") {
+			t.Errorf("Expected admin access level error at %s, got %v", level, err)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// defaultLogTailLines is the number of log lines collected per container
+const defaultLogTailLines = 50
+
+// maxEventsInReport bounds the number of events included in a report
+const maxEventsInReport = 20
+
+// PodReport is the structured result of diagnose_pod
+type PodReport struct {
+	Namespace      string            `json:"namespace"`
+	Name           string            `json:"name"`
+	Phase          string            `json:"phase"`
+	Node           string            `json:"node,omitempty"`
+	Owner          string            `json:"owner,omitempty"`
+	Containers     []ContainerReport `json:"containers"`
+	Findings       []Finding         `json:"findings"`
+	Events         []EventSummary    `json:"events,omitempty"`
+	NodeConditions []string          `json:"nodeConditions,omitempty"`
+	OwnerStatus    string            `json:"ownerStatus,omitempty"`
+	Logs           map[string]string `json:"logs,omitempty"`
+	PreviousLogs   map[string]string `json:"previousLogs,omitempty"`
+	// CollectionErrors lists data that could not be gathered (denied by the
+	// security configuration, not found, ...)
+	CollectionErrors []string `json:"collectionErrors,omitempty"`
+}
+
+// ContainerReport is the state of a single container
+type ContainerReport struct {
+	Name                  string `json:"name"`
+	Image                 string `json:"image"`
+	Init                  bool   `json:"init,omitempty"`
+	Ready                 bool   `json:"ready"`
+	RestartCount          int    `json:"restartCount"`
+	State                 string `json:"state"`
+	Reason                string `json:"reason,omitempty"`
+	ExitCode              *int   `json:"exitCode,omitempty"`
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+	LastTerminationCode   *int   `json:"lastTerminationExitCode,omitempty"`
+	MemoryLimit           string `json:"memoryLimit,omitempty"`
+}
+
+// EventSummary is a compact view of an event
+type EventSummary struct {
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+	Count    int    `json:"count,omitempty"`
+	LastSeen string `json:"lastSeen,omitempty"`
+}
+
+// podData is everything collected for a pod
+type podData struct {
+	pod          k8s.Pod
+	events       []k8s.Event
+	node         *k8s.Node
+	owner        string
+	deployment   *k8s.Deployment
+	logs         map[string]string
+	previousLogs map[string]string
+	errors       []string
+}
+
+// DiagnosePod collects the pod, its events, logs, previous logs, owner and
+// node in parallel and correlates them into a findings report
+func DiagnosePod(ctx context.Context, runner k8s.Runner, namespace, name string, tailLines int) (*PodReport, error) {
+	if tailLines <= 0 {
+		tailLines = defaultLogTailLines
+	}
+
+	data := &podData{
+		logs:         make(map[string]string),
+		previousLogs: make(map[string]string),
+	}
+	var mu sync.Mutex
+	addError := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		data.errors = append(data.errors, fmt.Sprintf(format, args...))
+	}
+
+	// The pod and its events are fetched together; the rest depends on the pod spec
+	var podErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		podErr = k8s.GetJSON(ctx, runner, fmt.Sprintf("get pod %s -n %s", name, namespace), &data.pod)
+	}()
+	go func() {
+		defer wg.Done()
+		var events k8s.List[k8s.Event]
+		command := fmt.Sprintf("get events -n %s --field-selector involvedObject.kind=Pod,involvedObject.name=%s", namespace, name)
+		if err := k8s.GetJSON(ctx, runner, command, &events); err != nil {
+			addError("events: %v", err)
+			return
+		}
+		data.events = events.Items
+	}()
+	wg.Wait()
+	if podErr != nil {
+		return nil, podErr
+	}
+
+	if nodeName := data.pod.Spec.NodeName; nodeName != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var node k8s.Node
+			if err := k8s.GetJSON(ctx, runner, "get node "+nodeName, &node); err != nil {
+				addError("node: %v", err)
+				return
+			}
+			data.node = &node
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		owner, deployment, err := resolveOwner(ctx, runner, data.pod)
+		mu.Lock()
+		defer mu.Unlock()
+		data.owner, data.deployment = owner, deployment
+		if err != nil {
+			data.errors = append(data.errors, fmt.Sprintf("owner: %v", err))
+		}
+	}()
+
+	for _, status := range allContainerStatuses(data.pod) {
+		// Containers that never started have no logs
+		if status.State.Waiting != nil && status.RestartCount == 0 {
+			continue
+		}
+		containerName := status.Name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			command := fmt.Sprintf("logs %s -n %s -c %s --tail=%d", name, namespace, containerName, tailLines)
+			output, err := runner.Run(ctx, command)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				data.errors = append(data.errors, fmt.Sprintf("logs %s: %v", containerName, err))
+				return
+			}
+			data.logs[containerName] = output
+		}()
+
+		if status.RestartCount > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				command := fmt.Sprintf("logs %s -n %s -c %s --previous --tail=%d", name, namespace, containerName, tailLines)
+				output, err := runner.Run(ctx, command)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					data.errors = append(data.errors, fmt.Sprintf("previous logs %s: %v", containerName, err))
+					return
+				}
+				data.previousLogs[containerName] = output
+			}()
+		}
+	}
+	wg.Wait()
+
+	return buildPodReport(data), nil
+}
+
+// resolveOwner follows the pod's controller to its Deployment when the
+// controller is a ReplicaSet owned by one
+func resolveOwner(ctx context.Context, runner k8s.Runner, pod k8s.Pod) (string, *k8s.Deployment, error) {
+	controller := k8s.ControllerOf(pod.Metadata)
+	if controller == nil {
+		return "", nil, nil
+	}
+	owner := controller.Kind + "/" + controller.Name
+	if controller.Kind != "ReplicaSet" {
+		return owner, nil, nil
+	}
+
+	var replicaSet k8s.ReplicaSet
+	if err := k8s.GetJSON(ctx, runner, fmt.Sprintf("get replicaset %s -n %s", controller.Name, pod.Metadata.Namespace), &replicaSet); err != nil {
+		return owner, nil, err
+	}
+	rsController := k8s.ControllerOf(replicaSet.Metadata)
+	if rsController == nil || rsController.Kind != "Deployment" {
+		return owner, nil, nil
+	}
+
+	owner = "Deployment/" + rsController.Name
+	var deployment k8s.Deployment
+	if err := k8s.GetJSON(ctx, runner, fmt.Sprintf("get deployment %s -n %s", rsController.Name, pod.Metadata.Namespace), &deployment); err != nil {
+		return owner, nil, err
+	}
+	return owner, &deployment, nil
+}
+
+// allContainerStatuses returns init and regular container statuses
+func allContainerStatuses(pod k8s.Pod) []k8s.ContainerStatus {
+	statuses := append([]k8s.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	return append(statuses, pod.Status.ContainerStatuses...)
+}
+
+// buildPodReport assembles the report and runs the analysis
+func buildPodReport(data *podData) *PodReport {
+	pod := data.pod
+	report := &PodReport{
+		Namespace:        pod.Metadata.Namespace,
+		Name:             pod.Metadata.Name,
+		Phase:            pod.Status.Phase,
+		Node:             pod.Spec.NodeName,
+		Owner:            data.owner,
+		Containers:       containerReports(pod),
+		Findings:         analyzePod(data),
+		Events:           summarizeEvents(data.events),
+		CollectionErrors: data.errors,
+	}
+	if len(data.logs) > 0 {
+		report.Logs = data.logs
+	}
+	if len(data.previousLogs) > 0 {
+		report.PreviousLogs = data.previousLogs
+	}
+	if data.node != nil {
+		for _, condition := range data.node.Status.Conditions {
+			report.NodeConditions = append(report.NodeConditions, condition.Type+"="+condition.Status)
+		}
+	}
+	if data.deployment != nil {
+		report.OwnerStatus = deploymentStatus(data.deployment)
+	}
+	sort.Strings(report.CollectionErrors)
+	return report
+}
+
+// containerReports builds the per-container view of a pod
+func containerReports(pod k8s.Pod) []ContainerReport {
+	specs := make(map[string]k8s.Container)
+	for _, c := range pod.Spec.InitContainers {
+		specs[c.Name] = c
+	}
+	for _, c := range pod.Spec.Containers {
+		specs[c.Name] = c
+	}
+
+	var reports []ContainerReport
+	add := func(statuses []k8s.ContainerStatus, init bool) {
+		for _, status := range statuses {
+			report := ContainerReport{
+				Name:         status.Name,
+				Image:        specs[status.Name].Image,
+				Init:         init,
+				Ready:        status.Ready,
+				RestartCount: status.RestartCount,
+				MemoryLimit:  specs[status.Name].Resources.Limits["memory"],
+			}
+			switch {
+			case status.State.Running != nil:
+				report.State = "Running"
+			case status.State.Waiting != nil:
+				report.State = "Waiting"
+				report.Reason = status.State.Waiting.Reason
+			case status.State.Terminated != nil:
+				report.State = "Terminated"
+				report.Reason = status.State.Terminated.Reason
+				exitCode := status.State.Terminated.ExitCode
+				report.ExitCode = &exitCode
+			}
+			if last := status.LastState.Terminated; last != nil {
+				report.LastTerminationReason = last.Reason
+				exitCode := last.ExitCode
+				report.LastTerminationCode = &exitCode
+			}
+			reports = append(reports, report)
+		}
+	}
+	add(pod.Status.InitContainerStatuses, true)
+	add(pod.Status.ContainerStatuses, false)
+	return reports
+}
+
+// summarizeEvents returns the most recent events, warnings first
+func summarizeEvents(events []k8s.Event) []EventSummary {
+	sorted := append([]k8s.Event{}, events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if (sorted[i].Type == "Warning") != (sorted[j].Type == "Warning") {
+			return sorted[i].Type == "Warning"
+		}
+		return eventTime(sorted[i]) > eventTime(sorted[j])
+	})
+	if len(sorted) > maxEventsInReport {
+		sorted = sorted[:maxEventsInReport]
+	}
+
+	summaries := make([]EventSummary, 0, len(sorted))
+	for _, event := range sorted {
+		summaries = append(summaries, EventSummary{
+			Type:     event.Type,
+			Reason:   event.Reason,
+			Message:  event.Message,
+			Count:    event.Count,
+			LastSeen: eventTime(event),
+		})
+	}
+	return summaries
+}
+
+// eventTime returns the most recent timestamp of an event (RFC 3339 strings sort chronologically)
+func eventTime(event k8s.Event) string {
+	if event.LastTimestamp != "" {
+		return event.LastTimestamp
+	}
+	if event.EventTime != "" {
+		return event.EventTime
+	}
+	return event.FirstTimestamp
+}
+
+// deploymentStatus renders a one-line deployment status
+func deploymentStatus(deployment *k8s.Deployment) string {
+	desired := 1
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	return fmt.Sprintf("%d/%d ready, %d updated, %d available",
+		deployment.Status.ReadyReplicas, desired, deployment.Status.UpdatedReplicas, deployment.Status.AvailableReplicas)
+}
+
+// lastLogLine returns the last non-empty line of a log excerpt
+func lastLogLine(logs string) string {
+	lines := strings.Split(strings.TrimSpace(logs), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+package diagnose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/ratelimit"
+)
+
+const crashLoopPod = `{
+  "metadata": {"name": "web-abc-1", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "web-abc", "controller": true}]},
+  "spec": {"nodeName": "node-1", "containers": [{"name": "app", "image": "web:1.0", "resources": {"limits": {"memory": "128Mi"}}}]},
+  "status": {
+    "phase": "Running",
+    "conditions": [{"type": "PodScheduled", "status": "True"}],
+    "containerStatuses": [{
+      "name": "app", "ready": false, "restartCount": 4,
+      "state": {"waiting": {"reason": "CrashLoopBackOff", "message": "back-off 40s"}},
+      "lastState": {"terminated": {"exitCode": 137, "reason": "OOMKilled"}}
+    }]
+  }
+}`
+
+func TestDiagnosePodCrashLoopOOM(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: map[string]string{
+		"get pod web-abc-1 -n shop":                crashLoopPod,
+		"get events -n shop":                       `{"items": [{"type": "Warning", "reason": "Unhealthy", "message": "Liveness probe failed: HTTP probe failed with statuscode: 500", "count": 3}]}`,
+		"get node node-1":                          `{"metadata": {"name": "node-1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}, {"type": "MemoryPressure", "status": "True"}]}}`,
+		"get replicaset web-abc -n":                `{"metadata": {"name": "web-abc", "ownerReferences": [{"kind": "Deployment", "name": "web", "controller": true}]}}`,
+		"get deployment web -n shop":               `{"spec": {"replicas": 2}, "status": {"readyReplicas": 1, "updatedReplicas": 2, "availableReplicas": 1, "unavailableReplicas": 1}}`,
+		"logs web-abc-1 -n shop -c app --previous": "starting\nallocating cache\n",
+		"logs web-abc-1 -n shop -c app":            "starting\n",
+	}}
+
+	report, err := DiagnosePod(context.Background(), runner, "shop", "web-abc-1", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Owner != "Deployment/web" {
+		t.Errorf("Expected owner Deployment/web, got %s", report.Owner)
+	}
+	if report.PreviousLogs["app"] == "" {
+		t.Error("Expected previous logs to be collected for a restarted container")
+	}
+	if len(report.CollectionErrors) != 0 {
+		t.Errorf("Expected no collection errors, got %v", report.CollectionErrors)
+	}
+
+	categories := make(map[string]Finding)
+	for _, f := range report.Findings {
+		categories[f.Category] = f
+	}
+	for _, category := range []string{CategoryCrashLoop, CategoryOOM, CategoryProbe, CategoryNode, CategoryRollout} {
+		if _, ok := categories[category]; !ok {
+			t.Errorf("Expected a %s finding, got %+v", category, report.Findings)
+		}
+	}
+	if !strings.Contains(categories[CategoryOOM].Message, "128Mi") {
+		t.Errorf("Expected OOM finding to mention the memory limit, got %q", categories[CategoryOOM].Message)
+	}
+	if categories[CategoryCrashLoop].Evidence != "allocating cache" {
+		t.Errorf("Expected crash-loop evidence from previous logs, got %q", categories[CategoryCrashLoop].Evidence)
+	}
+	if report.Findings[0].Severity != SeverityCritical {
+		t.Errorf("Expected critical findings first, got %s", report.Findings[0].Severity)
+	}
+
+	for _, command := range runner.Commands() {
+		if !strings.Contains(command, "-n shop") && !strings.HasPrefix(command, "get node ") {
+			t.Errorf("Expected every namespaced command to carry -n, got %q", command)
+		}
+	}
+}
+
+func TestDiagnosePodSchedulingAndImagePull(t *testing.T) {
+	tests := []struct {
+		name         string
+		pod          string
+		events       string
+		wantCategory string
+	}{
+		{
+			name:         "unschedulable",
+			pod:          `{"metadata": {"name": "p", "namespace": "ns"}, "spec": {"containers": [{"name": "c"}]}, "status": {"phase": "Pending", "conditions": [{"type": "PodScheduled", "status": "False", "reason": "Unschedulable", "message": "0/3 nodes are available: 3 Insufficient cpu."}]}}`,
+			events:       `{"items": []}`,
+			wantCategory: CategoryScheduling,
+		},
+		{
+			name:         "image pull",
+			pod:          `{"metadata": {"name": "p", "namespace": "ns"}, "spec": {"nodeName": "n", "containers": [{"name": "c", "image": "nginx:nope"}]}, "status": {"phase": "Pending", "containerStatuses": [{"name": "c", "state": {"waiting": {"reason": "ImagePullBackOff"}}}]}}`,
+			events:       `{"items": [{"type": "Warning", "reason": "Failed", "message": "Failed to pull image \"nginx:nope\": not found"}]}`,
+			wantCategory: CategoryImagePull,
+		},
+		{
+			name:         "missing config",
+			pod:          `{"metadata": {"name": "p", "namespace": "ns"}, "spec": {"nodeName": "n", "containers": [{"name": "c"}]}, "status": {"phase": "Pending", "containerStatuses": [{"name": "c", "state": {"waiting": {"reason": "CreateContainerConfigError", "message": "configmap \"app\" not found"}}}]}}`,
+			events:       `{"items": []}`,
+			wantCategory: CategoryConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: map[string]string{
+				"get pod p -n ns":  tt.pod,
+				"get events -n ns": tt.events,
+				"get node n":       `{"metadata": {"name": "n"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}}`,
+			}}
+			report, err := DiagnosePod(context.Background(), runner, "ns", "p", 10)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(report.Findings) == 0 || report.Findings[0].Category != tt.wantCategory {
+				t.Errorf("Expected first finding %s, got %+v", tt.wantCategory, report.Findings)
+			}
+			if report.Findings[0].Evidence == "" {
+				t.Errorf("Expected evidence for %s finding", tt.wantCategory)
+			}
+		})
+	}
+}
+
+func TestDiagnosePodNotFound(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: map[string]string{}}
+	if _, err := DiagnosePod(context.Background(), runner, "ns", "missing", 10); err == nil {
+		t.Error("Expected error for a missing pod")
+	}
+}
+
+// fakeKubectl is a kubectl stand-in for tests that run the real client. It
+// serves a pod with several running containers and, for each logs command,
+// records how many logs processes are running at that moment.
+const fakeKubectl = `#!/bin/sh
+case "$*" in
+*"get pod "*)
+	echo '{"metadata":{"name":"web","namespace":"default"},"status":{"phase":"Running","containerStatuses":[
+		{"name":"c1","ready":true,"state":{"running":{}}},{"name":"c2","ready":true,"state":{"running":{}}},
+		{"name":"c3","ready":true,"state":{"running":{}}},{"name":"c4","ready":true,"state":{"running":{}}},
+		{"name":"c5","ready":true,"state":{"running":{}}},{"name":"c6","ready":true,"state":{"running":{}}}]}}'
+	exit 0 ;;
+*"get events"*)
+	echo '{"items":[]}'
+	exit 0 ;;
+esac
+touch "$STATE_DIR/running.$$"
+ls "$STATE_DIR" | grep -c '^running\.' >> "$STATE_DIR/counts"
+sleep 0.2
+rm -f "$STATE_DIR/running.$$"
+echo "log line"
+`
+
+func TestPodExecutorCapsKubectlProcesses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	binDir, stateDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "kubectl"), []byte(fakeKubectl), 0o755); err != nil {
+		t.Fatalf("Failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STATE_DIR", stateDir)
+
+	const maxProcesses = 2
+	cfg := config.NewConfig()
+	cfg.ProcessLimiter = ratelimit.NewProcessLimiter(maxProcesses)
+
+	output, err := NewPodExecutor().Execute(context.Background(), map[string]interface{}{
+		"namespace": "default",
+		"name":      "web",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Count(output, "log line") != 6 {
+		t.Errorf("Expected logs for 6 containers, got %s", output)
+	}
+
+	data, err := os.ReadFile(filepath.Join(stateDir, "counts"))
+	if err != nil {
+		t.Fatalf("Failed to read process counts: %v", err)
+	}
+	counts := strings.Fields(string(data))
+	if len(counts) != 6 {
+		t.Fatalf("Expected 6 logs processes, got %d", len(counts))
+	}
+	for _, c := range counts {
+		if n, _ := strconv.Atoi(c); n > maxProcesses {
+			t.Errorf("Expected at most %d concurrent kubectl processes, got %d", maxProcesses, n)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package diagnose
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterDiagnosePod registers the diagnose_pod tool
+func RegisterDiagnosePod() mcp.Tool {
+	return mcp.NewTool("diagnose_pod",
+		mcp.WithDescription(`Diagnose a single pod in one call instead of separate describe/logs/events round-trips.
+
+Collects in parallel: the pod, its events, current and previous container logs, the owning Deployment (via its ReplicaSet) and the node's conditions.
+Correlates container states, restart reasons, probe failures, image pull errors, OOMKilled, volume mount and scheduling failures into a findings report, most severe first.
+
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the pod"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the pod"),
+		),
+		mcp.WithNumber("tail_lines",
+			mcp.Description("Number of log lines to collect per container (default: 50)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Diagnose Pod",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
+
+// RegisterDiagnoseNode registers the diagnose_node tool. The kubelet log
+// capture through "kubectl debug node/..." is only offered at the admin
+// access level.
+func RegisterDiagnoseNode(accessLevel string) mcp.Tool {
+	description := `Diagnose a node in one call.
+
+Collects in parallel: the node, the pods scheduled on it, its events, metrics-server usage and the DaemonSets of the allowed namespaces.
+Reports conditions (MemoryPressure, DiskPressure, PIDPressure, NetworkUnavailable), allocatable vs requested vs actual CPU and memory, taints with the pods that tolerate them, evicted pods, DaemonSets without a ready pod on the node, and recent node events, with findings most severe first.`
+
+	opts := []mcp.ToolOption{
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the node"),
+		),
+	}
+
+	readOnly := accessLevel != "admin"
+	if readOnly {
+		description += `
+
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`
+	} else {
+		description += `
+
+Optionally captures the kubelet journal through "kubectl debug node/<name>" with a privileged busybox pod, which is deleted afterwards.`
+		opts = append(opts,
+			mcp.WithBoolean("capture_kubelet_logs",
+				mcp.Description("Capture the kubelet logs with a node debugging pod (default: false)"),
+			),
+			mcp.WithString("debug_namespace",
+				mcp.Description("Namespace of the node debugging pod (default: default)"),
+			),
+			mcp.WithNumber("kubelet_log_lines",
+				mcp.Description("Number of kubelet journal lines to capture (default: 200, max: 2000)"),
+			),
+		)
+	}
+
+	opts = append([]mcp.ToolOption{mcp.WithDescription(description)}, opts...)
+	opts = append(opts, mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		Title:        "Diagnose Node",
+		ReadOnlyHint: boolPtr(readOnly),
+	}))
+	return mcp.NewTool("diagnose_node", opts...)
+}
+
+// RegisterDiagnoseStorage registers the diagnose_storage tool
+func RegisterDiagnoseStorage() mcp.Tool {
+	return mcp.NewTool("diagnose_storage",
+		mcp.WithDescription(`Diagnose persistent storage: why claims are Pending or cannot be attached or mounted, and which volumes are orphaned.
+
+Follows each PersistentVolumeClaim to its PersistentVolume, StorageClass, VolumeAttachments, CSI driver and the CSINode of the nodes using it, together with the pods mounting the claim and their events.
+Explains WaitForFirstConsumer binding, provisioning failures, why no pre-provisioned volume matches (capacity, access modes, volume mode), zone and node affinity mismatches between volumes and pods, ReadWriteOnce volumes used from several nodes, pending expansions, attach/detach errors and missing CSI drivers.
+Healthy bound claims are only counted. Released, Failed and unbound dynamically provisioned volumes are listed as orphaned.
+
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the claims (default: all allowed namespaces)"),
+		),
+		mcp.WithString("pvc",
+			mcp.Description("Name of a single PersistentVolumeClaim to diagnose; requires namespace"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Diagnose Storage",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
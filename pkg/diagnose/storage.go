@@ -0,0 +1,324 @@
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const (
+	// defaultClassAnnotation marks the default StorageClass
+	defaultClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	// provisionedByAnnotation names the provisioner of a dynamically provisioned volume
+	provisionedByAnnotation = "pv.kubernetes.io/provisioned-by"
+	// maxCandidateVolumes caps the available volumes explained for a pending claim
+	maxCandidateVolumes = 3
+)
+
+// zoneLabels are the node labels and PV topology keys that name a zone
+var zoneLabels = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"}
+
+// StorageOptions are the parameters of diagnose_storage
+type StorageOptions struct {
+	// Namespace limits the claims to one namespace (default: all allowed namespaces)
+	Namespace string
+	// PVC is the name of a single claim to diagnose; it requires Namespace
+	PVC string
+}
+
+// StorageReport is the structured result of diagnose_storage
+type StorageReport struct {
+	// Namespaces are the scanned namespaces; "*" means all namespaces
+	Namespaces []string `json:"namespaces"`
+	// Claims lists the claims with findings, or the requested claim
+	Claims []ClaimReport `json:"claims"`
+	// HealthyClaims counts the bound claims without findings left out of Claims
+	HealthyClaims   int              `json:"healthyClaims"`
+	OrphanedVolumes []OrphanedVolume `json:"orphanedVolumes,omitempty"`
+	// Findings are the problems that are not about a single claim
+	Findings         []Finding `json:"findings"`
+	CollectionErrors []string  `json:"collectionErrors,omitempty"`
+}
+
+// ClaimReport follows a claim to its volume, StorageClass, attachments and
+// CSI driver
+type ClaimReport struct {
+	Namespace    string             `json:"namespace"`
+	Name         string             `json:"name"`
+	Phase        string             `json:"phase"`
+	StorageClass string             `json:"storageClass,omitempty"`
+	BindingMode  string             `json:"bindingMode,omitempty"`
+	Provisioner  string             `json:"provisioner,omitempty"`
+	Requested    string             `json:"requested,omitempty"`
+	Capacity     string             `json:"capacity,omitempty"`
+	AccessModes  []string           `json:"accessModes,omitempty"`
+	Volume       *VolumeReport      `json:"volume,omitempty"`
+	Consumers    []ConsumerPod      `json:"consumers,omitempty"`
+	Attachments  []AttachmentReport `json:"attachments,omitempty"`
+	Findings     []Finding          `json:"findings"`
+	Events       []EventSummary     `json:"events,omitempty"`
+}
+
+// VolumeReport is the PersistentVolume bound to a claim
+type VolumeReport struct {
+	Name          string   `json:"name"`
+	Phase         string   `json:"phase"`
+	Capacity      string   `json:"capacity,omitempty"`
+	AccessModes   []string `json:"accessModes,omitempty"`
+	ReclaimPolicy string   `json:"reclaimPolicy,omitempty"`
+	CSIDriver     string   `json:"csiDriver,omitempty"`
+	// Zones are the zones the volume's node affinity restricts it to
+	Zones []string `json:"zones,omitempty"`
+}
+
+// ConsumerPod is a pod that mounts the claim
+type ConsumerPod struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+	Node  string `json:"node,omitempty"`
+	Zone  string `json:"zone,omitempty"`
+}
+
+// AttachmentReport is a VolumeAttachment of the claim's volume
+type AttachmentReport struct {
+	Name     string `json:"name"`
+	Node     string `json:"node"`
+	Attached bool   `json:"attached"`
+	Error    string `json:"error,omitempty"`
+}
+
+// OrphanedVolume is a PersistentVolume that no claim uses
+type OrphanedVolume struct {
+	Name          string `json:"name"`
+	Phase         string `json:"phase"`
+	StorageClass  string `json:"storageClass,omitempty"`
+	Capacity      string `json:"capacity,omitempty"`
+	ReclaimPolicy string `json:"reclaimPolicy,omitempty"`
+	// FormerClaim is the deleted claim of a Released volume
+	FormerClaim string `json:"formerClaim,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+// storageData is everything collected for diagnose_storage
+type storageData struct {
+	claims         []k8s.PersistentVolumeClaim
+	volumes        map[string]k8s.PersistentVolume
+	classes        map[string]k8s.StorageClass
+	defaultClass   string
+	attachments    []k8s.VolumeAttachment
+	csiDrivers     map[string]bool
+	csiNodes       map[string]k8s.CSINode
+	nodes          map[string]k8s.Node
+	pods           []k8s.Pod
+	events         []k8s.Event
+	allowedOrphans func(namespace string) bool
+	errors         []string
+}
+
+// DiagnoseStorage correlates claims with their PersistentVolumes,
+// StorageClasses, VolumeAttachments, CSI drivers, consumer pods and events.
+// Cluster-scoped objects are read without a namespace, which the validator
+// allows for these resource types.
+func DiagnoseStorage(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts StorageOptions) (*StorageReport, error) {
+	if opts.PVC != "" && opts.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required with pvc")
+	}
+	namespaces, err := k8s.ScanNamespaces(ctx, runner, secConfig, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	data := &storageData{
+		volumes:    map[string]k8s.PersistentVolume{},
+		classes:    map[string]k8s.StorageClass{},
+		csiDrivers: map[string]bool{},
+		csiNodes:   map[string]k8s.CSINode{},
+		nodes:      map[string]k8s.Node{},
+		allowedOrphans: func(namespace string) bool {
+			return secConfig == nil || !secConfig.HasNamespaceRestrictions() || secConfig.IsNamespaceAllowed(namespace)
+		},
+	}
+
+	if opts.PVC != "" {
+		var claim k8s.PersistentVolumeClaim
+		if err := k8s.GetJSON(ctx, runner, fmt.Sprintf("get pvc %s -n %s", opts.PVC, opts.Namespace), &claim); err != nil {
+			return nil, err
+		}
+		data.claims = []k8s.PersistentVolumeClaim{claim}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	collect := func(what string, fetch func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fetch(); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				data.errors = append(data.errors, fmt.Sprintf("%s: %v", what, err))
+			}
+		}()
+	}
+	collect("persistentvolumes", func() error {
+		var list k8s.List[k8s.PersistentVolume]
+		err := k8s.GetJSON(ctx, runner, "get persistentvolumes", &list)
+		for _, pv := range list.Items {
+			data.volumes[pv.Metadata.Name] = pv
+		}
+		return err
+	})
+	collect("storageclasses", func() error {
+		var list k8s.List[k8s.StorageClass]
+		err := k8s.GetJSON(ctx, runner, "get storageclasses", &list)
+		for _, class := range list.Items {
+			data.classes[class.Metadata.Name] = class
+			if class.Metadata.Annotations[defaultClassAnnotation] == "true" {
+				data.defaultClass = class.Metadata.Name
+			}
+		}
+		return err
+	})
+	collect("volumeattachments", func() error {
+		var list k8s.List[k8s.VolumeAttachment]
+		err := k8s.GetJSON(ctx, runner, "get volumeattachments", &list)
+		data.attachments = list.Items
+		return err
+	})
+	collect("csidrivers", func() error {
+		var list k8s.List[struct {
+			Metadata k8s.ObjectMeta `json:"metadata"`
+		}]
+		err := k8s.GetJSON(ctx, runner, "get csidrivers", &list)
+		for _, driver := range list.Items {
+			data.csiDrivers[driver.Metadata.Name] = true
+		}
+		return err
+	})
+	collect("csinodes", func() error {
+		var list k8s.List[k8s.CSINode]
+		err := k8s.GetJSON(ctx, runner, "get csinodes", &list)
+		for _, node := range list.Items {
+			data.csiNodes[node.Metadata.Name] = node
+		}
+		return err
+	})
+	collect("nodes", func() error {
+		var list k8s.List[k8s.Node]
+		err := k8s.GetJSON(ctx, runner, "get nodes", &list)
+		for _, node := range list.Items {
+			data.nodes[node.Metadata.Name] = node
+		}
+		return err
+	})
+	for _, namespace := range namespaces {
+		scope := "-n " + namespace
+		if namespace == k8s.AllNamespaces {
+			scope = "-A"
+		}
+		if opts.PVC == "" {
+			collect("persistentvolumeclaims in "+namespace, func() error {
+				var list k8s.List[k8s.PersistentVolumeClaim]
+				err := k8s.GetJSON(ctx, runner, "get persistentvolumeclaims "+scope, &list)
+				mu.Lock()
+				defer mu.Unlock()
+				data.claims = append(data.claims, list.Items...)
+				return err
+			})
+		}
+		collect("pods in "+namespace, func() error {
+			var list k8s.List[k8s.Pod]
+			err := k8s.GetJSON(ctx, runner, "get pods "+scope, &list)
+			mu.Lock()
+			defer mu.Unlock()
+			data.pods = append(data.pods, list.Items...)
+			return err
+		})
+		collect("events in "+namespace, func() error {
+			var list k8s.List[k8s.Event]
+			err := k8s.GetJSON(ctx, runner, "get events "+scope, &list)
+			mu.Lock()
+			defer mu.Unlock()
+			data.events = append(data.events, list.Items...)
+			return err
+		})
+	}
+	wg.Wait()
+	sort.Strings(data.errors)
+
+	report := &StorageReport{Namespaces: namespaces, Claims: []ClaimReport{}, CollectionErrors: data.errors}
+	sort.Slice(data.claims, func(i, j int) bool {
+		return data.claims[i].Metadata.Namespace+"/"+data.claims[i].Metadata.Name < data.claims[j].Metadata.Namespace+"/"+data.claims[j].Metadata.Name
+	})
+	for _, claim := range data.claims {
+		c := analyzeClaim(claim, data)
+		if opts.PVC == "" && c.Phase == "Bound" && len(c.Findings) == 0 {
+			report.HealthyClaims++
+			continue
+		}
+		report.Claims = append(report.Claims, c)
+	}
+	if opts.PVC == "" {
+		report.OrphanedVolumes = orphanedVolumes(data)
+	}
+	report.Findings = clusterStorageFindings(report, data)
+	return report, nil
+}
+
+// orphanedVolumes lists Released volumes, Available volumes that were
+// provisioned dynamically, and Failed volumes
+func orphanedVolumes(data *storageData) []OrphanedVolume {
+	var orphans []OrphanedVolume
+	for _, pv := range data.volumes {
+		orphan := OrphanedVolume{
+			Name:          pv.Metadata.Name,
+			Phase:         pv.Status.Phase,
+			StorageClass:  pv.Spec.StorageClassName,
+			Capacity:      pv.Spec.Capacity["storage"],
+			ReclaimPolicy: pv.Spec.PersistentVolumeReclaimPolicy,
+		}
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			if !data.allowedOrphans(ref.Namespace) {
+				continue
+			}
+			orphan.FormerClaim = ref.Namespace + "/" + ref.Name
+		}
+		switch {
+		case pv.Status.Phase == "Released":
+			orphan.Reason = "its claim was deleted and the " + firstNonEmpty(orphan.ReclaimPolicy, "Retain") + " reclaim policy keeps the volume and its data"
+		case pv.Status.Phase == "Failed":
+			orphan.Reason = firstNonEmpty(pv.Status.Message, "reclamation failed")
+		case pv.Status.Phase == "Available" && pv.Metadata.Annotations[provisionedByAnnotation] != "":
+			orphan.Reason = "dynamically provisioned but not bound to any claim"
+		default:
+			continue
+		}
+		orphans = append(orphans, orphan)
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Name < orphans[j].Name })
+	return orphans
+}
+
+// clusterStorageFindings reports orphaned volumes and a missing default
+// StorageClass
+func clusterStorageFindings(report *StorageReport, data *storageData) []Finding {
+	findings := []Finding{}
+	if len(report.OrphanedVolumes) > 0 {
+		var names []string
+		for _, orphan := range report.OrphanedVolumes {
+			names = append(names, orphan.Name)
+		}
+		findings = append(findings, Finding{Severity: SeverityInfo, Category: CategoryStorage,
+			Message:  fmt.Sprintf("%d PersistentVolume(s) are not used by any claim and may still incur cost", len(names)),
+			Evidence: strings.Join(names, ", ")})
+	}
+	if data.defaultClass == "" && len(data.classes) > 0 {
+		findings = append(findings, Finding{Severity: SeverityInfo, Category: CategoryStorage,
+			Message: "No default StorageClass; claims without storageClassName are only bound to pre-provisioned volumes"})
+	}
+	return findings
+}
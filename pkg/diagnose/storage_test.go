@@ -0,0 +1,221 @@
+package diagnose
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const storageClasses = `{"items": [
+  {"metadata": {"name": "managed-csi", "annotations": {"storageclass.kubernetes.io/is-default-class": "true"}}, "provisioner": "disk.csi.azure.com", "volumeBindingMode": "WaitForFirstConsumer", "allowVolumeExpansion": true},
+  {"metadata": {"name": "fixed"}, "provisioner": "disk.csi.azure.com", "volumeBindingMode": "Immediate", "allowVolumeExpansion": false}
+]}`
+
+const storageNodes = `{"items": [
+  {"metadata": {"name": "node-1", "labels": {"topology.kubernetes.io/zone": "eastus-1", "topology.disk.csi.azure.com/zone": "eastus-1"}}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}},
+  {"metadata": {"name": "node-2", "labels": {"topology.kubernetes.io/zone": "eastus-2", "topology.disk.csi.azure.com/zone": "eastus-2"}}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}}
+]}`
+
+const csiNodes = `{"items": [
+  {"metadata": {"name": "node-1"}, "spec": {"drivers": [{"name": "disk.csi.azure.com"}]}},
+  {"metadata": {"name": "node-2"}, "spec": {"drivers": [{"name": "file.csi.azure.com"}]}}
+]}`
+
+// boundVolume is a zonal disk bound to shop/data
+const boundVolume = `{"metadata": {"name": "pv-data", "annotations": {"pv.kubernetes.io/provisioned-by": "disk.csi.azure.com"}},
+  "spec": {"capacity": {"storage": "10Gi"}, "accessModes": ["ReadWriteOnce"], "storageClassName": "managed-csi", "persistentVolumeReclaimPolicy": "Delete",
+    "claimRef": {"namespace": "shop", "name": "data"}, "csi": {"driver": "disk.csi.azure.com", "volumeHandle": "disk-1"},
+    "nodeAffinity": {"required": {"nodeSelectorTerms": [{"matchExpressions": [{"key": "topology.disk.csi.azure.com/zone", "operator": "In", "values": ["eastus-1"]}]}]}}},
+  "status": {"phase": "Bound"}}`
+
+const boundClaim = `{"metadata": {"name": "data", "namespace": "shop"}, "spec": {"accessModes": ["ReadWriteOnce"], "storageClassName": "managed-csi", "volumeName": "pv-data", "resources": {"requests": {"storage": "10Gi"}}}, "status": {"phase": "Bound", "capacity": {"storage": "10Gi"}}}`
+
+// storageRunner returns a runner for the shop namespace with the given outputs on top of a healthy cluster
+func storageRunner(outputs map[string]string) *k8stest.Runner {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: map[string]string{
+		"get persistentvolumes":      `{"items": [` + boundVolume + `]}`,
+		"get storageclasses":         storageClasses,
+		"get volumeattachments":      `{"items": []}`,
+		"get csidrivers":             `{"items": [{"metadata": {"name": "disk.csi.azure.com"}}, {"metadata": {"name": "file.csi.azure.com"}}]}`,
+		"get csinodes":               csiNodes,
+		"get nodes":                  storageNodes,
+		"get pvc data -n shop":       boundClaim,
+		"get pods -n shop":           `{"items": []}`,
+		"get events -n shop":         `{"items": []}`,
+		"get persistentvolumeclaims": `{"items": [` + boundClaim + `]}`,
+	}}
+	for command, output := range outputs {
+		runner.Outputs[command] = output
+	}
+	return runner
+}
+
+// podUsing returns a pod that mounts the claim data on a node
+func podUsing(name, node string) string {
+	return `{"metadata": {"name": "` + name + `", "namespace": "shop"}, "spec": {"nodeName": "` + node + `", "containers": [{"name": "c"}], "volumes": [{"name": "v", "persistentVolumeClaim": {"claimName": "data"}}]}, "status": {"phase": "Running"}}`
+}
+
+func TestDiagnoseStorageClaim(t *testing.T) {
+	tests := []struct {
+		name         string
+		outputs      map[string]string
+		wantSeverity string
+		wantMessage  string
+	}{
+		{
+			name: "waiting for first consumer",
+			outputs: map[string]string{
+				"get pvc data -n shop": `{"metadata": {"name": "data", "namespace": "shop"}, "spec": {"accessModes": ["ReadWriteOnce"], "resources": {"requests": {"storage": "10Gi"}}}, "status": {"phase": "Pending"}}`,
+			},
+			wantSeverity: SeverityInfo,
+			wantMessage:  "WaitForFirstConsumer",
+		},
+		{
+			name: "provisioning failed",
+			outputs: map[string]string{
+				"get pvc data -n shop": `{"metadata": {"name": "data", "namespace": "shop"}, "spec": {"storageClassName": "fixed", "resources": {"requests": {"storage": "10Gi"}}}, "status": {"phase": "Pending"}}`,
+				"get events -n shop":   `{"items": [{"type": "Warning", "reason": "ProvisioningFailed", "message": "failed to provision volume: quota exceeded", "involvedObject": {"kind": "PersistentVolumeClaim", "namespace": "shop", "name": "data"}}]}`,
+			},
+			wantSeverity: SeverityCritical,
+			wantMessage:  "provisioning failed",
+		},
+		{
+			name: "static volume too small",
+			outputs: map[string]string{
+				"get pvc data -n shop":  `{"metadata": {"name": "data", "namespace": "shop"}, "spec": {"storageClassName": "", "accessModes": ["ReadWriteMany"], "resources": {"requests": {"storage": "10Gi"}}}, "status": {"phase": "Pending"}}`,
+				"get persistentvolumes": `{"items": [{"metadata": {"name": "nfs-1"}, "spec": {"capacity": {"storage": "5Gi"}, "accessModes": ["ReadWriteOnce"], "storageClassName": ""}, "status": {"phase": "Available"}}]}`,
+			},
+			wantSeverity: SeverityCritical,
+			wantMessage:  "No PersistentVolume can bind",
+		},
+		{
+			name: "zone mismatch",
+			outputs: map[string]string{
+				"get pods -n shop": `{"items": [` + podUsing("web-1", "node-2") + `]}`,
+			},
+			wantSeverity: SeverityCritical,
+			wantMessage:  "outside the volume's node affinity",
+		},
+		{
+			name: "read-write-once on two nodes",
+			outputs: map[string]string{
+				"get persistentvolumes": `{"items": [{"metadata": {"name": "pv-data"}, "spec": {"capacity": {"storage": "10Gi"}, "accessModes": ["ReadWriteOnce"], "csi": {"driver": "disk.csi.azure.com"}}, "status": {"phase": "Bound"}}]}`,
+				"get csinodes":          `{"items": []}`,
+				"get pods -n shop":      `{"items": [` + podUsing("web-1", "node-1") + `, ` + podUsing("web-2", "node-2") + `]}`,
+			},
+			wantSeverity: SeverityCritical,
+			wantMessage:  "used by pods on 2 nodes",
+		},
+		{
+			name: "attach error",
+			outputs: map[string]string{
+				"get pods -n shop":      `{"items": [` + podUsing("web-1", "node-1") + `]}`,
+				"get volumeattachments": `{"items": [{"metadata": {"name": "csi-123"}, "spec": {"attacher": "disk.csi.azure.com", "nodeName": "node-1", "source": {"persistentVolumeName": "pv-data"}}, "status": {"attached": false, "attachError": {"message": "disk is attached to another VM"}}}]}`,
+			},
+			wantSeverity: SeverityCritical,
+			wantMessage:  "Attaching the volume to node node-1 failed",
+		},
+		{
+			name: "csi driver not on node",
+			outputs: map[string]string{
+				"get persistentvolumes": `{"items": [{"metadata": {"name": "pv-data"}, "spec": {"capacity": {"storage": "10Gi"}, "accessModes": ["ReadWriteOnce"], "csi": {"driver": "disk.csi.azure.com"}}, "status": {"phase": "Bound"}}]}`,
+				"get pods -n shop":      `{"items": [` + podUsing("web-1", "node-2") + `]}`,
+			},
+			wantSeverity: SeverityCritical,
+			wantMessage:  "not registered on node node-2",
+		},
+		{
+			name: "expansion not allowed",
+			outputs: map[string]string{
+				"get pvc data -n shop": `{"metadata": {"name": "data", "namespace": "shop"}, "spec": {"storageClassName": "fixed", "volumeName": "pv-data", "resources": {"requests": {"storage": "20Gi"}}}, "status": {"phase": "Bound", "capacity": {"storage": "10Gi"}}}`,
+			},
+			wantSeverity: SeverityWarning,
+			wantMessage:  "does not allow volume expansion",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := storageRunner(tt.outputs)
+			report, err := DiagnoseStorage(context.Background(), runner, nil, StorageOptions{Namespace: "shop", PVC: "data"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(report.CollectionErrors) != 0 {
+				t.Errorf("Expected no collection errors, got %v", report.CollectionErrors)
+			}
+			if len(report.Claims) != 1 {
+				t.Fatalf("Expected the requested claim in the report, got %d claims", len(report.Claims))
+			}
+			found := false
+			for _, f := range report.Claims[0].Findings {
+				if f.Severity == tt.wantSeverity && strings.Contains(f.Message, tt.wantMessage) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected a %s finding containing %q, got %+v", tt.wantSeverity, tt.wantMessage, report.Claims[0].Findings)
+			}
+		})
+	}
+}
+
+func TestDiagnoseStorageHealthyClaimAndOrphans(t *testing.T) {
+	runner := storageRunner(map[string]string{
+		"get pods -A":   `{"items": [` + podUsing("web-1", "node-1") + `]}`,
+		"get events -A": `{"items": []}`,
+		"get persistentvolumes": `{"items": [` + boundVolume + `,
+		  {"metadata": {"name": "pv-old"}, "spec": {"capacity": {"storage": "100Gi"}, "storageClassName": "managed-csi", "persistentVolumeReclaimPolicy": "Retain", "claimRef": {"namespace": "shop", "name": "old"}}, "status": {"phase": "Released"}},
+		  {"metadata": {"name": "pv-secret"}, "spec": {"persistentVolumeReclaimPolicy": "Retain", "claimRef": {"namespace": "kube-system", "name": "x"}}, "status": {"phase": "Released"}},
+		  {"metadata": {"name": "pv-static"}, "spec": {"capacity": {"storage": "1Gi"}}, "status": {"phase": "Available"}}
+		]}`,
+	})
+	report, err := DiagnoseStorage(context.Background(), runner, nil, StorageOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.HealthyClaims != 1 || len(report.Claims) != 0 {
+		t.Errorf("Expected the bound claim to be counted as healthy, got %d healthy and %+v", report.HealthyClaims, report.Claims)
+	}
+	if len(report.OrphanedVolumes) != 2 || report.OrphanedVolumes[0].Name != "pv-old" || report.OrphanedVolumes[0].FormerClaim != "shop/old" {
+		t.Errorf("Expected pv-old and pv-secret to be orphaned, got %+v", report.OrphanedVolumes)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Category != CategoryStorage {
+		t.Errorf("Expected one orphaned volume finding, got %+v", report.Findings)
+	}
+
+	// With namespace restrictions, volumes of other namespaces' claims are left out
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("shop")
+	runner.Outputs["get namespaces"] = `{"items": [{"metadata": {"name": "shop"}}, {"metadata": {"name": "kube-system"}}]}`
+	report, err = DiagnoseStorage(context.Background(), runner, secConfig, StorageOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, orphan := range report.OrphanedVolumes {
+		if orphan.Name == "pv-secret" {
+			t.Errorf("Expected volumes of disallowed namespaces to be left out, got %+v", report.OrphanedVolumes)
+		}
+	}
+	for _, command := range runner.Commands() {
+		if strings.Contains(command, "-A") && (strings.HasPrefix(command, "get pods") || strings.HasPrefix(command, "get persistentvolumeclaims")) {
+			continue
+		}
+		if strings.Contains(command, "kube-system") {
+			t.Errorf("Expected no commands in disallowed namespaces, got %q", command)
+		}
+	}
+}
+
+func TestDiagnoseStoragePVCRequiresNamespace(t *testing.T) {
+	runner := storageRunner(nil)
+	if _, err := DiagnoseStorage(context.Background(), runner, nil, StorageOptions{PVC: "data"}); err == nil {
+		t.Error("Expected error for a pvc without namespace")
+	}
+	if len(runner.Commands()) != 0 {
+		t.Errorf("Expected no commands, got %v", runner.Commands())
+	}
+}
@@ -0,0 +1,241 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// serverBlock is a parsed CoreDNS server block ("zone:port { plugins }")
+type serverBlock struct {
+	// Zones are the zones the block serves, without ports ("." for the root zone)
+	Zones []string
+	// Plugins are the plugin names used in the block
+	Plugins []string
+	// Forward holds the upstreams of the forward plugin
+	Forward []string
+	// KubernetesZones are the zones served by the kubernetes plugin
+	KubernetesZones []string
+	// Source is where the block was read from (ConfigMap/key)
+	Source string
+}
+
+// hasPlugin reports whether the block uses the plugin
+func (b serverBlock) hasPlugin(name string) bool {
+	for _, p := range b.Plugins {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isRoot reports whether the block serves the root zone
+func (b serverBlock) isRoot() bool {
+	for _, zone := range b.Zones {
+		if zone == "." {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCorefile parses the server blocks of a Corefile. Nested plugin blocks
+// are skipped; only the top-level plugin lines of each server block are read.
+func parseCorefile(content, source string) []serverBlock {
+	var blocks []serverBlock
+	var current *serverBlock
+	depth := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		opens := strings.Count(line, "{")
+		closes := strings.Count(line, "}")
+
+		switch {
+		case depth == 0 && opens > 0:
+			block := serverBlock{Source: source}
+			for _, f := range fields {
+				if f == "{" {
+					break
+				}
+				block.Zones = append(block.Zones, normalizeZone(strings.TrimSuffix(f, "{")))
+			}
+			blocks = append(blocks, block)
+			current = &blocks[len(blocks)-1]
+		case depth == 1 && current != nil:
+			parsePluginLine(current, fields)
+		}
+
+		depth += opens - closes
+		if depth <= 0 {
+			depth = 0
+			current = nil
+		}
+	}
+	return blocks
+}
+
+// parseOverride parses plugin lines that AKS merges into the default server
+// block (coredns-custom keys ending in .override)
+func parseOverride(content, source string) serverBlock {
+	block := serverBlock{Zones: []string{"."}, Source: source}
+	depth := 0
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if depth == 0 {
+			parsePluginLine(&block, fields)
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	return block
+}
+
+// parsePluginLine records a top-level plugin line of a server block
+func parsePluginLine(block *serverBlock, fields []string) {
+	if fields[0] == "}" || fields[0] == "{" {
+		return
+	}
+	plugin := strings.TrimSuffix(fields[0], "{")
+	block.Plugins = append(block.Plugins, plugin)
+
+	args := pluginArgs(fields[1:])
+	switch plugin {
+	case "forward":
+		// forward FROM TO...
+		if len(args) > 1 {
+			block.Forward = append(block.Forward, args[1:]...)
+		}
+	case "kubernetes":
+		block.KubernetesZones = append(block.KubernetesZones, args...)
+	}
+}
+
+// pluginArgs returns plugin arguments up to an opening brace
+func pluginArgs(fields []string) []string {
+	var args []string
+	for _, f := range fields {
+		if f == "{" {
+			break
+		}
+		args = append(args, strings.TrimSuffix(f, "{"))
+	}
+	return args
+}
+
+// normalizeZone strips the scheme and port from a server block key,
+// e.g. "dns://example.com:53" -> "example.com"
+func normalizeZone(zone string) string {
+	zone = strings.TrimPrefix(zone, "dns://")
+	if i := strings.LastIndex(zone, ":"); i >= 0 {
+		zone = zone[:i]
+	}
+	if zone == "" {
+		return "."
+	}
+	return zone
+}
+
+// validateUpstream checks a forward target. The forward plugin accepts IP
+// addresses (optionally with a port and dns:// or tls:// scheme) and
+// resolv.conf-style file paths; host names are rejected by CoreDNS.
+func validateUpstream(target string) error {
+	if strings.HasPrefix(target, "/") {
+		return nil
+	}
+	for _, scheme := range []string{"dns://", "tls://", "grpc://", "https://"} {
+		target = strings.TrimPrefix(target, scheme)
+	}
+
+	host := target
+	if h, _, err := net.SplitHostPort(target); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if net.ParseIP(host) == nil {
+		return fmt.Errorf("'%s' is not a valid IP address", target)
+	}
+	return nil
+}
+
+// StubDomain is a non-root zone forwarded to dedicated upstreams
+type StubDomain struct {
+	Zone      string   `json:"zone"`
+	Upstreams []string `json:"upstreams"`
+	Source    string   `json:"source"`
+}
+
+// analyzeCorefiles checks the default Corefile and the custom configuration
+func analyzeCorefiles(main []serverBlock, custom []serverBlock, overrides []serverBlock) ([]Cause, []StubDomain, string) {
+	var causes []Cause
+	var stubDomains []StubDomain
+	clusterDomain := "cluster.local"
+
+	if len(main) > 0 {
+		var root *serverBlock
+		for i := range main {
+			if main[i].isRoot() {
+				root = &main[i]
+				break
+			}
+		}
+		switch {
+		case root == nil:
+			causes = append(causes, Cause{Score: 95, Severity: SeverityCritical, Component: ComponentCoreDNSConfig,
+				Summary:     "CoreDNS Corefile has no server block for the root zone '.'",
+				Evidence:    main[0].Source,
+				Remediation: "Restore the default '.:53' server block in the coredns ConfigMap"})
+		case !root.hasPlugin("kubernetes"):
+			causes = append(causes, Cause{Score: 95, Severity: SeverityCritical, Component: ComponentCoreDNSConfig,
+				Summary:     "CoreDNS root server block has no kubernetes plugin, so cluster service names cannot resolve",
+				Evidence:    root.Source,
+				Remediation: "Restore the 'kubernetes cluster.local in-addr.arpa ip6.arpa' plugin in the coredns ConfigMap"})
+		case len(root.KubernetesZones) > 0:
+			clusterDomain = root.KubernetesZones[0]
+		}
+	}
+
+	for _, block := range custom {
+		if block.isRoot() {
+			causes = append(causes, Cause{Score: 80, Severity: SeverityCritical, Component: ComponentCoreDNSConfig,
+				Summary:     "Custom CoreDNS server block redefines the root zone '.', which conflicts with the default server block",
+				Evidence:    block.Source,
+				Remediation: "Move upstream changes into a '.override' key (forward plugin) instead of a '.server' block for '.'"})
+		} else {
+			for _, zone := range block.Zones {
+				stubDomains = append(stubDomains, StubDomain{Zone: zone, Upstreams: block.Forward, Source: block.Source})
+			}
+		}
+	}
+
+	for _, block := range append(append(append([]serverBlock{}, main...), custom...), overrides...) {
+		for _, upstream := range block.Forward {
+			if err := validateUpstream(upstream); err != nil {
+				causes = append(causes, Cause{Score: 90, Severity: SeverityCritical, Component: ComponentCoreDNSConfig,
+					Summary:     fmt.Sprintf("CoreDNS forwards zone %s to an invalid upstream: %v", strings.Join(block.Zones, ","), err),
+					Evidence:    block.Source,
+					Remediation: "Fix the forward plugin to point at reachable DNS server IP addresses"})
+			}
+		}
+	}
+
+	sort.Slice(stubDomains, func(i, j int) bool { return stubDomains[i].Zone < stubDomains[j].Zone })
+	return causes, stubDomains, clusterDomain
+}
@@ -0,0 +1,61 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// DNSExecutor implements the CommandExecutor interface for troubleshoot_dns
+type DNSExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures DNSExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*DNSExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*DNSExecutor)(nil)
+
+// NewExecutor creates a new DNSExecutor instance
+func NewExecutor() *DNSExecutor {
+	return &DNSExecutor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute troubleshoots cluster DNS and returns the report as JSON
+func (e *DNSExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := Options{}
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.Pod, _ = params["pod"].(string)
+	opts.RunLookup, _ = params["run_lookup"].(bool)
+	opts.LookupName, _ = params["lookup_name"].(string)
+
+	if opts.RunLookup && cfg.SecurityConfig.AccessLevel == security.AccessLevelReadOnly {
+		return "", fmt.Errorf("run_lookup requires readwrite or admin access level")
+	}
+
+	if err := errors.Join(k8s.ValidateNamespace("namespace", opts.Namespace), k8s.ValidateName("pod", opts.Pod)); err != nil {
+		return "", err
+	}
+
+	report, err := Troubleshoot(ctx, e.newRunner(cfg), opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that troubleshoot_dns always returns a JSON report
+func (e *DNSExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
@@ -0,0 +1,49 @@
+package dns
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterTroubleshootDNS registers the troubleshoot_dns tool. The optional
+// lookup pod is only offered at readwrite and admin access levels.
+func RegisterTroubleshootDNS(accessLevel string) mcp.Tool {
+	description := `Troubleshoot cluster DNS and return a ranked list of probable causes.
+
+Inspects the CoreDNS deployment and pods, the coredns and coredns-custom ConfigMaps (root zone, kubernetes plugin, forward upstreams, custom stub domains), and the kube-dns Service and endpoints.
+With a namespace, also finds NetworkPolicies that block egress to kube-dns on UDP/TCP 53 and checks pod dnsPolicy/dnsConfig (nameservers, search domains, ndots).`
+
+	opts := []mcp.ToolOption{
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the workloads with DNS problems (enables NetworkPolicy and pod DNS checks)"),
+		),
+		mcp.WithString("pod",
+			mcp.Description("Restrict pod DNS checks to this pod (requires namespace)"),
+		),
+	}
+
+	readOnly := accessLevel == "readonly"
+	if !readOnly {
+		description += `
+With run_lookup, creates a temporary busybox pod in the namespace that resolves a name with nslookup and is deleted afterwards. Creating the pod is a write and needs readwrite or admin access.`
+		opts = append(opts,
+			mcp.WithBoolean("run_lookup",
+				mcp.Description("Create a temporary lookup pod in the namespace (default: false)"),
+			),
+			mcp.WithString("lookup_name",
+				mcp.Description("Name resolved by the lookup pod (default: kubernetes.default.svc.cluster.local)"),
+			),
+		)
+	}
+
+	opts = append([]mcp.ToolOption{mcp.WithDescription(description)}, opts...)
+	opts = append(opts, mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		Title:        "Troubleshoot DNS",
+		ReadOnlyHint: boolPtr(readOnly),
+	}))
+	return mcp.NewTool("troubleshoot_dns", opts...)
+}
@@ -0,0 +1,679 @@
+package dns
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// Cause severities
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Cause components
+const (
+	ComponentCoreDNS       = "coredns"
+	ComponentCoreDNSConfig = "coredns-config"
+	ComponentService       = "kube-dns-service"
+	ComponentNetworkPolicy = "network-policy"
+	ComponentPodDNSConfig  = "pod-dns-config"
+	ComponentLookup        = "lookup"
+)
+
+const (
+	// dnsSystemNamespace is where CoreDNS runs
+	dnsSystemNamespace = "kube-system"
+	// dnsLabelSelector selects the CoreDNS deployment and pods
+	dnsLabelSelector = "k8s-app=kube-dns"
+	// defaultNdots is the ndots value the kubelet configures for ClusterFirst pods
+	defaultNdots = 5
+	// defaultLookupName is resolved by the optional lookup pod
+	defaultLookupName = "kubernetes.default.svc.cluster.local"
+	// lookupImage is the image used for the optional lookup pod
+	lookupImage = "busybox:1.36"
+)
+
+// lookupNamePattern restricts lookup names to DNS name characters
+var lookupNamePattern = regexp.MustCompile(`^[a-zA-Z0-9.-]+$`)
+
+// Cause is a probable cause of DNS failures. Causes are ranked by Score.
+type Cause struct {
+	Rank        int      `json:"rank"`
+	Score       int      `json:"score"`
+	Severity    string   `json:"severity"`
+	Component   string   `json:"component"`
+	Summary     string   `json:"summary"`
+	Evidence    string   `json:"evidence,omitempty"`
+	Remediation string   `json:"remediation,omitempty"`
+	Affected    []string `json:"affected,omitempty"`
+}
+
+// Report is the structured result of troubleshoot_dns
+type Report struct {
+	Namespace        string        `json:"namespace,omitempty"`
+	ClusterDNSIP     string        `json:"clusterDNSIP,omitempty"`
+	ClusterDomain    string        `json:"clusterDomain"`
+	CoreDNS          CoreDNSStatus `json:"coredns"`
+	StubDomains      []StubDomain  `json:"stubDomains,omitempty"`
+	Lookup           *LookupResult `json:"lookup,omitempty"`
+	Causes           []Cause       `json:"causes"`
+	CollectionErrors []string      `json:"collectionErrors,omitempty"`
+}
+
+// CoreDNSStatus summarizes the CoreDNS deployment, pods and endpoints
+type CoreDNSStatus struct {
+	DesiredReplicas   int `json:"desiredReplicas"`
+	AvailableReplicas int `json:"availableReplicas"`
+	ReadyPods         int `json:"readyPods"`
+	Restarts          int `json:"restarts"`
+	ReadyEndpoints    int `json:"readyEndpoints"`
+	NotReadyEndpoints int `json:"notReadyEndpoints"`
+}
+
+// LookupResult is the outcome of the ephemeral lookup pod
+type LookupResult struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Succeeded bool   `json:"succeeded"`
+	Output    string `json:"output"`
+}
+
+// Options controls what troubleshoot_dns inspects
+type Options struct {
+	// Namespace whose pods and NetworkPolicies are checked (optional)
+	Namespace string
+	// Pod restricts the pod checks to a single pod (optional)
+	Pod string
+	// RunLookup starts an ephemeral pod in Namespace that resolves LookupName
+	RunLookup  bool
+	LookupName string
+}
+
+// clusterData is everything collected for the analysis
+type clusterData struct {
+	deployments      []k8s.Deployment
+	dnsPods          []k8s.Pod
+	corefile         *k8s.ConfigMap
+	customConfig     *k8s.ConfigMap
+	service          *k8s.Service
+	endpoints        *k8s.Endpoints
+	kubeSystemLabels map[string]string
+	policies         []k8s.NetworkPolicy
+	pods             []k8s.Pod
+	lookup           *LookupResult
+	errors           []string
+}
+
+// Troubleshoot collects the CoreDNS deployment, configuration, service and
+// endpoints together with the namespace's pods and NetworkPolicies, and
+// returns a ranked list of probable causes
+func Troubleshoot(ctx context.Context, runner k8s.Runner, opts Options) (*Report, error) {
+	if opts.RunLookup && opts.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required to run a lookup pod")
+	}
+	if opts.Pod != "" && opts.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required when pod is specified")
+	}
+	if opts.LookupName == "" {
+		opts.LookupName = defaultLookupName
+	}
+	if !lookupNamePattern.MatchString(opts.LookupName) {
+		return nil, fmt.Errorf("invalid lookup_name '%s'", opts.LookupName)
+	}
+
+	data := collect(ctx, runner, opts)
+	return analyze(data, opts), nil
+}
+
+// collect gathers all inputs in parallel. Failures are recorded rather than
+// returned, so that a denied kube-system read still yields the namespace checks.
+func collect(ctx context.Context, runner k8s.Runner, opts Options) *clusterData {
+	data := &clusterData{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	fetch := func(label, command string, out interface{}, optional bool, store func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := k8s.GetJSON(ctx, runner, command, out)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if !(optional && strings.Contains(err.Error(), "NotFound")) {
+					data.errors = append(data.errors, fmt.Sprintf("%s: %v", label, err))
+				}
+				return
+			}
+			store()
+		}()
+	}
+
+	var deployments k8s.List[k8s.Deployment]
+	fetch("coredns deployment", fmt.Sprintf("get deployments -n %s -l %s", dnsSystemNamespace, dnsLabelSelector), &deployments, false,
+		func() { data.deployments = deployments.Items })
+	var dnsPods k8s.List[k8s.Pod]
+	fetch("coredns pods", fmt.Sprintf("get pods -n %s -l %s", dnsSystemNamespace, dnsLabelSelector), &dnsPods, false,
+		func() { data.dnsPods = dnsPods.Items })
+	var corefile k8s.ConfigMap
+	fetch("coredns configmap", "get configmap coredns -n "+dnsSystemNamespace, &corefile, true,
+		func() { data.corefile = &corefile })
+	var customConfig k8s.ConfigMap
+	fetch("coredns-custom configmap", "get configmap coredns-custom -n "+dnsSystemNamespace, &customConfig, true,
+		func() { data.customConfig = &customConfig })
+	var service k8s.Service
+	fetch("kube-dns service", "get service kube-dns -n "+dnsSystemNamespace, &service, false,
+		func() { data.service = &service })
+	var endpoints k8s.Endpoints
+	fetch("kube-dns endpoints", "get endpoints kube-dns -n "+dnsSystemNamespace, &endpoints, false,
+		func() { data.endpoints = &endpoints })
+	var kubeSystem k8s.Namespace
+	fetch("kube-system namespace", "get namespace "+dnsSystemNamespace, &kubeSystem, false,
+		func() { data.kubeSystemLabels = kubeSystem.Metadata.Labels })
+
+	if opts.Namespace != "" {
+		var policies k8s.List[k8s.NetworkPolicy]
+		fetch("network policies", "get networkpolicies -n "+opts.Namespace, &policies, false,
+			func() { data.policies = policies.Items })
+		if opts.Pod != "" {
+			var pod k8s.Pod
+			fetch("pod", fmt.Sprintf("get pod %s -n %s", opts.Pod, opts.Namespace), &pod, false,
+				func() { data.pods = []k8s.Pod{pod} })
+		} else {
+			var pods k8s.List[k8s.Pod]
+			fetch("pods", "get pods -n "+opts.Namespace, &pods, false,
+				func() { data.pods = pods.Items })
+		}
+	}
+
+	if opts.RunLookup {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := runLookup(ctx, runner, opts.Namespace, opts.LookupName)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				data.errors = append(data.errors, fmt.Sprintf("lookup: %v", err))
+				return
+			}
+			data.lookup = result
+		}()
+	}
+
+	wg.Wait()
+	sort.Strings(data.errors)
+	return data
+}
+
+// runLookup resolves a name from a temporary pod that is removed afterwards.
+// "kubectl run" is a readwrite operation, so the validator rejects it at
+// readonly access, and k8s.Client invalidates the namespace's cached
+// responses once it ran.
+func runLookup(ctx context.Context, runner k8s.Runner, namespace, name string) (*LookupResult, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	podName := "dns-check-" + hex.EncodeToString(suffix)
+	command := fmt.Sprintf("run %s -n %s --image=%s --restart=Never --rm -i --quiet --pod-running-timeout=60s --command -- nslookup %s",
+		podName, namespace, lookupImage, name)
+
+	output, err := runner.Run(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	return &LookupResult{
+		Name:      name,
+		Namespace: namespace,
+		Succeeded: lookupSucceeded(output),
+		Output:    strings.TrimSpace(output),
+	}, nil
+}
+
+// lookupSucceeded reports whether nslookup output contains an answer
+func lookupSucceeded(output string) bool {
+	lower := strings.ToLower(output)
+	if strings.Contains(lower, "can't resolve") || strings.Contains(lower, "nxdomain") ||
+		strings.Contains(lower, "timed out") || strings.Contains(lower, "no servers could be reached") {
+		return false
+	}
+	// The first Address line is the server; an answer adds a Name line
+	return strings.Contains(output, "Name:")
+}
+
+// analyze turns the collected data into a ranked report
+func analyze(data *clusterData, opts Options) *Report {
+	report := &Report{
+		Namespace:        opts.Namespace,
+		Lookup:           data.lookup,
+		CollectionErrors: data.errors,
+	}
+	var causes []Cause
+
+	report.CoreDNS, causes = analyzeCoreDNS(data)
+
+	var mainBlocks, customBlocks, overrideBlocks []serverBlock
+	if data.corefile != nil {
+		mainBlocks = parseCorefile(data.corefile.Data["Corefile"], "configmap/coredns")
+	}
+	if data.customConfig != nil {
+		keys := make([]string, 0, len(data.customConfig.Data))
+		for key := range data.customConfig.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			source := "configmap/coredns-custom:" + key
+			switch {
+			case strings.HasSuffix(key, ".server"):
+				customBlocks = append(customBlocks, parseCorefile(data.customConfig.Data[key], source)...)
+			case strings.HasSuffix(key, ".override"):
+				overrideBlocks = append(overrideBlocks, parseOverride(data.customConfig.Data[key], source))
+			}
+		}
+	}
+	configCauses, stubDomains, clusterDomain := analyzeCorefiles(mainBlocks, customBlocks, overrideBlocks)
+	causes = append(causes, configCauses...)
+	report.StubDomains = stubDomains
+	report.ClusterDomain = clusterDomain
+
+	if data.service != nil {
+		report.ClusterDNSIP = data.service.Spec.ClusterIP
+	}
+
+	if opts.Namespace != "" {
+		causes = append(causes, analyzeNetworkPolicies(data)...)
+		causes = append(causes, analyzePodDNSConfig(data.pods, report.ClusterDNSIP, clusterDomain)...)
+	}
+
+	if data.lookup != nil && !data.lookup.Succeeded {
+		causes = append(causes, Cause{Score: 70, Severity: SeverityCritical, Component: ComponentLookup,
+			Summary:  fmt.Sprintf("Lookup of %s from namespace %s failed", data.lookup.Name, data.lookup.Namespace),
+			Evidence: lastLine(data.lookup.Output)})
+	}
+
+	report.Causes = rankCauses(causes)
+	return report
+}
+
+// analyzeCoreDNS checks the CoreDNS deployment, pods, service and endpoints
+func analyzeCoreDNS(data *clusterData) (CoreDNSStatus, []Cause) {
+	var status CoreDNSStatus
+	var causes []Cause
+
+	for _, deployment := range data.deployments {
+		desired := 1
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		status.DesiredReplicas += desired
+		status.AvailableReplicas += deployment.Status.AvailableReplicas
+	}
+	var crashing []string
+	for _, pod := range data.dnsPods {
+		ready := true
+		for _, c := range pod.Status.ContainerStatuses {
+			status.Restarts += c.RestartCount
+			ready = ready && c.Ready
+			if c.State.Waiting != nil && c.State.Waiting.Reason == "CrashLoopBackOff" {
+				crashing = append(crashing, pod.Metadata.Name)
+			}
+		}
+		if ready && len(pod.Status.ContainerStatuses) > 0 {
+			status.ReadyPods++
+		}
+	}
+	if data.endpoints != nil {
+		for _, subset := range data.endpoints.Subsets {
+			status.ReadyEndpoints += len(subset.Addresses)
+			status.NotReadyEndpoints += len(subset.NotReadyAddresses)
+		}
+	}
+
+	deploymentsKnown := !hasError(data.errors, "coredns deployment")
+	switch {
+	case deploymentsKnown && len(data.deployments) == 0:
+		causes = append(causes, Cause{Score: 100, Severity: SeverityCritical, Component: ComponentCoreDNS,
+			Summary:     "No CoreDNS deployment (label " + dnsLabelSelector + ") found in " + dnsSystemNamespace,
+			Remediation: "Check whether CoreDNS was deleted or scaled away"})
+	case deploymentsKnown && status.AvailableReplicas == 0:
+		causes = append(causes, Cause{Score: 100, Severity: SeverityCritical, Component: ComponentCoreDNS,
+			Summary:  fmt.Sprintf("CoreDNS has no available replicas (0/%d)", status.DesiredReplicas),
+			Affected: crashing})
+	case status.AvailableReplicas < status.DesiredReplicas:
+		causes = append(causes, Cause{Score: 60, Severity: SeverityWarning, Component: ComponentCoreDNS,
+			Summary:  fmt.Sprintf("CoreDNS is degraded (%d/%d replicas available)", status.AvailableReplicas, status.DesiredReplicas),
+			Affected: crashing})
+	}
+	if len(crashing) > 0 && status.AvailableReplicas > 0 {
+		causes = append(causes, Cause{Score: 65, Severity: SeverityWarning, Component: ComponentCoreDNS,
+			Summary:     "CoreDNS pods are crash-looping, often due to an invalid Corefile",
+			Affected:    crashing,
+			Remediation: "Check 'kubectl logs -n kube-system -l k8s-app=kube-dns' for Corefile errors"})
+	}
+
+	if !hasError(data.errors, "kube-dns service") && data.service == nil {
+		causes = append(causes, Cause{Score: 100, Severity: SeverityCritical, Component: ComponentService,
+			Summary: "kube-dns Service is missing in " + dnsSystemNamespace})
+	}
+	if data.endpoints != nil && status.ReadyEndpoints == 0 {
+		causes = append(causes, Cause{Score: 95, Severity: SeverityCritical, Component: ComponentService,
+			Summary:  "kube-dns Service has no ready endpoints, so every lookup times out",
+			Evidence: fmt.Sprintf("%d not-ready endpoint(s)", status.NotReadyEndpoints)})
+	}
+	return status, causes
+}
+
+// analyzeNetworkPolicies finds pods whose egress policies do not allow DNS
+// to kube-dns on UDP or TCP port 53
+func analyzeNetworkPolicies(data *clusterData) []Cause {
+	kubeSystemLabels := data.kubeSystemLabels
+	if kubeSystemLabels == nil {
+		// Set automatically on every namespace since Kubernetes 1.22
+		kubeSystemLabels = map[string]string{"kubernetes.io/metadata.name": dnsSystemNamespace}
+	}
+	dnsPodLabels := map[string]string{"k8s-app": "kube-dns"}
+	if len(data.dnsPods) > 0 {
+		dnsPodLabels = data.dnsPods[0].Metadata.Labels
+	}
+	var dnsIPs []net.IP
+	if data.service != nil {
+		if ip := net.ParseIP(data.service.Spec.ClusterIP); ip != nil {
+			dnsIPs = append(dnsIPs, ip)
+		}
+	}
+	if data.endpoints != nil {
+		for _, subset := range data.endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				if ip := net.ParseIP(address.IP); ip != nil {
+					dnsIPs = append(dnsIPs, ip)
+				}
+			}
+		}
+	}
+	target := dnsTarget{namespaceLabels: kubeSystemLabels, podLabels: dnsPodLabels, ips: dnsIPs}
+
+	// Without pods, each policy's own selector stands in for the pods it selects
+	pods := data.pods
+	if len(pods) == 0 {
+		for _, policy := range data.policies {
+			pods = append(pods, k8s.Pod{Metadata: k8s.ObjectMeta{
+				Name:   "(pods selected by " + policy.Metadata.Name + ")",
+				Labels: policy.Spec.PodSelector.MatchLabels,
+			}})
+		}
+	}
+
+	type blocked struct {
+		policies []string
+		pods     []string
+		udp      bool
+	}
+	byPolicies := make(map[string]*blocked)
+	var keys []string
+	for _, pod := range pods {
+		var selecting []string
+		udpAllowed, tcpAllowed := false, false
+		for _, policy := range data.policies {
+			if !policy.AffectsEgress() || !policy.Spec.PodSelector.Matches(pod.Metadata.Labels) {
+				continue
+			}
+			selecting = append(selecting, policy.Metadata.Name)
+			for _, rule := range policy.Spec.Egress {
+				udpAllowed = udpAllowed || target.allowedBy(rule, "UDP", policy.Metadata.Namespace)
+				tcpAllowed = tcpAllowed || target.allowedBy(rule, "TCP", policy.Metadata.Namespace)
+			}
+		}
+		if len(selecting) == 0 || (udpAllowed && tcpAllowed) {
+			continue
+		}
+
+		key := strings.Join(selecting, ",") + "|" + strconv.FormatBool(udpAllowed)
+		entry, ok := byPolicies[key]
+		if !ok {
+			entry = &blocked{policies: selecting, udp: !udpAllowed}
+			byPolicies[key] = entry
+			keys = append(keys, key)
+		}
+		entry.pods = append(entry.pods, pod.Metadata.Name)
+	}
+
+	var causes []Cause
+	for _, key := range keys {
+		entry := byPolicies[key]
+		cause := Cause{Component: ComponentNetworkPolicy, Affected: entry.pods,
+			Evidence:    "networkpolicy/" + strings.Join(entry.policies, ", networkpolicy/"),
+			Remediation: "Add an egress rule allowing UDP and TCP port 53 to pods labeled k8s-app=kube-dns in namespace kube-system"}
+		if entry.udp {
+			cause.Score, cause.Severity = 92, SeverityCritical
+			cause.Summary = "NetworkPolicy egress rules block DNS (UDP 53) to kube-dns"
+		} else {
+			cause.Score, cause.Severity = 45, SeverityWarning
+			cause.Summary = "NetworkPolicy egress rules allow DNS over UDP but block TCP 53, so large responses fail"
+		}
+		causes = append(causes, cause)
+	}
+	return causes
+}
+
+// dnsTarget describes the kube-dns pods for NetworkPolicy evaluation
+type dnsTarget struct {
+	namespaceLabels map[string]string
+	podLabels       map[string]string
+	ips             []net.IP
+}
+
+// allowedBy reports whether an egress rule allows DNS to kube-dns over the protocol
+func (t dnsTarget) allowedBy(rule k8s.NetworkPolicyEgressRule, protocol, policyNamespace string) bool {
+	if !portAllowsDNS(rule.Ports, protocol) {
+		return false
+	}
+	if len(rule.To) == 0 {
+		return true
+	}
+	for _, peer := range rule.To {
+		if t.peerMatches(peer, policyNamespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerMatches reports whether a peer selects the kube-dns pods
+func (t dnsTarget) peerMatches(peer k8s.NetworkPolicyPeer, policyNamespace string) bool {
+	if peer.IPBlock != nil {
+		_, cidr, err := net.ParseCIDR(peer.IPBlock.CIDR)
+		if err != nil {
+			return false
+		}
+		for _, ip := range t.ips {
+			if cidr.Contains(ip) && !excluded(ip, peer.IPBlock.Except) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if peer.NamespaceSelector == nil {
+		// A bare podSelector selects pods in the policy's own namespace
+		if policyNamespace != dnsSystemNamespace {
+			return false
+		}
+	} else if !peer.NamespaceSelector.Matches(t.namespaceLabels) {
+		return false
+	}
+	return peer.PodSelector == nil || peer.PodSelector.Matches(t.podLabels)
+}
+
+// excluded reports whether the IP falls in one of the except CIDRs
+func excluded(ip net.IP, except []string) bool {
+	for _, e := range except {
+		if _, cidr, err := net.ParseCIDR(e); err == nil && cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// portAllowsDNS reports whether the rule's ports include 53 over the protocol
+func portAllowsDNS(ports []k8s.NetworkPolicyPort, protocol string) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, port := range ports {
+		portProtocol := port.Protocol
+		if portProtocol == "" {
+			portProtocol = "TCP"
+		}
+		if portProtocol != protocol {
+			continue
+		}
+		switch {
+		case port.Port == nil:
+			return true
+		case port.Port.IsStr:
+			// CoreDNS names its ports "dns" (UDP) and "dns-tcp" (TCP)
+			if port.Port.StrVal == "dns" || port.Port.StrVal == "dns-tcp" {
+				return true
+			}
+		case port.Port.IntVal == 53:
+			return true
+		case port.EndPort != nil && port.Port.IntVal <= 53 && *port.EndPort >= 53:
+			return true
+		}
+	}
+	return false
+}
+
+// analyzePodDNSConfig checks pod dnsPolicy and dnsConfig. Pods sharing an
+// owner and configuration are reported together.
+func analyzePodDNSConfig(pods []k8s.Pod, clusterDNSIP, clusterDomain string) []Cause {
+	causes := make(map[string]*Cause)
+	var order []string
+	add := func(score int, severity, summary, evidence, remediation, pod string) {
+		key := summary + "|" + evidence
+		cause, ok := causes[key]
+		if !ok {
+			cause = &Cause{Score: score, Severity: severity, Component: ComponentPodDNSConfig,
+				Summary: summary, Evidence: evidence, Remediation: remediation}
+			causes[key] = cause
+			order = append(order, key)
+		}
+		cause.Affected = append(cause.Affected, pod)
+	}
+
+	for _, pod := range pods {
+		name := pod.Metadata.Name
+		spec := pod.Spec
+		policy := spec.DNSPolicy
+		if policy == "" {
+			policy = "ClusterFirst"
+		}
+
+		switch {
+		case policy == "Default":
+			add(75, SeverityCritical, "Pod uses dnsPolicy Default (the node's resolv.conf), so cluster service names do not resolve",
+				"dnsPolicy: Default", "Use dnsPolicy ClusterFirst unless the pod must only resolve external names", name)
+		case policy == "ClusterFirst" && spec.HostNetwork:
+			add(70, SeverityCritical, "Pod uses hostNetwork with dnsPolicy ClusterFirst, which falls back to the node's resolv.conf",
+				"hostNetwork: true, dnsPolicy: ClusterFirst", "Use dnsPolicy ClusterFirstWithHostNet", name)
+		}
+
+		if spec.DNSConfig == nil {
+			continue
+		}
+		config := spec.DNSConfig
+
+		if policy == "None" && clusterDNSIP != "" && !containsString(config.Nameservers, clusterDNSIP) {
+			add(85, SeverityCritical,
+				fmt.Sprintf("Pod uses dnsPolicy None with nameservers %s instead of the cluster DNS %s", strings.Join(config.Nameservers, ","), clusterDNSIP),
+				"nameservers: "+strings.Join(config.Nameservers, ","),
+				"Use dnsPolicy ClusterFirst, or add the kube-dns ClusterIP as the first nameserver", name)
+		}
+
+		if policy == "None" && len(config.Searches) > 0 && !hasClusterSearch(config.Searches, clusterDomain) {
+			add(55, SeverityWarning,
+				"Pod search domains do not include the cluster domain, so short service names do not resolve",
+				"searches: "+strings.Join(config.Searches, ","),
+				fmt.Sprintf("Include <namespace>.svc.%s, svc.%s and %s in searches", clusterDomain, clusterDomain, clusterDomain), name)
+		}
+
+		for _, option := range config.Options {
+			if option.Name != "ndots" || option.Value == nil {
+				continue
+			}
+			if ndots, err := strconv.Atoi(*option.Value); err == nil && ndots < defaultNdots {
+				add(25, SeverityInfo,
+					fmt.Sprintf("Pod sets ndots:%d (Kubernetes default is %d); names with %d or more dots skip the search domains", ndots, defaultNdots, ndots),
+					"options: ndots="+*option.Value, "", name)
+			}
+		}
+	}
+
+	result := make([]Cause, 0, len(order))
+	for _, key := range order {
+		result = append(result, *causes[key])
+	}
+	return result
+}
+
+// hasClusterSearch reports whether the search list includes the cluster domain
+func hasClusterSearch(searches []string, clusterDomain string) bool {
+	for _, search := range searches {
+		if search == clusterDomain || strings.HasSuffix(search, "."+clusterDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// rankCauses sorts causes by score and assigns ranks
+func rankCauses(causes []Cause) []Cause {
+	sort.SliceStable(causes, func(i, j int) bool { return causes[i].Score > causes[j].Score })
+	for i := range causes {
+		causes[i].Rank = i + 1
+	}
+	if causes == nil {
+		causes = []Cause{}
+	}
+	return causes
+}
+
+// hasError reports whether a collection error was recorded for the label
+func hasError(errors []string, label string) bool {
+	for _, e := range errors {
+		if strings.HasPrefix(e, label+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether values contains v
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// lastLine returns the last non-empty line of the output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
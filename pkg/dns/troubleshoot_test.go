@@ -0,0 +1,204 @@
+package dns
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// healthyCoreDNS is the kube-system state of a working cluster
+var healthyCoreDNS = map[string]string{
+	"get deployments -n kube-system": `{"items": [{"metadata": {"name": "coredns"}, "spec": {"replicas": 2}, "status": {"availableReplicas": 2}}]}`,
+	"get pods -n kube-system":        `{"items": [{"metadata": {"name": "coredns-1", "labels": {"k8s-app": "kube-dns"}}, "status": {"containerStatuses": [{"name": "coredns", "ready": true}]}}]}`,
+	"get configmap coredns -n":       `{"data": {"Corefile": ".:53 {\n    errors\n    kubernetes cluster.local in-addr.arpa ip6.arpa {\n      pods insecure\n    }\n    forward . /etc/resolv.conf\n    cache 30\n}\n"}}`,
+	"get service kube-dns -n":        `{"spec": {"clusterIP": "10.0.0.10"}}`,
+	"get endpoints kube-dns -n":      `{"subsets": [{"addresses": [{"ip": "10.244.0.5"}]}]}`,
+	"get namespace kube-system":      `{"metadata": {"name": "kube-system", "labels": {"kubernetes.io/metadata.name": "kube-system"}}}`,
+}
+
+func withOutputs(extra map[string]string) map[string]string {
+	outputs := make(map[string]string)
+	for k, v := range healthyCoreDNS {
+		outputs[k] = v
+	}
+	for k, v := range extra {
+		outputs[k] = v
+	}
+	return outputs
+}
+
+func TestTroubleshootHealthyCluster(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: withOutputs(nil)}
+	report, err := Troubleshoot(context.Background(), runner, Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Causes) != 0 {
+		t.Errorf("Expected no causes for a healthy cluster, got %+v", report.Causes)
+	}
+	if report.ClusterDNSIP != "10.0.0.10" || report.ClusterDomain != "cluster.local" {
+		t.Errorf("Expected cluster DNS 10.0.0.10 and domain cluster.local, got %s %s", report.ClusterDNSIP, report.ClusterDomain)
+	}
+	if len(report.CollectionErrors) != 0 {
+		t.Errorf("Expected no collection errors (coredns-custom is optional), got %v", report.CollectionErrors)
+	}
+}
+
+func TestTroubleshootCustomCoreDNSConfig(t *testing.T) {
+	// Mirrors example/test_data/comprehensive-dns-test-env/coredns-custom-config.yaml
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: withOutputs(map[string]string{
+		"get configmap coredns-custom -n": `{"data": {
+			"custom.server": "example.com:53 {\n    forward . 1.1.1.1  # This forward might cause issues\n    log\n}\n",
+			"bad-upstream.server": ". {\n    forward . 192.168.999.999  # Invalid upstream DNS server\n    log\n}\n"
+		}}`,
+	})}
+
+	report, err := Troubleshoot(context.Background(), runner, Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.StubDomains) != 1 || report.StubDomains[0].Zone != "example.com" || report.StubDomains[0].Upstreams[0] != "1.1.1.1" {
+		t.Errorf("Expected example.com stub domain forwarding to 1.1.1.1, got %+v", report.StubDomains)
+	}
+	if len(report.Causes) != 2 {
+		t.Fatalf("Expected invalid upstream and root override causes, got %+v", report.Causes)
+	}
+	if report.Causes[0].Rank != 1 || !strings.Contains(report.Causes[0].Summary, "192.168.999.999") {
+		t.Errorf("Expected the invalid upstream to rank first, got %+v", report.Causes[0])
+	}
+}
+
+func TestTroubleshootNetworkPolicyAndPodDNS(t *testing.T) {
+	// Mirrors restrictive-network-policy.yaml and the dns-dependent-app in failing-workloads.yaml
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: withOutputs(map[string]string{
+		"get networkpolicies -n app": `{"items": [
+			{"metadata": {"name": "restrict-dns-access", "namespace": "app"}, "spec": {
+				"podSelector": {"matchLabels": {"app": "backend-app"}},
+				"policyTypes": ["Egress"],
+				"egress": [{"to": [], "ports": [{"protocol": "TCP", "port": 80}, {"protocol": "TCP", "port": 443}]}]
+			}}
+		]}`,
+		"get pods -n app": `{"items": [
+			{"metadata": {"name": "failing-worker-1", "labels": {"app": "backend-app"}}, "spec": {"containers": [{"name": "worker"}]}},
+			{"metadata": {"name": "dns-dependent-app-1", "labels": {"app": "dns-dependent-app"}}, "spec": {
+				"containers": [{"name": "app"}],
+				"dnsPolicy": "None",
+				"dnsConfig": {"nameservers": ["8.8.8.8"], "searches": ["production.local", "company.internal"], "options": [{"name": "ndots", "value": "2"}]}
+			}}
+		]}`,
+	})}
+
+	report, err := Troubleshoot(context.Background(), runner, Options{Namespace: "app"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	components := make(map[string][]Cause)
+	for _, cause := range report.Causes {
+		components[cause.Component] = append(components[cause.Component], cause)
+	}
+	netpol := components[ComponentNetworkPolicy]
+	if len(netpol) != 1 || netpol[0].Severity != SeverityCritical || netpol[0].Affected[0] != "failing-worker-1" {
+		t.Errorf("Expected restrict-dns-access to block UDP 53 for failing-worker-1, got %+v", netpol)
+	}
+	if len(components[ComponentPodDNSConfig]) != 3 {
+		t.Errorf("Expected nameserver, search and ndots causes, got %+v", components[ComponentPodDNSConfig])
+	}
+	if report.Causes[0].Component != ComponentNetworkPolicy {
+		t.Errorf("Expected the NetworkPolicy to rank first, got %+v", report.Causes[0])
+	}
+}
+
+func TestPortAndPeerAllowDNS(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: withOutputs(map[string]string{
+		"get networkpolicies -n app": `{"items": [
+			{"metadata": {"name": "default-deny", "namespace": "app"}, "spec": {"podSelector": {}, "policyTypes": ["Egress"]}},
+			{"metadata": {"name": "allow-dns", "namespace": "app"}, "spec": {
+				"podSelector": {},
+				"policyTypes": ["Egress"],
+				"egress": [{
+					"to": [{"namespaceSelector": {"matchLabels": {"kubernetes.io/metadata.name": "kube-system"}}, "podSelector": {"matchLabels": {"k8s-app": "kube-dns"}}}],
+					"ports": [{"protocol": "UDP", "port": 53}, {"protocol": "TCP", "port": "dns-tcp"}]
+				}]
+			}}
+		]}`,
+		"get pods -n app": `{"items": [{"metadata": {"name": "web-1", "labels": {"app": "web"}}, "spec": {"containers": [{"name": "web"}]}}]}`,
+	})}
+
+	report, err := Troubleshoot(context.Background(), runner, Options{Namespace: "app"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Causes) != 0 {
+		t.Errorf("Expected allow-dns to permit DNS, got %+v", report.Causes)
+	}
+}
+
+func TestTroubleshootLookup(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.NotFound, Outputs: withOutputs(map[string]string{
+		"get networkpolicies -n app": `{"items": []}`,
+		"get pods -n app":            `{"items": []}`,
+		"run dns-check-":             "Server:\t\t10.0.0.10\nAddress:\t10.0.0.10:53\n\n** server can't find kubernetes.default.svc.cluster.local: NXDOMAIN\n",
+	})}
+
+	report, err := Troubleshoot(context.Background(), runner, Options{Namespace: "app", RunLookup: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Lookup == nil || report.Lookup.Succeeded {
+		t.Fatalf("Expected a failed lookup, got %+v", report.Lookup)
+	}
+	if report.Causes[0].Component != ComponentLookup {
+		t.Errorf("Expected a lookup cause, got %+v", report.Causes)
+	}
+
+	if _, err := Troubleshoot(context.Background(), runner, Options{RunLookup: true}); err == nil {
+		t.Error("Expected error when running a lookup without a namespace")
+	}
+	if _, err := Troubleshoot(context.Background(), runner, Options{Namespace: "app", RunLookup: true, LookupName: "x; rm -rf /"}); err == nil {
+		t.Error("Expected error for an invalid lookup name")
+	}
+}
+
+func TestDNSExecutorRunLookupAccessLevel(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Outputs: withOutputs(map[string]string{
+		"run dns-check-": "Name:\tkubernetes.default.svc.cluster.local\nAddress: 10.0.0.1\n",
+	})}
+	executor := &DNSExecutor{newRunner: func(cfg *config.ConfigData) k8s.Runner { return runner }}
+	params := map[string]interface{}{"namespace": "app", "run_lookup": true}
+
+	cfg := config.NewConfig()
+	cfg.SecurityConfig = security.NewSecurityConfig()
+	cfg.SecurityConfig.AccessLevel = security.AccessLevelReadOnly
+	if _, err := executor.Execute(context.Background(), params, cfg); err == nil || !strings.Contains(err.Error(), "requires readwrite") {
+		t.Errorf("Expected run_lookup to be rejected at readonly, got %v", err)
+	}
+	if len(runner.Commands()) != 0 {
+		t.Errorf("Expected no commands at readonly, got %v", runner.Commands())
+	}
+
+	cfg.SecurityConfig.AccessLevel = security.AccessLevelReadWrite
+	if _, err := executor.Execute(context.Background(), params, cfg); err != nil {
+		t.Fatalf("Expected no error at readwrite, got %v", err)
+	}
+}
+
+func TestRegisterTroubleshootDNS(t *testing.T) {
+	readOnly := RegisterTroubleshootDNS("readonly")
+	if _, ok := readOnly.InputSchema.Properties["run_lookup"]; ok {
+		t.Error("Expected run_lookup to be hidden at readonly")
+	}
+
+	readWrite := RegisterTroubleshootDNS("readwrite")
+	if _, ok := readWrite.InputSchema.Properties["run_lookup"]; !ok {
+		t.Error("Expected run_lookup at readwrite")
+	}
+	if !strings.Contains(readWrite.Description, "creates a temporary busybox pod") {
+		t.Errorf("Expected description to mention the temporary pod, got %q", readWrite.Description)
+	}
+}
@@ -0,0 +1,49 @@
+package flux
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// FluxExecutor implements the CommandExecutor interface for flux commands
+type FluxExecutor struct{}
+
+// This line ensures FluxExecutor implements the CommandExecutor interface
+var _ tools.CommandExecutor = (*FluxExecutor)(nil)
+var _ tools.CommandDescriber = (*FluxExecutor)(nil)
+
+// NewExecutor creates a new FluxExecutor instance
+func NewExecutor() *FluxExecutor {
+	return &FluxExecutor{}
+}
+
+// Execute handles flux command execution
+func (e *FluxExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	fluxCmd, ok := params["command"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid command parameter")
+	}
+
+	// Validate the command against security settings
+	validator := security.NewValidator(cfg.SecurityConfig)
+	err := validator.ValidateCommand(fluxCmd, security.CommandTypeFlux)
+	if err != nil {
+		return "", err
+	}
+
+	// Execute the command
+	process := command.NewShellProcess("flux", cfg.Timeout)
+	process.Limiter = cfg.ProcessLimiter
+	return process.Run(fluxCmd)
+}
+
+// DescribeCommand returns the flux command a call would run
+func (e *FluxExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	fluxCmd, ok := params["command"].(string)
+	return security.CommandTypeFlux, fluxCmd, ok
+}
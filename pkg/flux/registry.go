@@ -0,0 +1,25 @@
+package flux
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterFlux registers the flux tool
+func RegisterFlux() mcp.Tool {
+	return mcp.NewTool("call_flux",
+		mcp.WithDescription("Run Flux CLI commands to inspect and reconcile GitOps resources (get, diff, logs, tree, events; reconcile, suspend and resume need readwrite)"),
+		mcp.WithString("command",
+			mcp.Required(),
+			mcp.Description("Full flux command to execute (e.g., 'flux get kustomizations -n flux-system', 'flux reconcile helmrelease web -n team-a')"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Call Flux",
+			DestructiveHint: boolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,53 @@
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// StatusExecutor implements the CommandExecutor interface for gitops_status
+type StatusExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures StatusExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*StatusExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*StatusExecutor)(nil)
+
+// NewExecutor creates a new StatusExecutor instance
+func NewExecutor() *StatusExecutor {
+	return &StatusExecutor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute summarizes the GitOps resources and returns the report as JSON
+func (e *StatusExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := Options{}
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.IncludeHealthy, _ = params["include_healthy"].(bool)
+
+	if err := k8s.ValidateNamespace("namespace", opts.Namespace); err != nil {
+		return "", err
+	}
+
+	report, err := Summarize(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that gitops_status always returns a JSON report
+func (e *StatusExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
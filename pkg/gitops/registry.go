@@ -0,0 +1,32 @@
+package gitops
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterGitOpsStatus registers the gitops_status tool
+func RegisterGitOpsStatus() mcp.Tool {
+	return mcp.NewTool("gitops_status",
+		mcp.WithDescription(`Summarize the health of Flux Kustomizations and HelmReleases and Argo CD Applications across the allowed namespaces.
+
+Returns counts per kind and the resources that are failed, out of sync, progressing, suspended or unknown, most severe first, with their last reconciliation or sync error, source and revision.`),
+		mcp.WithString("namespace",
+			mcp.Description("Only report resources in this namespace (default: all allowed namespaces)"),
+		),
+		mcp.WithBoolean("include_healthy",
+			mcp.Description("Also list ready resources (default: false)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "GitOps Status",
+			ReadOnlyHint:    boolPtr(true),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,335 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// Statuses of a GitOps resource, most severe first
+const (
+	StatusFailed      = "Failed"
+	StatusOutOfSync   = "OutOfSync"
+	StatusProgressing = "Progressing"
+	StatusSuspended   = "Suspended"
+	StatusUnknown     = "Unknown"
+	StatusReady       = "Ready"
+)
+
+// statusRank orders statuses for the problem list
+var statusRank = map[string]int{
+	StatusFailed:      0,
+	StatusOutOfSync:   1,
+	StatusProgressing: 2,
+	StatusSuspended:   3,
+	StatusUnknown:     4,
+	StatusReady:       5,
+}
+
+// gitopsKind is a GitOps custom resource that is summarized
+type gitopsKind struct {
+	Kind     string
+	Resource string
+	parse    func(item object) Resource
+}
+
+// gitopsKinds are the Flux and Argo CD resources in the report
+var gitopsKinds = []gitopsKind{
+	{Kind: "Kustomization", Resource: "kustomizations.kustomize.toolkit.fluxcd.io", parse: parseFluxResource},
+	{Kind: "HelmRelease", Resource: "helmreleases.helm.toolkit.fluxcd.io", parse: parseFluxResource},
+	{Kind: "Application", Resource: "applications.argoproj.io", parse: parseApplication},
+}
+
+// Options are the parameters of gitops_status
+type Options struct {
+	// Namespace restricts the report to one namespace
+	Namespace string
+	// IncludeHealthy lists ready resources as well as problems
+	IncludeHealthy bool
+}
+
+// Report is the result of gitops_status
+type Report struct {
+	Namespaces []string      `json:"namespaces"`
+	Summary    []KindSummary `json:"summary"`
+	Problems   []Resource    `json:"problems"`
+	Healthy    []Resource    `json:"healthy,omitempty"`
+	Notes      []string      `json:"notes,omitempty"`
+}
+
+// KindSummary counts the resources of one kind by status
+type KindSummary struct {
+	Kind        string `json:"kind"`
+	Total       int    `json:"total"`
+	Ready       int    `json:"ready"`
+	Failed      int    `json:"failed"`
+	OutOfSync   int    `json:"outOfSync"`
+	Progressing int    `json:"progressing"`
+	Suspended   int    `json:"suspended"`
+	Unknown     int    `json:"unknown"`
+}
+
+// Resource is the health of one Flux Kustomization or HelmRelease, or Argo
+// CD Application
+type Resource struct {
+	Kind                 string `json:"kind"`
+	Namespace            string `json:"namespace"`
+	Name                 string `json:"name"`
+	Status               string `json:"status"`
+	Sync                 string `json:"sync,omitempty"`
+	Health               string `json:"health,omitempty"`
+	Source               string `json:"source,omitempty"`
+	DestinationNamespace string `json:"destinationNamespace,omitempty"`
+	Revision             string `json:"revision,omitempty"`
+	AttemptedRevision    string `json:"attemptedRevision,omitempty"`
+	Reason               string `json:"reason,omitempty"`
+	Message              string `json:"message,omitempty"`
+	LastTransition       string `json:"lastTransition,omitempty"`
+}
+
+// object is a Kubernetes object decoded from JSON
+type object map[string]interface{}
+
+// objectList is the JSON output of kubectl get
+type objectList struct {
+	Items []object `json:"items"`
+}
+
+// Summarize lists the Flux Kustomizations and HelmReleases and the Argo CD
+// Applications in the allowed namespaces and reports their health. With
+// namespace restrictions, each allowed namespace is queried on its own.
+// Kinds whose CRD is not installed are skipped with a note.
+func Summarize(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts Options) (*Report, error) {
+	namespaces, err := k8s.ScanNamespaces(ctx, runner, secConfig, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Namespaces: namespaces, Problems: []Resource{}}
+	if len(namespaces) == 0 {
+		report.Notes = append(report.Notes, "No allowed namespace exists in the cluster")
+	}
+
+	for _, kind := range gitopsKinds {
+		summary := KindSummary{Kind: kind.Kind}
+		for _, namespace := range namespaces {
+			scope := "-n " + namespace
+			if namespace == k8s.AllNamespaces {
+				scope = "-A"
+			}
+			var list objectList
+			if err := k8s.GetJSON(ctx, runner, "get "+kind.Resource+" "+scope, &list); err != nil {
+				if isMissingResource(err) {
+					report.Notes = append(report.Notes, fmt.Sprintf("%s (%s) is not installed", kind.Kind, kind.Resource))
+					break
+				}
+				report.Notes = append(report.Notes, fmt.Sprintf("Could not list %s in %s: %v", kind.Resource, namespace, err))
+				continue
+			}
+			for _, item := range list.Items {
+				resource := kind.parse(item)
+				resource.Kind = kind.Kind
+				summary.add(resource.Status)
+				if resource.Status == StatusReady {
+					if opts.IncludeHealthy {
+						report.Healthy = append(report.Healthy, resource)
+					}
+					continue
+				}
+				report.Problems = append(report.Problems, resource)
+			}
+		}
+		report.Summary = append(report.Summary, summary)
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		a, b := report.Problems[i], report.Problems[j]
+		if statusRank[a.Status] != statusRank[b.Status] {
+			return statusRank[a.Status] < statusRank[b.Status]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Kind+"/"+a.Name < b.Kind+"/"+b.Name
+	})
+	return report, nil
+}
+
+// add counts a resource status
+func (s *KindSummary) add(status string) {
+	s.Total++
+	switch status {
+	case StatusReady:
+		s.Ready++
+	case StatusFailed:
+		s.Failed++
+	case StatusOutOfSync:
+		s.OutOfSync++
+	case StatusProgressing:
+		s.Progressing++
+	case StatusSuspended:
+		s.Suspended++
+	default:
+		s.Unknown++
+	}
+}
+
+// parseFluxResource reports a Flux Kustomization or HelmRelease from its
+// Ready and Stalled conditions
+func parseFluxResource(item object) Resource {
+	r := Resource{
+		Namespace:         str(item, "metadata", "namespace"),
+		Name:              str(item, "metadata", "name"),
+		Revision:          str(item, "status", "lastAppliedRevision"),
+		AttemptedRevision: str(item, "status", "lastAttemptedRevision"),
+		Status:            StatusUnknown,
+	}
+	if r.Revision == "" {
+		// helm.toolkit.fluxcd.io/v2 records releases in status.history
+		if history, _ := nested(item, "status", "history").([]interface{}); len(history) > 0 {
+			latest, _ := history[0].(map[string]interface{})
+			r.Revision = str(latest, "chartVersion")
+		}
+	}
+	if r.AttemptedRevision == r.Revision {
+		r.AttemptedRevision = ""
+	}
+
+	if sourceKind, sourceName := str(item, "spec", "sourceRef", "kind"), str(item, "spec", "sourceRef", "name"); sourceName != "" {
+		r.Source = sourceKind + "/" + sourceName
+	} else if chart := str(item, "spec", "chart", "spec", "chart"); chart != "" {
+		r.Source = chart
+		if sourceName := str(item, "spec", "chart", "spec", "sourceRef", "name"); sourceName != "" {
+			r.Source = str(item, "spec", "chart", "spec", "sourceRef", "kind") + "/" + sourceName + " " + chart
+		}
+	} else if refName := str(item, "spec", "chartRef", "name"); refName != "" {
+		r.Source = str(item, "spec", "chartRef", "kind") + "/" + refName
+	}
+
+	ready := condition(item, "Ready")
+	if ready != nil {
+		r.Reason = str(ready, "reason")
+		r.Message = str(ready, "message")
+		r.LastTransition = str(ready, "lastTransitionTime")
+		switch str(ready, "status") {
+		case "True":
+			r.Status = StatusReady
+		case "False":
+			r.Status = StatusFailed
+		case "Unknown":
+			r.Status = StatusProgressing
+		}
+	}
+	if stalled := condition(item, "Stalled"); stalled != nil && str(stalled, "status") == "True" {
+		r.Status = StatusFailed
+		r.Reason = str(stalled, "reason")
+		r.Message = str(stalled, "message")
+	}
+	if suspend, _ := nested(item, "spec", "suspend").(bool); suspend {
+		r.Status = StatusSuspended
+	}
+	return r
+}
+
+// parseApplication reports an Argo CD Application from its sync status,
+// health, last operation and error conditions
+func parseApplication(item object) Resource {
+	r := Resource{
+		Namespace:            str(item, "metadata", "namespace"),
+		Name:                 str(item, "metadata", "name"),
+		Sync:                 str(item, "status", "sync", "status"),
+		Health:               str(item, "status", "health", "status"),
+		Revision:             str(item, "status", "sync", "revision"),
+		DestinationNamespace: str(item, "spec", "destination", "namespace"),
+		LastTransition:       str(item, "status", "operationState", "finishedAt"),
+	}
+	if repo := str(item, "spec", "source", "repoURL"); repo != "" {
+		r.Source = repo
+		if path := str(item, "spec", "source", "path"); path != "" {
+			r.Source += " " + path
+		} else if chart := str(item, "spec", "source", "chart"); chart != "" {
+			r.Source += " " + chart
+		}
+	}
+	if r.LastTransition == "" {
+		r.LastTransition = str(item, "status", "reconciledAt")
+	}
+
+	var failures []string
+	conditions, _ := nested(item, "status", "conditions").([]interface{})
+	for _, c := range conditions {
+		c, _ := c.(map[string]interface{})
+		if conditionType := str(c, "type"); strings.HasSuffix(conditionType, "Error") {
+			failures = append(failures, conditionType+": "+str(c, "message"))
+		}
+	}
+	phase := str(item, "status", "operationState", "phase")
+
+	switch {
+	case len(failures) > 0:
+		r.Status = StatusFailed
+		r.Reason = "ConditionError"
+		r.Message = strings.Join(failures, "; ")
+	case phase == "Failed" || phase == "Error":
+		r.Status = StatusFailed
+		r.Reason = "Sync" + phase
+		r.Message = str(item, "status", "operationState", "message")
+	case r.Health == "Degraded" || r.Health == "Missing":
+		r.Status = StatusFailed
+		r.Reason = r.Health
+		r.Message = str(item, "status", "health", "message")
+	case r.Health == "Progressing" || phase == "Running":
+		r.Status = StatusProgressing
+		r.Message = str(item, "status", "health", "message")
+	case r.Health == "Suspended":
+		r.Status = StatusSuspended
+	case r.Sync == "OutOfSync":
+		r.Status = StatusOutOfSync
+		r.Reason = "OutOfSync"
+		r.Message = "Live state differs from the desired state in Git"
+	case r.Sync == "Synced" && r.Health == "Healthy":
+		r.Status = StatusReady
+	default:
+		r.Status = StatusUnknown
+	}
+	return r
+}
+
+// isMissingResource reports whether kubectl failed because a resource type
+// is not served by the cluster
+func isMissingResource(err error) bool {
+	return strings.Contains(err.Error(), "doesn't have a resource type")
+}
+
+// condition returns the status condition of the given type, or nil
+func condition(item object, conditionType string) map[string]interface{} {
+	conditions, _ := nested(item, "status", "conditions").([]interface{})
+	for _, c := range conditions {
+		if c, ok := c.(map[string]interface{}); ok && str(c, "type") == conditionType {
+			return c
+		}
+	}
+	return nil
+}
+
+// nested returns the value at a path of map keys, or nil
+func nested(value map[string]interface{}, path ...string) interface{} {
+	var current interface{} = value
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+// str returns the string at a path of map keys, or an empty string
+func str(value map[string]interface{}, path ...string) string {
+	s, _ := nested(value, path...).(string)
+	return s
+}
@@ -0,0 +1,122 @@
+package gitops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const kustomizations = `{"items": [
+  {"metadata": {"name": "apps", "namespace": "team-a"},
+   "spec": {"sourceRef": {"kind": "GitRepository", "name": "fleet"}},
+   "status": {"lastAppliedRevision": "main@sha1:aaa", "lastAttemptedRevision": "main@sha1:bbb",
+     "conditions": [{"type": "Ready", "status": "False", "reason": "ReconciliationFailed", "message": "Deployment/team-a/web dry-run failed: field is immutable", "lastTransitionTime": "2026-10-19T10:00:00Z"}]}},
+  {"metadata": {"name": "infra", "namespace": "team-a"},
+   "spec": {"sourceRef": {"kind": "GitRepository", "name": "fleet"}},
+   "status": {"lastAppliedRevision": "main@sha1:aaa", "lastAttemptedRevision": "main@sha1:aaa",
+     "conditions": [{"type": "Ready", "status": "True", "reason": "ReconciliationSucceeded"}]}}
+]}`
+
+const helmReleases = `{"items": [
+  {"metadata": {"name": "redis", "namespace": "team-a"},
+   "spec": {"suspend": true, "chart": {"spec": {"chart": "redis", "sourceRef": {"kind": "HelmRepository", "name": "bitnami"}}}},
+   "status": {"history": [{"chartVersion": "19.0.1"}], "conditions": [{"type": "Ready", "status": "True"}]}}
+]}`
+
+const applications = `{"items": [
+  {"metadata": {"name": "shop", "namespace": "team-a"},
+   "spec": {"source": {"repoURL": "https://git.example.com/shop.git", "path": "deploy"}, "destination": {"namespace": "shop"}},
+   "status": {"sync": {"status": "OutOfSync", "revision": "abc"}, "health": {"status": "Healthy"},
+     "conditions": [{"type": "SyncError", "message": "one or more objects failed to apply"}],
+     "operationState": {"phase": "Failed", "message": "one or more objects failed to apply", "finishedAt": "2026-10-19T09:00:00Z"}}},
+  {"metadata": {"name": "docs", "namespace": "team-a"},
+   "spec": {"source": {"repoURL": "https://git.example.com/docs.git", "path": "k8s"}},
+   "status": {"sync": {"status": "OutOfSync"}, "health": {"status": "Healthy"}}},
+  {"metadata": {"name": "api", "namespace": "team-a"},
+   "status": {"sync": {"status": "Synced"}, "health": {"status": "Healthy"}}}
+]}`
+
+func TestSummarize(t *testing.T) {
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.UnknownResource, Outputs: map[string]string{
+		"get kustomizations.kustomize.toolkit.fluxcd.io -A": kustomizations,
+		"get helmreleases.helm.toolkit.fluxcd.io -A":        helmReleases,
+		"get applications.argoproj.io -A":                   applications,
+	}}
+	report, err := Summarize(context.Background(), runner, security.NewSecurityConfig(), Options{IncludeHealthy: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var problems []string
+	for _, r := range report.Problems {
+		problems = append(problems, r.Status+" "+r.Kind+"/"+r.Name)
+	}
+	expected := "Failed Application/shop,Failed Kustomization/apps,OutOfSync Application/docs,Suspended HelmRelease/redis"
+	if strings.Join(problems, ",") != expected {
+		t.Errorf("Expected problems %s, got %s", expected, strings.Join(problems, ","))
+	}
+	if len(report.Healthy) != 2 {
+		t.Errorf("Expected 2 healthy resources, got %+v", report.Healthy)
+	}
+
+	apps := report.Problems[1]
+	if apps.Reason != "ReconciliationFailed" || !strings.Contains(apps.Message, "immutable") || apps.AttemptedRevision != "main@sha1:bbb" || apps.Source != "GitRepository/fleet" {
+		t.Errorf("Expected the reconciliation error of apps, got %+v", apps)
+	}
+	shop := report.Problems[0]
+	if !strings.Contains(shop.Message, "SyncError: one or more objects failed to apply") || shop.DestinationNamespace != "shop" {
+		t.Errorf("Expected the sync error of shop, got %+v", shop)
+	}
+	if redis := report.Problems[3]; redis.Revision != "19.0.1" || redis.Source != "HelmRepository/bitnami redis" {
+		t.Errorf("Expected the chart version and source of redis, got %+v", redis)
+	}
+
+	expectedSummary := []KindSummary{
+		{Kind: "Kustomization", Total: 2, Ready: 1, Failed: 1},
+		{Kind: "HelmRelease", Total: 1, Suspended: 1},
+		{Kind: "Application", Total: 3, Ready: 1, Failed: 1, OutOfSync: 1},
+	}
+	for i, s := range expectedSummary {
+		if report.Summary[i] != s {
+			t.Errorf("Expected summary %+v, got %+v", s, report.Summary[i])
+		}
+	}
+}
+
+func TestSummarizeAllowedNamespaces(t *testing.T) {
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("team-a,team-b")
+	runner := &k8stest.Runner{MatchPrefix: true, Fallback: k8stest.UnknownResource, Outputs: map[string]string{
+		"get namespaces": `{"items": [{"metadata": {"name": "team-a"}}, {"metadata": {"name": "team-c"}}, {"metadata": {"name": "team-b"}}]}`,
+		"get kustomizations.kustomize.toolkit.fluxcd.io -n team-a": kustomizations,
+		"get kustomizations.kustomize.toolkit.fluxcd.io -n team-b": `{"items": []}`,
+		"get helmreleases.helm.toolkit.fluxcd.io -n team-a":        `{"items": []}`,
+		"get helmreleases.helm.toolkit.fluxcd.io -n team-b":        `{"items": []}`,
+	}}
+
+	report, err := Summarize(context.Background(), runner, secConfig, Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(report.Namespaces, ",") != "team-a,team-b" {
+		t.Errorf("Expected the allowed namespaces that exist, got %v", report.Namespaces)
+	}
+	for _, command := range runner.Commands() {
+		if strings.Contains(command, "-A") || strings.Contains(command, "team-c") {
+			t.Errorf("Expected only allowed namespaces to be queried, got %s", command)
+		}
+	}
+	if len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "applications.argoproj.io") {
+		t.Errorf("Expected a note that Argo CD is not installed, got %v", report.Notes)
+	}
+	if len(report.Problems) != 1 || report.Problems[0].Name != "apps" {
+		t.Errorf("Expected the failed Kustomization, got %+v", report.Problems)
+	}
+
+	if _, err := Summarize(context.Background(), runner, secConfig, Options{Namespace: "team-c"}); err == nil {
+		t.Errorf("Expected a denied namespace to be rejected")
+	}
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// Output formats of resource_graph
+const (
+	FormatJSON    = "json"
+	FormatMermaid = "mermaid"
+)
+
+// Executor implements the CommandExecutor interface for resource_graph
+type Executor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures Executor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*Executor)(nil)
+var _ tools.StructuredOutputExecutor = (*Executor)(nil)
+
+// NewExecutor creates a new Executor instance
+func NewExecutor() *Executor {
+	return &Executor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute builds the graph around an object and returns it as JSON or as a
+// Mermaid diagram
+func (e *Executor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := Options{}
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.Kind, _ = params["kind"].(string)
+	opts.Name, _ = params["name"].(string)
+	format := outputFormat(params)
+	if format != FormatJSON && format != FormatMermaid {
+		return "", fmt.Errorf("unsupported format '%s': expected json or mermaid", format)
+	}
+
+	if err := errors.Join(k8s.ValidateNamespace("namespace", opts.Namespace), k8s.ValidateName("name", opts.Name)); err != nil {
+		return "", err
+	}
+
+	graph, err := Build(ctx, e.newRunner(cfg), opts)
+	if err != nil {
+		return "", err
+	}
+	if format == FormatMermaid {
+		return graph.Mermaid(), nil
+	}
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal graph: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that resource_graph returns JSON unless
+// a Mermaid diagram is requested
+func (e *Executor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return outputFormat(params) == FormatJSON
+}
+
+// outputFormat returns the requested format, json by default
+func outputFormat(params map[string]interface{}) string {
+	if format, ok := params["format"].(string); ok && format != "" {
+		return format
+	}
+	return FormatJSON
+}
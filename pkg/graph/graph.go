@@ -0,0 +1,463 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// maxNodes bounds the size of a graph
+const maxNodes = 150
+
+// Relations between nodes. Edges point from the owner, selector or
+// referrer to the object it acts on.
+const (
+	RelationOwns       = "owns"
+	RelationSelects    = "selects"
+	RelationEndpoints  = "endpoints"
+	RelationTargets    = "targets"
+	RelationRoutesTo   = "routes-to"
+	RelationScales     = "scales"
+	RelationProtects   = "protects"
+	RelationAppliesTo  = "applies-to"
+	RelationMounts     = "mounts"
+	RelationReferences = "references"
+)
+
+// Options are the parameters of resource_graph
+type Options struct {
+	Namespace string
+	Kind      string
+	Name      string
+}
+
+// Graph is the result of resource_graph
+type Graph struct {
+	Namespace string `json:"namespace"`
+	// Focus is the ID of the requested object
+	Focus string `json:"focus"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+	// Truncated is set when the graph was cut at maxNodes
+	Truncated bool `json:"truncated,omitempty"`
+	// CollectionErrors lists kinds that could not be listed
+	CollectionErrors []string `json:"collectionErrors,omitempty"`
+}
+
+// Node is an object of the graph. Its ID is "Kind/name".
+type Node struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Health string `json:"health"`
+	Status string `json:"status,omitempty"`
+}
+
+// Edge is a relation between two nodes
+type Edge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+}
+
+// builder holds the objects of a namespace and the relations between them
+type builder struct {
+	objects map[string]*object
+	// loaded records the kinds that were listed successfully
+	loaded map[string]bool
+	// extra are referenced objects that were not loaded
+	extra    map[string]Node
+	edges    []Edge
+	adjacent map[string][]int
+	errors   []string
+}
+
+// Build lists the objects of a namespace and returns the graph connected to
+// the requested object: its ownerReference chain, the objects selecting it
+// or selected by it, and the ConfigMaps, Secrets and PVCs its pods use.
+func Build(ctx context.Context, runner k8s.Runner, opts Options) (*Graph, error) {
+	if opts.Namespace == "" || opts.Name == "" {
+		return nil, fmt.Errorf("namespace and name are required")
+	}
+	kind, err := lookupKind(opts.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	b := load(ctx, runner, opts.Namespace)
+	focus := nodeID(kind.Kind, opts.Name)
+	if _, ok := b.objects[focus]; !ok {
+		if !b.loaded[kind.Kind] {
+			return nil, fmt.Errorf("failed to list %s: %s", kind.Resource, strings.Join(b.errors, "; "))
+		}
+		return nil, fmt.Errorf("%s '%s' not found in namespace '%s'", kind.Kind, opts.Name, opts.Namespace)
+	}
+	b.link()
+	return b.walk(focus, opts.Namespace), nil
+}
+
+// load lists every kind of the graph in parallel
+func load(ctx context.Context, runner k8s.Runner, namespace string) *builder {
+	b := &builder{
+		objects:  map[string]*object{},
+		loaded:   map[string]bool{},
+		extra:    map[string]Node{},
+		adjacent: map[string][]int{},
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, kind := range kinds {
+		wg.Add(1)
+		go func(kind kindInfo) {
+			defer wg.Done()
+			objects, err := list(ctx, runner, kind, namespace)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				b.errors = append(b.errors, fmt.Sprintf("%s: %v", kind.Resource, err))
+				return
+			}
+			b.loaded[kind.Kind] = true
+			for _, o := range objects {
+				b.objects[nodeID(o.Kind, o.Metadata.Name)] = o
+			}
+		}(kind)
+	}
+	wg.Wait()
+	sort.Strings(b.errors)
+	return b
+}
+
+// list returns the objects of one kind in a namespace
+func list(ctx context.Context, runner k8s.Runner, kind kindInfo, namespace string) ([]*object, error) {
+	command := fmt.Sprintf("get %s -n %s", kind.Resource, namespace)
+	var objects []*object
+	if kind.nameOnly {
+		output, err := runner.Run(ctx, command+" -o name")
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(output, "\n") {
+			if _, name, ok := strings.Cut(strings.TrimSpace(line), "/"); ok {
+				o := &object{Kind: kind.Kind}
+				o.Metadata.Name = name
+				objects = append(objects, o)
+			}
+		}
+		return objects, nil
+	}
+
+	var items k8s.List[json.RawMessage]
+	if err := k8s.GetJSON(ctx, runner, command, &items); err != nil {
+		return nil, err
+	}
+	for _, item := range items.Items {
+		o := &object{}
+		if err := json.Unmarshal(item, o); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", kind.Resource, err)
+		}
+		o.Kind = kind.Kind
+		// ReplicaSets left over from earlier rollouts are noise
+		if o.Kind == "ReplicaSet" && o.desiredReplicas() == 0 && o.Status.Replicas == 0 {
+			continue
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+// nodeID returns the ID of an object
+func nodeID(kind, name string) string {
+	return kind + "/" + name
+}
+
+// ref returns the ID of a referenced object. An object that was not
+// loaded becomes a Missing node, or Unknown when its kind is not listed
+// (e.g. an Argo Rollout owning ReplicaSets) or could not be listed.
+func (b *builder) ref(kind, name string) string {
+	id := nodeID(kind, name)
+	if _, ok := b.objects[id]; ok {
+		return id
+	}
+	if _, ok := b.extra[id]; !ok {
+		health, status := HealthMissing, "not found"
+		if !b.loaded[kind] {
+			health, status = HealthUnknown, ""
+		}
+		b.extra[id] = Node{ID: id, Kind: kind, Name: name, Health: health, Status: status}
+	}
+	return id
+}
+
+// addEdge records a relation
+func (b *builder) addEdge(from, to, relation string) {
+	b.edges = append(b.edges, Edge{From: from, To: to, Relation: relation})
+}
+
+// link computes the relations between the objects of the namespace
+func (b *builder) link() {
+	var pods, workloads []*object
+	for _, o := range b.sortedObjects() {
+		switch o.Kind {
+		case "Pod":
+			pods = append(pods, o)
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet":
+			workloads = append(workloads, o)
+		}
+	}
+
+	for _, o := range b.sortedObjects() {
+		id := nodeID(o.Kind, o.Metadata.Name)
+		if owner := k8s.ControllerOf(o.Metadata); owner != nil {
+			b.addEdge(b.ref(owner.Kind, owner.Name), id, RelationOwns)
+		}
+
+		switch o.Kind {
+		case "Service":
+			if selector, ok := o.selector(); ok {
+				for _, pod := range pods {
+					if selector.Matches(pod.Metadata.Labels) {
+						b.addEdge(id, b.ref("Pod", pod.Metadata.Name), RelationSelects)
+					}
+				}
+			}
+		case "EndpointSlice":
+			if service := o.Metadata.Labels["kubernetes.io/service-name"]; service != "" {
+				b.addEdge(b.ref("Service", service), id, RelationEndpoints)
+			}
+			for _, ep := range o.Endpoints {
+				if ep.TargetRef != nil && ep.TargetRef.Kind == "Pod" {
+					b.addEdge(id, b.ref("Pod", ep.TargetRef.Name), RelationTargets)
+				}
+			}
+		case "Ingress":
+			seen := map[string]bool{}
+			for _, backend := range ingressBackends(o) {
+				if !seen[backend] {
+					seen[backend] = true
+					b.addEdge(id, b.ref("Service", backend), RelationRoutesTo)
+				}
+			}
+		case "HorizontalPodAutoscaler":
+			if target := o.Spec.ScaleTargetRef; target != nil && target.Name != "" {
+				b.addEdge(id, b.ref(target.Kind, target.Name), RelationScales)
+			}
+		case "PodDisruptionBudget":
+			selector, ok := o.selector()
+			if !ok {
+				break
+			}
+			protected := false
+			for _, w := range workloads {
+				if w.Spec.Template != nil && k8s.ControllerOf(w.Metadata) == nil && selector.Matches(w.Spec.Template.Metadata.Labels) {
+					b.addEdge(id, b.ref(w.Kind, w.Metadata.Name), RelationProtects)
+					protected = true
+				}
+			}
+			if !protected {
+				for _, pod := range pods {
+					if selector.Matches(pod.Metadata.Labels) {
+						b.addEdge(id, b.ref("Pod", pod.Metadata.Name), RelationProtects)
+					}
+				}
+			}
+		case "NetworkPolicy":
+			if selector, ok := o.selector(); ok {
+				for _, pod := range pods {
+					if selector.Matches(pod.Metadata.Labels) {
+						b.addEdge(id, b.ref("Pod", pod.Metadata.Name), RelationAppliesTo)
+					}
+				}
+			}
+		case "Pod":
+			b.linkPodReferences(o)
+		}
+	}
+
+	for i, e := range b.edges {
+		b.adjacent[e.From] = append(b.adjacent[e.From], i)
+		b.adjacent[e.To] = append(b.adjacent[e.To], i)
+	}
+}
+
+// linkPodReferences links a pod to the ConfigMaps, Secrets and PVCs it
+// mounts or reads environment variables from
+func (b *builder) linkPodReferences(pod *object) {
+	id := nodeID("Pod", pod.Metadata.Name)
+	type reference struct{ kind, name, relation string }
+	var refs []reference
+	for _, v := range pod.Spec.Volumes {
+		switch {
+		case v.PersistentVolumeClaim != nil:
+			refs = append(refs, reference{"PersistentVolumeClaim", v.PersistentVolumeClaim.ClaimName, RelationMounts})
+		case v.ConfigMap != nil && !isOptional(v.ConfigMap.Optional):
+			refs = append(refs, reference{"ConfigMap", v.ConfigMap.Name, RelationMounts})
+		case v.Secret != nil && !isOptional(v.Secret.Optional):
+			refs = append(refs, reference{"Secret", v.Secret.SecretName, RelationMounts})
+		}
+	}
+	for _, c := range append(append([]k8s.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		for _, from := range c.EnvFrom {
+			if from.ConfigMapRef != nil && !isOptional(from.ConfigMapRef.Optional) {
+				refs = append(refs, reference{"ConfigMap", from.ConfigMapRef.Name, RelationReferences})
+			}
+			if from.SecretRef != nil && !isOptional(from.SecretRef.Optional) {
+				refs = append(refs, reference{"Secret", from.SecretRef.Name, RelationReferences})
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil && !isOptional(ref.Optional) {
+				refs = append(refs, reference{"ConfigMap", ref.Name, RelationReferences})
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil && !isOptional(ref.Optional) {
+				refs = append(refs, reference{"Secret", ref.Name, RelationReferences})
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		key := nodeID(ref.kind, ref.name)
+		if ref.name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		b.addEdge(id, b.ref(ref.kind, ref.name), ref.relation)
+	}
+}
+
+// walk collects the nodes connected to the focus. Leaf kinds are included
+// but not expanded unless they are the focus.
+func (b *builder) walk(focus, namespace string) *Graph {
+	g := &Graph{Namespace: namespace, Focus: focus, Nodes: []Node{}, Edges: []Edge{}, CollectionErrors: b.errors}
+	visited := map[string]bool{focus: true}
+	queue := []string{focus}
+	included := map[int]bool{}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		g.Nodes = append(g.Nodes, b.node(id))
+		if id != focus && isLeaf(kindOf(id)) {
+			continue
+		}
+		for _, i := range b.adjacent[id] {
+			e := b.edges[i]
+			next := e.To
+			if next == id {
+				next = e.From
+			}
+			if !visited[next] {
+				if len(visited) >= maxNodes {
+					g.Truncated = true
+					continue
+				}
+				visited[next] = true
+				queue = append(queue, next)
+			}
+			included[i] = true
+		}
+	}
+
+	for i := range included {
+		e := b.edges[i]
+		if visited[e.From] && visited[e.To] {
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, c := g.Edges[i], g.Edges[j]
+		if a.From != c.From {
+			return a.From < c.From
+		}
+		if a.To != c.To {
+			return a.To < c.To
+		}
+		return a.Relation < c.Relation
+	})
+	return g
+}
+
+// node returns the node of an ID with its health
+func (b *builder) node(id string) Node {
+	if o, ok := b.objects[id]; ok {
+		health, status := o.health(b)
+		return Node{ID: id, Kind: o.Kind, Name: o.Metadata.Name, Health: health, Status: status}
+	}
+	return b.extra[id]
+}
+
+// serviceHealth derives the health of a Service from its EndpointSlices
+func (b *builder) serviceHealth(service *object) (string, string) {
+	if service.Spec.Type == "ExternalName" {
+		return HealthHealthy, "ExternalName"
+	}
+	if _, ok := service.selector(); !ok {
+		return HealthUnknown, "no selector"
+	}
+	if !b.loaded["EndpointSlice"] {
+		return HealthUnknown, ""
+	}
+	ready := 0
+	for _, o := range b.objects {
+		if o.Kind == "EndpointSlice" && o.Metadata.Labels["kubernetes.io/service-name"] == service.Metadata.Name {
+			r, _ := sliceReadiness(o)
+			ready += r
+		}
+	}
+	if ready == 0 {
+		return HealthFailed, "no ready endpoints"
+	}
+	return HealthHealthy, fmt.Sprintf("%d ready endpoints", ready)
+}
+
+// sortedObjects returns the objects in ID order, for deterministic edges
+func (b *builder) sortedObjects() []*object {
+	ids := make([]string, 0, len(b.objects))
+	for id := range b.objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	objects := make([]*object, len(ids))
+	for i, id := range ids {
+		objects[i] = b.objects[id]
+	}
+	return objects
+}
+
+// ingressBackends returns the Services an Ingress routes to
+func ingressBackends(o *object) []string {
+	var services []string
+	if o.Spec.DefaultBackend != nil && o.Spec.DefaultBackend.Service != nil {
+		services = append(services, o.Spec.DefaultBackend.Service.Name)
+	}
+	for _, rule := range o.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				services = append(services, path.Backend.Service.Name)
+			}
+		}
+	}
+	return services
+}
+
+// kindOf returns the kind of a node ID
+func kindOf(id string) string {
+	kind, _, _ := strings.Cut(id, "/")
+	return kind
+}
+
+// isOptional reports whether a reference is marked optional
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
@@ -0,0 +1,168 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+)
+
+func shopRunner() *k8stest.Runner {
+	return &k8stest.Runner{Outputs: map[string]string{
+		"get ingresses.networking.k8s.io -n shop -o json": `{"items": [
+			{"metadata": {"name": "web"}, "spec": {"rules": [{"http": {"paths": [{"backend": {"service": {"name": "web"}}}, {"backend": {"service": {"name": "checkout"}}}]}}]},
+			 "status": {"loadBalancer": {"ingress": [{"ip": "20.1.2.3"}]}}}]}`,
+		"get services -n shop -o json": `{"items": [
+			{"metadata": {"name": "web"}, "spec": {"selector": {"app": "web"}}}]}`,
+		"get endpointslices.discovery.k8s.io -n shop -o json": `{"items": [
+			{"metadata": {"name": "web-abc12", "labels": {"kubernetes.io/service-name": "web"}},
+			 "endpoints": [{"conditions": {"ready": true}, "targetRef": {"kind": "Pod", "name": "web-7d9f-a"}}, {"conditions": {"ready": false}, "targetRef": {"kind": "Pod", "name": "web-7d9f-b"}}]}]}`,
+		"get pods -n shop -o json": `{"items": [
+			{"metadata": {"name": "web-7d9f-a", "labels": {"app": "web"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "web-7d9f", "controller": true}]},
+			 "spec": {"containers": [{"name": "web", "envFrom": [{"configMapRef": {"name": "web-config"}}], "env": [{"name": "DB_PASSWORD", "valueFrom": {"secretKeyRef": {"name": "db", "key": "password"}}}]}],
+			          "volumes": [{"name": "data", "persistentVolumeClaim": {"claimName": "web-data"}}, {"name": "tls", "secret": {"secretName": "web-tls"}}]},
+			 "status": {"phase": "Running", "containerStatuses": [{"name": "web", "ready": true}]}},
+			{"metadata": {"name": "web-7d9f-b", "labels": {"app": "web"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "web-7d9f", "controller": true}]},
+			 "spec": {"containers": [{"name": "web"}]},
+			 "status": {"phase": "Running", "containerStatuses": [{"name": "web", "ready": false, "state": {"waiting": {"reason": "CrashLoopBackOff"}}}]}},
+			{"metadata": {"name": "worker-1", "labels": {"app": "worker"}}, "spec": {"containers": [{"name": "worker", "envFrom": [{"configMapRef": {"name": "web-config"}}]}]},
+			 "status": {"phase": "Running", "containerStatuses": [{"name": "worker", "ready": true}]}}]}`,
+		"get replicasets.apps -n shop -o json": `{"items": [
+			{"metadata": {"name": "web-7d9f", "ownerReferences": [{"kind": "Deployment", "name": "web", "controller": true}]}, "spec": {"replicas": 2}, "status": {"replicas": 2, "readyReplicas": 1}},
+			{"metadata": {"name": "web-5c4b", "ownerReferences": [{"kind": "Deployment", "name": "web", "controller": true}]}, "spec": {"replicas": 0}, "status": {"replicas": 0}}]}`,
+		"get deployments.apps -n shop -o json": `{"items": [
+			{"metadata": {"name": "web"}, "spec": {"replicas": 2, "selector": {"matchLabels": {"app": "web"}}, "template": {"metadata": {"labels": {"app": "web"}}}},
+			 "status": {"replicas": 2, "updatedReplicas": 2, "readyReplicas": 1}}]}`,
+		"get horizontalpodautoscalers.autoscaling -n shop -o json": `{"items": [
+			{"metadata": {"name": "web"}, "spec": {"scaleTargetRef": {"kind": "Deployment", "name": "web"}}, "status": {"currentReplicas": 2, "desiredReplicas": 2}}]}`,
+		"get poddisruptionbudgets.policy -n shop -o json": `{"items": [
+			{"metadata": {"name": "web"}, "spec": {"selector": {"matchLabels": {"app": "web"}}}, "status": {"currentHealthy": 1, "desiredHealthy": 1, "disruptionsAllowed": 0, "expectedPods": 2}}]}`,
+		"get networkpolicies.networking.k8s.io -n shop -o json": `{"items": [
+			{"metadata": {"name": "default-deny"}, "spec": {"podSelector": {}}}]}`,
+		"get persistentvolumeclaims -n shop -o json": `{"items": [
+			{"metadata": {"name": "web-data"}, "status": {"phase": "Bound"}}]}`,
+		"get configmaps -n shop -o name": "configmap/web-config\nconfigmap/kube-root-ca.crt\n",
+		"get secrets -n shop -o name":    "secret/db\n",
+	}}
+}
+
+func TestBuildFromPod(t *testing.T) {
+	g, err := Build(context.Background(), shopRunner(), Options{Namespace: "shop", Kind: "po", Name: "web-7d9f-a"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if g.Focus != "Pod/web-7d9f-a" || g.Nodes[0].ID != g.Focus {
+		t.Errorf("Expected the focus pod first, got %s and %+v", g.Focus, g.Nodes[0])
+	}
+
+	nodes := map[string]Node{}
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	expectedHealth := map[string]string{
+		"Pod/web-7d9f-a":                 HealthHealthy,
+		"Pod/web-7d9f-b":                 HealthFailed,
+		"ReplicaSet/web-7d9f":            HealthDegraded,
+		"Deployment/web":                 HealthDegraded,
+		"Service/web":                    HealthHealthy,
+		"EndpointSlice/web-abc12":        HealthDegraded,
+		"Ingress/web":                    HealthHealthy,
+		"HorizontalPodAutoscaler/web":    HealthHealthy,
+		"PodDisruptionBudget/web":        HealthDegraded,
+		"NetworkPolicy/default-deny":     HealthHealthy,
+		"PersistentVolumeClaim/web-data": HealthHealthy,
+		"ConfigMap/web-config":           HealthHealthy,
+		"Secret/db":                      HealthHealthy,
+		"Secret/web-tls":                 HealthMissing,
+	}
+	for id, health := range expectedHealth {
+		if nodes[id].Health != health {
+			t.Errorf("Expected %s to be %s, got %+v", id, health, nodes[id])
+		}
+	}
+	if nodes["Pod/web-7d9f-b"].Status != "CrashLoopBackOff" {
+		t.Errorf("Expected the crash loop status, got %q", nodes["Pod/web-7d9f-b"].Status)
+	}
+	// Leaf kinds are not expanded: the ConfigMap does not pull in the
+	// worker, the NetworkPolicy does not either, the Ingress does not pull
+	// in its other backend
+	for _, id := range []string{"Pod/worker-1", "Service/checkout", "ReplicaSet/web-5c4b"} {
+		if _, ok := nodes[id]; ok {
+			t.Errorf("Expected %s not to be in the graph", id)
+		}
+	}
+
+	edges := map[string]bool{}
+	for _, e := range g.Edges {
+		edges[e.From+" "+e.Relation+" "+e.To] = true
+	}
+	for _, edge := range []string{
+		"Deployment/web owns ReplicaSet/web-7d9f",
+		"ReplicaSet/web-7d9f owns Pod/web-7d9f-a",
+		"Service/web selects Pod/web-7d9f-a",
+		"Service/web endpoints EndpointSlice/web-abc12",
+		"EndpointSlice/web-abc12 targets Pod/web-7d9f-b",
+		"Ingress/web routes-to Service/web",
+		"HorizontalPodAutoscaler/web scales Deployment/web",
+		"PodDisruptionBudget/web protects Deployment/web",
+		"NetworkPolicy/default-deny applies-to Pod/web-7d9f-b",
+		"Pod/web-7d9f-a mounts PersistentVolumeClaim/web-data",
+		"Pod/web-7d9f-a references ConfigMap/web-config",
+		"Pod/web-7d9f-a references Secret/db",
+		"Pod/web-7d9f-a mounts Secret/web-tls",
+	} {
+		if !edges[edge] {
+			t.Errorf("Expected edge %s, got %v", edge, g.Edges)
+		}
+	}
+}
+
+func TestBuildFromIngress(t *testing.T) {
+	g, err := Build(context.Background(), shopRunner(), Options{Namespace: "shop", Kind: "Ingress", Name: "web"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	nodes := map[string]Node{}
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	if nodes["Service/checkout"].Health != HealthMissing {
+		t.Errorf("Expected the missing backend to be reported, got %+v", nodes["Service/checkout"])
+	}
+	if nodes["Deployment/web"].ID == "" {
+		t.Errorf("Expected the graph to reach the Deployment through the Service, got %+v", g.Nodes)
+	}
+
+	mermaid := g.Mermaid()
+	for _, expected := range []string{"flowchart LR", `n0["Ingress web<br/>Healthy: 20.1.2.3"]:::healthy`, "-->|routes-to|", "classDef missing", "style n0 stroke-width:4px"} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("Expected Mermaid output to contain %q, got:\n%s", expected, mermaid)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		opts     Options
+		expected string
+	}{
+		{Options{Namespace: "shop", Kind: "Widget", Name: "web"}, "unsupported kind"},
+		{Options{Namespace: "shop", Kind: "Deployment", Name: "api"}, "not found"},
+		{Options{Kind: "Deployment", Name: "web"}, "required"},
+	}
+	for _, tt := range tests {
+		_, err := Build(context.Background(), shopRunner(), tt.opts)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected error containing %q for %+v, got %v", tt.expected, tt.opts, err)
+		}
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	label := escapeLabel(fmt.Sprintf("Pod web<br/>%s", `Failed: "bad" <image>`))
+	if label != "Pod web<br/>Failed: #quot;bad#quot; #lt;image#gt;" {
+		t.Errorf("Expected quotes and brackets to be escaped, got %s", label)
+	}
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// healthClasses are the Mermaid classes of the node health values
+var healthClasses = map[string]string{
+	HealthHealthy:     "healthy fill:#d4edda,stroke:#28a745",
+	HealthProgressing: "progressing fill:#d1ecf1,stroke:#17a2b8",
+	HealthDegraded:    "degraded fill:#fff3cd,stroke:#ffc107",
+	HealthFailed:      "failed fill:#f8d7da,stroke:#dc3545",
+	HealthMissing:     "missing fill:#f8d7da,stroke:#dc3545,stroke-dasharray:4",
+	HealthUnknown:     "unknown fill:#e2e3e5,stroke:#6c757d",
+}
+
+// mermaidEscaper escapes characters that end a quoted Mermaid label
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+// Mermaid renders the graph as a Mermaid flowchart. Nodes are colored by
+// health and the focus has a thick border.
+func (g *Graph) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	ids := map[string]string{}
+	used := map[string]bool{}
+	for i, node := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.ID] = id
+		label := node.Kind + " " + node.Name + "<br/>" + node.Health
+		if node.Status != "" {
+			label += ": " + node.Status
+		}
+		class := strings.Fields(healthClasses[node.Health])
+		name := "unknown"
+		if len(class) > 0 {
+			name = class[0]
+		}
+		used[node.Health] = true
+		fmt.Fprintf(&sb, "  %s[\"%s\"]:::%s\n", id, escapeLabel(label), name)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %s -->|%s| %s\n", ids[e.From], e.Relation, ids[e.To])
+	}
+	for _, health := range []string{HealthHealthy, HealthProgressing, HealthDegraded, HealthFailed, HealthMissing, HealthUnknown} {
+		if used[health] {
+			fmt.Fprintf(&sb, "  classDef %s\n", healthClasses[health])
+		}
+	}
+	if focus, ok := ids[g.Focus]; ok {
+		fmt.Fprintf(&sb, "  style %s stroke-width:4px\n", focus)
+	}
+	return sb.String()
+}
+
+// escapeLabel escapes a node label, keeping the line breaks it inserts
+func escapeLabel(label string) string {
+	parts := strings.Split(label, "<br/>")
+	for i, part := range parts {
+		parts[i] = mermaidEscaper.Replace(part)
+	}
+	return strings.Join(parts, "<br/>")
+}
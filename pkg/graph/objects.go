@@ -0,0 +1,354 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// Health of a node, from best to worst
+const (
+	HealthHealthy     = "Healthy"
+	HealthProgressing = "Progressing"
+	HealthDegraded    = "Degraded"
+	HealthFailed      = "Failed"
+	// HealthMissing marks an object that is referenced but does not exist
+	HealthMissing = "Missing"
+	// HealthUnknown marks an object whose kind is not loaded (e.g. the
+	// custom resource owning a ReplicaSet) or could not be listed
+	HealthUnknown = "Unknown"
+)
+
+// kindInfo describes a kind loaded into the graph
+type kindInfo struct {
+	Kind     string
+	Resource string
+	aliases  []string
+	// leaf kinds are not expanded unless they are the focus, so that a
+	// ConfigMap or NetworkPolicy shared by many workloads does not pull
+	// them all into the graph
+	leaf bool
+	// nameOnly kinds are listed with "-o name": only their existence is
+	// needed, and Secret data must not be read
+	nameOnly bool
+}
+
+// kinds are the kinds of the graph, in the order they are listed
+var kinds = []kindInfo{
+	{Kind: "Ingress", Resource: "ingresses.networking.k8s.io", aliases: []string{"ingress", "ingresses", "ing"}, leaf: true},
+	{Kind: "Service", Resource: "services", aliases: []string{"service", "services", "svc"}},
+	{Kind: "EndpointSlice", Resource: "endpointslices.discovery.k8s.io", aliases: []string{"endpointslice", "endpointslices"}},
+	{Kind: "Pod", Resource: "pods", aliases: []string{"pod", "pods", "po"}},
+	{Kind: "ReplicaSet", Resource: "replicasets.apps", aliases: []string{"replicaset", "replicasets", "rs"}},
+	{Kind: "Deployment", Resource: "deployments.apps", aliases: []string{"deployment", "deployments", "deploy"}},
+	{Kind: "StatefulSet", Resource: "statefulsets.apps", aliases: []string{"statefulset", "statefulsets", "sts"}},
+	{Kind: "DaemonSet", Resource: "daemonsets.apps", aliases: []string{"daemonset", "daemonsets", "ds"}},
+	{Kind: "Job", Resource: "jobs.batch", aliases: []string{"job", "jobs"}},
+	{Kind: "CronJob", Resource: "cronjobs.batch", aliases: []string{"cronjob", "cronjobs", "cj"}},
+	{Kind: "HorizontalPodAutoscaler", Resource: "horizontalpodautoscalers.autoscaling", aliases: []string{"horizontalpodautoscaler", "horizontalpodautoscalers", "hpa"}, leaf: true},
+	{Kind: "PodDisruptionBudget", Resource: "poddisruptionbudgets.policy", aliases: []string{"poddisruptionbudget", "poddisruptionbudgets", "pdb"}, leaf: true},
+	{Kind: "NetworkPolicy", Resource: "networkpolicies.networking.k8s.io", aliases: []string{"networkpolicy", "networkpolicies", "netpol"}, leaf: true},
+	{Kind: "PersistentVolumeClaim", Resource: "persistentvolumeclaims", aliases: []string{"persistentvolumeclaim", "persistentvolumeclaims", "pvc"}, leaf: true},
+	{Kind: "ConfigMap", Resource: "configmaps", aliases: []string{"configmap", "configmaps", "cm"}, leaf: true, nameOnly: true},
+	{Kind: "Secret", Resource: "secrets", aliases: []string{"secret", "secrets"}, leaf: true, nameOnly: true},
+}
+
+// lookupKind resolves a kind name, resource name or short name
+func lookupKind(name string) (kindInfo, error) {
+	lower := strings.ToLower(name)
+	for _, k := range kinds {
+		if strings.ToLower(k.Kind) == lower || lower == strings.ToLower(k.Resource) {
+			return k, nil
+		}
+		for _, alias := range k.aliases {
+			if alias == lower {
+				return k, nil
+			}
+		}
+	}
+	var names []string
+	for _, k := range kinds {
+		names = append(names, k.Kind)
+	}
+	return kindInfo{}, fmt.Errorf("unsupported kind '%s': expected one of %s", name, strings.Join(names, ", "))
+}
+
+// isLeaf reports whether a kind is only expanded as the focus
+func isLeaf(kind string) bool {
+	for _, k := range kinds {
+		if k.Kind == kind {
+			return k.leaf
+		}
+	}
+	return false
+}
+
+// object holds the fields of every kind in the graph. Each kind only fills
+// the fields it has.
+type object struct {
+	Kind      string          `json:"-"`
+	Metadata  k8s.ObjectMeta  `json:"metadata"`
+	Spec      objectSpec      `json:"spec"`
+	Status    objectStatus    `json:"status"`
+	Endpoints []sliceEndpoint `json:"endpoints,omitempty"`
+}
+
+// objectSpec is the union of the spec fields used by the graph
+type objectSpec struct {
+	// Pod fields
+	k8s.PodSpec
+	// Selector is a map for Services and a LabelSelector for other kinds
+	Selector json.RawMessage `json:"selector,omitempty"`
+	Replicas *int            `json:"replicas,omitempty"`
+	Template *struct {
+		Metadata k8s.ObjectMeta `json:"metadata"`
+	} `json:"template,omitempty"`
+	Suspend        *bool              `json:"suspend,omitempty"`
+	Type           string             `json:"type,omitempty"`
+	PodSelector    *k8s.LabelSelector `json:"podSelector,omitempty"`
+	ScaleTargetRef *struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"scaleTargetRef,omitempty"`
+	Rules          []ingressRule   `json:"rules,omitempty"`
+	DefaultBackend *ingressBackend `json:"defaultBackend,omitempty"`
+}
+
+// objectStatus is the union of the status fields used by the graph
+type objectStatus struct {
+	Phase                  string                `json:"phase,omitempty"`
+	Reason                 string                `json:"reason,omitempty"`
+	Conditions             []k8s.Condition       `json:"conditions,omitempty"`
+	ContainerStatuses      []k8s.ContainerStatus `json:"containerStatuses,omitempty"`
+	Replicas               int                   `json:"replicas,omitempty"`
+	ReadyReplicas          int                   `json:"readyReplicas,omitempty"`
+	UpdatedReplicas        int                   `json:"updatedReplicas,omitempty"`
+	DesiredNumberScheduled int                   `json:"desiredNumberScheduled,omitempty"`
+	NumberReady            int                   `json:"numberReady,omitempty"`
+	Active                 int                   `json:"active,omitempty"`
+	CurrentReplicas        int                   `json:"currentReplicas,omitempty"`
+	DesiredReplicas        int                   `json:"desiredReplicas,omitempty"`
+	CurrentHealthy         int                   `json:"currentHealthy,omitempty"`
+	DesiredHealthy         int                   `json:"desiredHealthy,omitempty"`
+	DisruptionsAllowed     int                   `json:"disruptionsAllowed,omitempty"`
+	ExpectedPods           int                   `json:"expectedPods,omitempty"`
+	LoadBalancer           struct {
+		Ingress []struct {
+			IP       string `json:"ip,omitempty"`
+			Hostname string `json:"hostname,omitempty"`
+		} `json:"ingress,omitempty"`
+	} `json:"loadBalancer,omitempty"`
+}
+
+// ingressRule is an Ingress rule
+type ingressRule struct {
+	HTTP *struct {
+		Paths []struct {
+			Backend ingressBackend `json:"backend"`
+		} `json:"paths"`
+	} `json:"http,omitempty"`
+}
+
+// ingressBackend is an Ingress backend; only Service backends are followed
+type ingressBackend struct {
+	Service *struct {
+		Name string `json:"name"`
+	} `json:"service,omitempty"`
+}
+
+// sliceEndpoint is an endpoint of an EndpointSlice
+type sliceEndpoint struct {
+	Conditions struct {
+		Ready *bool `json:"ready,omitempty"`
+	} `json:"conditions"`
+	TargetRef *k8s.ObjectReference `json:"targetRef,omitempty"`
+}
+
+// selector returns the label selector of a Service, workload, PDB or
+// NetworkPolicy. A missing selector selects nothing.
+func (o *object) selector() (k8s.LabelSelector, bool) {
+	if o.Kind == "NetworkPolicy" {
+		if o.Spec.PodSelector == nil {
+			return k8s.LabelSelector{}, false
+		}
+		return *o.Spec.PodSelector, true
+	}
+	if len(o.Spec.Selector) == 0 || string(o.Spec.Selector) == "null" {
+		return k8s.LabelSelector{}, false
+	}
+	if o.Kind == "Service" {
+		var labels map[string]string
+		if err := json.Unmarshal(o.Spec.Selector, &labels); err != nil || len(labels) == 0 {
+			return k8s.LabelSelector{}, false
+		}
+		return k8s.LabelSelector{MatchLabels: labels}, true
+	}
+	var selector k8s.LabelSelector
+	if err := json.Unmarshal(o.Spec.Selector, &selector); err != nil {
+		return k8s.LabelSelector{}, false
+	}
+	return selector, true
+}
+
+// desiredReplicas returns spec.replicas, which defaults to 1
+func (o *object) desiredReplicas() int {
+	if o.Spec.Replicas == nil {
+		return 1
+	}
+	return *o.Spec.Replicas
+}
+
+// health returns the health of an object and a short status
+func (o *object) health(b *builder) (string, string) {
+	switch o.Kind {
+	case "Pod":
+		return podHealth(o)
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		desired, ready := o.desiredReplicas(), o.Status.ReadyReplicas
+		status := fmt.Sprintf("%d/%d ready", ready, desired)
+		if c := k8s.FindCondition(o.Status.Conditions, "Progressing"); c != nil && c.Reason == "ProgressDeadlineExceeded" {
+			return HealthFailed, status + ", progress deadline exceeded"
+		}
+		switch {
+		case desired == 0:
+			return HealthHealthy, "scaled to 0"
+		case ready == 0:
+			return HealthFailed, status
+		case ready < desired && o.Kind == "Deployment" && o.Status.UpdatedReplicas < desired:
+			return HealthProgressing, status + ", rolling out"
+		case ready < desired:
+			return HealthDegraded, status
+		}
+		return HealthHealthy, status
+	case "DaemonSet":
+		desired, ready := o.Status.DesiredNumberScheduled, o.Status.NumberReady
+		status := fmt.Sprintf("%d/%d ready", ready, desired)
+		switch {
+		case desired > 0 && ready == 0:
+			return HealthFailed, status
+		case ready < desired:
+			return HealthDegraded, status
+		}
+		return HealthHealthy, status
+	case "Job":
+		if c := k8s.FindCondition(o.Status.Conditions, "Failed"); c != nil && c.Status == "True" {
+			return HealthFailed, strings.TrimSpace("Failed " + c.Reason)
+		}
+		if c := k8s.FindCondition(o.Status.Conditions, "Complete"); c != nil && c.Status == "True" {
+			return HealthHealthy, "Complete"
+		}
+		if o.Status.Active > 0 {
+			return HealthProgressing, fmt.Sprintf("%d active", o.Status.Active)
+		}
+		return HealthUnknown, ""
+	case "CronJob":
+		if o.Spec.Suspend != nil && *o.Spec.Suspend {
+			return HealthHealthy, "suspended"
+		}
+		return HealthHealthy, ""
+	case "Service":
+		return b.serviceHealth(o)
+	case "EndpointSlice":
+		ready, total := sliceReadiness(o)
+		status := fmt.Sprintf("%d/%d endpoints ready", ready, total)
+		switch {
+		case total > 0 && ready == 0:
+			return HealthFailed, status
+		case ready < total:
+			return HealthDegraded, status
+		}
+		return HealthHealthy, status
+	case "Ingress":
+		if lbs := o.Status.LoadBalancer.Ingress; len(lbs) > 0 {
+			return HealthHealthy, firstNonEmpty(lbs[0].IP, lbs[0].Hostname)
+		}
+		return HealthProgressing, "no address assigned"
+	case "HorizontalPodAutoscaler":
+		status := fmt.Sprintf("%d/%d replicas", o.Status.CurrentReplicas, o.Status.DesiredReplicas)
+		if c := k8s.FindCondition(o.Status.Conditions, "ScalingActive"); c != nil && c.Status == "False" {
+			return HealthFailed, c.Reason
+		}
+		if c := k8s.FindCondition(o.Status.Conditions, "AbleToScale"); c != nil && c.Status == "False" {
+			return HealthDegraded, c.Reason
+		}
+		if c := k8s.FindCondition(o.Status.Conditions, "ScalingLimited"); c != nil && c.Status == "True" {
+			return HealthDegraded, status + ", " + c.Reason
+		}
+		return HealthHealthy, status
+	case "PodDisruptionBudget":
+		status := fmt.Sprintf("%d/%d healthy, %d disruptions allowed", o.Status.CurrentHealthy, o.Status.DesiredHealthy, o.Status.DisruptionsAllowed)
+		switch {
+		case o.Status.ExpectedPods == 0:
+			return HealthDegraded, "selects no pods"
+		case o.Status.CurrentHealthy < o.Status.DesiredHealthy:
+			return HealthDegraded, status
+		case o.Status.DisruptionsAllowed == 0:
+			return HealthDegraded, status + " (blocks evictions)"
+		}
+		return HealthHealthy, status
+	case "PersistentVolumeClaim":
+		switch o.Status.Phase {
+		case "Bound":
+			return HealthHealthy, "Bound"
+		case "Lost":
+			return HealthFailed, "Lost"
+		}
+		return HealthProgressing, o.Status.Phase
+	}
+	return HealthHealthy, ""
+}
+
+// podHealth derives the health of a pod from its phase and containers
+func podHealth(o *object) (string, string) {
+	if o.Metadata.DeletionTimestamp != "" {
+		return HealthProgressing, "Terminating"
+	}
+	switch o.Status.Phase {
+	case "Succeeded":
+		return HealthHealthy, "Succeeded"
+	case "Failed":
+		return HealthFailed, strings.TrimSpace("Failed " + o.Status.Reason)
+	}
+	ready := 0
+	for _, cs := range o.Status.ContainerStatuses {
+		if cs.Ready {
+			ready++
+		}
+		if w := cs.State.Waiting; w != nil && w.Reason != "" && w.Reason != "ContainerCreating" && w.Reason != "PodInitializing" {
+			return HealthFailed, w.Reason
+		}
+	}
+	total := len(o.Spec.Containers)
+	status := fmt.Sprintf("%s %d/%d ready", o.Status.Phase, ready, total)
+	switch {
+	case o.Status.Phase == "Pending":
+		return HealthProgressing, "Pending"
+	case ready < total:
+		return HealthDegraded, status
+	}
+	return HealthHealthy, status
+}
+
+// sliceReadiness counts the ready endpoints of an EndpointSlice. An
+// endpoint without a ready condition is ready.
+func sliceReadiness(o *object) (ready, total int) {
+	for _, ep := range o.Endpoints {
+		total++
+		if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+			ready++
+		}
+	}
+	return ready, total
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterResourceGraph registers the resource_graph tool
+func RegisterResourceGraph() mcp.Tool {
+	return mcp.NewTool("resource_graph",
+		mcp.WithDescription(`Return the topology around a Kubernetes object as a graph with the health of every node, instead of walking Ingress -> Service -> EndpointSlice -> Pod -> ReplicaSet -> Deployment one get at a time.
+
+Lists the namespace's workloads, pods, Services, EndpointSlices, Ingresses, HPAs, PDBs, NetworkPolicies, PVCs, ConfigMaps and Secrets (names only), and follows from the object:
+- ownerReference chains (owns)
+- Service and EndpointSlice to Pods (selects, endpoints, targets), Ingress to Services (routes-to)
+- HPA and PDB to their workload (scales, protects), NetworkPolicy to Pods (applies-to)
+- Pods to the ConfigMaps, Secrets and PVCs they mount or reference (mounts, references); missing ones are marked Missing
+
+Node health is Healthy, Progressing, Degraded, Failed, Missing or Unknown, with a short status.
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the object"),
+		),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Kind of the object, e.g. Deployment, svc, pod, ingress, hpa, pdb, cm"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the object"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: json (nodes and edges) or mermaid (a flowchart diagram)"),
+			mcp.Enum(FormatJSON, FormatMermaid),
+			mcp.DefaultString(FormatJSON),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Resource Graph",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
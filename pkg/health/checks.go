@@ -0,0 +1,430 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+const (
+	// csrGracePeriod is how long a CSR may await approval before it is reported
+	csrGracePeriod = 10 * time.Minute
+	// maxKubeletSkew is the number of minor versions a kubelet may be older
+	// than the API server
+	maxKubeletSkew = 3
+	// maxExampleLength truncates event messages
+	maxExampleLength = 200
+)
+
+var (
+	versionPattern     = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+	failingReadyzCheck = regexp.MustCompile(`\[-\]([\w./:-]+)`)
+)
+
+// pressureConditions are node conditions that are unhealthy when True
+var pressureConditions = map[string]string{
+	"MemoryPressure":     SeverityWarning,
+	"DiskPressure":       SeverityWarning,
+	"PIDPressure":        SeverityWarning,
+	"NetworkUnavailable": SeverityCritical,
+}
+
+// failingWaitingReasons are container waiting reasons of a failing pod
+var failingWaitingReasons = map[string]bool{
+	"CrashLoopBackOff": true, "ImagePullBackOff": true, "ErrImagePull": true,
+	"CreateContainerConfigError": true, "CreateContainerError": true,
+	"InvalidImageName": true, "RunContainerError": true,
+}
+
+// checkNodes summarizes node readiness and conditions, and compares the
+// kubelet and kubectl versions with the API server
+func checkNodes(ctx context.Context, c *checker) ([]Issue, []string) {
+	var issues []Issue
+	var notes []string
+	var nodes k8s.List[k8s.Node]
+	if err := k8s.GetJSON(ctx, c.runner, "get nodes", &nodes); err != nil {
+		notes = append(notes, fmt.Sprintf("Could not list nodes: %v", err))
+	}
+	summary := &c.report.Nodes
+	kubelets := map[string]int{}
+	for _, node := range nodes.Items {
+		name := node.Metadata.Name
+		summary.Total++
+		if ready := k8s.FindCondition(node.Status.Conditions, "Ready"); ready != nil && ready.Status == "True" {
+			summary.Ready++
+		} else {
+			summary.NotReady = append(summary.NotReady, name)
+			reason := "no Ready condition"
+			if ready != nil {
+				reason = strings.TrimSpace(ready.Reason + " " + ready.Message)
+			}
+			issues = append(issues, Issue{SeverityCritical, "nodes", fmt.Sprintf("Node %s is not ready: %s", name, reason)})
+		}
+		for _, condition := range node.Status.Conditions {
+			if severity, ok := pressureConditions[condition.Type]; ok && condition.Status == "True" {
+				summary.Pressure = append(summary.Pressure, name+": "+condition.Type)
+				issues = append(issues, Issue{severity, "nodes", fmt.Sprintf("Node %s has %s", name, condition.Type)})
+			}
+		}
+		if node.Spec.Unschedulable {
+			summary.Cordoned = append(summary.Cordoned, name)
+		}
+		if version := node.Status.NodeInfo.KubeletVersion; version != "" {
+			kubelets[version]++
+		}
+	}
+	if len(kubelets) > 0 {
+		c.report.Version.Kubelets = kubelets
+	}
+
+	var version struct {
+		ClientVersion struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"clientVersion"`
+		ServerVersion struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"serverVersion"`
+	}
+	if err := k8s.GetJSON(ctx, c.runner, "version", &version); err != nil {
+		return issues, append(notes, fmt.Sprintf("Could not read versions: %v", err))
+	}
+	c.report.Version.Server = version.ServerVersion.GitVersion
+	c.report.Version.Client = version.ClientVersion.GitVersion
+	return append(issues, checkSkew(&c.report.Version)...), notes
+}
+
+// checkSkew applies the Kubernetes version skew policy: kubelets may be up
+// to three minor versions older than the API server but never newer, and
+// kubectl may differ by one minor version
+func checkSkew(v *VersionSummary) []Issue {
+	server, ok := minorVersion(v.Server)
+	if !ok {
+		return nil
+	}
+	var issues []Issue
+	versions := make([]string, 0, len(v.Kubelets))
+	for version := range v.Kubelets {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	for _, version := range versions {
+		minor, ok := minorVersion(version)
+		if !ok || minor == server {
+			continue
+		}
+		skew := fmt.Sprintf("%d node(s) run kubelet %s with API server %s", v.Kubelets[version], version, v.Server)
+		v.Skew = append(v.Skew, skew)
+		if minor > server || server-minor > maxKubeletSkew {
+			issues = append(issues, Issue{SeverityCritical, "version", skew + ", outside the supported skew"})
+		}
+	}
+	if client, ok := minorVersion(v.Client); ok && (client > server+1 || client < server-1) {
+		skew := fmt.Sprintf("kubectl %s is more than one minor version from API server %s", v.Client, v.Server)
+		v.Skew = append(v.Skew, skew)
+		issues = append(issues, Issue{SeverityWarning, "version", skew})
+	}
+	return issues
+}
+
+// minorVersion returns the minor version of a version such as v1.29.2
+func minorVersion(version string) (int, bool) {
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return 0, false
+	}
+	minor, err := strconv.Atoi(m[2])
+	return minor, err == nil
+}
+
+// checkControlPlane reads the API server's verbose readyz checks, falling
+// back to componentstatuses when readyz cannot be read
+func checkControlPlane(ctx context.Context, c *checker) ([]Issue, []string) {
+	cp := &c.report.ControlPlane
+	output, err := c.runner.Run(ctx, "get --raw /readyz?verbose")
+	text := output
+	if err != nil {
+		text = err.Error()
+	}
+	failing := failingReadyzCheck.FindAllStringSubmatch(text, -1)
+	if err == nil || len(failing) > 0 {
+		cp.Source = "readyz"
+		for _, m := range failing {
+			cp.Failing = append(cp.Failing, m[1])
+		}
+	} else {
+		var statuses k8s.List[struct {
+			Metadata   k8s.ObjectMeta  `json:"metadata"`
+			Conditions []k8s.Condition `json:"conditions"`
+		}]
+		if csErr := k8s.GetJSON(ctx, c.runner, "get componentstatuses", &statuses); csErr != nil {
+			return nil, []string{fmt.Sprintf("Could not read control plane health: %v", err)}
+		}
+		cp.Source = "componentstatuses"
+		for _, status := range statuses.Items {
+			if healthy := k8s.FindCondition(status.Conditions, "Healthy"); healthy == nil || healthy.Status != "True" {
+				cp.Failing = append(cp.Failing, status.Metadata.Name)
+			}
+		}
+	}
+
+	cp.Healthy = len(cp.Failing) == 0
+	if cp.Healthy {
+		return nil, nil
+	}
+	return []Issue{{SeverityCritical, "controlPlane", "Failing control plane checks: " + strings.Join(cp.Failing, ", ")}}, nil
+}
+
+// checkPods counts the failing pods of the scanned namespaces
+func checkPods(ctx context.Context, c *checker) ([]Issue, []string) {
+	pods, notes := list[k8s.Pod](ctx, c, "pods", "")
+	summary := &c.report.Pods
+	byNamespace := map[string]*NamespaceFailures{}
+	for _, pod := range pods {
+		summary.Total++
+		reason := podFailure(pod, c.now)
+		if reason == "" {
+			continue
+		}
+		summary.Failing++
+		failures, ok := byNamespace[pod.Metadata.Namespace]
+		if !ok {
+			failures = &NamespaceFailures{Namespace: pod.Metadata.Namespace, Pods: []FailingPod{}}
+			byNamespace[pod.Metadata.Namespace] = failures
+		}
+		failures.Failing++
+		if len(failures.Pods) < maxPodsPerNamespace {
+			failures.Pods = append(failures.Pods, FailingPod{Name: pod.Metadata.Name, Reason: reason})
+		}
+	}
+
+	var issues []Issue
+	for _, failures := range byNamespace {
+		summary.ByNamespace = append(summary.ByNamespace, *failures)
+	}
+	sort.Slice(summary.ByNamespace, func(i, j int) bool {
+		a, b := summary.ByNamespace[i], summary.ByNamespace[j]
+		if a.Failing != b.Failing {
+			return a.Failing > b.Failing
+		}
+		return a.Namespace < b.Namespace
+	})
+	for _, failures := range summary.ByNamespace {
+		issues = append(issues, Issue{SeverityWarning, "pods", fmt.Sprintf("%d failing pod(s) in namespace %s", failures.Failing, failures.Namespace)})
+	}
+	return issues, notes
+}
+
+// podFailure returns why a pod is failing, or "" when it is not
+func podFailure(pod k8s.Pod, now time.Time) string {
+	switch pod.Status.Phase {
+	case "Succeeded":
+		return ""
+	case "Failed":
+		if pod.Status.Reason != "" {
+			return pod.Status.Reason
+		}
+		return "Failed"
+	case "Unknown":
+		return "Unknown"
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		if w := status.State.Waiting; w != nil && failingWaitingReasons[w.Reason] {
+			return "Init:" + w.Reason
+		}
+		if t := status.State.Terminated; t != nil && t.ExitCode != 0 {
+			return "Init:" + firstNonEmpty(t.Reason, "Error")
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if w := status.State.Waiting; w != nil && failingWaitingReasons[w.Reason] {
+			return w.Reason
+		}
+	}
+	if pod.Status.Phase == "Pending" {
+		if d, ok := age(now, pod.Metadata.CreationTimestamp); ok && d < pendingGracePeriod {
+			return ""
+		}
+		if scheduled := k8s.FindCondition(pod.Status.Conditions, "PodScheduled"); scheduled != nil && scheduled.Status == "False" {
+			return firstNonEmpty(scheduled.Reason, "Unschedulable")
+		}
+		return "Pending"
+	}
+	return ""
+}
+
+// checkPVCs lists the claims that are not bound
+func checkPVCs(ctx context.Context, c *checker) ([]Issue, []string) {
+	claims, notes := list[k8s.PersistentVolumeClaim](ctx, c, "persistentvolumeclaims", "")
+	var issues []Issue
+	for _, claim := range claims {
+		name := claim.Metadata.Namespace + "/" + claim.Metadata.Name
+		switch claim.Status.Phase {
+		case "Pending":
+			c.report.PendingPVCs = append(c.report.PendingPVCs, name)
+		case "Lost":
+			issues = append(issues, Issue{SeverityCritical, "storage", fmt.Sprintf("PersistentVolumeClaim %s lost its volume", name)})
+		}
+	}
+	sort.Strings(c.report.PendingPVCs)
+	if n := len(c.report.PendingPVCs); n > 0 {
+		issues = append(issues, Issue{SeverityWarning, "storage", fmt.Sprintf("%d PersistentVolumeClaim(s) pending", n)})
+	}
+	return issues, notes
+}
+
+// checkDeployments lists the Deployments with fewer available replicas
+// than desired
+func checkDeployments(ctx context.Context, c *checker) ([]Issue, []string) {
+	deployments, notes := list[k8s.Deployment](ctx, c, "deployments.apps", "")
+	var issues []Issue
+	for _, d := range deployments {
+		desired := 1
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		if desired == 0 || d.Status.AvailableReplicas >= desired {
+			continue
+		}
+		status := DeploymentStatus{
+			Namespace: d.Metadata.Namespace,
+			Name:      d.Metadata.Name,
+			Desired:   desired,
+			Ready:     d.Status.ReadyReplicas,
+			Available: d.Status.AvailableReplicas,
+		}
+		c.report.Deployments = append(c.report.Deployments, status)
+		severity := SeverityWarning
+		if status.Available == 0 {
+			severity = SeverityCritical
+		}
+		issues = append(issues, Issue{severity, "workloads", fmt.Sprintf("Deployment %s/%s has %d/%d replicas available", status.Namespace, status.Name, status.Available, desired)})
+	}
+	sort.Slice(c.report.Deployments, func(i, j int) bool {
+		a, b := c.report.Deployments[i], c.report.Deployments[j]
+		return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+	})
+	return issues, notes
+}
+
+// checkEvents counts the warning events of the event window by reason
+func checkEvents(ctx context.Context, c *checker) ([]Issue, []string) {
+	events, notes := list[k8s.Event](ctx, c, "events", "--field-selector type=Warning")
+	cutoff := c.now.Add(-eventWindow)
+	reasons := map[string]*ReasonCount{}
+	objects := map[string]map[string]bool{}
+	latest := map[string]time.Time{}
+	summary := &c.report.WarningEvents
+	for _, event := range events {
+		seen := lastSeen(event)
+		if seen.Before(cutoff) {
+			continue
+		}
+		count := event.Count
+		if event.Series != nil && event.Series.Count > count {
+			count = event.Series.Count
+		}
+		if count < 1 {
+			count = 1
+		}
+		r, ok := reasons[event.Reason]
+		if !ok {
+			r = &ReasonCount{Reason: event.Reason}
+			reasons[event.Reason], objects[event.Reason] = r, map[string]bool{}
+		}
+		r.Count += count
+		summary.Total += count
+		objects[event.Reason][event.InvolvedObject.Namespace+"/"+event.InvolvedObject.Kind+"/"+event.InvolvedObject.Name] = true
+		if !seen.Before(latest[event.Reason]) {
+			latest[event.Reason] = seen
+			r.Example = truncate(strings.TrimSpace(event.Message), maxExampleLength)
+		}
+	}
+	for reason, r := range reasons {
+		r.Objects = len(objects[reason])
+		summary.Reasons = append(summary.Reasons, *r)
+	}
+	sort.Slice(summary.Reasons, func(i, j int) bool {
+		a, b := summary.Reasons[i], summary.Reasons[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+	if len(summary.Reasons) > maxEventReasons {
+		summary.Reasons = summary.Reasons[:maxEventReasons]
+	}
+	return nil, notes
+}
+
+// lastSeen returns when an event last occurred, from its last timestamp,
+// event time or series
+func lastSeen(event k8s.Event) time.Time {
+	var last time.Time
+	candidates := []string{event.LastTimestamp, event.EventTime, event.FirstTimestamp}
+	if event.Series != nil {
+		candidates = append(candidates, event.Series.LastObservedTime)
+	}
+	for _, value := range candidates {
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil && t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// checkCSRs counts the CertificateSigningRequests that are neither
+// approved nor denied
+func checkCSRs(ctx context.Context, c *checker) ([]Issue, []string) {
+	var csrs k8s.List[struct {
+		Metadata k8s.ObjectMeta `json:"metadata"`
+		Status   struct {
+			Conditions []k8s.Condition `json:"conditions"`
+		} `json:"status"`
+	}]
+	if err := k8s.GetJSON(ctx, c.runner, "get certificatesigningrequests", &csrs); err != nil {
+		return nil, []string{fmt.Sprintf("Could not list CertificateSigningRequests: %v", err)}
+	}
+	summary := &c.report.CSRs
+	var oldest time.Duration
+	for _, csr := range csrs.Items {
+		if len(csr.Status.Conditions) > 0 {
+			continue
+		}
+		summary.Pending++
+		if d, ok := age(c.now, csr.Metadata.CreationTimestamp); ok && d > oldest {
+			oldest = d
+		}
+	}
+	if summary.Pending == 0 {
+		return nil, nil
+	}
+	summary.OldestPending = formatAge(oldest)
+	if oldest < csrGracePeriod {
+		return nil, nil
+	}
+	return []Issue{{SeverityWarning, "certificates", fmt.Sprintf("%d CertificateSigningRequest(s) awaiting approval, the oldest for %s", summary.Pending, summary.OldestPending)}}, nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
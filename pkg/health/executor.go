@@ -0,0 +1,52 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// Executor implements the CommandExecutor interface for cluster_health
+type Executor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures Executor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*Executor)(nil)
+var _ tools.StructuredOutputExecutor = (*Executor)(nil)
+
+// NewExecutor creates a new Executor instance
+func NewExecutor() *Executor {
+	return &Executor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute checks the health of the cluster and returns the report as JSON
+func (e *Executor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := Options{}
+	opts.Namespace, _ = params["namespace"].(string)
+
+	if err := k8s.ValidateNamespace("namespace", opts.Namespace); err != nil {
+		return "", err
+	}
+
+	report, err := Check(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal health report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that cluster_health returns JSON
+func (e *Executor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
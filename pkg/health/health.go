@@ -0,0 +1,262 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// Overall status of the cluster
+const (
+	StatusHealthy  = "Healthy"
+	StatusWarning  = "Warning"
+	StatusCritical = "Critical"
+)
+
+// Issue severities
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+)
+
+const (
+	// eventWindow is how far back warning events are summarized
+	eventWindow = time.Hour
+	// pendingGracePeriod is how long a pod may be Pending before it is failing
+	pendingGracePeriod = 5 * time.Minute
+	// maxPodsPerNamespace caps the failing pods listed per namespace
+	maxPodsPerNamespace = 5
+	// maxEventReasons caps the warning event reasons listed
+	maxEventReasons = 10
+)
+
+// Options are the parameters of cluster_health
+type Options struct {
+	// Namespace restricts the namespaced checks to one namespace
+	Namespace string
+	// Now is the reference time for ages and the event window (default: the current time)
+	Now time.Time
+}
+
+// Report is the result of cluster_health
+type Report struct {
+	Status string `json:"status"`
+	// Namespaces are the namespaces of the pod, PVC, Deployment and event
+	// checks; "*" means all namespaces
+	Namespaces    []string           `json:"namespaces"`
+	Issues        []Issue            `json:"issues"`
+	Nodes         NodeSummary        `json:"nodes"`
+	ControlPlane  ControlPlane       `json:"controlPlane"`
+	Version       VersionSummary     `json:"version"`
+	Pods          PodSummary         `json:"pods"`
+	PendingPVCs   []string           `json:"pendingPVCs"`
+	Deployments   []DeploymentStatus `json:"deploymentsBelowDesired"`
+	WarningEvents EventSummary       `json:"warningEvents"`
+	CSRs          CSRSummary         `json:"certificateSigningRequests"`
+	Notes         []string           `json:"notes,omitempty"`
+}
+
+// Issue is a problem found by one of the checks
+type Issue struct {
+	Severity string `json:"severity"`
+	Area     string `json:"area"`
+	Message  string `json:"message"`
+}
+
+// NodeSummary counts nodes by readiness and lists the unhealthy ones
+type NodeSummary struct {
+	Total    int      `json:"total"`
+	Ready    int      `json:"ready"`
+	NotReady []string `json:"notReady,omitempty"`
+	// Pressure lists nodes with a pressure or network condition, as
+	// "node: MemoryPressure"
+	Pressure []string `json:"pressure,omitempty"`
+	Cordoned []string `json:"cordoned,omitempty"`
+}
+
+// ControlPlane is the health of the API server and its components
+type ControlPlane struct {
+	// Source is readyz or componentstatuses
+	Source  string   `json:"source,omitempty"`
+	Healthy bool     `json:"healthy"`
+	Failing []string `json:"failing,omitempty"`
+}
+
+// VersionSummary is the API server version and the kubelet and kubectl
+// versions that differ from it
+type VersionSummary struct {
+	Server string `json:"server,omitempty"`
+	Client string `json:"client,omitempty"`
+	// Kubelets counts nodes by kubelet version
+	Kubelets map[string]int `json:"kubelets,omitempty"`
+	Skew     []string       `json:"skew,omitempty"`
+}
+
+// PodSummary counts failing pods by namespace
+type PodSummary struct {
+	Total       int                 `json:"total"`
+	Failing     int                 `json:"failing"`
+	ByNamespace []NamespaceFailures `json:"byNamespace,omitempty"`
+}
+
+// NamespaceFailures lists the failing pods of a namespace
+type NamespaceFailures struct {
+	Namespace string       `json:"namespace"`
+	Failing   int          `json:"failing"`
+	Pods      []FailingPod `json:"pods"`
+}
+
+// FailingPod is a pod that is not running as intended
+type FailingPod struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// DeploymentStatus is a Deployment with fewer available replicas than desired
+type DeploymentStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Desired   int    `json:"desired"`
+	Ready     int    `json:"ready"`
+	Available int    `json:"available"`
+}
+
+// EventSummary counts the warning events of the last hour by reason
+type EventSummary struct {
+	Window  string        `json:"window"`
+	Total   int           `json:"total"`
+	Reasons []ReasonCount `json:"reasons,omitempty"`
+}
+
+// ReasonCount is the number of warning events and objects of a reason
+type ReasonCount struct {
+	Reason  string `json:"reason"`
+	Count   int    `json:"count"`
+	Objects int    `json:"objects"`
+	Example string `json:"example,omitempty"`
+}
+
+// CSRSummary counts the CertificateSigningRequests awaiting approval
+type CSRSummary struct {
+	Pending int `json:"pending"`
+	// OldestPending is the age of the oldest pending request
+	OldestPending string `json:"oldestPending,omitempty"`
+}
+
+// check runs one area of the report. Each check only writes its own fields
+// of the report and returns its issues and notes.
+type check func(ctx context.Context, c *checker) ([]Issue, []string)
+
+// checker holds the inputs shared by the checks
+type checker struct {
+	runner     k8s.Runner
+	namespaces []string
+	now        time.Time
+	report     *Report
+}
+
+// Check summarizes the health of the cluster. Cluster-scoped checks (nodes,
+// control plane, versions, CSRs) always run; pods, PVCs, Deployments and
+// events are limited to the allowed namespaces.
+func Check(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts Options) (*Report, error) {
+	namespaces, err := k8s.ScanNamespaces(ctx, runner, secConfig, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	report := &Report{
+		Namespaces:    namespaces,
+		Issues:        []Issue{},
+		PendingPVCs:   []string{},
+		Deployments:   []DeploymentStatus{},
+		WarningEvents: EventSummary{Window: eventWindow.String()},
+	}
+	c := &checker{runner: runner, namespaces: namespaces, now: opts.Now, report: report}
+
+	checks := []check{checkNodes, checkControlPlane, checkPods, checkPVCs, checkDeployments, checkEvents, checkCSRs}
+	issues := make([][]Issue, len(checks))
+	notes := make([][]string, len(checks))
+	var wg sync.WaitGroup
+	for i, run := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			issues[i], notes[i] = run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	if len(namespaces) == 0 {
+		report.Notes = append(report.Notes, "No allowed namespace exists in the cluster")
+	}
+	for i := range checks {
+		report.Issues = append(report.Issues, issues[i]...)
+		report.Notes = append(report.Notes, notes[i]...)
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Severity == SeverityCritical && report.Issues[j].Severity != SeverityCritical
+	})
+	report.Status = StatusHealthy
+	for _, issue := range report.Issues {
+		if issue.Severity == SeverityCritical {
+			report.Status = StatusCritical
+			break
+		}
+		report.Status = StatusWarning
+	}
+	return report, nil
+}
+
+// list runs a get command in every scanned namespace and returns the items
+func list[T any](ctx context.Context, c *checker, resource string, extra string) ([]T, []string) {
+	var items []T
+	var notes []string
+	for _, namespace := range c.namespaces {
+		scope := "-n " + namespace
+		if namespace == k8s.AllNamespaces {
+			scope = "-A"
+		}
+		command := "get " + resource + " " + scope
+		if extra != "" {
+			command += " " + extra
+		}
+		var l k8s.List[T]
+		if err := k8s.GetJSON(ctx, c.runner, command, &l); err != nil {
+			notes = append(notes, fmt.Sprintf("Could not list %s in %s: %v", resource, namespace, err))
+			continue
+		}
+		items = append(items, l.Items...)
+	}
+	return items, notes
+}
+
+// age returns the time elapsed since an RFC 3339 timestamp, and false when
+// it cannot be parsed
+func age(now time.Time, timestamp string) (time.Duration, bool) {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return 0, false
+	}
+	return now.Sub(t), true
+}
+
+// formatAge renders a duration as kubectl does, e.g. 45s, 12m, 5h or 3d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,172 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func clusterRunner() *k8stest.Runner {
+	return &k8stest.Runner{
+		Outputs: map[string]string{
+			"get nodes -o json": `{"items": [
+				{"metadata": {"name": "node-1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}, {"type": "MemoryPressure", "status": "True"}], "nodeInfo": {"kubeletVersion": "v1.29.4"}}},
+				{"metadata": {"name": "node-2"}, "spec": {"unschedulable": true}, "status": {"conditions": [{"type": "Ready", "status": "Unknown", "reason": "NodeStatusUnknown", "message": "Kubelet stopped posting node status."}], "nodeInfo": {"kubeletVersion": "v1.25.1"}}},
+				{"metadata": {"name": "node-3"}, "status": {"conditions": [{"type": "Ready", "status": "True"}], "nodeInfo": {"kubeletVersion": "v1.29.4"}}}]}`,
+			"version -o json":           `{"clientVersion": {"gitVersion": "v1.30.1"}, "serverVersion": {"gitVersion": "v1.29.4"}}`,
+			"get --raw /readyz?verbose": "[+]ping ok\n[+]etcd ok\nreadyz check passed\n",
+			"get pods -A -o json": `{"items": [
+				{"metadata": {"name": "web-1", "namespace": "shop"}, "status": {"phase": "Running", "containerStatuses": [{"name": "web", "state": {"waiting": {"reason": "CrashLoopBackOff"}}}]}},
+				{"metadata": {"name": "web-2", "namespace": "shop"}, "status": {"phase": "Running", "containerStatuses": [{"name": "web", "state": {"running": {}}}]}},
+				{"metadata": {"name": "batch-1", "namespace": "jobs", "creationTimestamp": "2026-03-01T11:00:00Z"}, "status": {"phase": "Pending", "conditions": [{"type": "PodScheduled", "status": "False", "reason": "Unschedulable"}]}},
+				{"metadata": {"name": "batch-2", "namespace": "jobs", "creationTimestamp": "2026-03-01T11:58:00Z"}, "status": {"phase": "Pending"}},
+				{"metadata": {"name": "batch-3", "namespace": "jobs"}, "status": {"phase": "Succeeded"}},
+				{"metadata": {"name": "init-1", "namespace": "shop"}, "status": {"phase": "Pending", "initContainerStatuses": [{"name": "migrate", "state": {"terminated": {"exitCode": 1, "reason": "Error"}}}]}}]}`,
+			"get persistentvolumeclaims -A -o json": `{"items": [
+				{"metadata": {"name": "data", "namespace": "shop"}, "status": {"phase": "Pending"}},
+				{"metadata": {"name": "cache", "namespace": "shop"}, "status": {"phase": "Bound"}}]}`,
+			"get deployments.apps -A -o json": `{"items": [
+				{"metadata": {"name": "web", "namespace": "shop"}, "spec": {"replicas": 2}, "status": {"readyReplicas": 1, "availableReplicas": 1}},
+				{"metadata": {"name": "api", "namespace": "shop"}, "status": {}},
+				{"metadata": {"name": "idle", "namespace": "shop"}, "spec": {"replicas": 0}, "status": {}}]}`,
+			"get events -A --field-selector type=Warning -o json": `{"items": [
+				{"involvedObject": {"kind": "Pod", "name": "web-1", "namespace": "shop"}, "reason": "BackOff", "message": "Back-off restarting failed container", "count": 7, "lastTimestamp": "2026-03-01T11:50:00Z"},
+				{"involvedObject": {"kind": "Pod", "name": "batch-1", "namespace": "jobs"}, "reason": "FailedScheduling", "message": "0/3 nodes are available", "series": {"count": 3, "lastObservedTime": "2026-03-01T11:55:00.000000Z"}, "eventTime": "2026-03-01T11:30:00.000000Z"},
+				{"involvedObject": {"kind": "Pod", "name": "old", "namespace": "jobs"}, "reason": "FailedMount", "message": "old", "lastTimestamp": "2026-03-01T09:00:00Z"}]}`,
+			"get certificatesigningrequests -o json": `{"items": [
+				{"metadata": {"name": "csr-a", "creationTimestamp": "2026-03-01T10:00:00Z"}, "status": {}},
+				{"metadata": {"name": "csr-b", "creationTimestamp": "2026-03-01T11:00:00Z"}, "status": {"conditions": [{"type": "Approved", "status": "True"}]}}]}`,
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	report, err := Check(context.Background(), clusterRunner(), security.NewSecurityConfig(), Options{Now: now})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Status != StatusCritical || report.Issues[0].Severity != SeverityCritical {
+		t.Errorf("Expected a critical status with critical issues first, got %s %+v", report.Status, report.Issues)
+	}
+
+	nodes := report.Nodes
+	if nodes.Total != 3 || nodes.Ready != 2 || strings.Join(nodes.NotReady, ",") != "node-2" ||
+		strings.Join(nodes.Pressure, ",") != "node-1: MemoryPressure" || strings.Join(nodes.Cordoned, ",") != "node-2" {
+		t.Errorf("Unexpected node summary %+v", nodes)
+	}
+	if !report.ControlPlane.Healthy || report.ControlPlane.Source != "readyz" {
+		t.Errorf("Expected a healthy control plane from readyz, got %+v", report.ControlPlane)
+	}
+	if len(report.Version.Skew) != 1 || !strings.Contains(report.Version.Skew[0], "kubelet v1.25.1") || report.Version.Kubelets["v1.29.4"] != 2 {
+		t.Errorf("Expected the v1.25 kubelet to be reported as skewed, got %+v", report.Version)
+	}
+
+	pods := report.Pods
+	if pods.Total != 6 || pods.Failing != 3 || len(pods.ByNamespace) != 2 {
+		t.Fatalf("Expected 3 failing pods in 2 namespaces, got %+v", pods)
+	}
+	shop := pods.ByNamespace[0]
+	if shop.Namespace != "shop" || shop.Failing != 2 || shop.Pods[0].Reason != "CrashLoopBackOff" || shop.Pods[1].Reason != "Init:Error" {
+		t.Errorf("Expected shop first with CrashLoopBackOff and Init:Error, got %+v", shop)
+	}
+	if jobs := pods.ByNamespace[1]; jobs.Failing != 1 || jobs.Pods[0].Name != "batch-1" || jobs.Pods[0].Reason != "Unschedulable" {
+		t.Errorf("Expected only the long pending unschedulable pod in jobs, got %+v", jobs)
+	}
+
+	if strings.Join(report.PendingPVCs, ",") != "shop/data" {
+		t.Errorf("Expected shop/data pending, got %v", report.PendingPVCs)
+	}
+	if len(report.Deployments) != 2 || report.Deployments[0].Name != "api" || report.Deployments[0].Desired != 1 || report.Deployments[1].Available != 1 {
+		t.Errorf("Expected api and web below desired replicas, got %+v", report.Deployments)
+	}
+
+	events := report.WarningEvents
+	if events.Total != 10 || len(events.Reasons) != 2 || events.Reasons[0].Reason != "BackOff" || events.Reasons[1].Count != 3 {
+		t.Errorf("Expected 10 warning events of the last hour, got %+v", events)
+	}
+	if report.CSRs.Pending != 1 || report.CSRs.OldestPending != "2h" {
+		t.Errorf("Expected 1 CSR pending for 2h, got %+v", report.CSRs)
+	}
+}
+
+func TestCheckControlPlaneFallback(t *testing.T) {
+	runner := clusterRunner()
+	runner.Errors = map[string]error{
+		"get --raw /readyz?verbose": fmt.Errorf("Error: Command does not specify a namespace"),
+	}
+	runner.Outputs["get componentstatuses -o json"] = `{"items": [
+		{"metadata": {"name": "scheduler"}, "conditions": [{"type": "Healthy", "status": "True"}]},
+		{"metadata": {"name": "etcd-0"}, "conditions": [{"type": "Healthy", "status": "False", "error": "dial tcp: connection refused"}]}]}`
+	report, err := Check(context.Background(), runner, nil, Options{Now: now})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cp := report.ControlPlane; cp.Source != "componentstatuses" || cp.Healthy || strings.Join(cp.Failing, ",") != "etcd-0" {
+		t.Errorf("Expected etcd-0 failing in componentstatuses, got %+v", cp)
+	}
+
+	runner.Errors["get --raw /readyz?verbose"] = fmt.Errorf(`Error from server (InternalError): an error on the server ("[+]ping ok\n[-]etcd failed: reason withheld\nreadyz check failed") has prevented the request from succeeding`)
+	report, err = Check(context.Background(), runner, nil, Options{Now: now})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cp := report.ControlPlane; cp.Source != "readyz" || strings.Join(cp.Failing, ",") != "etcd" {
+		t.Errorf("Expected etcd failing in readyz, got %+v", cp)
+	}
+}
+
+func TestCheckAllowedNamespaces(t *testing.T) {
+	runner := &k8stest.Runner{Outputs: map[string]string{
+		"get namespaces -o json": `{"items": [{"metadata": {"name": "shop"}}, {"metadata": {"name": "jobs"}}, {"metadata": {"name": "kube-system"}}]}`,
+		"get pods -n shop -o json": `{"items": [
+			{"metadata": {"name": "web-1", "namespace": "shop"}, "status": {"phase": "Failed", "reason": "Evicted"}}]}`,
+		"get pods -A -o json": `{"items": [{"metadata": {"name": "leak", "namespace": "kube-system"}, "status": {"phase": "Failed"}}]}`,
+	}}
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("shop,jobs")
+
+	report, err := Check(context.Background(), runner, secConfig, Options{Now: now})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(report.Namespaces, ",") != "jobs,shop" {
+		t.Errorf("Expected jobs and shop to be scanned, got %v", report.Namespaces)
+	}
+	if report.Pods.Failing != 1 || report.Pods.ByNamespace[0].Pods[0].Reason != "Evicted" {
+		t.Errorf("Expected only the evicted pod of shop, got %+v", report.Pods)
+	}
+
+	if _, err := Check(context.Background(), runner, secConfig, Options{Namespace: "kube-system"}); err == nil {
+		t.Error("Expected an error for a namespace that is not allowed")
+	}
+}
+
+func TestCheckSkew(t *testing.T) {
+	testCases := []struct {
+		name     string
+		version  VersionSummary
+		issues   int
+		critical bool
+	}{
+		{"supported", VersionSummary{Server: "v1.29.2", Client: "v1.29.0", Kubelets: map[string]int{"v1.29.2": 2, "v1.27.9": 1}}, 0, false},
+		{"kubelet too old", VersionSummary{Server: "v1.30.0", Kubelets: map[string]int{"v1.26.3": 1}}, 1, true},
+		{"kubelet newer", VersionSummary{Server: "v1.28.0-gke.1", Kubelets: map[string]int{"v1.29.0": 1}}, 1, true},
+		{"kubectl skew", VersionSummary{Server: "v1.28.3", Client: "v1.31.0"}, 1, false},
+		{"unknown server", VersionSummary{Client: "v1.31.0"}, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := checkSkew(&tc.version)
+			if len(issues) != tc.issues || (tc.issues > 0 && (issues[0].Severity == SeverityCritical) != tc.critical) {
+				t.Errorf("Expected %d issue(s) (critical: %v), got %+v", tc.issues, tc.critical, issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package health
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterClusterHealth registers the cluster_health tool
+func RegisterClusterHealth() mcp.Tool {
+	return mcp.NewTool("cluster_health",
+		mcp.WithDescription(`Summarize the health of the cluster in one compact report, as a first step before investigating a problem.
+
+Reports an overall status (Healthy, Warning or Critical) with a list of issues, and:
+- nodes: readiness, memory/disk/PID pressure, network unavailable, cordoned nodes
+- control plane: failing API server readyz checks (or componentstatuses)
+- versions: API server, kubelet and kubectl version skew
+- failing pods by namespace (CrashLoopBackOff, image pull errors, unschedulable, failed)
+- pending PersistentVolumeClaims and Deployments below their desired replicas
+- warning events of the last hour by reason
+- CertificateSigningRequests awaiting approval
+
+Pods, PVCs, Deployments and events are limited to the allowed namespaces.
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("namespace",
+			mcp.Description("Limit the pod, PVC, Deployment and event checks to this namespace (default: all allowed namespaces)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Cluster Health",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
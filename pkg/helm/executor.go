@@ -2,7 +2,10 @@ package helm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/Azure/mcp-kubernetes/pkg/command"
 	"github.com/Azure/mcp-kubernetes/pkg/config"
@@ -14,19 +17,30 @@ import (
 type HelmExecutor struct{}
 
 var _ tools.CommandExecutor = (*HelmExecutor)(nil)
+var _ tools.CommandDescriber = (*HelmExecutor)(nil)
 
 // NewExecutor creates a new HelmExecutor instance
 func NewExecutor() *HelmExecutor {
 	return &HelmExecutor{}
 }
 
-// Execute handles helm command execution
+// Execute handles helm command execution. Values under sensitive keys and
+// Secret data are masked in the output.
 func (e *HelmExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
 	helmCmd, ok := params["command"].(string)
 	if !ok {
 		return "", fmt.Errorf("invalid command parameter")
 	}
 
+	output, err := e.run(helmCmd, cfg)
+	if err != nil {
+		return "", err
+	}
+	return newRedactor(cfg.SecurityConfig).output(helmCmd, output), nil
+}
+
+// run validates and executes a helm command and returns its unmasked output
+func (e *HelmExecutor) run(helmCmd string, cfg *config.ConfigData) (string, error) {
 	// Validate the command against security settings
 	validator := security.NewValidator(cfg.SecurityConfig)
 	err := validator.ValidateCommand(helmCmd, security.CommandTypeHelm)
@@ -36,5 +50,277 @@ func (e *HelmExecutor) Execute(ctx context.Context, params map[string]interface{
 
 	// Execute the command
 	process := command.NewShellProcess("helm", cfg.Timeout)
+	process.Limiter = cfg.ProcessLimiter
 	return process.Run(helmCmd)
 }
+
+// DescribeCommand returns the helm command a call would run
+func (e *HelmExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	helmCmd, ok := params["command"].(string)
+	return security.CommandTypeHelm, helmCmd, ok
+}
+
+// releaseOutputFormat is the response cache format of the release tools
+const releaseOutputFormat = "release"
+
+// ReleaseExecutor implements the CommandExecutor interface for the
+// structured helm release tools. Every command goes through HelmExecutor, so
+// it is validated against the configured access level and namespaces. The
+// executor reads unmasked output, which previews need to reuse values, and
+// masks what it returns itself.
+type ReleaseExecutor struct {
+	run func(ctx context.Context, command string, cfg *config.ConfigData) (string, error)
+}
+
+// This line ensures ReleaseExecutor implements the CommandExecutor, CommandDescriber, OutputFormatDescriber and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*ReleaseExecutor)(nil)
+var _ tools.CommandDescriber = (*ReleaseExecutor)(nil)
+var _ tools.OutputFormatDescriber = (*ReleaseExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*ReleaseExecutor)(nil)
+
+// NewReleaseExecutor creates a new ReleaseExecutor instance
+func NewReleaseExecutor() *ReleaseExecutor {
+	return &ReleaseExecutor{
+		run: func(ctx context.Context, command string, cfg *config.ConfigData) (string, error) {
+			return NewExecutor().run(command, cfg)
+		},
+	}
+}
+
+// Execute runs the helm command for the tool named by _tool_name and returns
+// its result as JSON
+func (e *ReleaseExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	call, err := parseReleaseCall(params)
+	if err != nil {
+		return "", err
+	}
+
+	var result interface{}
+	switch call.Tool {
+	case toolReleases:
+		result, err = e.releases(ctx, call, cfg)
+	case toolInstallUpgrade:
+		result, err = e.installUpgrade(ctx, call, cfg)
+	case toolRollback:
+		result, err = e.rollback(ctx, call, cfg)
+	case toolUninstall:
+		result, err = e.uninstall(ctx, call, cfg)
+	case toolPreview:
+		result, err = e.preview(ctx, call, cfg)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return string(data), nil
+}
+
+// releases runs a read-only helm_releases operation
+func (e *ReleaseExecutor) releases(ctx context.Context, call *releaseCall, cfg *config.ConfigData) (interface{}, error) {
+	command := call.command("")
+	output, err := e.run(ctx, command, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch call.Operation {
+	case operationList:
+		var releases []map[string]interface{}
+		if err := parseJSON(output, command, &releases); err != nil {
+			return nil, err
+		}
+		if releases == nil {
+			releases = []map[string]interface{}{}
+		}
+		return map[string]interface{}{"releases": releases}, nil
+	case operationStatus:
+		return parseReleaseStatus(output, command)
+	case operationHistory:
+		var history []map[string]interface{}
+		if err := parseJSON(output, command, &history); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"release": call.Release, "namespace": call.Namespace, "history": history}, nil
+	case operationValues:
+		var values map[string]interface{}
+		if err := parseJSON(newRedactor(cfg.SecurityConfig).valuesText(output, "json"), command, &values); err != nil {
+			return nil, err
+		}
+		if values == nil {
+			values = map[string]interface{}{}
+		}
+		return map[string]interface{}{"release": call.Release, "namespace": call.Namespace, "values": values}, nil
+	default:
+		if err := helmError(output); err != nil {
+			return nil, err
+		}
+		manifest := newRedactor(cfg.SecurityConfig).manifest(output)
+		return map[string]interface{}{"release": call.Release, "namespace": call.Namespace, "manifest": manifest}, nil
+	}
+}
+
+// installUpgrade installs or upgrades a release
+func (e *ReleaseExecutor) installUpgrade(ctx context.Context, call *releaseCall, cfg *config.ConfigData) (interface{}, error) {
+	valuesFile, cleanup, err := writeValuesFile(call.Values)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	command := call.command(valuesFile)
+	output, err := e.run(ctx, command, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return parseReleaseStatus(output, command)
+}
+
+// preview renders the chart with the proposed values and diffs the result
+// against the release's current manifest. Like helm upgrade, it reuses the
+// release's user-supplied values when no values are given.
+func (e *ReleaseExecutor) preview(ctx context.Context, call *releaseCall, cfg *config.ConfigData) (interface{}, error) {
+	report := &PreviewReport{Release: call.Release, Namespace: call.Namespace, Chart: call.Chart, Version: call.Version}
+
+	manifestCall := &releaseCall{Tool: toolReleases, Operation: operationManifest, Release: call.Release, Namespace: call.Namespace}
+	output, err := e.run(ctx, manifestCall.command(""), cfg)
+	if err != nil {
+		return nil, err
+	}
+	exists := true
+	if err := helmError(output); err != nil {
+		if !strings.Contains(err.Error(), "not found") {
+			return nil, err
+		}
+		exists, output = false, ""
+		report.Notes = append(report.Notes, "The release does not exist yet; every rendered object would be installed")
+	}
+	current, err := parseManifest(output)
+	if err != nil {
+		return nil, err
+	}
+
+	values := call.Values
+	if values == nil && exists {
+		valuesCall := &releaseCall{Tool: toolReleases, Operation: operationValues, Release: call.Release, Namespace: call.Namespace}
+		command := valuesCall.command("")
+		output, err := e.run(ctx, command, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := parseJSON(output, command, &values); err != nil {
+			return nil, err
+		}
+		if len(values) > 0 {
+			report.Notes = append(report.Notes, "No values were given, so the release's current user-supplied values are reused as helm upgrade does")
+		}
+	}
+
+	valuesFile, cleanup, err := writeValuesFile(values)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	command := call.command(valuesFile)
+	report.RenderCommand = call.command("")
+	output, err = e.run(ctx, command, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := helmError(output); err != nil {
+		return nil, err
+	}
+	rendered, err := parseManifest(output)
+	if err != nil {
+		return nil, err
+	}
+
+	diffManifests(current, rendered, newRedactor(cfg.SecurityConfig), report)
+	report.Notes = append(report.Notes, "Rendered with helm template: hooks and tests are excluded, and templates that use lookup render without cluster state")
+	return report, nil
+}
+
+// writeValuesFile writes values to a temporary JSON file, which helm reads
+// as YAML. It returns an empty path when there are no values; the cleanup
+// function removes the file.
+func writeValuesFile(values map[string]interface{}) (string, func(), error) {
+	if len(values) == 0 {
+		return "", func() {}, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal values: %w", err)
+	}
+	file, err := os.CreateTemp("", "helm-values-*.json")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create values file: %w", err)
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write values file: %w", err)
+	}
+	return file.Name(), cleanup, nil
+}
+
+// rollback rolls a release back and returns its resulting status
+func (e *ReleaseExecutor) rollback(ctx context.Context, call *releaseCall, cfg *config.ConfigData) (interface{}, error) {
+	output, err := e.run(ctx, call.command(""), cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := helmError(output); err != nil {
+		return nil, err
+	}
+
+	status := &releaseCall{Tool: toolReleases, Operation: operationStatus, Release: call.Release, Namespace: call.Namespace}
+	return e.releases(ctx, status, cfg)
+}
+
+// uninstall removes a release
+func (e *ReleaseExecutor) uninstall(ctx context.Context, call *releaseCall, cfg *config.ConfigData) (interface{}, error) {
+	output, err := e.run(ctx, call.command(""), cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := helmError(output); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"release":     call.Release,
+		"namespace":   call.Namespace,
+		"uninstalled": true,
+		"message":     strings.TrimSpace(output),
+	}, nil
+}
+
+// DescribeCommand returns the helm command a call would run, so read calls
+// can be cached and write calls invalidate the cache. A preview runs several
+// commands and depends on the values passed, so it is not described.
+func (e *ReleaseExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	call, err := parseReleaseCall(params)
+	if err != nil || call.Tool == toolPreview {
+		return "", "", false
+	}
+	return security.CommandTypeHelm, call.command(""), true
+}
+
+// DescribeOutputFormat returns releaseOutputFormat. The release tools run the
+// same helm commands as call_helm but return a JSON report instead of helm's
+// output, so their responses must not share call_helm's cache entries.
+func (e *ReleaseExecutor) DescribeOutputFormat(params map[string]interface{}) string {
+	return releaseOutputFormat
+}
+
+// ReturnsStructuredOutput reports that the release tools always return a JSON object
+func (e *ReleaseExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
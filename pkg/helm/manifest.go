@@ -0,0 +1,102 @@
+package helm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestObject is one Kubernetes object of a rendered helm manifest
+type manifestObject struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Object     map[string]interface{}
+}
+
+// ObjectRef identifies an object in a preview report
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// ref returns the reference of the object
+func (o manifestObject) ref() ObjectRef {
+	return ObjectRef{APIVersion: o.APIVersion, Kind: o.Kind, Namespace: o.Namespace, Name: o.Name}
+}
+
+// key identifies the object across revisions. The API group is used rather
+// than the full apiVersion, so an object moving from v1beta1 to v1 is
+// reported as changed instead of removed and added.
+func (o manifestObject) key() string {
+	group := ""
+	if i := strings.LastIndex(o.APIVersion, "/"); i >= 0 {
+		group = o.APIVersion[:i]
+	}
+	return strings.Join([]string{group, o.Kind, o.Namespace, o.Name}, "/")
+}
+
+// String returns the object as Kind/namespace/name, or Kind/name for
+// objects without a namespace
+func (r ObjectRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// parseManifest splits a multi-document YAML manifest into objects. Empty
+// documents, comments and List wrappers are handled; documents that are not
+// Kubernetes objects are skipped.
+func parseManifest(manifest string) ([]manifestObject, error) {
+	var objects []manifestObject
+	decoder := yaml.NewDecoder(bytes.NewBufferString(manifest))
+	for {
+		var doc map[string]interface{}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		objects = append(objects, manifestObjects(doc)...)
+	}
+	return objects, nil
+}
+
+// manifestObjects returns the object a decoded document holds, or the items
+// of a List
+func manifestObjects(doc map[string]interface{}) []manifestObject {
+	if doc == nil {
+		return nil
+	}
+	kind, _ := doc["kind"].(string)
+	if items, ok := doc["items"].([]interface{}); ok && strings.HasSuffix(kind, "List") {
+		var objects []manifestObject
+		for _, item := range items {
+			if obj, ok := item.(map[string]interface{}); ok {
+				objects = append(objects, manifestObjects(obj)...)
+			}
+		}
+		return objects
+	}
+
+	obj := manifestObject{Kind: kind, Object: doc}
+	obj.APIVersion, _ = doc["apiVersion"].(string)
+	if metadata, ok := doc["metadata"].(map[string]interface{}); ok {
+		obj.Name, _ = metadata["name"].(string)
+		obj.Namespace, _ = metadata["namespace"].(string)
+	}
+	if obj.Kind == "" || obj.Name == "" {
+		return nil
+	}
+	return []manifestObject{obj}
+}
@@ -0,0 +1,285 @@
+package helm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxChangesPerObject caps the field changes reported for one object
+const maxChangesPerObject = 25
+
+// Risk severities
+const (
+	severityHigh   = "high"
+	severityMedium = "medium"
+)
+
+// Risk types
+const (
+	riskImmutableField = "immutable-field"
+	riskDeletedPVC     = "deleted-pvc"
+	riskDeletedData    = "deleted-data"
+	riskCRDChange      = "crd-change"
+)
+
+// immutableFields lists fields the API server rejects changes to, by kind.
+// A change to a listed path or anything below it makes the upgrade fail
+// unless the object is deleted and recreated.
+var immutableFields = map[string][]string{
+	"Deployment":            {"spec.selector"},
+	"ReplicaSet":            {"spec.selector"},
+	"DaemonSet":             {"spec.selector"},
+	"StatefulSet":           {"spec.selector", "spec.serviceName", "spec.volumeClaimTemplates", "spec.podManagementPolicy"},
+	"Job":                   {"spec.selector", "spec.template", "spec.completionMode"},
+	"Service":               {"spec.clusterIP", "spec.clusterIPs"},
+	"PersistentVolumeClaim": {"spec.accessModes", "spec.storageClassName", "spec.volumeName", "spec.volumeMode", "spec.selector", "spec.dataSource", "spec.dataSourceRef"},
+	"StorageClass":          {"provisioner", "parameters", "reclaimPolicy", "volumeBindingMode"},
+	"RoleBinding":           {"roleRef"},
+	"ClusterRoleBinding":    {"roleRef"},
+	"Secret":                {"type"},
+}
+
+// dataKinds are kinds whose removal deletes stored data or everything in them
+var dataKinds = map[string]bool{
+	"PersistentVolume": true,
+	"Namespace":        true,
+}
+
+// PreviewReport is the result of helm_upgrade_preview
+type PreviewReport struct {
+	Release       string         `json:"release"`
+	Namespace     string         `json:"namespace"`
+	Chart         string         `json:"chart"`
+	Version       string         `json:"version,omitempty"`
+	RenderCommand string         `json:"render_command"`
+	Summary       PreviewSummary `json:"summary"`
+	Added         []ObjectRef    `json:"added"`
+	Removed       []ObjectRef    `json:"removed"`
+	Changed       []ObjectChange `json:"changed"`
+	Risks         []PreviewRisk  `json:"risks"`
+	Notes         []string       `json:"notes,omitempty"`
+}
+
+// PreviewSummary counts objects by outcome
+type PreviewSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// ObjectChange lists the changed fields of an object present before and
+// after the upgrade
+type ObjectChange struct {
+	ObjectRef
+	PreviousAPIVersion string        `json:"previousApiVersion,omitempty"`
+	Changes            []FieldChange `json:"changes"`
+	Truncated          bool          `json:"truncated,omitempty"`
+}
+
+// FieldChange is one changed field. Before or after is omitted when the
+// field is added or removed; both are omitted for Secrets and for fields
+// under keys whose values are masked.
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// PreviewRisk flags a change that is likely to fail or lose data
+type PreviewRisk struct {
+	Severity string `json:"severity"`
+	Type     string `json:"type"`
+	Object   string `json:"object"`
+	Message  string `json:"message"`
+}
+
+// diffManifests compares the current and rendered manifests of a release.
+// Field values of Secrets and under masked keys are left out of the changes.
+func diffManifests(current, rendered []manifestObject, masker *redactor, report *PreviewReport) {
+	before := make(map[string]manifestObject, len(current))
+	for _, obj := range current {
+		before[obj.key()] = obj
+	}
+	after := make(map[string]manifestObject, len(rendered))
+	for _, obj := range rendered {
+		after[obj.key()] = obj
+	}
+
+	report.Added, report.Removed, report.Changed, report.Risks = []ObjectRef{}, []ObjectRef{}, []ObjectChange{}, []PreviewRisk{}
+	for key, obj := range after {
+		old, ok := before[key]
+		if !ok {
+			report.Added = append(report.Added, obj.ref())
+			continue
+		}
+		if reflect.DeepEqual(old.Object, obj.Object) {
+			report.Summary.Unchanged++
+			continue
+		}
+		change := ObjectChange{ObjectRef: obj.ref()}
+		if old.APIVersion != obj.APIVersion {
+			change.PreviousAPIVersion = old.APIVersion
+		}
+		var changes []FieldChange
+		diffValues("", old.Object, obj.Object, &changes)
+		for i := range changes {
+			if obj.Kind == "Secret" || masker.hidesPath(changes[i].Path) {
+				changes[i].Before, changes[i].After = nil, nil
+			}
+		}
+		report.Risks = append(report.Risks, changeRisks(old, obj, changes)...)
+		if len(changes) > maxChangesPerObject {
+			changes, change.Truncated = changes[:maxChangesPerObject], true
+		}
+		change.Changes = changes
+		report.Changed = append(report.Changed, change)
+	}
+	for key, obj := range before {
+		if _, ok := after[key]; !ok {
+			report.Removed = append(report.Removed, obj.ref())
+			report.Risks = append(report.Risks, removalRisks(obj)...)
+		}
+	}
+	for _, obj := range rendered {
+		if _, ok := before[obj.key()]; !ok && obj.Kind == "CustomResourceDefinition" {
+			report.Risks = append(report.Risks, PreviewRisk{Severity: severityMedium, Type: riskCRDChange, Object: obj.ref().String(),
+				Message: "The upgrade adds a CustomResourceDefinition, which is cluster-wide"})
+		}
+	}
+
+	sortRefs(report.Added)
+	sortRefs(report.Removed)
+	sort.Slice(report.Changed, func(i, j int) bool { return refLess(report.Changed[i].ObjectRef, report.Changed[j].ObjectRef) })
+	sort.SliceStable(report.Risks, func(i, j int) bool {
+		if report.Risks[i].Severity != report.Risks[j].Severity {
+			return report.Risks[i].Severity == severityHigh
+		}
+		return report.Risks[i].Object < report.Risks[j].Object
+	})
+	report.Summary.Added, report.Summary.Removed, report.Summary.Changed = len(report.Added), len(report.Removed), len(report.Changed)
+}
+
+// diffValues appends the differences between two decoded YAML values.
+// Maps are compared key by key and lists of equal length item by item;
+// anything else is reported as a change of the whole value.
+func diffValues(path string, before, after interface{}, changes *[]FieldChange) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make(map[string]bool)
+		for k := range beforeMap {
+			keys[k] = true
+		}
+		for k := range afterMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			diffValues(child, beforeMap[k], afterMap[k], changes)
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList && len(beforeList) == len(afterList) {
+		for i := range beforeList {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), beforeList[i], afterList[i], changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, FieldChange{Path: path, Before: before, After: after})
+	}
+}
+
+// changeRisks flags changes to immutable fields and CRDs
+func changeRisks(before, after manifestObject, changes []FieldChange) []PreviewRisk {
+	object := after.ref().String()
+	var risks []PreviewRisk
+	if after.Kind == "CustomResourceDefinition" {
+		risks = append(risks, PreviewRisk{Severity: severityMedium, Type: riskCRDChange, Object: object,
+			Message: "The upgrade changes a CustomResourceDefinition, which affects every custom resource of its kind cluster-wide"})
+	}
+
+	var immutable []string
+	for _, field := range immutableFields[after.Kind] {
+		if hasChangeUnder(changes, field) {
+			immutable = append(immutable, field)
+		}
+	}
+	if isImmutableData(before) {
+		for _, field := range []string{"data", "binaryData", "stringData"} {
+			if hasChangeUnder(changes, field) {
+				immutable = append(immutable, field)
+			}
+		}
+	}
+	if len(immutable) > 0 {
+		risks = append(risks, PreviewRisk{Severity: severityHigh, Type: riskImmutableField, Object: object,
+			Message: fmt.Sprintf("Immutable field(s) %s change; the upgrade fails unless the object is deleted and recreated", strings.Join(immutable, ", "))})
+	}
+	return risks
+}
+
+// removalRisks flags removed objects whose deletion loses data
+func removalRisks(obj manifestObject) []PreviewRisk {
+	object := obj.ref().String()
+	switch {
+	case obj.Kind == "PersistentVolumeClaim":
+		return []PreviewRisk{{Severity: severityHigh, Type: riskDeletedPVC, Object: object,
+			Message: "The upgrade deletes a PersistentVolumeClaim; with a Delete reclaim policy its volume and data are deleted too"}}
+	case obj.Kind == "CustomResourceDefinition":
+		return []PreviewRisk{{Severity: severityHigh, Type: riskCRDChange, Object: object,
+			Message: "The upgrade deletes a CustomResourceDefinition and with it every custom resource of its kind"}}
+	case dataKinds[obj.Kind]:
+		return []PreviewRisk{{Severity: severityHigh, Type: riskDeletedData, Object: object,
+			Message: fmt.Sprintf("The upgrade deletes a %s and everything stored in it", obj.Kind)}}
+	}
+	return nil
+}
+
+// hasChangeUnder reports whether a change is at the field or below it
+func hasChangeUnder(changes []FieldChange, field string) bool {
+	for _, c := range changes {
+		if c.Path == field || strings.HasPrefix(c.Path, field+".") || strings.HasPrefix(c.Path, field+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// isImmutableData reports whether the object is a ConfigMap or Secret
+// marked immutable
+func isImmutableData(obj manifestObject) bool {
+	immutable, _ := obj.Object["immutable"].(bool)
+	return immutable && (obj.Kind == "ConfigMap" || obj.Kind == "Secret")
+}
+
+// sortRefs sorts object references by kind, namespace and name
+func sortRefs(refs []ObjectRef) {
+	sort.Slice(refs, func(i, j int) bool { return refLess(refs[i], refs[j]) })
+}
+
+// refLess orders object references by kind, namespace and name
+func refLess(a, b ObjectRef) bool {
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
@@ -0,0 +1,276 @@
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+)
+
+// currentManifest is "helm get manifest" output of the deployed revision
+const currentManifest = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  clusterIP: 10.0.0.10
+  ports:
+  - port: 80
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+---
+# Source: web/templates/pvc.yaml
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: web-data
+spec:
+  accessModes: [ReadWriteOnce]
+  resources:
+    requests:
+      storage: 1Gi
+---
+# Source: web/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-auth
+data:
+  password: b2xk
+---
+# Source: web/templates/crd.yaml
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  versions: [{name: v1}]
+`
+
+// renderedManifest is "helm template" output with the proposed values
+const renderedManifest = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  clusterIP: 10.0.0.10
+  ports:
+  - port: 80
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+      tier: frontend
+  template:
+    metadata:
+      labels:
+        app: web
+        tier: frontend
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+---
+# Source: web/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-auth
+data:
+  password: bmV3
+---
+# Source: web/templates/crd.yaml
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  versions: [{name: v1}, {name: v2}]
+---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  mode: fast
+`
+
+func TestParseManifest(t *testing.T) {
+	manifest := "---\n# only a comment\n---\napiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  kind: ConfigMap\n  metadata:\n    name: a\n    namespace: team-a\n---\nfoo: bar\n"
+	objects, err := parseManifest(manifest)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(objects) != 1 || objects[0].ref().String() != "ConfigMap/team-a/a" {
+		t.Errorf("Expected the List item only, got %+v", objects)
+	}
+
+	if _, err := parseManifest("kind: [unclosed"); err == nil {
+		t.Errorf("Expected invalid YAML to fail")
+	}
+}
+
+func TestDiffManifests(t *testing.T) {
+	current, err := parseManifest(currentManifest)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rendered, err := parseManifest(renderedManifest)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report := &PreviewReport{}
+	diffManifests(current, rendered, newRedactor(nil), report)
+
+	expected := PreviewSummary{Added: 1, Removed: 1, Changed: 3, Unchanged: 1}
+	if report.Summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, report.Summary)
+	}
+	if report.Added[0].String() != "ConfigMap/web-config" || report.Removed[0].String() != "PersistentVolumeClaim/web-data" {
+		t.Errorf("Expected web-config added and web-data removed, got %+v %+v", report.Added, report.Removed)
+	}
+
+	var deployment *ObjectChange
+	for i := range report.Changed {
+		if report.Changed[i].Kind == "Deployment" {
+			deployment = &report.Changed[i]
+		}
+		if report.Changed[i].Kind == "Secret" {
+			for _, c := range report.Changed[i].Changes {
+				if c.Before != nil || c.After != nil {
+					t.Errorf("Expected Secret values to be hidden, got %+v", c)
+				}
+			}
+		}
+	}
+	if deployment == nil {
+		t.Fatalf("Expected the Deployment to change, got %+v", report.Changed)
+	}
+	paths := map[string]FieldChange{}
+	for _, c := range deployment.Changes {
+		paths[c.Path] = c
+	}
+	if c := paths["spec.template.spec.containers[0].image"]; c.Before != "nginx:1.25" || c.After != "nginx:1.27" {
+		t.Errorf("Expected image change, got %+v", deployment.Changes)
+	}
+	if _, ok := paths["spec.selector.matchLabels.tier"]; !ok {
+		t.Errorf("Expected selector change, got %+v", deployment.Changes)
+	}
+
+	risks := map[string]PreviewRisk{}
+	for _, r := range report.Risks {
+		risks[r.Type+" "+r.Object] = r
+	}
+	for _, key := range []string{
+		"immutable-field Deployment/web",
+		"deleted-pvc PersistentVolumeClaim/web-data",
+		"crd-change CustomResourceDefinition/widgets.example.com",
+	} {
+		if _, ok := risks[key]; !ok {
+			t.Errorf("Expected risk %s, got %+v", key, report.Risks)
+		}
+	}
+	if len(report.Risks) != 3 || report.Risks[0].Severity != severityHigh || report.Risks[2].Severity != severityMedium {
+		t.Errorf("Expected 3 risks with high severity first, got %+v", report.Risks)
+	}
+}
+
+func TestImmutableConfigMapChange(t *testing.T) {
+	before := manifestObject{Kind: "ConfigMap", Name: "a", Object: map[string]interface{}{"immutable": true, "data": map[string]interface{}{"k": "1"}}}
+	after := manifestObject{Kind: "ConfigMap", Name: "a", Object: map[string]interface{}{"immutable": true, "data": map[string]interface{}{"k": "2"}}}
+
+	var changes []FieldChange
+	diffValues("", before.Object, after.Object, &changes)
+	risks := changeRisks(before, after, changes)
+	if len(risks) != 1 || risks[0].Type != riskImmutableField || !strings.Contains(risks[0].Message, "data") {
+		t.Errorf("Expected an immutable data risk, got %+v", risks)
+	}
+}
+
+func TestReleaseExecutorPreview(t *testing.T) {
+	var commands []string
+	outputs := map[string]string{
+		"helm get manifest web -n team-a":       currentManifest,
+		"helm get values web -n team-a -o json": `{"replicaCount":2}`,
+		"helm template web ./charts/web":        renderedManifest,
+		"helm get manifest new -n team-a":       "Error: release: not found\n",
+		"helm template new ./charts/web":        renderedManifest,
+	}
+	executor := &ReleaseExecutor{run: func(ctx context.Context, command string, cfg *config.ConfigData) (string, error) {
+		commands = append(commands, command)
+		for prefix, output := range outputs {
+			if strings.HasPrefix(command, prefix) {
+				return output, nil
+			}
+		}
+		t.Fatalf("Unexpected command %s", command)
+		return "", nil
+	}}
+
+	params := map[string]interface{}{"_tool_name": toolPreview, "release": "web", "chart": "./charts/web", "namespace": "team-a"}
+	result, err := executor.Execute(context.Background(), params, config.NewConfig())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report PreviewReport
+	if err := json.Unmarshal([]byte(result), &report); err != nil {
+		t.Fatalf("Expected JSON report, got %v", err)
+	}
+	if report.Summary.Changed != 3 || report.RenderCommand != "helm template web ./charts/web -n team-a --is-upgrade --no-hooks --skip-tests" {
+		t.Errorf("Expected 3 changed objects from the render command, got %+v", report)
+	}
+	if len(commands) != 3 || !strings.Contains(commands[2], " -f ") {
+		t.Errorf("Expected the current values to be reused as a values file, got %v", commands)
+	}
+	if _, _, ok := executor.DescribeCommand(params); ok {
+		t.Errorf("Expected preview not to be described as a single command")
+	}
+
+	commands = nil
+	params["release"] = "new"
+	result, err = executor.Execute(context.Background(), params, config.NewConfig())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	report = PreviewReport{}
+	if err := json.Unmarshal([]byte(result), &report); err != nil {
+		t.Fatalf("Expected JSON report, got %v", err)
+	}
+	if report.Summary.Added != 5 || len(report.Removed) != 0 || len(commands) != 2 {
+		t.Errorf("Expected every object to be added for a new release, got %+v (commands %v)", report.Summary, commands)
+	}
+}
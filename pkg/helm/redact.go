@@ -0,0 +1,453 @@
+package helm
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces masked values
+const redactedValue = "<redacted>"
+
+// unrecognizedOutput replaces the output of helm commands the redactor
+// cannot classify, since it cannot tell which parts of it are sensitive
+const unrecognizedOutput = redactedValue + " (output of an unrecognized helm command or output format is not shown)\n"
+
+// Headers of the sections helm prints in "get all" and in the text output
+// of install and upgrade
+var (
+	valuesSectionHeaders   = map[string]bool{"USER-SUPPLIED VALUES:": true, "COMPUTED VALUES:": true}
+	manifestSectionHeaders = map[string]bool{"HOOKS:": true, "MANIFEST:": true}
+	otherSectionHeaders    = map[string]bool{"NOTES:": true}
+)
+
+// redactor masks sensitive data in helm output: values under keys that match
+// the configured patterns, and the data of Secret manifests
+type redactor struct {
+	secConfig *security.SecurityConfig
+}
+
+// newRedactor creates a redactor for the configured key patterns
+func newRedactor(secConfig *security.SecurityConfig) *redactor {
+	if secConfig == nil {
+		secConfig = security.NewSecurityConfig()
+	}
+	return &redactor{secConfig: secConfig}
+}
+
+// output masks the output of a helm command. Commands that print values,
+// manifests or release objects are masked, and so is the output of commands
+// whose subcommand or output format is not recognized. Only commands known
+// to print none of these, and helm's error text, are returned as is.
+func (r *redactor) output(command, output string) string {
+	if helmError(output) != nil {
+		return output
+	}
+
+	tokens := security.TokenizeCommand(command)
+	args := helmPositionalArgs(tokens)
+	if len(args) == 0 {
+		return unrecognizedOutput
+	}
+	format := helmOutputFormat(tokens)
+	switch args[0] {
+	case "get":
+		if len(args) < 2 {
+			return unrecognizedOutput
+		}
+		switch args[1] {
+		case "values":
+			if format == "" || format == "yaml" || format == "table" || format == "json" {
+				return r.valuesText(output, format)
+			}
+		case "manifest", "hooks":
+			return r.manifest(output)
+		case "all":
+			if !hasFlag(tokens, "--template") {
+				return r.sections(output)
+			}
+		case "notes", "metadata":
+			return output
+		}
+	case "show", "inspect":
+		if len(args) < 2 {
+			return unrecognizedOutput
+		}
+		switch args[1] {
+		case "values":
+			return r.valuesText(output, "")
+		case "chart", "readme", "crds":
+			return output
+		}
+	case "template":
+		return r.manifest(output)
+	case "status", "install", "upgrade", "rollback":
+		switch format {
+		case "json", "yaml":
+			return r.releaseObject(output, format)
+		case "", "table":
+			return r.sections(output)
+		}
+	case "list", "ls", "history", "hist", "repo", "search", "version", "dependency", "dep", "lint", "uninstall", "delete", "del", "un", "test":
+		return output
+	}
+	return unrecognizedOutput
+}
+
+// valuesText masks a values document: JSON for -o json, otherwise YAML,
+// optionally preceded by a "USER-SUPPLIED VALUES:" style header line
+func (r *redactor) valuesText(output, format string) string {
+	if format == "json" {
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(output), &node); err != nil {
+			return redactedValue
+		}
+		r.valuesNode(&node, false)
+		var values interface{}
+		if err := node.Decode(&values); err != nil {
+			return redactedValue
+		}
+		data, err := encodeJSON(values)
+		if err != nil {
+			return redactedValue
+		}
+		return data
+	}
+
+	header := ""
+	body := output
+	if first, rest, ok := strings.Cut(output, "\n"); ok && valuesSectionHeaders[strings.TrimSpace(first)] {
+		header, body = first+"\n", rest
+	}
+	return header + r.valuesYAML(body)
+}
+
+// valuesYAML masks a YAML values document, keeping key order and comments.
+// A document that cannot be parsed is masked entirely.
+func (r *redactor) valuesYAML(body string) string {
+	if strings.TrimSpace(body) == "" || strings.TrimSpace(body) == "null" {
+		return body
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(body), &node); err != nil {
+		return redactedValue + "\n"
+	}
+	r.valuesNode(&node, false)
+	return encodeNode(&node, body)
+}
+
+// valuesNode masks scalar values under matching keys. Once a key matches,
+// every string or number below it is masked; booleans, nulls and empty
+// strings are kept since they carry no secret.
+func (r *redactor) valuesNode(node *yaml.Node, masked bool) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			r.valuesNode(child, masked)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			r.valuesNode(node.Content[i+1], masked || r.secConfig.IsRedactedKey(node.Content[i].Value))
+		}
+	case yaml.AliasNode:
+		if masked {
+			maskScalar(node)
+		}
+	case yaml.ScalarNode:
+		if masked && node.Value != "" && node.Tag != "!!bool" && node.Tag != "!!null" {
+			maskScalar(node)
+		}
+	}
+}
+
+// manifest masks the data of Secret objects in a multi-document manifest.
+// Other documents, including their "# Source:" comments, are kept verbatim.
+func (r *redactor) manifest(manifest string) string {
+	docs := splitDocuments(manifest)
+	for i, doc := range docs {
+		if !strings.Contains(doc, "Secret") {
+			continue
+		}
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(doc), &node); err != nil {
+			docs[i] = "# " + redactedValue + " (unparseable document)\n"
+			continue
+		}
+		if maskSecretNode(&node) {
+			docs[i] = encodeNode(&node, doc)
+		}
+	}
+	return strings.Join(docs, "")
+}
+
+// sections masks helm's sectioned text output ("get all", and install or
+// upgrade without -o): values sections as values, hook and manifest
+// sections as manifests
+func (r *redactor) sections(output string) string {
+	var out, section strings.Builder
+	mask := func(s string) string { return s }
+	flush := func() {
+		out.WriteString(mask(section.String()))
+		section.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(output, "\n") {
+		header := strings.TrimSpace(line)
+		switch {
+		case valuesSectionHeaders[header]:
+			flush()
+			out.WriteString(line)
+			mask = r.valuesYAML
+		case manifestSectionHeaders[header]:
+			flush()
+			out.WriteString(line)
+			mask = r.manifest
+		case otherSectionHeaders[header]:
+			flush()
+			out.WriteString(line)
+			mask = func(s string) string { return s }
+		default:
+			section.WriteString(line)
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// releaseObject masks a release printed with -o json or -o yaml: its user
+// values (config), chart default values, manifest and hook manifests
+func (r *redactor) releaseObject(output, format string) string {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(output), &node); err != nil || len(node.Content) == 0 {
+		return unrecognizedOutput
+	}
+	root := node.Content[0]
+	if config := mappingValue(root, "config"); config != nil {
+		r.valuesNode(config, false)
+	}
+	if chart := mappingValue(root, "chart"); chart != nil {
+		if values := mappingValue(chart, "values"); values != nil {
+			r.valuesNode(values, false)
+		}
+	}
+	if manifest := mappingValue(root, "manifest"); manifest != nil && manifest.Kind == yaml.ScalarNode {
+		manifest.Value = r.manifest(manifest.Value)
+	}
+	if hooks := mappingValue(root, "hooks"); hooks != nil {
+		for _, hook := range hooks.Content {
+			if manifest := mappingValue(hook, "manifest"); manifest != nil && manifest.Kind == yaml.ScalarNode {
+				manifest.Value = r.manifest(manifest.Value)
+			}
+		}
+	}
+
+	if format == "yaml" {
+		return encodeNode(&node, output)
+	}
+	var release interface{}
+	if err := node.Decode(&release); err != nil {
+		return unrecognizedOutput
+	}
+	data, err := encodeJSON(release)
+	if err != nil {
+		return unrecognizedOutput
+	}
+	return data
+}
+
+// hidesPath reports whether a field path of a manifest diff passes through
+// a key whose values are masked, e.g. "data.db-password"
+func (r *redactor) hidesPath(path string) bool {
+	for _, key := range strings.FieldsFunc(path, func(c rune) bool { return c == '.' || c == '[' || c == ']' }) {
+		if r.secConfig.IsRedactedKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSecretNode masks the data and stringData values of a Secret, or of
+// the Secrets in a List. It reports whether anything was masked.
+func maskSecretNode(node *yaml.Node) bool {
+	if node.Kind == yaml.DocumentNode {
+		masked := false
+		for _, child := range node.Content {
+			masked = maskSecretNode(child) || masked
+		}
+		return masked
+	}
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+
+	kind := mappingValue(node, "kind")
+	if kind == nil {
+		return false
+	}
+	if strings.HasSuffix(kind.Value, "List") {
+		masked := false
+		if items := mappingValue(node, "items"); items != nil {
+			for _, item := range items.Content {
+				masked = maskSecretNode(item) || masked
+			}
+		}
+		return masked
+	}
+	if kind.Value != "Secret" {
+		return false
+	}
+
+	masked := false
+	for _, field := range []string{"data", "stringData"} {
+		data := mappingValue(node, field)
+		if data == nil || data.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 1; i < len(data.Content); i += 2 {
+			maskScalar(data.Content[i])
+			masked = true
+		}
+	}
+	return masked
+}
+
+// mappingValue returns the value node of a key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// maskScalar replaces a value node with the redaction marker
+func maskScalar(node *yaml.Node) {
+	*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: redactedValue}
+}
+
+// encodeNode re-encodes a YAML node with two-space indentation. If encoding
+// fails, which only happens for nodes the decoder would not produce, the
+// original text is replaced by the redaction marker rather than leaked.
+func encodeNode(node *yaml.Node, original string) string {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return redactedValue + "\n"
+	}
+	if err := encoder.Close(); err != nil {
+		return redactedValue + "\n"
+	}
+	if strings.HasPrefix(original, "---") {
+		return "---\n" + buf.String()
+	}
+	return buf.String()
+}
+
+// encodeJSON encodes v as a line of JSON without escaping HTML characters,
+// so the redaction marker stays readable
+func encodeJSON(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// splitDocuments splits a YAML stream before each "---" separator line,
+// keeping the separators so the documents can be joined back unchanged
+func splitDocuments(manifest string) []string {
+	var docs []string
+	var doc strings.Builder
+	for _, line := range strings.SplitAfter(manifest, "\n") {
+		if strings.HasPrefix(line, "---") && strings.TrimSpace(strings.TrimPrefix(line, "---")) == "" && doc.Len() > 0 {
+			docs = append(docs, doc.String())
+			doc.Reset()
+		}
+		doc.WriteString(line)
+	}
+	if doc.Len() > 0 {
+		docs = append(docs, doc.String())
+	}
+	return docs
+}
+
+// helmPositionalArgs returns the positional arguments of a tokenized helm
+// command after "helm", skipping flags and the values of flags that take one
+func helmPositionalArgs(tokens []string) []string {
+	flagsTakingValues := map[string]bool{
+		"-n": true, "--namespace": true, "-o": true, "--output": true,
+		"--revision": true, "--version": true, "-f": true, "--values": true,
+		"--set": true, "--set-string": true, "--set-file": true, "--set-json": true,
+		"--timeout": true, "--max": true, "--template": true, "--repo": true,
+		"--kube-context": true, "--kubeconfig": true, "--registry-config": true,
+	}
+	var args []string
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if i == 0 && token == "helm" {
+			continue
+		}
+		if token == "--" {
+			return append(args, tokens[i+1:]...)
+		}
+		if strings.HasPrefix(token, "-") {
+			if !strings.Contains(token, "=") && flagsTakingValues[token] {
+				i++
+			}
+			continue
+		}
+		args = append(args, token)
+	}
+	return args
+}
+
+// helmOutputFormat returns the -o/--output value of a tokenized helm
+// command, or an empty string for the default table output. It accepts
+// "-o json", "-o=json", "-ojson", "--output json" and "--output=json"; as
+// in helm, the last occurrence wins.
+func helmOutputFormat(tokens []string) string {
+	format := ""
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "--" {
+			break
+		}
+		var value string
+		switch {
+		case token == "-o" || token == "--output":
+			if i+1 < len(tokens) {
+				value = tokens[i+1]
+				i++
+			}
+		case strings.HasPrefix(token, "--output="):
+			value = strings.TrimPrefix(token, "--output=")
+		case strings.HasPrefix(token, "-o") && !strings.HasPrefix(token, "--"):
+			value = strings.TrimPrefix(strings.TrimPrefix(token, "-o"), "=")
+		default:
+			continue
+		}
+		format = strings.ToLower(value)
+	}
+	return format
+}
+
+// hasFlag reports whether a tokenized command sets a flag, as "--flag",
+// "--flag value" or "--flag=value"
+func hasFlag(tokens []string, flag string) bool {
+	for _, token := range tokens {
+		if token == flag || strings.HasPrefix(token, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
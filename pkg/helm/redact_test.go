@@ -0,0 +1,174 @@
+package helm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// secretManifest is "helm get manifest" output with a Secret between two
+// other objects
+const secretManifest = `---
+# Source: web/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-auth
+type: Opaque
+data:
+  password: c3VwZXJzZWNyZXQ=
+stringData:
+  token: abc123
+---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  mode: fast
+`
+
+func TestRedactOutput(t *testing.T) {
+	r := newRedactor(security.NewSecurityConfig())
+
+	tests := []struct {
+		name       string
+		command    string
+		output     string
+		contains   []string
+		notContain []string
+	}{
+		{
+			name:       "values table",
+			command:    "helm get values web -n team-a --all",
+			output:     "COMPUTED VALUES:\nreplicaCount: 2\nauth:\n  rootPassword: hunter2\n  enabled: true\ndb:\n  secrets:\n    primary: s3cr3t\n    port: 5432\napiKey: \"\"\n",
+			contains:   []string{"COMPUTED VALUES:\nreplicaCount: 2\n", "rootPassword: <redacted>", "enabled: true", "primary: <redacted>", "port: <redacted>", `apiKey: ""`},
+			notContain: []string{"hunter2", "s3cr3t", "5432"},
+		},
+		{
+			name:       "values json",
+			command:    "helm get values web -n team-a -o json",
+			output:     `{"image":{"tag":"1.25"},"postgresql":{"auth":{"password":"hunter2"}},"tokens":["a","b"]}`,
+			contains:   []string{`"tag":"1.25"`, `"password":"<redacted>"`, `"tokens":["<redacted>","<redacted>"]`},
+			notContain: []string{"hunter2"},
+		},
+		{
+			name:       "manifest",
+			command:    "helm get manifest web -n team-a",
+			output:     secretManifest,
+			contains:   []string{"# Source: web/templates/secret.yaml", "password: <redacted>", "token: <redacted>", "type: Opaque", "---\n# Source: web/templates/configmap.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-config\ndata:\n  mode: fast\n"},
+			notContain: []string{"c3VwZXJzZWNyZXQ=", "abc123"},
+		},
+		{
+			name:       "get all",
+			command:    "helm get all web -n team-a",
+			output:     "NAME: web\nUSER-SUPPLIED VALUES:\nadminPassword: hunter2\n\nHOOKS:\nMANIFEST:\n" + secretManifest + "\nNOTES:\nThe password is in the web-auth secret\n",
+			contains:   []string{"NAME: web\n", "adminPassword: <redacted>", "password: <redacted>", "NOTES:\nThe password is in the web-auth secret\n"},
+			notContain: []string{"hunter2", "abc123"},
+		},
+		{
+			name:       "status json",
+			command:    "helm status web -n team-a -o json",
+			output:     `{"name":"web","config":{"dbPassword":"hunter2","replicas":2},"manifest":` + jsonString(secretManifest) + `,"hooks":[{"manifest":` + jsonString(secretManifest) + `}]}`,
+			contains:   []string{`"replicas":2`, `"dbPassword":"<redacted>"`},
+			notContain: []string{"hunter2", "c3VwZXJzZWNyZXQ=", "abc123"},
+		},
+		{
+			name:       "quoted subcommand",
+			command:    `helm "get" values web -n team-a`,
+			output:     "password: hunter2\n",
+			contains:   []string{"password: <redacted>"},
+			notContain: []string{"hunter2"},
+		},
+		{
+			name:       "quoted values",
+			command:    `helm get 'values' web -n team-a`,
+			output:     "password: hunter2\n",
+			contains:   []string{"password: <redacted>"},
+			notContain: []string{"hunter2"},
+		},
+		{
+			name:       "compact output flag",
+			command:    "helm status web -n team-a -ojson",
+			output:     `{"name":"web","config":{"password":"hunter2"}}`,
+			contains:   []string{`"password":"<redacted>"`},
+			notContain: []string{"hunter2"},
+		},
+		{
+			name:       "output flag with equals",
+			command:    "helm get values web -n team-a --output=json",
+			output:     `{"password":"hunter2"}`,
+			contains:   []string{`"password":"<redacted>"`},
+			notContain: []string{"hunter2"},
+		},
+		{
+			name:       "unknown output format",
+			command:    "helm status web -n team-a -o toml",
+			output:     "password = \"hunter2\"\n",
+			contains:   []string{"<redacted>"},
+			notContain: []string{"hunter2"},
+		},
+		{
+			name:       "unrecognized command",
+			command:    "helm --kube-apiserver https://k8s get values web",
+			output:     "password: hunter2\n",
+			contains:   []string{"<redacted>"},
+			notContain: []string{"hunter2"},
+		},
+		{
+			name:       "template flag",
+			command:    `helm get all web -n team-a --template "{{.Release.Config}}"`,
+			output:     "map[password:hunter2]",
+			notContain: []string{"hunter2"},
+		},
+		{
+			name:     "error text",
+			command:  "helm get values missing -n team-a",
+			output:   "Error: release: not found\n",
+			contains: []string{"Error: release: not found"},
+		},
+		{
+			name:     "unrelated command",
+			command:  "helm list -n team-a",
+			output:   "NAME\tNAMESPACE\nweb\tteam-a\n",
+			contains: []string{"NAME\tNAMESPACE\nweb\tteam-a\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.output(tt.command, tt.output)
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("Expected output to contain %q, got:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.notContain {
+				if strings.Contains(got, s) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactConfiguredKeys(t *testing.T) {
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetRedactKeys("connectionString")
+	r := newRedactor(secConfig)
+
+	got := r.output("helm get values web -n team-a -o yaml", "password: hunter2\nconnectionString: Server=db;Pwd=x\n")
+	if !strings.Contains(got, "password: hunter2") || strings.Contains(got, "Pwd=x") {
+		t.Errorf("Expected only the configured key to be masked, got:\n%s", got)
+	}
+	if !r.hidesPath("spec.connectionString") || r.hidesPath("spec.replicas") {
+		t.Errorf("Expected diff paths to be hidden by the configured key")
+	}
+}
+
+// jsonString quotes s as a JSON string
+func jsonString(s string) string {
+	return `"` + strings.NewReplacer(`"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
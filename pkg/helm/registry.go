@@ -23,3 +23,198 @@ func RegisterHelm() mcp.Tool {
 		}),
 	)
 }
+
+// RegisterHelmReleaseTools returns the structured helm release tools for the
+// access level. helm_releases and helm_upgrade_preview are always
+// registered; the tools that change releases are only registered at
+// readwrite and admin access levels.
+func RegisterHelmReleaseTools(accessLevel string) []mcp.Tool {
+	tools := []mcp.Tool{createReleasesTool(), createPreviewTool()}
+	if accessLevel == "readwrite" || accessLevel == "admin" {
+		tools = append(tools, createInstallUpgradeTool(), createRollbackTool(), createUninstallTool())
+	}
+	return tools
+}
+
+// createReleasesTool creates the read-only helm_releases tool
+func createReleasesTool() mcp.Tool {
+	return mcp.NewTool(toolReleases,
+		mcp.WithDescription(`Inspect Helm releases and return JSON results.
+
+Operations:
+- list: releases in a namespace (or all namespaces)
+- status: release status, revision, chart and notes
+- history: revision history of a release
+- values: user-supplied values of a release (all_values=true includes chart defaults)
+- manifest: rendered Kubernetes manifest of a release`),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Enum(operationList, operationStatus, operationHistory, operationValues, operationManifest),
+			mcp.Description("Operation to perform"),
+		),
+		mcp.WithString("release",
+			mcp.Description("Release name (required for all operations except list)"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the release (list requires namespace or all_namespaces)"),
+		),
+		mcp.WithBoolean("all_namespaces",
+			mcp.Description("List releases in all namespaces (default: false)"),
+		),
+		mcp.WithNumber("revision",
+			mcp.Description("Release revision for status, values and manifest (default: latest)"),
+		),
+		mcp.WithBoolean("all_values",
+			mcp.Description("Include computed chart default values for the values operation (default: false)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Helm Releases",
+			ReadOnlyHint:    boolPtr(true),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+			OpenWorldHint:   boolPtr(false),
+		}),
+	)
+}
+
+// createPreviewTool creates the read-only helm_upgrade_preview tool
+func createPreviewTool() mcp.Tool {
+	return mcp.NewTool(toolPreview,
+		mcp.WithDescription(`Preview what a helm upgrade would change in the cluster, without changing anything.
+
+Renders the chart with the proposed values (helm template) and compares it with the release's current manifest (helm get manifest).
+Returns added, removed and changed objects with the changed fields, and flags risky changes: immutable field changes that make the upgrade fail, deleted PersistentVolumeClaims or other data-holding objects, and CustomResourceDefinition changes.
+When no values are given, the release's current user-supplied values are reused, as helm upgrade does.`),
+		mcp.WithString("release",
+			mcp.Required(),
+			mcp.Description("Release name"),
+		),
+		mcp.WithString("chart",
+			mcp.Required(),
+			mcp.Description("Chart reference: repo/chart, a local chart path, a chart archive URL or an oci:// reference"),
+		),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the release"),
+		),
+		mcp.WithString("version",
+			mcp.Description("Chart version or version constraint (default: latest)"),
+		),
+		mcp.WithObject("values",
+			mcp.Description("Proposed values for the chart as an object (default: the release's current values)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Helm Upgrade Preview",
+			ReadOnlyHint:    boolPtr(true),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		}),
+	)
+}
+
+// createInstallUpgradeTool creates the helm_install_upgrade tool
+func createInstallUpgradeTool() mcp.Tool {
+	return mcp.NewTool(toolInstallUpgrade,
+		mcp.WithDescription("Install a Helm chart as a release, or upgrade the release if it already exists (helm upgrade --install). Returns the resulting release status as JSON."),
+		mcp.WithString("release",
+			mcp.Required(),
+			mcp.Description("Release name"),
+		),
+		mcp.WithString("chart",
+			mcp.Required(),
+			mcp.Description("Chart reference: repo/chart, a local chart path, a chart archive URL or an oci:// reference"),
+		),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the release"),
+		),
+		mcp.WithString("version",
+			mcp.Description("Chart version or version constraint (default: latest)"),
+		),
+		mcp.WithObject("values",
+			mcp.Description("Values for the chart as an object, equivalent to a values file"),
+		),
+		mcp.WithBoolean("atomic",
+			mcp.Description("Roll back the changes if the upgrade fails; implies wait (default: false)"),
+		),
+		mcp.WithBoolean("wait",
+			mcp.Description("Wait until resources are ready before returning (default: false)"),
+		),
+		mcp.WithBoolean("create_namespace",
+			mcp.Description("Create the namespace if it does not exist (default: false)"),
+		),
+		mcp.WithString("timeout",
+			mcp.Description("Time to wait for Kubernetes operations, e.g. 5m (default: helm's 5m0s)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Helm Install or Upgrade",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		}),
+	)
+}
+
+// createRollbackTool creates the helm_rollback tool
+func createRollbackTool() mcp.Tool {
+	return mcp.NewTool(toolRollback,
+		mcp.WithDescription("Roll a Helm release back to a previous revision. Returns the resulting release status as JSON."),
+		mcp.WithString("release",
+			mcp.Required(),
+			mcp.Description("Release name"),
+		),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the release"),
+		),
+		mcp.WithNumber("revision",
+			mcp.Description("Revision to roll back to (default: the previous revision)"),
+		),
+		mcp.WithBoolean("wait",
+			mcp.Description("Wait until resources are ready before returning (default: false)"),
+		),
+		mcp.WithString("timeout",
+			mcp.Description("Time to wait for Kubernetes operations, e.g. 5m (default: helm's 5m0s)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Helm Rollback",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		}),
+	)
+}
+
+// createUninstallTool creates the helm_uninstall tool
+func createUninstallTool() mcp.Tool {
+	return mcp.NewTool(toolUninstall,
+		mcp.WithDescription("Uninstall a Helm release and delete its Kubernetes resources."),
+		mcp.WithString("release",
+			mcp.Required(),
+			mcp.Description("Release name"),
+		),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the release"),
+		),
+		mcp.WithBoolean("keep_history",
+			mcp.Description("Keep the release history so the release can be rolled back (default: false)"),
+		),
+		mcp.WithBoolean("wait",
+			mcp.Description("Wait until all resources are deleted before returning (default: false)"),
+		),
+		mcp.WithString("timeout",
+			mcp.Description("Time to wait for Kubernetes operations, e.g. 5m (default: helm's 5m0s)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Helm Uninstall",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		}),
+	)
+}
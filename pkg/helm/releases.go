@@ -0,0 +1,334 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// Names of the structured helm tools served by ReleaseExecutor
+const (
+	toolReleases       = "helm_releases"
+	toolInstallUpgrade = "helm_install_upgrade"
+	toolRollback       = "helm_rollback"
+	toolUninstall      = "helm_uninstall"
+	toolPreview        = "helm_upgrade_preview"
+)
+
+// Operations of the helm_releases tool
+const (
+	operationList     = "list"
+	operationStatus   = "status"
+	operationHistory  = "history"
+	operationValues   = "values"
+	operationManifest = "manifest"
+)
+
+// maxReleaseNameLength is the longest release name helm accepts
+const maxReleaseNameLength = 53
+
+var (
+	releaseNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	chartPattern       = regexp.MustCompile(`^[A-Za-z0-9./@][A-Za-z0-9._/:@+~-]*$`)
+	versionPattern     = regexp.MustCompile(`^[A-Za-z0-9.^~<>=*+,_-]+$`)
+	timeoutPattern     = regexp.MustCompile(`^([0-9]+(ms|s|m|h))+$`)
+)
+
+// releaseCall is a validated call of one of the structured helm tools
+type releaseCall struct {
+	Tool            string
+	Operation       string
+	Release         string
+	Namespace       string
+	AllNamespaces   bool
+	Revision        int
+	AllValues       bool
+	Chart           string
+	Version         string
+	Values          map[string]interface{}
+	Atomic          bool
+	Wait            bool
+	CreateNamespace bool
+	KeepHistory     bool
+	Timeout         string
+}
+
+// parseReleaseCall reads and validates the parameters of a structured helm
+// tool call. The tool name is injected by the handler as _tool_name.
+func parseReleaseCall(params map[string]interface{}) (*releaseCall, error) {
+	c := &releaseCall{}
+	c.Tool, _ = params["_tool_name"].(string)
+	c.Operation, _ = params["operation"].(string)
+	c.Release, _ = params["release"].(string)
+	c.Namespace, _ = params["namespace"].(string)
+	c.AllNamespaces, _ = params["all_namespaces"].(bool)
+	c.AllValues, _ = params["all_values"].(bool)
+	c.Chart, _ = params["chart"].(string)
+	c.Version, _ = params["version"].(string)
+	c.Atomic, _ = params["atomic"].(bool)
+	c.Wait, _ = params["wait"].(bool)
+	c.CreateNamespace, _ = params["create_namespace"].(bool)
+	c.KeepHistory, _ = params["keep_history"].(bool)
+	c.Timeout, _ = params["timeout"].(string)
+	if revision, ok := params["revision"].(float64); ok {
+		if revision < 1 || revision != float64(int(revision)) {
+			return nil, fmt.Errorf("revision must be a positive integer")
+		}
+		c.Revision = int(revision)
+	}
+
+	switch values := params["values"].(type) {
+	case nil:
+	case map[string]interface{}:
+		c.Values = values
+	case string:
+		if strings.TrimSpace(values) != "" {
+			if err := json.Unmarshal([]byte(values), &c.Values); err != nil {
+				return nil, fmt.Errorf("values must be a JSON object: %v", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("values must be an object")
+	}
+
+	return c, c.validate()
+}
+
+// validate checks the call for the selected tool. Names are checked against
+// helm's own rules so no parameter can smuggle extra flags into the command.
+func (c *releaseCall) validate() error {
+	switch c.Tool {
+	case toolReleases:
+		switch c.Operation {
+		case operationList:
+			if c.Namespace == "" && !c.AllNamespaces {
+				return fmt.Errorf("list requires namespace or all_namespaces")
+			}
+			if c.Namespace != "" && c.AllNamespaces {
+				return fmt.Errorf("namespace and all_namespaces are mutually exclusive")
+			}
+		case operationStatus, operationHistory, operationValues, operationManifest:
+			if err := c.validateRelease(); err != nil {
+				return err
+			}
+		case "":
+			return fmt.Errorf("operation is required")
+		default:
+			return fmt.Errorf("unsupported operation '%s' (supported: list, status, history, values, manifest)", c.Operation)
+		}
+	case toolInstallUpgrade, toolPreview:
+		if err := c.validateRelease(); err != nil {
+			return err
+		}
+		if c.Chart == "" {
+			return fmt.Errorf("chart is required")
+		}
+		if !chartPattern.MatchString(c.Chart) {
+			return fmt.Errorf("invalid chart reference '%s'", c.Chart)
+		}
+		if c.Version != "" && !versionPattern.MatchString(c.Version) {
+			return fmt.Errorf("invalid chart version '%s'", c.Version)
+		}
+	case toolRollback, toolUninstall:
+		if err := c.validateRelease(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown helm tool '%s'", c.Tool)
+	}
+
+	if err := k8s.ValidateNamespace("namespace", c.Namespace); err != nil {
+		return err
+	}
+	if c.Timeout != "" && !timeoutPattern.MatchString(c.Timeout) {
+		return fmt.Errorf("invalid timeout '%s' (expected a duration such as 5m or 300s)", c.Timeout)
+	}
+	return nil
+}
+
+// validateRelease checks the release name and namespace of a call that
+// targets a single release
+func (c *releaseCall) validateRelease() error {
+	if c.Release == "" {
+		return fmt.Errorf("release is required")
+	}
+	if len(c.Release) > maxReleaseNameLength || !releaseNamePattern.MatchString(c.Release) {
+		return fmt.Errorf("invalid release name '%s'", c.Release)
+	}
+	if c.Namespace == "" {
+		return fmt.Errorf("namespace is required")
+	}
+	return nil
+}
+
+// command returns the helm command line for the call. valuesFile is the
+// path of the rendered values file for helm_install_upgrade and
+// helm_upgrade_preview, or empty.
+func (c *releaseCall) command(valuesFile string) string {
+	var args []string
+	switch c.Tool {
+	case toolReleases:
+		switch c.Operation {
+		case operationList:
+			args = []string{"helm", "list", "-o", "json"}
+			if c.AllNamespaces {
+				args = append(args, "-A")
+			} else {
+				args = append(args, "-n", c.Namespace)
+			}
+			return strings.Join(args, " ")
+		case operationStatus:
+			args = []string{"helm", "status", c.Release, "-n", c.Namespace, "-o", "json"}
+		case operationHistory:
+			args = []string{"helm", "history", c.Release, "-n", c.Namespace, "-o", "json"}
+		case operationValues:
+			args = []string{"helm", "get", "values", c.Release, "-n", c.Namespace, "-o", "json"}
+			if c.AllValues {
+				args = append(args, "--all")
+			}
+		case operationManifest:
+			args = []string{"helm", "get", "manifest", c.Release, "-n", c.Namespace}
+		}
+		if c.Revision > 0 && c.Operation != operationHistory {
+			args = append(args, "--revision", fmt.Sprint(c.Revision))
+		}
+	case toolInstallUpgrade:
+		args = []string{"helm", "upgrade", "--install", c.Release, c.Chart, "-n", c.Namespace}
+		if c.Version != "" {
+			args = append(args, "--version", c.Version)
+		}
+		if valuesFile != "" {
+			args = append(args, "-f", valuesFile)
+		}
+		if c.Atomic {
+			args = append(args, "--atomic")
+		}
+		if c.Wait {
+			args = append(args, "--wait")
+		}
+		if c.CreateNamespace {
+			args = append(args, "--create-namespace")
+		}
+		if c.Timeout != "" {
+			args = append(args, "--timeout", c.Timeout)
+		}
+		args = append(args, "-o", "json")
+	case toolPreview:
+		args = []string{"helm", "template", c.Release, c.Chart, "-n", c.Namespace}
+		if c.Version != "" {
+			args = append(args, "--version", c.Version)
+		}
+		if valuesFile != "" {
+			args = append(args, "-f", valuesFile)
+		}
+		args = append(args, "--is-upgrade", "--no-hooks", "--skip-tests")
+	case toolRollback:
+		args = []string{"helm", "rollback", c.Release}
+		if c.Revision > 0 {
+			args = append(args, fmt.Sprint(c.Revision))
+		}
+		args = append(args, "-n", c.Namespace)
+		if c.Wait {
+			args = append(args, "--wait")
+		}
+		if c.Timeout != "" {
+			args = append(args, "--timeout", c.Timeout)
+		}
+	case toolUninstall:
+		args = []string{"helm", "uninstall", c.Release, "-n", c.Namespace}
+		if c.KeepHistory {
+			args = append(args, "--keep-history")
+		}
+		if c.Wait {
+			args = append(args, "--wait")
+		}
+		if c.Timeout != "" {
+			args = append(args, "--timeout", c.Timeout)
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// ReleaseStatus is the summary of a release returned by status, install,
+// upgrade and rollback. Rendered manifests, hooks and chart templates are
+// left out; use the manifest operation for the rendered objects.
+type ReleaseStatus struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Revision      int    `json:"revision"`
+	Status        string `json:"status"`
+	Description   string `json:"description,omitempty"`
+	FirstDeployed string `json:"first_deployed,omitempty"`
+	LastDeployed  string `json:"last_deployed,omitempty"`
+	Chart         string `json:"chart,omitempty"`
+	ChartVersion  string `json:"chart_version,omitempty"`
+	AppVersion    string `json:"app_version,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+}
+
+// helmRelease is the subset of helm's JSON release object that is reported
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		FirstDeployed string `json:"first_deployed"`
+		LastDeployed  string `json:"last_deployed"`
+		Description   string `json:"description"`
+		Status        string `json:"status"`
+		Notes         string `json:"notes"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// parseReleaseStatus converts helm's JSON release object to a ReleaseStatus
+func parseReleaseStatus(output, command string) (*ReleaseStatus, error) {
+	var release helmRelease
+	if err := parseJSON(output, command, &release); err != nil {
+		return nil, err
+	}
+	return &ReleaseStatus{
+		Name:          release.Name,
+		Namespace:     release.Namespace,
+		Revision:      release.Version,
+		Status:        release.Info.Status,
+		Description:   release.Info.Description,
+		FirstDeployed: release.Info.FirstDeployed,
+		LastDeployed:  release.Info.LastDeployed,
+		Chart:         release.Chart.Metadata.Name,
+		ChartVersion:  release.Chart.Metadata.Version,
+		AppVersion:    release.Chart.Metadata.AppVersion,
+		Notes:         release.Info.Notes,
+	}, nil
+}
+
+// parseJSON decodes helm's JSON output. helm reports failures as text on
+// stderr, which is returned as the error.
+func parseJSON(output, command string, out interface{}) error {
+	if err := helmError(output); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(output), out); err != nil {
+		return fmt.Errorf("failed to parse output of '%s': %s", command, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// helmError returns helm's error text as an error, or nil when the output
+// is not an error report
+func helmError(output string) error {
+	trimmed := strings.TrimSpace(output)
+	if strings.HasPrefix(trimmed, "Error:") {
+		return fmt.Errorf("%s", trimmed)
+	}
+	return nil
+}
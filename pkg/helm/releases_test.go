@@ -0,0 +1,292 @@
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// sampleRelease is trimmed "helm status -o json" output
+const sampleRelease = `{"name":"web","namespace":"team-a","version":3,"info":{"first_deployed":"2024-05-01T10:00:00Z","last_deployed":"2024-05-03T10:00:00Z","description":"Upgrade complete","status":"deployed","notes":"Visit http://web"},"chart":{"metadata":{"name":"nginx","version":"15.1.0","appVersion":"1.25.3"},"templates":[]},"config":{"replicaCount":2},"manifest":"---\n# Source: nginx/templates/svc.yaml\n"}`
+
+func TestReleaseCallCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "list namespace",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "list", "namespace": "team-a"},
+			expected: "helm list -o json -n team-a",
+		},
+		{
+			name:     "list all namespaces",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "list", "all_namespaces": true},
+			expected: "helm list -o json -A",
+		},
+		{
+			name:     "values at revision",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "values", "release": "web", "namespace": "team-a", "all_values": true, "revision": float64(2)},
+			expected: "helm get values web -n team-a -o json --all --revision 2",
+		},
+		{
+			name:     "manifest",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "manifest", "release": "web", "namespace": "team-a"},
+			expected: "helm get manifest web -n team-a",
+		},
+		{
+			name:     "install upgrade",
+			params:   map[string]interface{}{"_tool_name": toolInstallUpgrade, "release": "web", "chart": "bitnami/nginx", "namespace": "team-a", "version": "15.1.0", "atomic": true, "timeout": "10m"},
+			expected: "helm upgrade --install web bitnami/nginx -n team-a --version 15.1.0 --atomic --timeout 10m -o json",
+		},
+		{
+			name:     "preview",
+			params:   map[string]interface{}{"_tool_name": toolPreview, "release": "web", "chart": "./charts/web", "namespace": "team-a", "version": "^2.0.0"},
+			expected: "helm template web ./charts/web -n team-a --version ^2.0.0 --is-upgrade --no-hooks --skip-tests",
+		},
+		{
+			name:     "rollback",
+			params:   map[string]interface{}{"_tool_name": toolRollback, "release": "web", "namespace": "team-a", "revision": float64(2), "wait": true},
+			expected: "helm rollback web 2 -n team-a --wait",
+		},
+		{
+			name:     "uninstall",
+			params:   map[string]interface{}{"_tool_name": toolUninstall, "release": "web", "namespace": "team-a", "keep_history": true},
+			expected: "helm uninstall web -n team-a --keep-history",
+		},
+	}
+
+	secConfig := security.NewSecurityConfig()
+	secConfig.AccessLevel = security.AccessLevelReadWrite
+	validator := security.NewValidator(secConfig)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call, err := parseReleaseCall(tt.params)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			got := call.command("")
+			if got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+			if err := validator.ValidateCommand(got, security.CommandTypeHelm); err != nil {
+				t.Errorf("Expected command to pass readwrite validation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseReleaseCallErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		err    string
+	}{
+		{"missing operation", map[string]interface{}{"_tool_name": toolReleases}, "operation is required"},
+		{"list without namespace", map[string]interface{}{"_tool_name": toolReleases, "operation": "list"}, "namespace or all_namespaces"},
+		{"status without release", map[string]interface{}{"_tool_name": toolReleases, "operation": "status", "namespace": "team-a"}, "release is required"},
+		{"injected release", map[string]interface{}{"_tool_name": toolUninstall, "release": "web --no-hooks", "namespace": "team-a"}, "invalid release name"},
+		{"injected chart", map[string]interface{}{"_tool_name": toolInstallUpgrade, "release": "web", "chart": "--post-renderer=/bin/sh", "namespace": "team-a"}, "invalid chart"},
+		{"bad revision", map[string]interface{}{"_tool_name": toolRollback, "release": "web", "namespace": "team-a", "revision": float64(1.5)}, "revision"},
+		{"bad timeout", map[string]interface{}{"_tool_name": toolRollback, "release": "web", "namespace": "team-a", "timeout": "soon"}, "invalid timeout"},
+		{"bad values", map[string]interface{}{"_tool_name": toolInstallUpgrade, "release": "web", "chart": "bitnami/nginx", "namespace": "team-a", "values": "[1]"}, "values must be a JSON object"},
+		{"unknown tool", map[string]interface{}{}, "unknown helm tool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseReleaseCall(tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing '%s', got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestReleaseExecutor(t *testing.T) {
+	var commands []string
+	var valuesFile string
+	outputs := map[string]string{
+		"helm list -o json -n team-a":                        `[{"name":"web","namespace":"team-a","revision":"3","status":"deployed","chart":"nginx-15.1.0","app_version":"1.25.3"}]`,
+		"helm list -o json -n empty":                         "[]\n",
+		"helm status web -n team-a -o json":                  sampleRelease,
+		"helm status missing -n team-a -o json":              "Error: release: not found\n",
+		"helm get values web -n team-a -o json":              "null\n",
+		"helm get values db -n team-a -o json":               `{"auth":{"password":"hunter2"},"replicas":1}`,
+		"helm rollback web 2 -n team-a":                      "Rollback was a success! Happy Helming!\n",
+		"helm uninstall web -n team-a":                       "release \"web\" uninstalled\n",
+		"helm upgrade --install web bitnami/nginx -n team-a": sampleRelease,
+	}
+	executor := &ReleaseExecutor{run: func(ctx context.Context, command string, cfg *config.ConfigData) (string, error) {
+		commands = append(commands, command)
+		if strings.Contains(command, " -f ") {
+			fields := strings.Fields(command)
+			for i, field := range fields {
+				if field == "-f" {
+					valuesFile = fields[i+1]
+					data, err := os.ReadFile(valuesFile)
+					if err != nil {
+						t.Errorf("Expected values file to exist while helm runs, got %v", err)
+					}
+					if string(data) != `{"replicaCount":2}` {
+						t.Errorf("Expected values file to hold the values object, got %s", data)
+					}
+				}
+			}
+		}
+		for prefix, output := range outputs {
+			if strings.HasPrefix(command, prefix) {
+				return output, nil
+			}
+		}
+		t.Fatalf("Unexpected command %s", command)
+		return "", nil
+	}}
+	cfg := config.NewConfig()
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		expected string
+		err      string
+	}{
+		{
+			name:     "list",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "list", "namespace": "team-a"},
+			expected: `{"releases":[{"app_version":"1.25.3","chart":"nginx-15.1.0","name":"web","namespace":"team-a","revision":"3","status":"deployed"}]}`,
+		},
+		{
+			name:     "empty list",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "list", "namespace": "empty"},
+			expected: `{"releases":[]}`,
+		},
+		{
+			name:     "status",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "status", "release": "web", "namespace": "team-a"},
+			expected: `{"name":"web","namespace":"team-a","revision":3,"status":"deployed","description":"Upgrade complete","first_deployed":"2024-05-01T10:00:00Z","last_deployed":"2024-05-03T10:00:00Z","chart":"nginx","chart_version":"15.1.0","app_version":"1.25.3","notes":"Visit http://web"}`,
+		},
+		{
+			name:   "status error",
+			params: map[string]interface{}{"_tool_name": toolReleases, "operation": "status", "release": "missing", "namespace": "team-a"},
+			err:    "release: not found",
+		},
+		{
+			name:     "no user values",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "values", "release": "web", "namespace": "team-a"},
+			expected: `{"namespace":"team-a","release":"web","values":{}}`,
+		},
+		{
+			name:     "masked values",
+			params:   map[string]interface{}{"_tool_name": toolReleases, "operation": "values", "release": "db", "namespace": "team-a"},
+			expected: `"values":{"auth":{"password":"\u003credacted\u003e"},"replicas":1}`,
+		},
+		{
+			name:     "install with values",
+			params:   map[string]interface{}{"_tool_name": toolInstallUpgrade, "release": "web", "chart": "bitnami/nginx", "namespace": "team-a", "values": map[string]interface{}{"replicaCount": float64(2)}},
+			expected: `"revision":3`,
+		},
+		{
+			name:     "rollback returns status",
+			params:   map[string]interface{}{"_tool_name": toolRollback, "release": "web", "namespace": "team-a", "revision": float64(2)},
+			expected: `"status":"deployed"`,
+		},
+		{
+			name:     "uninstall",
+			params:   map[string]interface{}{"_tool_name": toolUninstall, "release": "web", "namespace": "team-a"},
+			expected: `{"message":"release \"web\" uninstalled","namespace":"team-a","release":"web","uninstalled":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := executor.Execute(context.Background(), tt.params, cfg)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing '%s', got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !json.Valid([]byte(result)) || !strings.Contains(result, tt.expected) {
+				t.Errorf("Expected result containing %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	if valuesFile == "" {
+		t.Fatalf("Expected install to pass a values file, got commands %v", commands)
+	}
+	if _, err := os.Stat(valuesFile); !os.IsNotExist(err) {
+		t.Errorf("Expected values file to be removed, got %v", err)
+	}
+	if last := commands[len(commands)-1]; last != "helm uninstall web -n team-a" {
+		t.Errorf("Expected uninstall to run last, got %s", last)
+	}
+}
+
+func TestReleaseExecutorAccessLevel(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.SecurityConfig = security.NewSecurityConfig()
+	cfg.SecurityConfig.AccessLevel = security.AccessLevelReadOnly
+
+	params := map[string]interface{}{"_tool_name": toolUninstall, "release": "web", "namespace": "team-a"}
+	_, err := NewReleaseExecutor().Execute(context.Background(), params, cfg)
+	if err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected uninstall to be rejected in read-only mode, got %v", err)
+	}
+}
+
+func TestReleaseExecutorCacheFormat(t *testing.T) {
+	// helm_releases runs the same command as this call_helm call, but returns
+	// a JSON report, so the two must not share a response cache entry
+	releaseParams := map[string]interface{}{"_tool_name": toolReleases, "operation": "list", "namespace": "team-a"}
+	_, releaseCmd, ok := NewReleaseExecutor().DescribeCommand(releaseParams)
+	if !ok {
+		t.Fatalf("Expected helm_releases to describe its command")
+	}
+	_, helmCmd, _ := NewExecutor().DescribeCommand(map[string]interface{}{"command": releaseCmd})
+	if helmCmd != releaseCmd {
+		t.Fatalf("Expected call_helm to describe %q, got %q", releaseCmd, helmCmd)
+	}
+
+	if _, ok := interface{}(NewExecutor()).(tools.OutputFormatDescriber); ok {
+		t.Fatalf("Expected call_helm to have no output format")
+	}
+	if got := NewReleaseExecutor().DescribeOutputFormat(releaseParams); got == "" {
+		t.Errorf("Expected helm_releases to describe a non-empty output format")
+	}
+}
+
+func TestRegisterHelmReleaseTools(t *testing.T) {
+	tests := map[string][]string{
+		"readonly":  {toolReleases, toolPreview},
+		"readwrite": {toolReleases, toolPreview, toolInstallUpgrade, toolRollback, toolUninstall},
+		"admin":     {toolReleases, toolPreview, toolInstallUpgrade, toolRollback, toolUninstall},
+	}
+
+	for accessLevel, expected := range tests {
+		tools := RegisterHelmReleaseTools(accessLevel)
+		if len(tools) != len(expected) {
+			t.Fatalf("Expected %d tools for %s, got %d", len(expected), accessLevel, len(tools))
+		}
+		for i, tool := range tools {
+			if tool.Name != expected[i] {
+				t.Errorf("Expected tool %s, got %s", expected[i], tool.Name)
+			}
+			readOnly := tool.Name == toolReleases || tool.Name == toolPreview
+			if *tool.Annotations.ReadOnlyHint != readOnly || *tool.Annotations.DestructiveHint == readOnly {
+				t.Errorf("Expected %s to be annotated read-only=%v, got %+v", tool.Name, readOnly, tool.Annotations)
+			}
+		}
+	}
+}
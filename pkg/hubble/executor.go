@@ -2,6 +2,7 @@ package hubble
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/Azure/mcp-kubernetes/pkg/command"
@@ -15,6 +16,7 @@ type HubbleExecutor struct{}
 
 // This line ensures HubbleExecutor implements the CommandExecutor interface
 var _ tools.CommandExecutor = (*HubbleExecutor)(nil)
+var _ tools.CommandDescriber = (*HubbleExecutor)(nil)
 
 // NewExecutor creates a new HubbleExecutor instance
 func NewExecutor() *HubbleExecutor {
@@ -37,5 +39,89 @@ func (e *HubbleExecutor) Execute(ctx context.Context, params map[string]interfac
 
 	// Execute the command
 	process := command.NewShellProcess("hubble", cfg.Timeout)
+	process.Limiter = cfg.ProcessLimiter
 	return process.Run(hubbleCmd)
 }
+
+// DescribeCommand returns the hubble command a call would run
+func (e *HubbleExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	hubbleCmd, ok := params["command"].(string)
+	return security.CommandTypeHubble, hubbleCmd, ok
+}
+
+// FlowsExecutor implements the CommandExecutor interface for hubble_flows
+type FlowsExecutor struct {
+	// run executes a hubble command through the hubble executor
+	run func(ctx context.Context, command string, cfg *config.ConfigData) (string, error)
+}
+
+// This line ensures FlowsExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*FlowsExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*FlowsExecutor)(nil)
+
+// NewFlowsExecutor creates a new FlowsExecutor instance
+func NewFlowsExecutor() *FlowsExecutor {
+	hubble := NewExecutor()
+	return &FlowsExecutor{
+		run: func(ctx context.Context, command string, cfg *config.ConfigData) (string, error) {
+			return hubble.Execute(ctx, map[string]interface{}{"command": command}, cfg)
+		},
+	}
+}
+
+// Execute runs hubble observe with the typed filters and returns aggregated flows as JSON
+func (e *FlowsExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	query := FlowQuery{}
+	query.Namespace, _ = params["namespace"].(string)
+	query.SourceNamespace, _ = params["source_namespace"].(string)
+	query.SourcePod, _ = params["source_pod"].(string)
+	query.DestinationNamespace, _ = params["destination_namespace"].(string)
+	query.DestinationPod, _ = params["destination_pod"].(string)
+	query.Verdict, _ = params["verdict"].(string)
+	query.Protocol, _ = params["protocol"].(string)
+	query.Since, _ = params["since"].(string)
+	if port, ok := params["port"].(float64); ok {
+		query.Port = int(port)
+	}
+	if maxFlows, ok := params["max_flows"].(float64); ok {
+		query.MaxFlows = int(maxFlows)
+	}
+	if err := query.Validate(); err != nil {
+		return "", err
+	}
+
+	// Flows are only observable for namespaces on the allow-list
+	var hidden redactor
+	if cfg.SecurityConfig.HasNamespaceRestrictions() {
+		namespaces := query.Namespaces()
+		if len(namespaces) == 0 {
+			return "", fmt.Errorf("a namespace filter (namespace, source_namespace or destination_namespace) is required when --allow-namespaces is configured")
+		}
+		for _, ns := range namespaces {
+			if !cfg.SecurityConfig.IsNamespaceAllowed(ns) {
+				return "", fmt.Errorf("access to namespace '%s' is denied by security configuration", ns)
+			}
+		}
+		hidden = func(namespace string) bool { return !cfg.SecurityConfig.IsNamespaceAllowed(namespace) }
+	}
+
+	command := query.Command()
+	output, err := e.run(ctx, command, cfg)
+	if err != nil {
+		return "", err
+	}
+	report, err := aggregateFlows(output, command, hidden)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that hubble_flows always returns a JSON report
+func (e *FlowsExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
@@ -0,0 +1,440 @@
+package hubble
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+const (
+	// defaultMaxFlows is the number of recent flows fetched when max_flows is not set
+	defaultMaxFlows = 1000
+	// maxMaxFlows caps max_flows
+	maxMaxFlows = 10000
+	// maxTopEntries caps the top dropped pairs, drop reasons and policies in a report
+	maxTopEntries = 10
+	// redactedName replaces pod names in namespaces outside the allow-list
+	redactedName = "<redacted>"
+)
+
+var (
+	// flowVerdicts are the verdicts accepted by the verdict parameter
+	flowVerdicts = map[string]bool{"DROPPED": true, "FORWARDED": true, "ERROR": true, "AUDIT": true}
+	// flowProtocols are the protocols accepted by the protocol parameter
+	flowProtocols = map[string]bool{"tcp": true, "udp": true, "icmp": true, "sctp": true, "http": true, "dns": true, "kafka": true}
+)
+
+// FlowQuery holds the typed filters of a hubble_flows call
+type FlowQuery struct {
+	// Namespace matches flows from or to the namespace
+	Namespace            string
+	SourceNamespace      string
+	SourcePod            string
+	DestinationNamespace string
+	DestinationPod       string
+	Verdict              string
+	Protocol             string
+	// Port is the destination port
+	Port int
+	// Since is a duration such as "5m"
+	Since    string
+	MaxFlows int
+}
+
+// Validate checks the query and applies defaults
+func (q *FlowQuery) Validate() error {
+	if err := errors.Join(
+		k8s.ValidateNamespace("namespace", q.Namespace),
+		k8s.ValidateNamespace("source_namespace", q.SourceNamespace),
+		k8s.ValidateName("source_pod", q.SourcePod),
+		k8s.ValidateNamespace("destination_namespace", q.DestinationNamespace),
+		k8s.ValidateName("destination_pod", q.DestinationPod),
+	); err != nil {
+		return err
+	}
+	if q.SourcePod != "" && q.SourceNamespace == "" && q.Namespace == "" {
+		return fmt.Errorf("source_pod requires source_namespace or namespace")
+	}
+	if q.DestinationPod != "" && q.DestinationNamespace == "" && q.Namespace == "" {
+		return fmt.Errorf("destination_pod requires destination_namespace or namespace")
+	}
+	q.Verdict = strings.ToUpper(q.Verdict)
+	if q.Verdict != "" && !flowVerdicts[q.Verdict] {
+		return fmt.Errorf("unsupported verdict '%s'", q.Verdict)
+	}
+	q.Protocol = strings.ToLower(q.Protocol)
+	if q.Protocol != "" && !flowProtocols[q.Protocol] {
+		return fmt.Errorf("unsupported protocol '%s'", q.Protocol)
+	}
+	if q.Port < 0 || q.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if q.Since != "" {
+		if d, err := time.ParseDuration(q.Since); err != nil || d <= 0 {
+			return fmt.Errorf("invalid since '%s': expected a duration such as 5m or 1h", q.Since)
+		}
+	}
+	switch {
+	case q.MaxFlows == 0:
+		q.MaxFlows = defaultMaxFlows
+	case q.MaxFlows < 0 || q.MaxFlows > maxMaxFlows:
+		return fmt.Errorf("max_flows must be between 1 and %d", maxMaxFlows)
+	}
+	return nil
+}
+
+// Namespaces returns the namespaces the query is filtered on
+func (q FlowQuery) Namespaces() []string {
+	var namespaces []string
+	for _, ns := range []string{q.Namespace, q.SourceNamespace, q.DestinationNamespace} {
+		if ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// Command builds the hubble observe command for the query
+func (q FlowQuery) Command() string {
+	args := []string{"hubble", "observe", "-o", "json", "--last", strconv.Itoa(q.MaxFlows)}
+	if q.Since != "" {
+		args = append(args, "--since", q.Since)
+	}
+	if q.Namespace != "" {
+		args = append(args, "--namespace", q.Namespace)
+	}
+	if q.SourcePod != "" {
+		args = append(args, "--from-pod", firstNonEmpty(q.SourceNamespace, q.Namespace)+"/"+q.SourcePod)
+	} else if q.SourceNamespace != "" {
+		args = append(args, "--from-namespace", q.SourceNamespace)
+	}
+	if q.DestinationPod != "" {
+		args = append(args, "--to-pod", firstNonEmpty(q.DestinationNamespace, q.Namespace)+"/"+q.DestinationPod)
+	} else if q.DestinationNamespace != "" {
+		args = append(args, "--to-namespace", q.DestinationNamespace)
+	}
+	if q.Verdict != "" {
+		args = append(args, "--verdict", q.Verdict)
+	}
+	if q.Protocol != "" {
+		args = append(args, "--protocol", q.Protocol)
+	}
+	if q.Port > 0 {
+		args = append(args, "--to-port", strconv.Itoa(q.Port))
+	}
+	return strings.Join(args, " ")
+}
+
+// flow is the subset of a Hubble flow used for aggregation. Hubble prints
+// flows with protobuf field names.
+type flow struct {
+	Verdict        string        `json:"verdict"`
+	DropReasonDesc string        `json:"drop_reason_desc"`
+	Source         *flowEndpoint `json:"source"`
+	Destination    *flowEndpoint `json:"destination"`
+	IP             *struct {
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+	} `json:"IP"`
+	L4 *struct {
+		TCP  *flowPorts `json:"TCP"`
+		UDP  *flowPorts `json:"UDP"`
+		SCTP *flowPorts `json:"SCTP"`
+	} `json:"l4"`
+	EgressDeniedBy  []flowPolicy `json:"egress_denied_by"`
+	IngressDeniedBy []flowPolicy `json:"ingress_denied_by"`
+}
+
+// flowEndpoint is the source or destination of a flow
+type flowEndpoint struct {
+	Namespace string   `json:"namespace"`
+	PodName   string   `json:"pod_name"`
+	Labels    []string `json:"labels"`
+	Workloads []struct {
+		Name string `json:"name"`
+		Kind string `json:"kind"`
+	} `json:"workloads"`
+}
+
+// flowPorts holds L4 ports
+type flowPorts struct {
+	DestinationPort int `json:"destination_port"`
+}
+
+// flowPolicy is a policy that allowed or denied a flow
+type flowPolicy struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+}
+
+// FlowReport is the aggregated result of hubble_flows
+type FlowReport struct {
+	Command         string         `json:"command"`
+	TotalFlows      int            `json:"totalFlows"`
+	ByVerdict       map[string]int `json:"byVerdict"`
+	TopDroppedPairs []DroppedPair  `json:"topDroppedPairs"`
+	DropReasons     []CountedItem  `json:"dropReasons"`
+	Policies        []PolicyCount  `json:"policies"`
+	UnparsedLines   int            `json:"unparsedLines,omitempty"`
+	Notes           []string       `json:"notes,omitempty"`
+}
+
+// DroppedPair counts dropped flows between two endpoints on a port
+type DroppedPair struct {
+	Source      string   `json:"source"`
+	Destination string   `json:"destination"`
+	Port        string   `json:"port,omitempty"`
+	Count       int      `json:"count"`
+	DropReasons []string `json:"dropReasons,omitempty"`
+	Policies    []string `json:"policies,omitempty"`
+}
+
+// CountedItem is a value with its number of occurrences
+type CountedItem struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// PolicyCount counts the flows a policy denied
+type PolicyCount struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Direction string `json:"direction"`
+	Count     int    `json:"count"`
+}
+
+// redactor hides pod and workload names of endpoints in namespaces the
+// caller may not access
+type redactor func(namespace string) bool
+
+// aggregateFlows parses `hubble observe -o json` output, one JSON object per
+// line, and aggregates drops by endpoint pair, reason and policy
+func aggregateFlows(output, command string, hidden redactor) (*FlowReport, error) {
+	report := &FlowReport{Command: command, ByVerdict: map[string]int{}, TopDroppedPairs: []DroppedPair{}, DropReasons: []CountedItem{}, Policies: []PolicyCount{}}
+	pairs := map[string]*DroppedPair{}
+	reasons := map[string]int{}
+	policies := map[string]*PolicyCount{}
+	var firstUnparsed string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		f, ok := parseFlowLine(line)
+		if !ok {
+			report.UnparsedLines++
+			if firstUnparsed == "" {
+				firstUnparsed = line
+			}
+			continue
+		}
+		report.TotalFlows++
+		report.ByVerdict[f.Verdict]++
+		if f.Verdict != "DROPPED" {
+			continue
+		}
+
+		reason := firstNonEmpty(f.DropReasonDesc, "UNKNOWN")
+		reasons[reason]++
+
+		src := endpointName(f.Source, sourceIP(f), hidden)
+		dst := endpointName(f.Destination, destinationIP(f), hidden)
+		port := flowPort(f)
+		key := src + "|" + dst + "|" + port
+		pair := pairs[key]
+		if pair == nil {
+			pair = &DroppedPair{Source: src, Destination: dst, Port: port}
+			pairs[key] = pair
+		}
+		pair.Count++
+		pair.DropReasons = appendUnique(pair.DropReasons, reason)
+
+		for _, denied := range []struct {
+			direction string
+			policies  []flowPolicy
+		}{{"egress", f.EgressDeniedBy}, {"ingress", f.IngressDeniedBy}} {
+			for _, p := range denied.policies {
+				name := p.Name
+				if p.Namespace != "" {
+					name = p.Namespace + "/" + p.Name
+				}
+				pair.Policies = appendUnique(pair.Policies, name)
+				policyKey := denied.direction + "|" + name
+				if policies[policyKey] == nil {
+					policies[policyKey] = &PolicyCount{Name: p.Name, Namespace: p.Namespace, Kind: p.Kind, Direction: denied.direction}
+				}
+				policies[policyKey].Count++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hubble output: %w", err)
+	}
+	if report.TotalFlows == 0 && firstUnparsed != "" {
+		// hubble reports failures (e.g. no relay connection) as plain text
+		return nil, fmt.Errorf("hubble observe failed: %s", firstUnparsed)
+	}
+
+	for _, pair := range pairs {
+		report.TopDroppedPairs = append(report.TopDroppedPairs, *pair)
+	}
+	sort.Slice(report.TopDroppedPairs, func(i, j int) bool {
+		a, b := report.TopDroppedPairs[i], report.TopDroppedPairs[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Source+a.Destination+a.Port < b.Source+b.Destination+b.Port
+	})
+	if len(report.TopDroppedPairs) > maxTopEntries {
+		report.Notes = append(report.Notes, fmt.Sprintf("Showing the top %d of %d dropped pairs", maxTopEntries, len(report.TopDroppedPairs)))
+		report.TopDroppedPairs = report.TopDroppedPairs[:maxTopEntries]
+	}
+
+	report.DropReasons = topCounts(reasons)
+	for _, p := range policies {
+		report.Policies = append(report.Policies, *p)
+	}
+	sort.Slice(report.Policies, func(i, j int) bool {
+		a, b := report.Policies[i], report.Policies[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Namespace+a.Name+a.Direction < b.Namespace+b.Name+b.Direction
+	})
+	if len(report.Policies) > maxTopEntries {
+		report.Policies = report.Policies[:maxTopEntries]
+	}
+	if len(reasons) > 0 && len(policies) == 0 && reasons["POLICY_DENIED"] > 0 {
+		report.Notes = append(report.Notes, "Policy drops carry no policy names; older Cilium versions do not report the denying policy")
+	}
+	return report, nil
+}
+
+// parseFlowLine parses one line of output. Newer hubble versions wrap the
+// flow in {"flow": ...}; older ones print the flow itself.
+func parseFlowLine(line string) (flow, bool) {
+	var wrapped struct {
+		Flow *flow `json:"flow"`
+	}
+	if err := json.Unmarshal([]byte(line), &wrapped); err != nil {
+		return flow{}, false
+	}
+	if wrapped.Flow != nil {
+		return *wrapped.Flow, true
+	}
+	var f flow
+	if err := json.Unmarshal([]byte(line), &f); err != nil || f.Verdict == "" {
+		return flow{}, false
+	}
+	return f, true
+}
+
+// endpointName names a flow endpoint by namespace and workload (or pod),
+// reserved identity label, or IP address
+func endpointName(e *flowEndpoint, ip string, hidden redactor) string {
+	if e == nil {
+		return firstNonEmpty(ip, "unknown")
+	}
+	if e.Namespace != "" {
+		if hidden != nil && hidden(e.Namespace) {
+			return e.Namespace + "/" + redactedName
+		}
+		name := e.PodName
+		if len(e.Workloads) > 0 {
+			name = e.Workloads[0].Name
+		}
+		return e.Namespace + "/" + firstNonEmpty(name, ip)
+	}
+	for _, label := range e.Labels {
+		if strings.HasPrefix(label, "reserved:") {
+			name := strings.TrimPrefix(label, "reserved:")
+			if ip != "" && name == "world" {
+				return "world (" + ip + ")"
+			}
+			return name
+		}
+	}
+	return firstNonEmpty(ip, "unknown")
+}
+
+// sourceIP returns the flow's source IP address
+func sourceIP(f flow) string {
+	if f.IP == nil {
+		return ""
+	}
+	return f.IP.Source
+}
+
+// destinationIP returns the flow's destination IP address
+func destinationIP(f flow) string {
+	if f.IP == nil {
+		return ""
+	}
+	return f.IP.Destination
+}
+
+// flowPort renders the destination port and protocol, e.g. "80/TCP"
+func flowPort(f flow) string {
+	if f.L4 == nil {
+		return ""
+	}
+	switch {
+	case f.L4.TCP != nil:
+		return fmt.Sprintf("%d/TCP", f.L4.TCP.DestinationPort)
+	case f.L4.UDP != nil:
+		return fmt.Sprintf("%d/UDP", f.L4.UDP.DestinationPort)
+	case f.L4.SCTP != nil:
+		return fmt.Sprintf("%d/SCTP", f.L4.SCTP.DestinationPort)
+	}
+	return ""
+}
+
+// topCounts sorts counts by frequency and keeps the most frequent
+func topCounts(counts map[string]int) []CountedItem {
+	items := make([]CountedItem, 0, len(counts))
+	for value, count := range counts {
+		items = append(items, CountedItem{Value: value, Count: count})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Value < items[j].Value
+	})
+	if len(items) > maxTopEntries {
+		items = items[:maxTopEntries]
+	}
+	return items
+}
+
+// appendUnique appends v unless values already contains it
+func appendUnique(values []string, v string) []string {
+	for _, value := range values {
+		if value == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
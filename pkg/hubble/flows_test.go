@@ -0,0 +1,168 @@
+package hubble
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// sampleFlows is "hubble observe -o json" output: two policy drops from the
+// worker deployment to kube-dns, one forwarded flow and one old-style flow
+const sampleFlows = `{"flow":{"verdict":"DROPPED","drop_reason_desc":"POLICY_DENIED","IP":{"source":"10.244.1.10","destination":"10.244.0.5"},"l4":{"UDP":{"source_port":41234,"destination_port":53}},"source":{"namespace":"team-a","pod_name":"worker-1","workloads":[{"name":"worker","kind":"Deployment"}]},"destination":{"namespace":"kube-system","pod_name":"coredns-1","workloads":[{"name":"coredns","kind":"Deployment"}]},"egress_denied_by":[{"name":"default-deny-all","namespace":"team-a","kind":"NetworkPolicy"}]},"node_name":"node-1"}
+{"flow":{"verdict":"DROPPED","drop_reason_desc":"POLICY_DENIED","IP":{"source":"10.244.1.11","destination":"10.244.0.5"},"l4":{"UDP":{"source_port":41235,"destination_port":53}},"source":{"namespace":"team-a","pod_name":"worker-2","workloads":[{"name":"worker","kind":"Deployment"}]},"destination":{"namespace":"kube-system","pod_name":"coredns-1","workloads":[{"name":"coredns","kind":"Deployment"}]},"egress_denied_by":[{"name":"default-deny-all","namespace":"team-a","kind":"NetworkPolicy"}]},"node_name":"node-1"}
+{"flow":{"verdict":"FORWARDED","IP":{"source":"10.244.1.10","destination":"10.244.2.20"},"l4":{"TCP":{"destination_port":80}},"source":{"namespace":"team-a","pod_name":"worker-1"},"destination":{"namespace":"team-b","pod_name":"api-0"}}}
+{"verdict":"DROPPED","drop_reason_desc":"CT_MAP_INSERTION_FAILED","IP":{"source":"10.244.1.10","destination":"93.184.216.34"},"l4":{"TCP":{"destination_port":443}},"source":{"namespace":"team-a","pod_name":"worker-1"},"destination":{"labels":["reserved:world"]}}
+`
+
+func TestFlowQueryCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    FlowQuery
+		expected string
+	}{
+		{
+			name:     "defaults",
+			query:    FlowQuery{},
+			expected: "hubble observe -o json --last 1000",
+		},
+		{
+			name:     "pods and filters",
+			query:    FlowQuery{SourceNamespace: "team-a", SourcePod: "worker-1", DestinationNamespace: "kube-system", Verdict: "dropped", Protocol: "UDP", Port: 53, Since: "5m", MaxFlows: 200},
+			expected: "hubble observe -o json --last 200 --since 5m --from-pod team-a/worker-1 --to-namespace kube-system --verdict DROPPED --protocol udp --to-port 53",
+		},
+		{
+			name:     "either side namespace",
+			query:    FlowQuery{Namespace: "team-a", DestinationPod: "api-0"},
+			expected: "hubble observe -o json --last 1000 --namespace team-a --to-pod team-a/api-0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query.Validate(); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := tt.query.Command(); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+			validator := security.NewValidator(security.NewSecurityConfig())
+			if err := validator.ValidateCommand(tt.query.Command(), security.CommandTypeHubble); err != nil {
+				t.Errorf("Expected command to pass readonly validation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFlowQueryValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query FlowQuery
+		err   string
+	}{
+		{"pod without namespace", FlowQuery{SourcePod: "worker-1"}, "source_pod requires"},
+		{"injected name", FlowQuery{Namespace: "team-a --all"}, "invalid name"},
+		{"bad verdict", FlowQuery{Verdict: "LOST"}, "verdict"},
+		{"bad protocol", FlowQuery{Protocol: "gre"}, "protocol"},
+		{"bad since", FlowQuery{Since: "yesterday"}, "since"},
+		{"too many flows", FlowQuery{MaxFlows: 20000}, "max_flows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing '%s', got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestAggregateFlows(t *testing.T) {
+	report, err := aggregateFlows(sampleFlows, "hubble observe -o json", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.TotalFlows != 4 || report.ByVerdict["DROPPED"] != 3 || report.ByVerdict["FORWARDED"] != 1 {
+		t.Errorf("Expected 4 flows (3 dropped, 1 forwarded), got %d %v", report.TotalFlows, report.ByVerdict)
+	}
+	if len(report.TopDroppedPairs) != 2 {
+		t.Fatalf("Expected 2 dropped pairs, got %+v", report.TopDroppedPairs)
+	}
+	top := report.TopDroppedPairs[0]
+	if top.Source != "team-a/worker" || top.Destination != "kube-system/coredns" || top.Port != "53/UDP" || top.Count != 2 {
+		t.Errorf("Expected worker -> coredns 53/UDP x2 first, got %+v", top)
+	}
+	if len(top.Policies) != 1 || top.Policies[0] != "team-a/default-deny-all" {
+		t.Errorf("Expected default-deny-all policy, got %v", top.Policies)
+	}
+	if report.TopDroppedPairs[1].Destination != "world (93.184.216.34)" {
+		t.Errorf("Expected world destination, got %s", report.TopDroppedPairs[1].Destination)
+	}
+	if report.DropReasons[0].Value != "POLICY_DENIED" || report.DropReasons[0].Count != 2 {
+		t.Errorf("Expected POLICY_DENIED x2 first, got %+v", report.DropReasons)
+	}
+	if len(report.Policies) != 1 || report.Policies[0].Direction != "egress" || report.Policies[0].Count != 2 {
+		t.Errorf("Expected one egress policy with 2 drops, got %+v", report.Policies)
+	}
+}
+
+func TestAggregateFlowsErrorOutput(t *testing.T) {
+	_, err := aggregateFlows("failed to connect to 'localhost:4245': connection error\n", "hubble observe -o json", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to connect") {
+		t.Errorf("Expected the hubble error to be returned, got %v", err)
+	}
+}
+
+func TestFlowsExecutorNamespaceRestrictions(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.SecurityConfig = security.NewSecurityConfig()
+	cfg.SecurityConfig.SetAllowedNamespaces("team-a")
+
+	var commands []string
+	executor := &FlowsExecutor{run: func(ctx context.Context, command string, cfg *config.ConfigData) (string, error) {
+		commands = append(commands, command)
+		return sampleFlows, nil
+	}}
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		err    string
+	}{
+		{"no namespace filter", map[string]interface{}{"verdict": "DROPPED"}, "namespace filter"},
+		{"denied namespace", map[string]interface{}{"namespace": "team-a", "destination_namespace": "team-b"}, "team-b"},
+		{"allowed namespace", map[string]interface{}{"source_namespace": "team-a"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands = nil
+			result, err := executor.Execute(context.Background(), tt.params, cfg)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing '%s', got %v", tt.err, err)
+				}
+				if len(commands) != 0 {
+					t.Errorf("Expected hubble not to run, got %v", commands)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var report FlowReport
+			if err := json.Unmarshal([]byte(result), &report); err != nil {
+				t.Fatalf("Expected JSON report, got %v", err)
+			}
+			if report.TopDroppedPairs[0].Destination != "kube-system/"+redactedName {
+				t.Errorf("Expected peers outside the allow-list to be redacted, got %s", report.TopDroppedPairs[0].Destination)
+			}
+			if report.TopDroppedPairs[0].Source != "team-a/worker" {
+				t.Errorf("Expected allowed namespace to be shown, got %s", report.TopDroppedPairs[0].Source)
+			}
+		})
+	}
+}
@@ -23,3 +23,49 @@ func RegisterHubble() mcp.Tool {
 		}),
 	)
 }
+
+// RegisterHubbleFlows registers the hubble_flows tool
+func RegisterHubbleFlows() mcp.Tool {
+	return mcp.NewTool("hubble_flows",
+		mcp.WithDescription(`Query Hubble flows with typed filters and return an aggregated report instead of raw flow text.
+
+Runs 'hubble observe -o json' with the given filters and aggregates the flows: counts per verdict, the top dropped source/destination pairs with their ports, drop reasons and the policies that denied them.
+When --allow-namespaces is configured, at least one namespace filter is required, every filtered namespace must be allowed, and pod names of peers in other namespaces are redacted.`),
+		mcp.WithString("namespace",
+			mcp.Description("Only flows from or to this namespace"),
+		),
+		mcp.WithString("source_namespace",
+			mcp.Description("Only flows from this namespace"),
+		),
+		mcp.WithString("source_pod",
+			mcp.Description("Only flows from this pod (requires source_namespace or namespace)"),
+		),
+		mcp.WithString("destination_namespace",
+			mcp.Description("Only flows to this namespace"),
+		),
+		mcp.WithString("destination_pod",
+			mcp.Description("Only flows to this pod (requires destination_namespace or namespace)"),
+		),
+		mcp.WithString("verdict",
+			mcp.Description("Only flows with this verdict"),
+			mcp.Enum("DROPPED", "FORWARDED", "ERROR", "AUDIT"),
+		),
+		mcp.WithString("protocol",
+			mcp.Description("Only flows with this protocol"),
+			mcp.Enum("tcp", "udp", "icmp", "sctp", "http", "dns", "kafka"),
+		),
+		mcp.WithNumber("port",
+			mcp.Description("Only flows to this destination port"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only flows within this time window, e.g. '5m' or '1h'"),
+		),
+		mcp.WithNumber("max_flows",
+			mcp.Description("Maximum number of recent flows to fetch (default: 1000, max: 10000)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Hubble Flows",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
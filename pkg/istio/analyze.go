@@ -0,0 +1,186 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// Levels of istioctl analyze messages, most severe first
+const (
+	LevelError   = "Error"
+	LevelWarning = "Warning"
+	LevelInfo    = "Info"
+)
+
+// levelRank orders messages by severity
+var levelRank = map[string]int{
+	LevelError:   0,
+	LevelWarning: 1,
+	LevelInfo:    2,
+}
+
+// RunFunc runs an istioctl command (without the "istioctl" prefix) and
+// returns its output
+type RunFunc func(ctx context.Context, command string) (string, error)
+
+// AnalyzeOptions are the parameters of mesh_analyze
+type AnalyzeOptions struct {
+	// Namespace restricts the analysis to one namespace
+	Namespace string
+	// MinLevel is the least severe level reported (default: Info)
+	MinLevel string
+}
+
+// AnalyzeReport is the result of mesh_analyze
+type AnalyzeReport struct {
+	Namespaces []string  `json:"namespaces"`
+	Errors     int       `json:"errors"`
+	Warnings   int       `json:"warnings"`
+	Info       int       `json:"info"`
+	Messages   []Message `json:"messages"`
+	Notes      []string  `json:"notes,omitempty"`
+}
+
+// Message is one finding of istioctl analyze. Origin is split into the
+// kind, namespace and name of the resource it is about.
+type Message struct {
+	Code             string `json:"code"`
+	Level            string `json:"level"`
+	Message          string `json:"message"`
+	Kind             string `json:"kind,omitempty"`
+	Namespace        string `json:"namespace,omitempty"`
+	Name             string `json:"name,omitempty"`
+	Origin           string `json:"origin,omitempty"`
+	Reference        string `json:"reference,omitempty"`
+	DocumentationURL string `json:"documentationUrl,omitempty"`
+}
+
+// Analyze runs istioctl analyze for the allowed namespaces and returns its
+// messages, most severe first. With namespace restrictions, each allowed
+// namespace is analyzed on its own and messages about resources in other
+// namespaces are omitted.
+func Analyze(ctx context.Context, runner k8s.Runner, run RunFunc, secConfig *security.SecurityConfig, opts AnalyzeOptions) (*AnalyzeReport, error) {
+	if opts.MinLevel == "" {
+		opts.MinLevel = LevelInfo
+	}
+	if _, ok := levelRank[opts.MinLevel]; !ok {
+		return nil, fmt.Errorf("unsupported level '%s': expected Error, Warning or Info", opts.MinLevel)
+	}
+	namespaces, err := k8s.ScanNamespaces(ctx, runner, secConfig, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	report := &AnalyzeReport{Namespaces: namespaces, Messages: []Message{}}
+	if len(namespaces) == 0 {
+		report.Notes = append(report.Notes, "No allowed namespace exists in the cluster")
+	}
+
+	restricted := secConfig != nil && secConfig.HasNamespaceRestrictions()
+	seen := map[string]bool{}
+	omitted, failed := 0, 0
+	for _, namespace := range namespaces {
+		scope := "-n " + namespace
+		if namespace == k8s.AllNamespaces {
+			scope = "-A"
+		}
+		output, err := run(ctx, "analyze "+scope+" -o json --output-threshold "+opts.MinLevel)
+		var messages []Message
+		if err == nil {
+			messages, err = parseMessages(output)
+		}
+		if err != nil {
+			if len(namespaces) == 1 {
+				return nil, err
+			}
+			failed++
+			report.Notes = append(report.Notes, fmt.Sprintf("Could not analyze %s: %v", namespace, err))
+			continue
+		}
+		for _, m := range messages {
+			if restricted && m.Namespace != "" && !secConfig.IsNamespaceAllowed(m.Namespace) {
+				omitted++
+				continue
+			}
+			key := m.Code + "|" + m.Origin + "|" + m.Message
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			report.add(m)
+		}
+	}
+	if failed > 0 && failed == len(namespaces) {
+		return nil, fmt.Errorf("istioctl analyze failed for every namespace: %s", report.Notes[0])
+	}
+	if omitted > 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("%d messages about resources outside the allowed namespaces were omitted", omitted))
+	}
+
+	sort.SliceStable(report.Messages, func(i, j int) bool {
+		a, b := report.Messages[i], report.Messages[j]
+		if levelRank[a.Level] != levelRank[b.Level] {
+			return levelRank[a.Level] < levelRank[b.Level]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Origin != b.Origin {
+			return a.Origin < b.Origin
+		}
+		return a.Code < b.Code
+	})
+	return report, nil
+}
+
+// add records a message and counts its level
+func (r *AnalyzeReport) add(m Message) {
+	switch m.Level {
+	case LevelError:
+		r.Errors++
+	case LevelWarning:
+		r.Warnings++
+	case LevelInfo:
+		r.Info++
+	}
+	r.Messages = append(r.Messages, m)
+}
+
+// parseMessages decodes the JSON output of istioctl analyze. istioctl prints
+// a confirmation instead of an empty list when nothing was found.
+func parseMessages(output string) ([]Message, error) {
+	trimmed := strings.TrimSpace(output)
+	if trimmed == "" || strings.Contains(trimmed, "No validation issues found") {
+		return nil, nil
+	}
+	if !strings.HasPrefix(trimmed, "[") {
+		return nil, fmt.Errorf("%s", trimmed)
+	}
+	var messages []Message
+	if err := json.Unmarshal([]byte(trimmed), &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse output of istioctl analyze: %w", err)
+	}
+	for i := range messages {
+		messages[i].Kind, messages[i].Namespace, messages[i].Name = parseOrigin(messages[i].Origin)
+	}
+	return messages, nil
+}
+
+// parseOrigin splits an origin such as "VirtualService reviews.bookinfo"
+// into its kind, namespace and name. Namespaces cannot contain dots, so the
+// namespace follows the last one; cluster-scoped origins have none.
+func parseOrigin(origin string) (kind, namespace, name string) {
+	kind, rest, ok := strings.Cut(strings.TrimSpace(origin), " ")
+	if !ok {
+		return "", "", ""
+	}
+	if i := strings.LastIndex(rest, "."); i >= 0 {
+		return kind, rest[i+1:], rest[:i]
+	}
+	return kind, "", rest
+}
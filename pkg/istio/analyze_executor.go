@@ -0,0 +1,69 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// AnalyzeExecutor implements the CommandExecutor interface for mesh_analyze
+type AnalyzeExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+	// run executes an istioctl command after validating it like call_istioctl
+	run func(ctx context.Context, command string, cfg *config.ConfigData) (string, error)
+}
+
+// This line ensures AnalyzeExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*AnalyzeExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*AnalyzeExecutor)(nil)
+
+// NewAnalyzeExecutor creates a new AnalyzeExecutor instance
+func NewAnalyzeExecutor() *AnalyzeExecutor {
+	return &AnalyzeExecutor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+		run: func(ctx context.Context, istioctlCmd string, cfg *config.ConfigData) (string, error) {
+			if err := validateCommand(istioctlCmd, cfg.SecurityConfig); err != nil {
+				return "", err
+			}
+			process := command.NewShellProcess("istioctl", cfg.Timeout)
+			process.Limiter = cfg.ProcessLimiter
+			// istioctl analyze exits non-zero when it finds errors
+			process.ReturnStdoutOnError = true
+			return process.Run(istioctlCmd)
+		},
+	}
+}
+
+// Execute runs istioctl analyze and returns its messages as JSON
+func (e *AnalyzeExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := AnalyzeOptions{}
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.MinLevel, _ = params["min_level"].(string)
+
+	if err := k8s.ValidateNamespace("namespace", opts.Namespace); err != nil {
+		return "", err
+	}
+
+	run := func(ctx context.Context, command string) (string, error) {
+		return e.run(ctx, command, cfg)
+	}
+	report, err := Analyze(ctx, e.newRunner(cfg), run, cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that mesh_analyze always returns a JSON report
+func (e *AnalyzeExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
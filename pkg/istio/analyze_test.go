@@ -0,0 +1,103 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const bookinfoMessages = `[
+  {"code": "IST0118", "documentationUrl": "https://istio.io/latest/docs/reference/config/analysis/ist0118/", "level": "Info", "message": "Port name  (port: 9080, targetPort: 9080) doesn't follow the naming convention of Istio port.", "origin": "Service details.bookinfo"},
+  {"code": "IST0101", "documentationUrl": "https://istio.io/latest/docs/reference/config/analysis/ist0101/", "level": "Error", "message": "Referenced host not found: \"reviews2\"", "origin": "VirtualService reviews.bookinfo", "reference": "reviews.yaml:12"},
+  {"code": "IST0101", "level": "Error", "message": "Referenced gateway not found: \"istio-system/public\"", "origin": "Gateway public.istio-system"},
+  {"code": "IST0102", "level": "Warning", "message": "The namespace is not enabled for Istio injection.", "origin": "Namespace bookinfo"}
+]`
+
+func TestAnalyze(t *testing.T) {
+	var commands []string
+	run := func(ctx context.Context, command string) (string, error) {
+		commands = append(commands, command)
+		switch {
+		case strings.Contains(command, "-n bookinfo"):
+			return bookinfoMessages, nil
+		case strings.Contains(command, "-n ratings"):
+			return "✔ No validation issues found when analyzing namespace: ratings.", nil
+		}
+		return "", fmt.Errorf("unexpected command %q", command)
+	}
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("bookinfo,ratings")
+	runner := &k8stest.Runner{Fallback: k8stest.Unexpected, Outputs: map[string]string{
+		"get namespaces -o json": `{"items": [{"metadata": {"name": "bookinfo"}}, {"metadata": {"name": "istio-system"}}, {"metadata": {"name": "ratings"}}]}`,
+	}}
+
+	report, err := Analyze(context.Background(), runner, run, secConfig, AnalyzeOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(commands, ";") != "analyze -n bookinfo -o json --output-threshold Info;analyze -n ratings -o json --output-threshold Info" {
+		t.Errorf("Expected each allowed namespace to be analyzed, got %v", commands)
+	}
+	if report.Errors != 1 || report.Warnings != 1 || report.Info != 1 || len(report.Messages) != 3 {
+		t.Fatalf("Expected 1 error, 1 warning and 1 info message, got %+v", report)
+	}
+	first := report.Messages[0]
+	if first.Code != "IST0101" || first.Kind != "VirtualService" || first.Namespace != "bookinfo" || first.Name != "reviews" || first.Reference != "reviews.yaml:12" {
+		t.Errorf("Expected the VirtualService error first, got %+v", first)
+	}
+	if ns := report.Messages[1]; ns.Kind != "Namespace" || ns.Name != "bookinfo" || ns.Namespace != "" {
+		t.Errorf("Expected the cluster-scoped Namespace warning, got %+v", ns)
+	}
+	if len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "1 messages") {
+		t.Errorf("Expected a note about the omitted istio-system message, got %v", report.Notes)
+	}
+
+	if _, err := Analyze(context.Background(), runner, run, secConfig, AnalyzeOptions{Namespace: "istio-system"}); err == nil {
+		t.Errorf("Expected a denied namespace to be rejected")
+	}
+	if _, err := Analyze(context.Background(), runner, run, secConfig, AnalyzeOptions{MinLevel: "Debug"}); err == nil {
+		t.Errorf("Expected an unsupported level to be rejected")
+	}
+}
+
+func TestAnalyzeAllNamespaces(t *testing.T) {
+	run := func(ctx context.Context, command string) (string, error) {
+		if command != "analyze -A -o json --output-threshold Warning" {
+			return "", fmt.Errorf("unexpected command %q", command)
+		}
+		return "Error: failed to fetch Istio configuration: connection refused", nil
+	}
+	_, err := Analyze(context.Background(), &k8stest.Runner{Fallback: k8stest.Unexpected}, run, security.NewSecurityConfig(), AnalyzeOptions{MinLevel: LevelWarning})
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Expected the istioctl error, got %v", err)
+	}
+}
+
+func TestValidateProxyTargets(t *testing.T) {
+	tests := []struct {
+		command     string
+		shouldError bool
+	}{
+		{"proxy-config routes productpage-v1-6b746f74dc-9stvs -n bookinfo", false},
+		{"istioctl pc cluster productpage-v1-6b746f74dc-9stvs.ratings", true},
+		{"proxy-config cluster deployment/productpage.ratings -n bookinfo", true},
+		{"proxy-config cluster productpage-v1 -n bookinfo --fqdn reviews.bookinfo.svc.cluster.local", false},
+		{"x describe pod productpage-v1 -n bookinfo -o json", false},
+		{"x describe pod productpage-v1.ratings -n bookinfo", true},
+		{"analyze -n bookinfo samples/bookinfo.yaml", false},
+	}
+
+	for _, tt := range tests {
+		err := validateProxyTargets(tt.command)
+		if tt.shouldError && err == nil {
+			t.Errorf("Expected error for command '%s', got nil", tt.command)
+		}
+		if !tt.shouldError && err != nil {
+			t.Errorf("Expected no error for command '%s', got %v", tt.command, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/google/shlex"
+)
+
+// IstioctlExecutor implements the CommandExecutor interface for istioctl commands
+type IstioctlExecutor struct{}
+
+// This line ensures IstioctlExecutor implements the CommandExecutor interface
+var _ tools.CommandExecutor = (*IstioctlExecutor)(nil)
+var _ tools.CommandDescriber = (*IstioctlExecutor)(nil)
+
+// NewExecutor creates a new IstioctlExecutor instance
+func NewExecutor() *IstioctlExecutor {
+	return &IstioctlExecutor{}
+}
+
+// Execute handles istioctl command execution
+func (e *IstioctlExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	istioctlCmd, ok := params["command"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid command parameter")
+	}
+
+	// Validate the command against security settings
+	if err := validateCommand(istioctlCmd, cfg.SecurityConfig); err != nil {
+		return "", err
+	}
+
+	// Execute the command
+	process := command.NewShellProcess("istioctl", cfg.Timeout)
+	process.Limiter = cfg.ProcessLimiter
+	return process.Run(istioctlCmd)
+}
+
+// DescribeCommand returns the istioctl command a call would run
+func (e *IstioctlExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	istioctlCmd, ok := params["command"].(string)
+	return security.CommandTypeIstioctl, istioctlCmd, ok
+}
+
+// validateCommand checks an istioctl command against the security settings
+func validateCommand(istioctlCmd string, secConfig *security.SecurityConfig) error {
+	validator := security.NewValidator(secConfig)
+	if err := validator.ValidateCommand(istioctlCmd, security.CommandTypeIstioctl); err != nil {
+		return err
+	}
+	if secConfig.HasNamespaceRestrictions() {
+		return validateProxyTargets(istioctlCmd)
+	}
+	return nil
+}
+
+// valueFlags are istioctl flags whose values may contain dots
+var valueFlags = map[string]bool{
+	"--fqdn": true, "--address": true, "--file": true, "-f": true,
+	"--meshConfigFile": true, "--level": true, "-o": true, "--output": true,
+}
+
+// validateProxyTargets rejects pod and workload targets qualified with a
+// namespace ("web.team-b" or "deployment/web.team-b"). istioctl resolves
+// them in that namespace regardless of -n, which would bypass
+// --allow-namespaces. The positional arguments of analyze are local files
+// and are not checked.
+func validateProxyTargets(istioctlCmd string) error {
+	tokens, err := shlex.Split(istioctlCmd)
+	if err != nil {
+		return fmt.Errorf("invalid command: %v", err)
+	}
+	var args []string
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if strings.HasPrefix(token, "-") {
+			if valueFlags[token] {
+				i++
+			}
+			continue
+		}
+		if len(args) == 0 && token == security.CommandTypeIstioctl {
+			continue
+		}
+		args = append(args, token)
+	}
+	if len(args) == 0 || args[0] == "analyze" {
+		return nil
+	}
+	for _, arg := range args[1:] {
+		if strings.Contains(arg, ".") {
+			return &security.ValidationError{Message: "Error: Targets qualified with a namespace ('" + arg + "') are not allowed when --allow-namespaces is configured; use -n/--namespace"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package istio
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterIstioctl registers the istioctl tool
+func RegisterIstioctl() mcp.Tool {
+	return mcp.NewTool("call_istioctl",
+		mcp.WithDescription("Run istioctl diagnostics for the Istio service mesh: analyze configuration, check proxy sync status and inspect Envoy configuration"),
+		mcp.WithString("command",
+			mcp.Required(),
+			mcp.Description("Full istioctl command to execute (e.g., 'istioctl analyze -n bookinfo', 'istioctl proxy-status -n bookinfo', 'istioctl proxy-config routes productpage-v1-6b746f74dc-9stvs -n bookinfo', 'istioctl x describe pod productpage-v1-6b746f74dc-9stvs -n bookinfo')"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Call istioctl",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
+
+// RegisterMeshAnalyze registers the mesh_analyze tool
+func RegisterMeshAnalyze() mcp.Tool {
+	return mcp.NewTool("mesh_analyze",
+		mcp.WithDescription(`Run 'istioctl analyze' across the allowed namespaces and return structured messages.
+
+Each message has its code (e.g. IST0101), level, text, the kind, namespace and name of the resource it is about, and a documentation link. Messages are ordered by severity. Misconfigured VirtualServices, DestinationRules and Gateways, such as references to missing hosts or subsets, are reported here.`),
+		mcp.WithString("namespace",
+			mcp.Description("Only analyze this namespace (default: all allowed namespaces)"),
+		),
+		mcp.WithString("min_level",
+			mcp.Description("Least severe level to report (default: Info)"),
+			mcp.Enum(LevelError, LevelWarning, LevelInfo),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Mesh Analyze",
+			ReadOnlyHint:    boolPtr(true),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,118 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// Runner runs a kubectl command (without the "kubectl" prefix) and returns its output
+type Runner interface {
+	Run(ctx context.Context, command string) (string, error)
+}
+
+// Client runs kubectl commands for composite tools. Every command goes
+// through the kubectl executor, so it is checked by the same validator
+// (access level and namespace restrictions) as call_kubectl.
+type Client struct {
+	cfg      *config.ConfigData
+	executor *kubectl.KubectlExecutor
+}
+
+// This line ensures Client implements the Runner interface
+var _ Runner = (*Client)(nil)
+
+// NewClient creates a new Client for the given configuration
+func NewClient(cfg *config.ConfigData) *Client {
+	return &Client{
+		cfg:      cfg,
+		executor: kubectl.NewExecutor(),
+	}
+}
+
+// Run validates and executes a kubectl command. kubectl failures are
+// reported as errors rather than returned as output. A mutating command
+// invalidates the response cache like it would through call_kubectl.
+func (c *Client) Run(ctx context.Context, command string) (string, error) {
+	output, err := c.executor.Execute(ctx, map[string]interface{}{"command": command}, c.cfg)
+	tools.InvalidateAfterCommand(c.cfg, security.CommandTypeKubectl, command, err)
+	if err != nil {
+		return "", err
+	}
+	if IsErrorOutput(output) {
+		return "", fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+	return output, nil
+}
+
+// KubeletLogRunner is a Runner that can also capture a node's kubelet
+// journal through a node debugging pod
+type KubeletLogRunner interface {
+	Runner
+	// RunKubeletLogs runs security.KubeletLogCommand and returns its output
+	RunKubeletLogs(ctx context.Context, node, namespace string, lines int) (string, error)
+}
+
+// This line ensures Client implements the KubeletLogRunner interface
+var _ KubeletLogRunner = (*Client)(nil)
+
+// RunKubeletLogs captures the last lines of a node's kubelet journal. The
+// command is checked by the validator's dedicated kubelet log entry point
+// rather than by the general kubectl validation, which rejects kubectl debug.
+// The debugging pod it creates invalidates the namespace's cache entries.
+func (c *Client) RunKubeletLogs(ctx context.Context, node, namespace string, lines int) (string, error) {
+	command := security.KubeletLogCommand(node, namespace, lines)
+	output, err := c.executor.ExecuteKubeletLogCommand(ctx, command, c.cfg)
+	tools.InvalidateAfterCommand(c.cfg, security.CommandTypeKubectl, command, err)
+	if err != nil {
+		return "", err
+	}
+	if IsErrorOutput(output) {
+		return "", fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+	return output, nil
+}
+
+// IsErrorOutput reports whether kubectl output is an error message. The
+// shell process returns stderr as output when a command fails.
+func IsErrorOutput(output string) bool {
+	trimmed := strings.TrimSpace(output)
+	return strings.HasPrefix(trimmed, "Error from server") ||
+		strings.HasPrefix(trimmed, "error:") ||
+		strings.HasPrefix(trimmed, "Error:") ||
+		strings.HasPrefix(trimmed, "The connection to the server")
+}
+
+// GetJSON runs a kubectl command with "-o json" appended and decodes the
+// result into out
+func GetJSON(ctx context.Context, runner Runner, command string, out interface{}) error {
+	output, err := runner.Run(ctx, command+" -o json")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(output), out); err != nil {
+		return fmt.Errorf("failed to parse output of 'kubectl %s': %s", command, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// GetSensitiveJSON is GetJSON for objects whose output carries credentials,
+// such as Secrets: a parse failure is reported without echoing the output.
+// out should only declare the fields the caller needs, so that the rest of
+// the data is never decoded.
+func GetSensitiveJSON(ctx context.Context, runner Runner, command string, out interface{}) error {
+	output, err := runner.Run(ctx, command+" -o json")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(output), out); err != nil {
+		return fmt.Errorf("failed to parse output of 'kubectl %s' (output withheld)", command)
+	}
+	return nil
+}
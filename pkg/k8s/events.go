@@ -0,0 +1,30 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// NodeEventNamespace is the namespace the kubelet records node events in
+const NodeEventNamespace = "default"
+
+// NodeEvents returns the events of a node. Without namespace restrictions
+// they are listed across all namespaces; with restrictions only the
+// namespace the kubelet records them in is read, and it must be allowed.
+func NodeEvents(ctx context.Context, runner Runner, secConfig *security.SecurityConfig, node string) ([]Event, error) {
+	scope := "-A"
+	if secConfig != nil && secConfig.HasNamespaceRestrictions() {
+		if !secConfig.IsNamespaceAllowed(NodeEventNamespace) {
+			return nil, fmt.Errorf("events of node %s are recorded in namespace %s, which is not allowed", node, NodeEventNamespace)
+		}
+		scope = "-n " + NodeEventNamespace
+	}
+	var events List[Event]
+	command := fmt.Sprintf("get events %s --field-selector involvedObject.kind=Node,involvedObject.name=%s", scope, node)
+	if err := GetJSON(ctx, runner, command, &events); err != nil {
+		return nil, err
+	}
+	return events.Items, nil
+}
@@ -0,0 +1,100 @@
+// Package k8stest provides a configurable k8s.Runner for testing composite tools
+package k8stest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// Runner returns canned output for kubectl commands and records every
+// command it is asked to run. It is safe for concurrent use.
+type Runner struct {
+	// Outputs maps a command to its output
+	Outputs map[string]string
+	// Errors maps a command to the error returned for it; Errors takes
+	// precedence over Outputs
+	Errors map[string]error
+	// MatchPrefix matches commands by the longest key that is a prefix of
+	// the command instead of exactly
+	MatchPrefix bool
+	// Fallback handles commands that match neither Errors nor Outputs. When
+	// nil, such commands return EmptyList.
+	Fallback func(command string) (string, error)
+
+	mu       sync.Mutex
+	commands []string
+}
+
+// This line ensures Runner implements the KubeletLogRunner interface
+var _ k8s.KubeletLogRunner = (*Runner)(nil)
+
+// EmptyList is the output returned for unmatched commands when no Fallback is set
+const EmptyList = `{"items": []}`
+
+// Run records the command and returns its canned output or error
+func (r *Runner) Run(ctx context.Context, command string) (string, error) {
+	r.mu.Lock()
+	r.commands = append(r.commands, command)
+	r.mu.Unlock()
+
+	if err, ok := lookup(r.Errors, command, r.MatchPrefix); ok {
+		return "", err
+	}
+	if output, ok := lookup(r.Outputs, command, r.MatchPrefix); ok {
+		return output, nil
+	}
+	if r.Fallback != nil {
+		return r.Fallback(command)
+	}
+	return EmptyList, nil
+}
+
+// RunKubeletLogs runs security.KubeletLogCommand through Run, so tests
+// configure kubelet log output like any other command
+func (r *Runner) RunKubeletLogs(ctx context.Context, node, namespace string, lines int) (string, error) {
+	return r.Run(ctx, security.KubeletLogCommand(node, namespace, lines))
+}
+
+// Commands returns the commands run so far, in the order they were run
+func (r *Runner) Commands() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.commands...)
+}
+
+// lookup finds the value for a command, exactly or by the longest key that
+// is a prefix of it
+func lookup[V any](m map[string]V, command string, matchPrefix bool) (V, bool) {
+	if !matchPrefix {
+		value, ok := m[command]
+		return value, ok
+	}
+	match, found := "", false
+	for key := range m {
+		if strings.HasPrefix(command, key) && (!found || len(key) > len(match)) {
+			match, found = key, true
+		}
+	}
+	return m[match], found
+}
+
+// NotFound is a Fallback that reports the command's object as not found
+func NotFound(command string) (string, error) {
+	return "", fmt.Errorf("Error from server (NotFound): %s", command)
+}
+
+// UnknownResource is a Fallback that reports the command's resource type as
+// not served by the cluster, as for a CRD that is not installed
+func UnknownResource(command string) (string, error) {
+	return "", fmt.Errorf("error: the server doesn't have a resource type %q", command)
+}
+
+// Unexpected is a Fallback that fails any command the test did not configure
+func Unexpected(command string) (string, error) {
+	return "", fmt.Errorf("unexpected command %q", command)
+}
@@ -0,0 +1,66 @@
+package k8stest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+	outputs := map[string]string{
+		"get pods":              "all pods",
+		"get pods -n team-a":    "team-a pods",
+		"get deployment web -n": "web",
+	}
+	errs := map[string]error{"get pods -n broken": fmt.Errorf("Error from server (Forbidden)")}
+
+	tests := []struct {
+		name        string
+		runner      *Runner
+		command     string
+		expected    string
+		expectError bool
+	}{
+		{name: "exact match", runner: &Runner{Outputs: outputs}, command: "get pods", expected: "all pods"},
+		{name: "exact miss returns empty list", runner: &Runner{Outputs: outputs}, command: "get pods -A", expected: EmptyList},
+		{name: "longest prefix wins", runner: &Runner{Outputs: outputs, MatchPrefix: true}, command: "get pods -n team-a -o json", expected: "team-a pods"},
+		{name: "shorter prefix", runner: &Runner{Outputs: outputs, MatchPrefix: true}, command: "get pods -A -o json", expected: "all pods"},
+		{name: "errors take precedence", runner: &Runner{Outputs: outputs, Errors: errs, MatchPrefix: true}, command: "get pods -n broken -o json", expectError: true},
+		{name: "fallback for unmatched commands", runner: &Runner{Outputs: outputs, Fallback: NotFound}, command: "get nodes", expectError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := tc.runner.Run(ctx, tc.command)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("Expected error for %q, got output %q", tc.command, output)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if output != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, output)
+			}
+		})
+	}
+}
+
+func TestRunnerRecordsCommands(t *testing.T) {
+	runner := &Runner{}
+	_, _ = runner.Run(context.Background(), "get pods")
+	_, _ = runner.RunKubeletLogs(context.Background(), "node-1", "ops", 10)
+
+	commands := runner.Commands()
+	if len(commands) != 2 || commands[0] != "get pods" {
+		t.Fatalf("Expected both commands to be recorded in order, got %v", commands)
+	}
+	commands[0] = "changed"
+	if reflect.DeepEqual(commands, runner.Commands()) {
+		t.Error("Expected Commands to return a copy")
+	}
+}
@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var (
+	// dns1123Label matches namespace and container names
+	dns1123Label = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// dns1123Subdomain matches the names of most objects, including pods and nodes
+	dns1123Subdomain = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// ValidateName checks a user-supplied object name before it is put into a
+// kubectl command: it must be a DNS-1123 subdomain, so it cannot add flags
+// or arguments to the command. An empty name is valid; callers check
+// required parameters themselves.
+func ValidateName(param, name string) error {
+	if name != "" && (len(name) > 253 || !dns1123Subdomain.MatchString(name)) {
+		return fmt.Errorf("invalid %s '%s': must be a lowercase DNS-1123 name", param, name)
+	}
+	return nil
+}
+
+// ValidateNamespace checks a user-supplied namespace (or container name):
+// it must be a DNS-1123 label. An empty namespace is valid.
+func ValidateNamespace(param, namespace string) error {
+	if namespace != "" && (len(namespace) > 63 || !dns1123Label.MatchString(namespace)) {
+		return fmt.Errorf("invalid %s '%s': must be a lowercase DNS-1123 label", param, namespace)
+	}
+	return nil
+}
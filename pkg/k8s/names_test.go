@@ -0,0 +1,46 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	testCases := []struct {
+		name  string
+		valid bool
+	}{
+		{"", true},
+		{"web-abc-1", true},
+		{"aks-nodepool1-123.internal", true},
+		{"Web", false},
+		{"x -A", false},
+		{"x --raw=/metrics", false},
+		{"-n", false},
+		{"web-", false},
+		{strings.Repeat("a", 254), false},
+	}
+	for _, tc := range testCases {
+		if err := ValidateName("name", tc.name); (err == nil) != tc.valid {
+			t.Errorf("Expected ValidateName(%q) valid=%v, got %v", tc.name, tc.valid, err)
+		}
+	}
+}
+
+func TestValidateNamespace(t *testing.T) {
+	testCases := []struct {
+		namespace string
+		valid     bool
+	}{
+		{"", true},
+		{"kube-system", true},
+		{"team.a", false},
+		{"x -A", false},
+		{strings.Repeat("a", 64), false},
+	}
+	for _, tc := range testCases {
+		if err := ValidateNamespace("namespace", tc.namespace); (err == nil) != tc.valid {
+			t.Errorf("Expected ValidateNamespace(%q) valid=%v, got %v", tc.namespace, tc.valid, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// AllNamespaces is returned by ScanNamespaces when every namespace may be
+// queried at once
+const AllNamespaces = "*"
+
+// ScanNamespaces returns the namespaces a composite tool should query: the
+// requested one, every allowed namespace that exists, or AllNamespaces
+// when no restrictions are configured
+func ScanNamespaces(ctx context.Context, runner Runner, secConfig *security.SecurityConfig, namespace string) ([]string, error) {
+	restricted := secConfig != nil && secConfig.HasNamespaceRestrictions()
+	if namespace != "" {
+		if restricted && !secConfig.IsNamespaceAllowed(namespace) {
+			return nil, fmt.Errorf("access to namespace '%s' is denied by security configuration", namespace)
+		}
+		return []string{namespace}, nil
+	}
+	if !restricted {
+		return []string{AllNamespaces}, nil
+	}
+
+	var list List[Namespace]
+	if err := GetJSON(ctx, runner, "get namespaces", &list); err != nil {
+		return nil, err
+	}
+	namespaces := []string{}
+	for _, item := range list.Items {
+		if item.Metadata.Name != "" && secConfig.IsNamespaceAllowed(item.Metadata.Name) {
+			namespaces = append(namespaces, item.Metadata.Name)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
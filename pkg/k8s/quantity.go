@@ -0,0 +1,83 @@
+package k8s
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// quantitySuffixes are the multipliers of Kubernetes quantity suffixes,
+// binary suffixes first so that "Mi" is not read as "M"
+var quantitySuffixes = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+	{"n", 1e-9}, {"u", 1e-6}, {"m", 1e-3},
+	{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+}
+
+// ParseQuantity parses a resource quantity such as "250m", "1.5", "512Mi"
+// or "1e3" into its value in base units (cores, bytes)
+func ParseQuantity(quantity string) (float64, error) {
+	s := strings.TrimSpace(quantity)
+	if s == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+	multiplier := 1.0
+	for _, q := range quantitySuffixes {
+		if strings.HasSuffix(s, q.suffix) {
+			s, multiplier = strings.TrimSuffix(s, q.suffix), q.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("invalid quantity '%s'", quantity)
+	}
+	return value * multiplier, nil
+}
+
+// MilliCPU parses a CPU quantity into millicores; invalid or empty
+// quantities count as zero
+func MilliCPU(quantity string) int64 {
+	value, err := ParseQuantity(quantity)
+	if err != nil {
+		return 0
+	}
+	return int64(math.Ceil(value * 1000))
+}
+
+// Bytes parses a memory or storage quantity into bytes; invalid or empty
+// quantities count as zero
+func Bytes(quantity string) int64 {
+	value, err := ParseQuantity(quantity)
+	if err != nil {
+		return 0
+	}
+	return int64(math.Ceil(value))
+}
+
+// FormatMilliCPU renders millicores as a CPU quantity, e.g. 250m or 2
+func FormatMilliCPU(milli int64) string {
+	if milli%1000 == 0 {
+		return strconv.FormatInt(milli/1000, 10)
+	}
+	return strconv.FormatInt(milli, 10) + "m"
+}
+
+// FormatBytes renders bytes with the largest binary suffix that keeps the
+// value at or above one, with at most one decimal, e.g. 512Mi or 1.5Gi
+func FormatBytes(bytes int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"Ti", 1 << 40}, {"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10}} {
+		if bytes >= unit.size {
+			value := math.Round(float64(bytes)/float64(unit.size)*10) / 10
+			return strconv.FormatFloat(value, 'f', -1, 64) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(bytes, 10)
+}
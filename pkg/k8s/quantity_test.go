@@ -0,0 +1,56 @@
+package k8s
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	testCases := []struct {
+		quantity string
+		expected float64
+		valid    bool
+	}{
+		{"250m", 0.25, true},
+		{"2", 2, true},
+		{"1.5", 1.5, true},
+		{"512Mi", 512 << 20, true},
+		{"1Gi", 1 << 30, true},
+		{"1G", 1e9, true},
+		{"100k", 1e5, true},
+		{"1e3", 1000, true},
+		{"500n", 5e-7, true},
+		{"", 0, false},
+		{"abc", 0, false},
+		{"1Xi", 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.quantity, func(t *testing.T) {
+			value, err := ParseQuantity(tc.quantity)
+			if (err == nil) != tc.valid {
+				t.Fatalf("Expected valid=%v, got error %v", tc.valid, err)
+			}
+			if math.Abs(value-tc.expected) > 1e-12 {
+				t.Errorf("Expected %v, got %v", tc.expected, value)
+			}
+		})
+	}
+}
+
+func TestFormatQuantities(t *testing.T) {
+	if got := FormatMilliCPU(MilliCPU("1.25")); got != "1250m" {
+		t.Errorf("Expected 1250m, got %s", got)
+	}
+	if got := FormatMilliCPU(MilliCPU("2000m")); got != "2" {
+		t.Errorf("Expected 2, got %s", got)
+	}
+	if got := FormatBytes(Bytes("1536Mi")); got != "1.5Gi" {
+		t.Errorf("Expected 1.5Gi, got %s", got)
+	}
+	if got := FormatBytes(Bytes("100Mi")); got != "100Mi" {
+		t.Errorf("Expected 100Mi, got %s", got)
+	}
+	if got := FormatBytes(512); got != "512" {
+		t.Errorf("Expected 512, got %s", got)
+	}
+}
@@ -0,0 +1,115 @@
+package k8s
+
+import "strconv"
+
+// Tolerates reports whether the toleration matches a taint, following the
+// scheduler's rules: an empty effect matches every effect, and an empty
+// key with operator Exists matches every taint
+func (t Toleration) Tolerates(taint Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key != "" && t.Key != taint.Key {
+		return false
+	}
+	switch t.Operator {
+	case "Exists":
+		return true
+	case "", "Equal":
+		return t.Key != "" && t.Value == taint.Value
+	}
+	return false
+}
+
+// ToleratesAll reports whether the tolerations match every taint with one
+// of the given effects
+func ToleratesAll(tolerations []Toleration, taints []Taint, effects ...string) bool {
+	for _, taint := range taints {
+		if !contains(effects, taint.Effect) {
+			continue
+		}
+		tolerated := false
+		for _, t := range tolerations {
+			if t.Tolerates(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesNode reports whether a pod spec's nodeSelector and required node
+// affinity allow a node
+func (s PodSpec) MatchesNode(node Node) bool {
+	for key, value := range s.NodeSelector {
+		if node.Metadata.Labels[key] != value {
+			return false
+		}
+	}
+	if s.Affinity == nil || s.Affinity.NodeAffinity == nil || s.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	return s.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.Matches(node)
+}
+
+// Matches reports whether any term of the node selector matches the node
+func (s NodeSelector) Matches(node Node) bool {
+	for _, term := range s.NodeSelectorTerms {
+		if term.matches(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether every requirement of the term matches the node.
+// An empty term matches no node, as in the scheduler.
+func (t NodeSelectorTerm) matches(node Node) bool {
+	if len(t.MatchExpressions) == 0 && len(t.MatchFields) == 0 {
+		return false
+	}
+	for _, req := range t.MatchExpressions {
+		value, exists := node.Metadata.Labels[req.Key]
+		if !req.matches(value, exists) {
+			return false
+		}
+	}
+	for _, req := range t.MatchFields {
+		if req.Key != "metadata.name" || !req.matches(node.Metadata.Name, true) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches evaluates a requirement against a label or field value
+func (r NodeSelectorRequirement) matches(value string, exists bool) bool {
+	switch r.Operator {
+	case "In":
+		return exists && contains(r.Values, value)
+	case "NotIn":
+		return !exists || !contains(r.Values, value)
+	case "Exists":
+		return exists
+	case "DoesNotExist":
+		return !exists
+	case "Gt", "Lt":
+		if !exists || len(r.Values) != 1 {
+			return false
+		}
+		actual, err1 := strconv.ParseInt(value, 10, 64)
+		bound, err2 := strconv.ParseInt(r.Values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if r.Operator == "Gt" {
+			return actual > bound
+		}
+		return actual < bound
+	}
+	return false
+}
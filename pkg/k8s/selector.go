@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// LabelSelector is a Kubernetes label selector
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// LabelSelectorRequirement is a single set-based selector requirement
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// Matches reports whether the labels satisfy the selector. An empty
+// selector matches everything.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for key, value := range s.MatchLabels {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	for _, req := range s.MatchExpressions {
+		value, exists := labels[req.Key]
+		switch req.Operator {
+		case "In":
+			if !exists || !contains(req.Values, value) {
+				return false
+			}
+		case "NotIn":
+			if exists && contains(req.Values, value) {
+				return false
+			}
+		case "Exists":
+			if !exists {
+				return false
+			}
+		case "DoesNotExist":
+			if exists {
+				return false
+			}
+		default:
+			// Unknown operators never match, as in the API server
+			return false
+		}
+	}
+	return true
+}
+
+// IsEmpty reports whether the selector has no requirements
+func (s LabelSelector) IsEmpty() bool {
+	return len(s.MatchLabels) == 0 && len(s.MatchExpressions) == 0
+}
+
+// contains reports whether values contains v
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// IntOrString holds a value that is either a number or a name, such as a
+// port ("8080" or "http")
+type IntOrString struct {
+	IntVal int
+	StrVal string
+	IsStr  bool
+}
+
+// UnmarshalJSON accepts a JSON number or string
+func (v *IntOrString) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		v.IsStr = true
+		return json.Unmarshal(data, &v.StrVal)
+	}
+	v.IsStr = false
+	return json.Unmarshal(data, &v.IntVal)
+}
+
+// MarshalJSON renders the value as a JSON number or string
+func (v IntOrString) MarshalJSON() ([]byte, error) {
+	if v.IsStr {
+		return json.Marshal(v.StrVal)
+	}
+	return json.Marshal(v.IntVal)
+}
+
+// String renders the value as text
+func (v IntOrString) String() string {
+	if v.IsStr {
+		return v.StrVal
+	}
+	return strconv.Itoa(v.IntVal)
+}
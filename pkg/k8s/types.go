@@ -0,0 +1,598 @@
+package k8s
+
+// The types below are the subset of the Kubernetes API that composite tools
+// read from "kubectl ... -o json" output. Fields that no tool uses are left out.
+
+// List is a kubectl List of objects
+type List[T any] struct {
+	Items []T `json:"items"`
+}
+
+// ObjectMeta is the subset of object metadata used by composite tools
+type ObjectMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	OwnerReferences   []OwnerReference  `json:"ownerReferences,omitempty"`
+	CreationTimestamp string            `json:"creationTimestamp,omitempty"`
+	DeletionTimestamp string            `json:"deletionTimestamp,omitempty"`
+}
+
+// OwnerReference identifies the controller of an object
+type OwnerReference struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Controller bool   `json:"controller,omitempty"`
+}
+
+// ControllerOf returns the controlling owner of an object, or the first owner
+func ControllerOf(meta ObjectMeta) *OwnerReference {
+	for i := range meta.OwnerReferences {
+		if meta.OwnerReferences[i].Controller {
+			return &meta.OwnerReferences[i]
+		}
+	}
+	if len(meta.OwnerReferences) > 0 {
+		return &meta.OwnerReferences[0]
+	}
+	return nil
+}
+
+// Condition is a status condition shared by most kinds
+type Condition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+// FindCondition returns the condition of the given type, or nil
+func FindCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// Pod is the subset of a Pod used by composite tools
+type Pod struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+	Status   PodStatus  `json:"status"`
+}
+
+// PodSpec is the subset of a pod spec used by composite tools
+type PodSpec struct {
+	NodeName           string            `json:"nodeName,omitempty"`
+	ServiceAccountName string            `json:"serviceAccountName,omitempty"`
+	InitContainers     []Container       `json:"initContainers,omitempty"`
+	Containers         []Container       `json:"containers"`
+	Volumes            []Volume          `json:"volumes,omitempty"`
+	HostNetwork        bool              `json:"hostNetwork,omitempty"`
+	DNSPolicy          string            `json:"dnsPolicy,omitempty"`
+	DNSConfig          *PodDNSConfig     `json:"dnsConfig,omitempty"`
+	NodeSelector       map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations        []Toleration      `json:"tolerations,omitempty"`
+	Affinity           *Affinity         `json:"affinity,omitempty"`
+	PriorityClassName  string            `json:"priorityClassName,omitempty"`
+}
+
+// Toleration lets a pod schedule onto nodes with a matching taint
+type Toleration struct {
+	Key      string `json:"key,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Effect   string `json:"effect,omitempty"`
+}
+
+// Affinity is the subset of pod affinity used by composite tools
+type Affinity struct {
+	NodeAffinity *NodeAffinity `json:"nodeAffinity,omitempty"`
+}
+
+// NodeAffinity holds the required node affinity of a pod
+type NodeAffinity struct {
+	RequiredDuringSchedulingIgnoredDuringExecution *NodeSelector `json:"requiredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+// NodeSelector matches nodes when any of its terms matches
+type NodeSelector struct {
+	NodeSelectorTerms []NodeSelectorTerm `json:"nodeSelectorTerms"`
+}
+
+// NodeSelectorTerm matches nodes when all of its requirements match
+type NodeSelectorTerm struct {
+	MatchExpressions []NodeSelectorRequirement `json:"matchExpressions,omitempty"`
+	MatchFields      []NodeSelectorRequirement `json:"matchFields,omitempty"`
+}
+
+// NodeSelectorRequirement is a requirement on a node label or field
+type NodeSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// PodDNSConfig is a pod's custom DNS configuration
+type PodDNSConfig struct {
+	Nameservers []string             `json:"nameservers,omitempty"`
+	Searches    []string             `json:"searches,omitempty"`
+	Options     []PodDNSConfigOption `json:"options,omitempty"`
+}
+
+// PodDNSConfigOption is a resolver option such as ndots
+type PodDNSConfigOption struct {
+	Name  string  `json:"name"`
+	Value *string `json:"value,omitempty"`
+}
+
+// Container is the subset of a container spec used by composite tools
+type Container struct {
+	Name           string               `json:"name"`
+	Image          string               `json:"image"`
+	Ports          []ContainerPort      `json:"ports,omitempty"`
+	Resources      ResourceRequirements `json:"resources,omitempty"`
+	LivenessProbe  *Probe               `json:"livenessProbe,omitempty"`
+	ReadinessProbe *Probe               `json:"readinessProbe,omitempty"`
+	StartupProbe   *Probe               `json:"startupProbe,omitempty"`
+	Env            []EnvVar             `json:"env,omitempty"`
+	EnvFrom        []EnvFromSource      `json:"envFrom,omitempty"`
+}
+
+// EnvVar is a container environment variable. Only references to
+// ConfigMaps and Secrets are kept.
+type EnvVar struct {
+	Name      string        `json:"name"`
+	ValueFrom *EnvVarSource `json:"valueFrom,omitempty"`
+}
+
+// EnvVarSource references the key of a ConfigMap or Secret
+type EnvVarSource struct {
+	ConfigMapKeyRef *KeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *KeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// KeySelector selects a key of a ConfigMap or Secret
+type KeySelector struct {
+	Name     string `json:"name"`
+	Key      string `json:"key"`
+	Optional *bool  `json:"optional,omitempty"`
+}
+
+// EnvFromSource imports every key of a ConfigMap or Secret
+type EnvFromSource struct {
+	ConfigMapRef *LocalObjectReferenceOptional `json:"configMapRef,omitempty"`
+	SecretRef    *LocalObjectReferenceOptional `json:"secretRef,omitempty"`
+}
+
+// ContainerPort is a port exposed by a container
+type ContainerPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol,omitempty"`
+}
+
+// ResourceRequirements holds container requests and limits as quantity strings
+type ResourceRequirements struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// Probe is the subset of a probe used by composite tools
+type Probe struct {
+	InitialDelaySeconds int `json:"initialDelaySeconds,omitempty"`
+	TimeoutSeconds      int `json:"timeoutSeconds,omitempty"`
+	PeriodSeconds       int `json:"periodSeconds,omitempty"`
+	FailureThreshold    int `json:"failureThreshold,omitempty"`
+}
+
+// Volume is the subset of a pod volume used by composite tools
+type Volume struct {
+	Name                  string                        `json:"name"`
+	PersistentVolumeClaim *PersistentVolumeClaimVolume  `json:"persistentVolumeClaim,omitempty"`
+	ConfigMap             *LocalObjectReferenceOptional `json:"configMap,omitempty"`
+	Secret                *SecretVolume                 `json:"secret,omitempty"`
+}
+
+// PersistentVolumeClaimVolume references a PVC from a pod volume
+type PersistentVolumeClaimVolume struct {
+	ClaimName string `json:"claimName"`
+}
+
+// LocalObjectReferenceOptional references an object by name in the same namespace
+type LocalObjectReferenceOptional struct {
+	Name     string `json:"name"`
+	Optional *bool  `json:"optional,omitempty"`
+}
+
+// SecretVolume references a secret from a pod volume
+type SecretVolume struct {
+	SecretName string `json:"secretName"`
+	Optional   *bool  `json:"optional,omitempty"`
+}
+
+// PodStatus is the subset of a pod status used by composite tools
+type PodStatus struct {
+	Phase                 string            `json:"phase,omitempty"`
+	PodIP                 string            `json:"podIP,omitempty"`
+	Reason                string            `json:"reason,omitempty"`
+	Message               string            `json:"message,omitempty"`
+	Conditions            []Condition       `json:"conditions,omitempty"`
+	InitContainerStatuses []ContainerStatus `json:"initContainerStatuses,omitempty"`
+	ContainerStatuses     []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+// ContainerStatus is the status of a single container
+type ContainerStatus struct {
+	Name         string         `json:"name"`
+	Image        string         `json:"image,omitempty"`
+	Ready        bool           `json:"ready"`
+	RestartCount int            `json:"restartCount"`
+	State        ContainerState `json:"state"`
+	LastState    ContainerState `json:"lastState"`
+}
+
+// ContainerState holds exactly one of the container states
+type ContainerState struct {
+	Waiting    *ContainerStateWaiting    `json:"waiting,omitempty"`
+	Running    *ContainerStateRunning    `json:"running,omitempty"`
+	Terminated *ContainerStateTerminated `json:"terminated,omitempty"`
+}
+
+// ContainerStateWaiting is a waiting container state
+type ContainerStateWaiting struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ContainerStateRunning is a running container state
+type ContainerStateRunning struct {
+	StartedAt string `json:"startedAt,omitempty"`
+}
+
+// ContainerStateTerminated is a terminated container state
+type ContainerStateTerminated struct {
+	ExitCode   int    `json:"exitCode"`
+	Reason     string `json:"reason,omitempty"`
+	Message    string `json:"message,omitempty"`
+	FinishedAt string `json:"finishedAt,omitempty"`
+}
+
+// Event is the subset of a core/v1 Event used by composite tools
+type Event struct {
+	Metadata       ObjectMeta      `json:"metadata"`
+	InvolvedObject ObjectReference `json:"involvedObject"`
+	Type           string          `json:"type"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Count          int             `json:"count,omitempty"`
+	FirstTimestamp string          `json:"firstTimestamp,omitempty"`
+	LastTimestamp  string          `json:"lastTimestamp,omitempty"`
+	EventTime      string          `json:"eventTime,omitempty"`
+	Series         *EventSeries    `json:"series,omitempty"`
+	Source         EventSource     `json:"source,omitempty"`
+	// ReportingComponent replaces Source.Component in events.k8s.io/v1
+	ReportingComponent string `json:"reportingComponent,omitempty"`
+}
+
+// EventSeries aggregates repeated occurrences of an event recorded with
+// the events.k8s.io API
+type EventSeries struct {
+	Count            int    `json:"count"`
+	LastObservedTime string `json:"lastObservedTime,omitempty"`
+}
+
+// EventSource is the component and host that reported an event
+type EventSource struct {
+	Component string `json:"component,omitempty"`
+	Host      string `json:"host,omitempty"`
+}
+
+// ObjectReference identifies an object, such as the object an event is
+// about or the claim bound to a volume
+type ObjectReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// Node is the subset of a Node used by composite tools
+type Node struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     NodeSpec   `json:"spec"`
+	Status   NodeStatus `json:"status"`
+}
+
+// NodeSpec is the subset of a node spec used by composite tools
+type NodeSpec struct {
+	Unschedulable bool    `json:"unschedulable,omitempty"`
+	Taints        []Taint `json:"taints,omitempty"`
+}
+
+// Taint is a node taint
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// NodeStatus is the subset of a node status used by composite tools
+type NodeStatus struct {
+	Conditions  []Condition       `json:"conditions,omitempty"`
+	Capacity    map[string]string `json:"capacity,omitempty"`
+	Allocatable map[string]string `json:"allocatable,omitempty"`
+	NodeInfo    NodeSystemInfo    `json:"nodeInfo,omitempty"`
+}
+
+// NodeSystemInfo is the subset of a node's system info used by composite tools
+type NodeSystemInfo struct {
+	KubeletVersion          string `json:"kubeletVersion,omitempty"`
+	ContainerRuntimeVersion string `json:"containerRuntimeVersion,omitempty"`
+	OSImage                 string `json:"osImage,omitempty"`
+}
+
+// ReplicaSet is the subset of a ReplicaSet used to resolve pod owners
+type ReplicaSet struct {
+	Metadata ObjectMeta `json:"metadata"`
+}
+
+// Deployment is the subset of a Deployment used by composite tools
+type Deployment struct {
+	Metadata ObjectMeta       `json:"metadata"`
+	Spec     DeploymentSpec   `json:"spec"`
+	Status   DeploymentStatus `json:"status"`
+}
+
+// DeploymentSpec is the subset of a deployment spec used by composite tools
+type DeploymentSpec struct {
+	Replicas *int `json:"replicas,omitempty"`
+}
+
+// DeploymentStatus is the subset of a deployment status used by composite tools
+type DeploymentStatus struct {
+	Replicas            int         `json:"replicas,omitempty"`
+	UpdatedReplicas     int         `json:"updatedReplicas,omitempty"`
+	ReadyReplicas       int         `json:"readyReplicas,omitempty"`
+	AvailableReplicas   int         `json:"availableReplicas,omitempty"`
+	UnavailableReplicas int         `json:"unavailableReplicas,omitempty"`
+	Conditions          []Condition `json:"conditions,omitempty"`
+}
+
+// PersistentVolumeClaim is the subset of a PersistentVolumeClaim used by
+// composite tools
+type PersistentVolumeClaim struct {
+	Metadata ObjectMeta                  `json:"metadata"`
+	Spec     PersistentVolumeClaimSpec   `json:"spec"`
+	Status   PersistentVolumeClaimStatus `json:"status"`
+}
+
+// PersistentVolumeClaimSpec is the subset of a claim spec used by composite tools
+type PersistentVolumeClaimSpec struct {
+	StorageClassName *string              `json:"storageClassName,omitempty"`
+	VolumeName       string               `json:"volumeName,omitempty"`
+	AccessModes      []string             `json:"accessModes,omitempty"`
+	VolumeMode       string               `json:"volumeMode,omitempty"`
+	Resources        ResourceRequirements `json:"resources,omitempty"`
+}
+
+// PersistentVolumeClaimStatus is the subset of a claim status used by composite tools
+type PersistentVolumeClaimStatus struct {
+	Phase       string            `json:"phase,omitempty"`
+	AccessModes []string          `json:"accessModes,omitempty"`
+	Capacity    map[string]string `json:"capacity,omitempty"`
+	Conditions  []Condition       `json:"conditions,omitempty"`
+}
+
+// PersistentVolume is the subset of a PersistentVolume used by composite tools
+type PersistentVolume struct {
+	Metadata ObjectMeta             `json:"metadata"`
+	Spec     PersistentVolumeSpec   `json:"spec"`
+	Status   PersistentVolumeStatus `json:"status"`
+}
+
+// PersistentVolumeSpec is the subset of a volume spec used by composite tools
+type PersistentVolumeSpec struct {
+	Capacity                      map[string]string `json:"capacity,omitempty"`
+	AccessModes                   []string          `json:"accessModes,omitempty"`
+	VolumeMode                    string            `json:"volumeMode,omitempty"`
+	StorageClassName              string            `json:"storageClassName,omitempty"`
+	PersistentVolumeReclaimPolicy string            `json:"persistentVolumeReclaimPolicy,omitempty"`
+	ClaimRef                      *ObjectReference  `json:"claimRef,omitempty"`
+	CSI                           *CSIVolumeSource  `json:"csi,omitempty"`
+	NodeAffinity                  *struct {
+		Required *NodeSelector `json:"required,omitempty"`
+	} `json:"nodeAffinity,omitempty"`
+}
+
+// CSIVolumeSource identifies the CSI driver and volume of a PersistentVolume
+type CSIVolumeSource struct {
+	Driver       string `json:"driver"`
+	VolumeHandle string `json:"volumeHandle"`
+}
+
+// PersistentVolumeStatus is the subset of a volume status used by composite tools
+type PersistentVolumeStatus struct {
+	Phase   string `json:"phase,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// StorageClass is the subset of a StorageClass used by composite tools
+type StorageClass struct {
+	Metadata             ObjectMeta             `json:"metadata"`
+	Provisioner          string                 `json:"provisioner"`
+	ReclaimPolicy        string                 `json:"reclaimPolicy,omitempty"`
+	VolumeBindingMode    string                 `json:"volumeBindingMode,omitempty"`
+	AllowVolumeExpansion *bool                  `json:"allowVolumeExpansion,omitempty"`
+	AllowedTopologies    []TopologySelectorTerm `json:"allowedTopologies,omitempty"`
+}
+
+// TopologySelectorTerm restricts the topology of provisioned volumes
+type TopologySelectorTerm struct {
+	MatchLabelExpressions []struct {
+		Key    string   `json:"key"`
+		Values []string `json:"values"`
+	} `json:"matchLabelExpressions,omitempty"`
+}
+
+// VolumeAttachment is the subset of a VolumeAttachment used by composite tools
+type VolumeAttachment struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Attacher string `json:"attacher"`
+		NodeName string `json:"nodeName"`
+		Source   struct {
+			PersistentVolumeName string `json:"persistentVolumeName,omitempty"`
+		} `json:"source"`
+	} `json:"spec"`
+	Status struct {
+		Attached    bool         `json:"attached"`
+		AttachError *VolumeError `json:"attachError,omitempty"`
+		DetachError *VolumeError `json:"detachError,omitempty"`
+	} `json:"status"`
+}
+
+// VolumeError is an attach or detach error of a VolumeAttachment
+type VolumeError struct {
+	Message string `json:"message,omitempty"`
+	Time    string `json:"time,omitempty"`
+}
+
+// CSINode lists the CSI drivers registered on a node
+type CSINode struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Drivers []struct {
+			Name         string   `json:"name"`
+			TopologyKeys []string `json:"topologyKeys,omitempty"`
+			Allocatable  *struct {
+				Count *int `json:"count,omitempty"`
+			} `json:"allocatable,omitempty"`
+		} `json:"drivers"`
+	} `json:"spec"`
+}
+
+// Namespace is the subset of a Namespace used by composite tools
+type Namespace struct {
+	Metadata ObjectMeta `json:"metadata"`
+}
+
+// ConfigMap is the subset of a ConfigMap used by composite tools
+type ConfigMap struct {
+	Metadata ObjectMeta        `json:"metadata"`
+	Data     map[string]string `json:"data,omitempty"`
+}
+
+// Service is the subset of a Service used by composite tools
+type Service struct {
+	Metadata ObjectMeta  `json:"metadata"`
+	Spec     ServiceSpec `json:"spec"`
+}
+
+// ServiceSpec is the subset of a service spec used by composite tools
+type ServiceSpec struct {
+	Type      string            `json:"type,omitempty"`
+	ClusterIP string            `json:"clusterIP,omitempty"`
+	Selector  map[string]string `json:"selector,omitempty"`
+	Ports     []ServicePort     `json:"ports,omitempty"`
+}
+
+// ServicePort is a port exposed by a service. TargetPort is a number or a
+// named container port.
+type ServicePort struct {
+	Name       string      `json:"name,omitempty"`
+	Protocol   string      `json:"protocol,omitempty"`
+	Port       int         `json:"port"`
+	TargetPort IntOrString `json:"targetPort,omitempty"`
+}
+
+// Endpoints is the subset of an Endpoints object used by composite tools
+type Endpoints struct {
+	Metadata ObjectMeta       `json:"metadata"`
+	Subsets  []EndpointSubset `json:"subsets,omitempty"`
+}
+
+// EndpointSubset groups endpoint addresses by readiness
+type EndpointSubset struct {
+	Addresses         []EndpointAddress `json:"addresses,omitempty"`
+	NotReadyAddresses []EndpointAddress `json:"notReadyAddresses,omitempty"`
+}
+
+// EndpointAddress is a single endpoint address
+type EndpointAddress struct {
+	IP string `json:"ip"`
+}
+
+// NetworkPolicy is a networking.k8s.io/v1 NetworkPolicy
+type NetworkPolicy struct {
+	Metadata ObjectMeta        `json:"metadata"`
+	Spec     NetworkPolicySpec `json:"spec"`
+}
+
+// NetworkPolicySpec is the spec of a NetworkPolicy
+type NetworkPolicySpec struct {
+	PodSelector LabelSelector              `json:"podSelector"`
+	PolicyTypes []string                   `json:"policyTypes,omitempty"`
+	Ingress     []NetworkPolicyIngressRule `json:"ingress,omitempty"`
+	Egress      []NetworkPolicyEgressRule  `json:"egress,omitempty"`
+}
+
+// NetworkPolicyIngressRule allows traffic from peers on ports
+type NetworkPolicyIngressRule struct {
+	From  []NetworkPolicyPeer `json:"from,omitempty"`
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+}
+
+// NetworkPolicyEgressRule allows traffic to peers on ports
+type NetworkPolicyEgressRule struct {
+	To    []NetworkPolicyPeer `json:"to,omitempty"`
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+}
+
+// NetworkPolicyPeer selects pods, namespaces or an IP block
+type NetworkPolicyPeer struct {
+	PodSelector       *LabelSelector `json:"podSelector,omitempty"`
+	NamespaceSelector *LabelSelector `json:"namespaceSelector,omitempty"`
+	IPBlock           *IPBlock       `json:"ipBlock,omitempty"`
+}
+
+// IPBlock is a CIDR with optional exceptions
+type IPBlock struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+// NetworkPolicyPort is a port (number or name) and protocol; a nil Port matches all ports
+type NetworkPolicyPort struct {
+	Protocol string       `json:"protocol,omitempty"`
+	Port     *IntOrString `json:"port,omitempty"`
+	EndPort  *int         `json:"endPort,omitempty"`
+}
+
+// AffectsEgress reports whether the policy restricts egress traffic
+func (p NetworkPolicy) AffectsEgress() bool {
+	for _, t := range p.Spec.PolicyTypes {
+		if t == "Egress" {
+			return true
+		}
+	}
+	return len(p.Spec.PolicyTypes) == 0 && len(p.Spec.Egress) > 0
+}
+
+// AffectsIngress reports whether the policy restricts ingress traffic. A
+// policy without policyTypes always affects ingress.
+func (p NetworkPolicy) AffectsIngress() bool {
+	if len(p.Spec.PolicyTypes) == 0 {
+		return true
+	}
+	for _, t := range p.Spec.PolicyTypes {
+		if t == "Ingress" {
+			return true
+		}
+	}
+	return false
+}
@@ -16,6 +16,7 @@ type KubectlExecutor struct{}
 
 // This line ensures KubectlExecutor implements the CommandExecutor interface
 var _ tools.CommandExecutor = (*KubectlExecutor)(nil)
+var _ tools.CommandDescriber = (*KubectlExecutor)(nil)
 
 // NewExecutor creates a new KubectlExecutor instance
 func NewExecutor() *KubectlExecutor {
@@ -25,6 +26,7 @@ func NewExecutor() *KubectlExecutor {
 // executeKubectlCommand executes a kubectl command with the given arguments
 func (e *KubectlExecutor) executeKubectlCommand(ctx context.Context, cmd string, args string, cfg *config.ConfigData) (string, error) {
 	process := command.NewShellProcess("kubectl", cfg.Timeout)
+	process.Limiter = cfg.ProcessLimiter
 
 	var fullCmd string
 	if strings.HasPrefix(cmd, "kubectl ") {
@@ -59,6 +61,23 @@ func (e *KubectlExecutor) Execute(ctx context.Context, params map[string]interfa
 	return e.executeKubectlCommand(ctx, kubectlCmd, "", cfg)
 }
 
+// ExecuteKubeletLogCommand runs a kubelet journal capture built by
+// security.KubeletLogCommand. It is validated by the dedicated
+// ValidateKubeletLogCommand, since ValidateCommand rejects kubectl debug.
+func (e *KubectlExecutor) ExecuteKubeletLogCommand(ctx context.Context, kubectlCmd string, cfg *config.ConfigData) (string, error) {
+	validator := security.NewValidator(cfg.SecurityConfig)
+	if err := validator.ValidateKubeletLogCommand(kubectlCmd); err != nil {
+		return "", err
+	}
+	return e.executeKubectlCommand(ctx, kubectlCmd, "", cfg)
+}
+
+// DescribeCommand returns the kubectl command a call would run
+func (e *KubectlExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	kubectlCmd, ok := params["command"].(string)
+	return security.CommandTypeKubectl, kubectlCmd, ok
+}
+
 // ExecuteSpecificCommand executes a specific kubectl command with the given arguments
 func (e *KubectlExecutor) ExecuteSpecificCommand(ctx context.Context, cmd string, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
 	args, ok := params["args"].(string)
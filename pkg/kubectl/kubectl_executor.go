@@ -7,6 +7,7 @@ import (
 
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
 )
 
 // KubectlToolExecutor handles structured kubectl command execution for grouped tools
@@ -14,6 +15,12 @@ type KubectlToolExecutor struct {
 	executor *KubectlExecutor
 }
 
+// This line ensures KubectlToolExecutor implements the CommandExecutor, CommandDescriber, OutputFormatDescriber and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*KubectlToolExecutor)(nil)
+var _ tools.CommandDescriber = (*KubectlToolExecutor)(nil)
+var _ tools.OutputFormatDescriber = (*KubectlToolExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*KubectlToolExecutor)(nil)
+
 // NewKubectlToolExecutor creates a new kubectl tool executor
 func NewKubectlToolExecutor() *KubectlToolExecutor {
 	return &KubectlToolExecutor{
@@ -28,14 +35,11 @@ func (e *KubectlToolExecutor) Execute(ctx context.Context, params map[string]int
 
 	// Handle call_kubectl with command parameter
 	if toolName == "call_kubectl" {
-		command, ok := params["command"].(string)
-		if !ok {
-			return "", fmt.Errorf("command parameter is required and must be a string")
+		fullCommand, outputFormat, err := e.callKubectlCommand(params)
+		if err != nil {
+			return "", err
 		}
 
-		// Remove "kubectl " prefix if present, as it will be added by executeKubectlCommand
-		fullCommand := strings.TrimPrefix(command, "kubectl ")
-
 		// Validate the command against security settings (includes access level and namespace checks)
 		validator := security.NewValidator(cfg.SecurityConfig)
 		if err := validator.ValidateCommand(fullCommand, security.CommandTypeKubectl); err != nil {
@@ -43,7 +47,11 @@ func (e *KubectlToolExecutor) Execute(ctx context.Context, params map[string]int
 		}
 
 		// Execute the command directly
-		return e.executor.executeKubectlCommand(ctx, fullCommand, "", cfg)
+		output, err := e.executor.executeKubectlCommand(ctx, fullCommand, "", cfg)
+		if err != nil {
+			return "", err
+		}
+		return formatOutput(output, outputFormat), nil
 	}
 
 	// Handle legacy specialized tools with operation/resource/args parameters
@@ -87,6 +95,81 @@ func (e *KubectlToolExecutor) Execute(ctx context.Context, params map[string]int
 	return e.executor.executeKubectlCommand(ctx, fullCommand, "", cfg)
 }
 
+// DescribeCommand returns the kubectl command a call would validate and run,
+// for both the unified call_kubectl tool and the legacy specialized tools
+func (e *KubectlToolExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	toolName, _ := params["_tool_name"].(string)
+
+	if toolName == "call_kubectl" {
+		command, _, err := e.callKubectlCommand(params)
+		if err != nil {
+			return "", "", false
+		}
+		return security.CommandTypeKubectl, command, true
+	}
+
+	operation, ok1 := params["operation"].(string)
+	resource, ok2 := params["resource"].(string)
+	args, ok3 := params["args"].(string)
+	if !ok1 || !ok2 || !ok3 {
+		return "", "", false
+	}
+
+	kubectlCommand, err := MapOperationToCommand(toolName, operation, resource)
+	if err != nil || kubectlCommand == "" {
+		return "", "", false
+	}
+	return security.CommandTypeKubectl, e.buildCommand(kubectlCommand, resource, args), true
+}
+
+// DescribeOutputFormat returns the output_format of a call_kubectl call.
+// json and summary both run the command with "-o json", so the format keeps
+// their cached responses apart.
+func (e *KubectlToolExecutor) DescribeOutputFormat(params map[string]interface{}) string {
+	toolName, _ := params["_tool_name"].(string)
+	if toolName != "call_kubectl" {
+		return ""
+	}
+	_, outputFormat, err := e.callKubectlCommand(params)
+	if err != nil {
+		return ""
+	}
+	return outputFormat
+}
+
+// ReturnsStructuredOutput reports whether a call returns a JSON object that
+// should also be sent as structuredContent
+func (e *KubectlToolExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	toolName, _ := params["_tool_name"].(string)
+	if toolName != "call_kubectl" {
+		return false
+	}
+	_, outputFormat, err := e.callKubectlCommand(params)
+	return err == nil && outputFormat != OutputFormatText
+}
+
+// callKubectlCommand returns the kubectl command (without the "kubectl "
+// prefix) and output format for a call_kubectl invocation. For structured
+// output formats the command is rewritten to emit JSON.
+func (e *KubectlToolExecutor) callKubectlCommand(params map[string]interface{}) (string, string, error) {
+	command, ok := params["command"].(string)
+	if !ok {
+		return "", "", fmt.Errorf("command parameter is required and must be a string")
+	}
+
+	outputFormat, err := parseOutputFormat(params)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Remove "kubectl " prefix if present, as it will be added by executeKubectlCommand
+	fullCommand, err := applyOutputFormat(strings.TrimPrefix(command, "kubectl "), outputFormat)
+	if err != nil {
+		return "", "", err
+	}
+	return fullCommand, outputFormat, nil
+}
+
 // validateCombination validates if the operation/resource combination is valid for the tool
 // Note: This is only used for legacy specialized tools (kubectl_resources, kubectl_workloads, etc.)
 // The unified call_kubectl tool does not use this validation
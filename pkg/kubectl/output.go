@@ -0,0 +1,106 @@
+package kubectl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// Output format constants for the call_kubectl output_format parameter
+const (
+	OutputFormatText    = "text"
+	OutputFormatJSON    = "json"
+	OutputFormatSummary = "summary"
+)
+
+// lastAppliedAnnotation is the client-side apply annotation holding a full copy of the object
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// structuredOutputVerbs are the kubectl verbs that can be forced to emit JSON objects
+var structuredOutputVerbs = map[string]bool{
+	"get":    true,
+	"events": true,
+}
+
+// parseOutputFormat extracts and validates the output_format parameter
+func parseOutputFormat(params map[string]interface{}) (string, error) {
+	format, ok := params["output_format"].(string)
+	if !ok || format == "" {
+		return OutputFormatText, nil
+	}
+	switch format {
+	case OutputFormatText, OutputFormatJSON, OutputFormatSummary:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid output_format '%s'. Valid formats: text, json, summary", format)
+	}
+}
+
+// applyOutputFormat rewrites a kubectl command so that it emits JSON when a
+// structured output format is requested. kubectl (pflag) honors the last
+// occurrence of a flag, so appending "-o json" overrides any -o already in
+// the command.
+func applyOutputFormat(command, format string) (string, error) {
+	if format == OutputFormatText {
+		return command, nil
+	}
+
+	validator := security.NewValidator(security.NewSecurityConfig())
+	operation := validator.ExtractOperation(command, security.CommandTypeKubectl)
+	if !structuredOutputVerbs[operation] {
+		return "", fmt.Errorf("output_format '%s' is only supported for get and events commands, got '%s'", format, operation)
+	}
+
+	return command + " -o json", nil
+}
+
+// formatOutput converts kubectl JSON output into the requested format. When
+// the output is not a JSON object (e.g. a kubectl error message), it is
+// returned unchanged.
+func formatOutput(output, format string) string {
+	if format == OutputFormatText {
+		return output
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &obj); err != nil {
+		return output
+	}
+	stripNoisyFields(obj)
+
+	var result interface{} = obj
+	if format == OutputFormatSummary {
+		result = map[string]interface{}{"summary": summarize(obj)}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return output
+	}
+	return string(data)
+}
+
+// stripNoisyFields removes fields that are large and rarely useful to a
+// model: managedFields, the last-applied-configuration annotation and
+// resourceVersion. List items are cleaned recursively.
+func stripNoisyFields(obj map[string]interface{}) {
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+		delete(metadata, "resourceVersion")
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, lastAppliedAnnotation)
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+
+	if items, ok := obj["items"].([]interface{}); ok {
+		for _, item := range items {
+			if itemObj, ok := item.(map[string]interface{}); ok {
+				stripNoisyFields(itemObj)
+			}
+		}
+	}
+}
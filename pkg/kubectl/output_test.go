@@ -0,0 +1,136 @@
+package kubectl
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestApplyOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{name: "text unchanged", command: "get pods -o wide", format: OutputFormatText, want: "get pods -o wide"},
+		{name: "json get", command: "get pods -n default", format: OutputFormatJSON, want: "get pods -n default -o json"},
+		{name: "summary overrides output", command: "get pods -o wide", format: OutputFormatSummary, want: "get pods -o wide -o json"},
+		{name: "json events", command: "events -A", format: OutputFormatJSON, want: "events -A -o json"},
+		{name: "json describe", command: "describe pod nginx", format: OutputFormatJSON, wantErr: true},
+		{name: "json logs", command: "logs nginx", format: OutputFormatJSON, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyOutputFormat(tt.command, tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got none", tt.command)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	if format, err := parseOutputFormat(map[string]interface{}{}); err != nil || format != OutputFormatText {
+		t.Errorf("Expected default format text, got %q (%v)", format, err)
+	}
+	if _, err := parseOutputFormat(map[string]interface{}{"output_format": "yaml"}); err == nil {
+		t.Error("Expected error for unsupported output format")
+	}
+}
+
+func TestDescribeOutputFormat(t *testing.T) {
+	executor := NewKubectlToolExecutor()
+	params := func(format string) map[string]interface{} {
+		return map[string]interface{}{"_tool_name": "call_kubectl", "command": "get pods -n default", "output_format": format}
+	}
+
+	_, jsonCommand, _ := executor.DescribeCommand(params(OutputFormatJSON))
+	_, summaryCommand, _ := executor.DescribeCommand(params(OutputFormatSummary))
+	if jsonCommand != summaryCommand {
+		t.Fatalf("Expected json and summary to run the same command, got %q and %q", jsonCommand, summaryCommand)
+	}
+	if got := executor.DescribeOutputFormat(params(OutputFormatJSON)); got != OutputFormatJSON {
+		t.Errorf("Expected output format json, got %q", got)
+	}
+	if got := executor.DescribeOutputFormat(params(OutputFormatSummary)); got != OutputFormatSummary {
+		t.Errorf("Expected output format summary, got %q", got)
+	}
+	if got := executor.DescribeOutputFormat(map[string]interface{}{"_tool_name": "call_kubectl", "command": "get pods -n default"}); got != OutputFormatText {
+		t.Errorf("Expected default output format text, got %q", got)
+	}
+}
+
+const testPodList = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "metadata": {"resourceVersion": ""},
+  "items": [
+    {
+      "kind": "Pod",
+      "metadata": {
+        "name": "web-1",
+        "namespace": "default",
+        "resourceVersion": "123",
+        "managedFields": [{"manager": "kubectl"}],
+        "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}", "team": "web"}
+      },
+      "status": {"phase": "Running"}
+    },
+    {
+      "kind": "Pod",
+      "metadata": {"name": "api-1", "namespace": "backend"},
+      "status": {"phase": "Pending"}
+    }
+  ]
+}`
+
+func TestFormatOutputJSON(t *testing.T) {
+	output := formatOutput(testPodList, OutputFormatJSON)
+	for _, noisy := range []string{"managedFields", "resourceVersion", "last-applied-configuration"} {
+		if strings.Contains(output, noisy) {
+			t.Errorf("Expected %s to be stripped, got %s", noisy, output)
+		}
+	}
+	if !strings.Contains(output, `"team":"web"`) {
+		t.Errorf("Expected other annotations to be kept, got %s", output)
+	}
+}
+
+func TestFormatOutputSummary(t *testing.T) {
+	output := formatOutput(testPodList, OutputFormatSummary)
+
+	var result struct {
+		Summary listSummary `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Expected summary JSON, got %v", err)
+	}
+	if result.Summary.Total != 2 {
+		t.Errorf("Expected 2 items, got %d", result.Summary.Total)
+	}
+	if len(result.Summary.Kinds) != 1 || result.Summary.Kinds[0].Kind != "Pod" {
+		t.Fatalf("Expected a single Pod summary, got %+v", result.Summary.Kinds)
+	}
+	if unhealthy := result.Summary.Kinds[0].Unhealthy; len(unhealthy) != 1 || unhealthy[0].Names[0] != "api-1" {
+		t.Errorf("Expected api-1 as the only unhealthy pod, got %+v", unhealthy)
+	}
+}
+
+func TestFormatOutputNonJSON(t *testing.T) {
+	errText := "Error from server (NotFound): pods \"x\" not found"
+	if got := formatOutput(errText, OutputFormatJSON); got != errText {
+		t.Errorf("Expected non-JSON output unchanged, got %q", got)
+	}
+}
@@ -484,6 +484,11 @@ Examples:
 			mcp.Required(),
 			mcp.Description("Full kubectl command to execute (e.g., 'kubectl get pods -n default', 'kubectl describe deployment myapp', 'kubectl logs nginx-pod -f')"),
 		),
+		mcp.WithString("output_format",
+			mcp.Enum(OutputFormatText, OutputFormatJSON, OutputFormatSummary),
+			mcp.DefaultString(OutputFormatText),
+			mcp.Description("Output format: 'text' (default) returns kubectl output as-is; 'json' runs get/events commands with -o json and returns the objects without managedFields, last-applied-configuration and resourceVersion; 'summary' returns status counts per kind, a one-line rollup of healthy objects and details only for unhealthy ones, grouped by namespace/owner/status (use for large lists such as 'kubectl get pods -A'). json and summary results are also returned as structuredContent."),
+		),
 	}
 
 	// Add annotation based on access level
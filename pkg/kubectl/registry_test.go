@@ -534,6 +534,12 @@ func TestCallKubectlToolStructure(t *testing.T) {
 					t.Errorf("call_kubectl description missing: %s", expected)
 				}
 			}
+
+			// Text results and kubectl errors carry no structuredContent, so
+			// the tool must not declare an output schema
+			if tool.RawOutputSchema != nil || tool.OutputSchema.Type != "" {
+				t.Errorf("Expected call_kubectl to declare no output schema")
+			}
 		})
 	}
 }
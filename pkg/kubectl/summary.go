@@ -0,0 +1,529 @@
+package kubectl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxUnhealthyGroups bounds the number of unhealthy groups listed per kind
+const maxUnhealthyGroups = 50
+
+// maxGroupNames bounds the number of object names listed per unhealthy group
+const maxGroupNames = 5
+
+// listSummary is a token-efficient view of a kubectl result: per kind, status
+// counts and a one-line rollup for healthy objects, with details only for
+// unhealthy ones
+type listSummary struct {
+	Total int           `json:"total"`
+	Kinds []kindSummary `json:"kinds"`
+}
+
+// kindSummary summarizes the objects of a single kind
+type kindSummary struct {
+	Kind        string                     `json:"kind"`
+	Total       int                        `json:"total"`
+	Healthy     int                        `json:"healthy"`
+	ByStatus    map[string]int             `json:"byStatus"`
+	ByNamespace map[string]namespaceCounts `json:"byNamespace,omitempty"`
+	// Rollup is a one-line description of the healthy objects
+	Rollup string `json:"rollup"`
+	// Unhealthy groups unhealthy objects by namespace, owner and status
+	Unhealthy []unhealthyGroup `json:"unhealthy,omitempty"`
+	// TruncatedGroups is the number of unhealthy groups left out of Unhealthy
+	TruncatedGroups int `json:"truncatedGroups,omitempty"`
+}
+
+// namespaceCounts holds per-namespace object counts
+type namespaceCounts struct {
+	Total     int `json:"total"`
+	Unhealthy int `json:"unhealthy,omitempty"`
+}
+
+// unhealthyGroup is a set of unhealthy objects sharing namespace, owner and status
+type unhealthyGroup struct {
+	Namespace string   `json:"namespace,omitempty"`
+	Owner     string   `json:"owner,omitempty"`
+	Status    string   `json:"status"`
+	Count     int      `json:"count"`
+	Names     []string `json:"names"`
+	Reason    string   `json:"reason,omitempty"`
+}
+
+// objectStatus is the classification of a single object
+type objectStatus struct {
+	Namespace string
+	Name      string
+	Owner     string
+	Status    string
+	Reason    string
+	Healthy   bool
+	// Count is the number of occurrences the object stands for (events carry a repeat count)
+	Count int
+}
+
+// kindClassifiers maps object kinds to their status classifier. Other kinds
+// use classifyGeneric.
+var kindClassifiers = map[string]func(obj map[string]interface{}) objectStatus{
+	"Pod":                   classifyPod,
+	"Deployment":            classifyDeployment,
+	"Node":                  classifyNode,
+	"PersistentVolumeClaim": classifyPVC,
+	"Event":                 classifyEvent,
+	"Job":                   classifyJob,
+}
+
+// summarize builds a summary of a single object or a List
+func summarize(obj map[string]interface{}) listSummary {
+	var items []map[string]interface{}
+	if rawItems, ok := obj["items"].([]interface{}); ok {
+		for _, item := range rawItems {
+			if itemObj, ok := item.(map[string]interface{}); ok {
+				items = append(items, itemObj)
+			}
+		}
+	} else {
+		items = []map[string]interface{}{obj}
+	}
+
+	byKind := make(map[string][]objectStatus)
+	var kinds []string
+	for _, item := range items {
+		kind, _ := item["kind"].(string)
+		classify, ok := kindClassifiers[kind]
+		if !ok {
+			classify = classifyGeneric
+		}
+		if _, seen := byKind[kind]; !seen {
+			kinds = append(kinds, kind)
+		}
+		byKind[kind] = append(byKind[kind], classify(item))
+	}
+
+	summary := listSummary{Total: len(items), Kinds: make([]kindSummary, 0, len(kinds))}
+	for _, kind := range kinds {
+		summary.Kinds = append(summary.Kinds, summarizeKind(kind, byKind[kind]))
+	}
+	return summary
+}
+
+// summarizeKind aggregates the classified objects of one kind
+func summarizeKind(kind string, statuses []objectStatus) kindSummary {
+	ks := kindSummary{
+		Kind:        kind,
+		Total:       len(statuses),
+		ByStatus:    make(map[string]int),
+		ByNamespace: make(map[string]namespaceCounts),
+	}
+
+	healthyStatuses := make(map[string]int)
+	healthyNamespaces := make(map[string]bool)
+	healthyOwners := make(map[string]bool)
+	groups := make(map[string]*unhealthyGroup)
+
+	for _, s := range statuses {
+		ks.ByStatus[s.Status] += s.Count
+		if s.Namespace != "" {
+			counts := ks.ByNamespace[s.Namespace]
+			counts.Total++
+			if !s.Healthy {
+				counts.Unhealthy++
+			}
+			ks.ByNamespace[s.Namespace] = counts
+		}
+
+		if s.Healthy {
+			ks.Healthy++
+			healthyStatuses[s.Status]++
+			if s.Namespace != "" {
+				healthyNamespaces[s.Namespace] = true
+			}
+			if s.Owner != "" {
+				healthyOwners[s.Namespace+"/"+s.Owner] = true
+			}
+			continue
+		}
+
+		// Objects without an owner are grouped on their own
+		owner := s.Owner
+		if owner == "" {
+			owner = s.Name
+		}
+		groupKey := s.Namespace + "\x00" + owner + "\x00" + s.Status
+		group, ok := groups[groupKey]
+		if !ok {
+			group = &unhealthyGroup{Namespace: s.Namespace, Owner: s.Owner, Status: s.Status, Reason: s.Reason}
+			groups[groupKey] = group
+		}
+		group.Count += s.Count
+		if len(group.Names) < maxGroupNames {
+			group.Names = append(group.Names, s.Name)
+		}
+	}
+
+	if len(ks.ByNamespace) == 0 {
+		ks.ByNamespace = nil
+	}
+
+	for _, group := range groups {
+		ks.Unhealthy = append(ks.Unhealthy, *group)
+	}
+	sort.Slice(ks.Unhealthy, func(i, j int) bool {
+		a, b := ks.Unhealthy[i], ks.Unhealthy[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		return a.Names[0] < b.Names[0]
+	})
+	if len(ks.Unhealthy) > maxUnhealthyGroups {
+		ks.TruncatedGroups = len(ks.Unhealthy) - maxUnhealthyGroups
+		ks.Unhealthy = ks.Unhealthy[:maxUnhealthyGroups]
+	}
+
+	ks.Rollup = healthyRollup(kind, ks.Healthy, ks.Total, healthyStatuses, len(healthyNamespaces), len(healthyOwners))
+	return ks
+}
+
+// healthyRollup renders the one-line description of the healthy objects, e.g.
+// "2950/3000 Pod healthy (Running=2900, Succeeded=50) across 40 namespaces and 310 owners"
+func healthyRollup(kind string, healthy, total int, statuses map[string]int, namespaces, owners int) string {
+	rollup := fmt.Sprintf("%d/%d %s healthy", healthy, total, kind)
+	if len(statuses) > 0 {
+		rollup += " (" + formatCounts(statuses) + ")"
+	}
+
+	var scopes []string
+	if namespaces > 0 {
+		scopes = append(scopes, pluralize(namespaces, "namespace"))
+	}
+	if owners > 0 {
+		scopes = append(scopes, pluralize(owners, "owner"))
+	}
+	if len(scopes) > 0 {
+		rollup += " across " + strings.Join(scopes, " and ")
+	}
+	return rollup
+}
+
+// formatCounts renders counts as "a=1, b=2" in key order
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, counts[k]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pluralize renders a count with a naively pluralized noun
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// baseStatus fills the fields common to every kind
+func baseStatus(obj map[string]interface{}) objectStatus {
+	metadata := nestedMap(obj, "metadata")
+	s := objectStatus{Count: 1}
+	s.Namespace, _ = metadata["namespace"].(string)
+	s.Name, _ = metadata["name"].(string)
+
+	if owners, ok := metadata["ownerReferences"].([]interface{}); ok && len(owners) > 0 {
+		if owner, ok := owners[0].(map[string]interface{}); ok {
+			ownerKind, _ := owner["kind"].(string)
+			ownerName, _ := owner["name"].(string)
+			s.Owner = ownerKind + "/" + ownerName
+		}
+	}
+	return s
+}
+
+// classifyPod reports the most specific pod status, preferring container
+// waiting/terminated reasons (CrashLoopBackOff, ImagePullBackOff, OOMKilled)
+// over the pod phase, the way kubectl's STATUS column does
+func classifyPod(obj map[string]interface{}) objectStatus {
+	s := baseStatus(obj)
+	s.Owner = podOwner(obj, s.Owner)
+	status := nestedMap(obj, "status")
+	phase, _ := status["phase"].(string)
+	s.Status = phase
+
+	if reason, ok := status["reason"].(string); ok && reason != "" && phase != "Running" {
+		// e.g. Evicted, NodeLost
+		s.Status = reason
+		s.Reason, _ = status["message"].(string)
+	}
+
+	ready := phase == "Running"
+	for _, field := range []string{"initContainerStatuses", "containerStatuses"} {
+		containers, _ := status[field].([]interface{})
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if isReady, _ := container["ready"].(bool); !isReady && field == "containerStatuses" {
+				ready = false
+			}
+			state := nestedMap(container, "state")
+			if waiting := nestedMap(state, "waiting"); waiting != nil {
+				if reason, _ := waiting["reason"].(string); reason != "" && reason != "PodInitializing" && reason != "ContainerCreating" {
+					s.Status = reason
+					s.Reason, _ = waiting["message"].(string)
+				}
+			}
+			if terminated := nestedMap(state, "terminated"); terminated != nil && phase != "Succeeded" {
+				if reason, _ := terminated["reason"].(string); reason != "" && reason != "Completed" {
+					s.Status = reason
+					s.Reason, _ = terminated["message"].(string)
+				}
+			}
+		}
+	}
+
+	if _, deleting := nestedMap(obj, "metadata")["deletionTimestamp"]; deleting {
+		s.Status = "Terminating"
+	}
+
+	switch {
+	case s.Status == "Succeeded":
+		s.Healthy = true
+	case s.Status == "Running" && ready:
+		s.Healthy = true
+	case s.Status == "Running":
+		s.Status = "NotReady"
+	}
+	return s
+}
+
+// podOwner collapses ReplicaSet owners to their Deployment using the
+// pod-template-hash label, so pods of one Deployment share an owner across
+// rollouts
+func podOwner(obj map[string]interface{}, owner string) string {
+	hash, _ := nestedMap(nestedMap(obj, "metadata"), "labels")["pod-template-hash"].(string)
+	if hash == "" || !strings.HasPrefix(owner, "ReplicaSet/") || !strings.HasSuffix(owner, "-"+hash) {
+		return owner
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(owner, "ReplicaSet/"), "-"+hash)
+	return "Deployment/" + name
+}
+
+// classifyDeployment compares desired, updated and available replicas
+func classifyDeployment(obj map[string]interface{}) objectStatus {
+	s := baseStatus(obj)
+	spec := nestedMap(obj, "spec")
+	status := nestedMap(obj, "status")
+
+	desired := intField(spec, "replicas", 1)
+	updated := intField(status, "updatedReplicas", 0)
+	available := intField(status, "availableReplicas", 0)
+
+	for _, condition := range conditions(status) {
+		if condition["type"] == "Progressing" && condition["status"] == "False" {
+			s.Status, _ = condition["reason"].(string)
+			s.Reason, _ = condition["message"].(string)
+			return s
+		}
+	}
+
+	switch {
+	case desired == 0:
+		s.Status = "ScaledDown"
+		s.Healthy = true
+	case available < desired:
+		s.Status = "Unavailable"
+		s.Reason = fmt.Sprintf("%d/%d replicas available", available, desired)
+	case updated < desired:
+		s.Status = "Progressing"
+		s.Reason = fmt.Sprintf("%d/%d replicas updated", updated, desired)
+		s.Healthy = true
+	default:
+		s.Status = "Available"
+		s.Healthy = true
+	}
+	return s
+}
+
+// classifyNode reports NotReady, pressure conditions and cordoned nodes
+func classifyNode(obj map[string]interface{}) objectStatus {
+	s := baseStatus(obj)
+	status := nestedMap(obj, "status")
+
+	s.Status = "NotReady"
+	var pressures []string
+	for _, condition := range conditions(status) {
+		conditionType, _ := condition["type"].(string)
+		if conditionType == "Ready" {
+			if condition["status"] == "True" {
+				s.Status = "Ready"
+			} else {
+				s.Reason, _ = condition["message"].(string)
+			}
+			continue
+		}
+		if condition["status"] == "True" && strings.HasSuffix(conditionType, "Pressure") {
+			pressures = append(pressures, conditionType)
+		}
+	}
+
+	switch {
+	case s.Status != "Ready":
+	case len(pressures) > 0:
+		s.Status = strings.Join(pressures, ",")
+	case boolField(nestedMap(obj, "spec"), "unschedulable"):
+		s.Status = "SchedulingDisabled"
+	default:
+		s.Healthy = true
+	}
+	return s
+}
+
+// classifyPVC reports the claim phase; only Bound claims are healthy
+func classifyPVC(obj map[string]interface{}) objectStatus {
+	s := baseStatus(obj)
+	s.Status, _ = nestedMap(obj, "status")["phase"].(string)
+	if s.Status == "" {
+		s.Status = "Unknown"
+	}
+	s.Healthy = s.Status == "Bound"
+	return s
+}
+
+// classifyEvent groups events by involved object and reason; Warning events
+// are unhealthy and carry their repeat count
+func classifyEvent(obj map[string]interface{}) objectStatus {
+	s := baseStatus(obj)
+	s.Status, _ = obj["reason"].(string)
+	s.Reason, _ = obj["message"].(string)
+	if note, ok := obj["note"].(string); ok && s.Reason == "" {
+		s.Reason = note
+	}
+
+	involved := nestedMap(obj, "involvedObject")
+	if involved == nil {
+		involved = nestedMap(obj, "regarding")
+	}
+	if involved != nil {
+		kind, _ := involved["kind"].(string)
+		name, _ := involved["name"].(string)
+		s.Owner = kind + "/" + name
+	}
+
+	if count := intField(obj, "count", 0); count > 0 {
+		s.Count = count
+	}
+	eventType, _ := obj["type"].(string)
+	s.Healthy = eventType != "Warning"
+	return s
+}
+
+// classifyJob reports Complete, Failed, Suspended or Running
+func classifyJob(obj map[string]interface{}) objectStatus {
+	s := baseStatus(obj)
+	status := nestedMap(obj, "status")
+
+	for _, condition := range conditions(status) {
+		if condition["status"] != "True" {
+			continue
+		}
+		switch condition["type"] {
+		case "Complete":
+			s.Status = "Complete"
+			s.Healthy = true
+			return s
+		case "Failed":
+			s.Status = "Failed"
+			s.Reason, _ = condition["message"].(string)
+			return s
+		}
+	}
+
+	switch {
+	case boolField(nestedMap(obj, "spec"), "suspend"):
+		s.Status = "Suspended"
+		s.Healthy = true
+	case intField(status, "active", 0) > 0:
+		s.Status = "Running"
+		s.Healthy = true
+	default:
+		s.Status = "Pending"
+	}
+	return s
+}
+
+// classifyGeneric uses the phase or Ready condition when present. Objects
+// without either are counted as healthy.
+func classifyGeneric(obj map[string]interface{}) objectStatus {
+	s := baseStatus(obj)
+	status := nestedMap(obj, "status")
+	s.Healthy = true
+
+	if phase, ok := status["phase"].(string); ok {
+		s.Status = phase
+		s.Healthy = phase != "Failed" && phase != "Pending" && phase != "Unknown" && phase != "Lost"
+		return s
+	}
+	for _, condition := range conditions(status) {
+		if condition["type"] == "Ready" {
+			if condition["status"] == "True" {
+				s.Status = "Ready"
+			} else {
+				s.Status = "NotReady"
+				s.Healthy = false
+				s.Reason, _ = condition["message"].(string)
+			}
+			return s
+		}
+	}
+	s.Status = "Present"
+	return s
+}
+
+// nestedMap returns obj[key] as a map, or nil
+func nestedMap(obj map[string]interface{}, key string) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	m, _ := obj[key].(map[string]interface{})
+	return m
+}
+
+// conditions returns the status conditions of an object
+func conditions(status map[string]interface{}) []map[string]interface{} {
+	raw, _ := status["conditions"].([]interface{})
+	result := make([]map[string]interface{}, 0, len(raw))
+	for _, c := range raw {
+		if condition, ok := c.(map[string]interface{}); ok {
+			result = append(result, condition)
+		}
+	}
+	return result
+}
+
+// intField returns a JSON number field as an int, or def when absent
+func intField(obj map[string]interface{}, key string, def int) int {
+	if v, ok := obj[key].(float64); ok {
+		return int(v)
+	}
+	return def
+}
+
+// boolField returns a JSON boolean field, or false when absent
+func boolField(obj map[string]interface{}, key string) bool {
+	v, _ := obj[key].(bool)
+	return v
+}
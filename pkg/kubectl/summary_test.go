@@ -0,0 +1,174 @@
+package kubectl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &obj); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	return obj
+}
+
+func TestClassifyPod(t *testing.T) {
+	tests := []struct {
+		name        string
+		pod         string
+		wantStatus  string
+		wantHealthy bool
+		wantOwner   string
+	}{
+		{
+			name:        "running and ready",
+			pod:         `{"kind":"Pod","metadata":{"name":"a","labels":{"pod-template-hash":"abc12"},"ownerReferences":[{"kind":"ReplicaSet","name":"web-abc12"}]},"status":{"phase":"Running","containerStatuses":[{"ready":true,"state":{"running":{}}}]}}`,
+			wantStatus:  "Running",
+			wantHealthy: true,
+			wantOwner:   "Deployment/web",
+		},
+		{
+			name:       "crash loop",
+			pod:        `{"kind":"Pod","metadata":{"name":"b"},"status":{"phase":"Running","containerStatuses":[{"ready":false,"state":{"waiting":{"reason":"CrashLoopBackOff","message":"back-off"}}}]}}`,
+			wantStatus: "CrashLoopBackOff",
+		},
+		{
+			name:       "running not ready",
+			pod:        `{"kind":"Pod","metadata":{"name":"c"},"status":{"phase":"Running","containerStatuses":[{"ready":false,"state":{"running":{}}}]}}`,
+			wantStatus: "NotReady",
+		},
+		{
+			name:       "evicted",
+			pod:        `{"kind":"Pod","metadata":{"name":"d"},"status":{"phase":"Failed","reason":"Evicted","message":"low on memory"}}`,
+			wantStatus: "Evicted",
+		},
+		{
+			name:        "completed",
+			pod:         `{"kind":"Pod","metadata":{"name":"e","ownerReferences":[{"kind":"Job","name":"backup"}]},"status":{"phase":"Succeeded","containerStatuses":[{"ready":false,"state":{"terminated":{"reason":"Completed"}}}]}}`,
+			wantStatus:  "Succeeded",
+			wantHealthy: true,
+			wantOwner:   "Job/backup",
+		},
+		{
+			name:       "oom killed",
+			pod:        `{"kind":"Pod","metadata":{"name":"f"},"status":{"phase":"Failed","containerStatuses":[{"ready":false,"state":{"terminated":{"reason":"OOMKilled"}}}]}}`,
+			wantStatus: "OOMKilled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := classifyPod(mustParse(t, tt.pod))
+			if s.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, s.Status)
+			}
+			if s.Healthy != tt.wantHealthy {
+				t.Errorf("Expected healthy %v, got %v", tt.wantHealthy, s.Healthy)
+			}
+			if s.Owner != tt.wantOwner {
+				t.Errorf("Expected owner %q, got %q", tt.wantOwner, s.Owner)
+			}
+		})
+	}
+}
+
+func TestClassifyOtherKinds(t *testing.T) {
+	tests := []struct {
+		name        string
+		obj         string
+		wantStatus  string
+		wantHealthy bool
+	}{
+		{name: "deployment available", obj: `{"kind":"Deployment","spec":{"replicas":3},"status":{"updatedReplicas":3,"availableReplicas":3}}`, wantStatus: "Available", wantHealthy: true},
+		{name: "deployment unavailable", obj: `{"kind":"Deployment","spec":{"replicas":3},"status":{"updatedReplicas":3,"availableReplicas":1}}`, wantStatus: "Unavailable"},
+		{name: "deployment deadline", obj: `{"kind":"Deployment","spec":{"replicas":3},"status":{"conditions":[{"type":"Progressing","status":"False","reason":"ProgressDeadlineExceeded"}]}}`, wantStatus: "ProgressDeadlineExceeded"},
+		{name: "node ready", obj: `{"kind":"Node","status":{"conditions":[{"type":"Ready","status":"True"}]}}`, wantStatus: "Ready", wantHealthy: true},
+		{name: "node pressure", obj: `{"kind":"Node","status":{"conditions":[{"type":"Ready","status":"True"},{"type":"DiskPressure","status":"True"}]}}`, wantStatus: "DiskPressure"},
+		{name: "node cordoned", obj: `{"kind":"Node","spec":{"unschedulable":true},"status":{"conditions":[{"type":"Ready","status":"True"}]}}`, wantStatus: "SchedulingDisabled"},
+		{name: "node not ready", obj: `{"kind":"Node","status":{"conditions":[{"type":"Ready","status":"Unknown"}]}}`, wantStatus: "NotReady"},
+		{name: "pvc bound", obj: `{"kind":"PersistentVolumeClaim","status":{"phase":"Bound"}}`, wantStatus: "Bound", wantHealthy: true},
+		{name: "pvc pending", obj: `{"kind":"PersistentVolumeClaim","status":{"phase":"Pending"}}`, wantStatus: "Pending"},
+		{name: "job complete", obj: `{"kind":"Job","status":{"conditions":[{"type":"Complete","status":"True"}]}}`, wantStatus: "Complete", wantHealthy: true},
+		{name: "job failed", obj: `{"kind":"Job","status":{"conditions":[{"type":"Failed","status":"True","message":"BackoffLimitExceeded"}]}}`, wantStatus: "Failed"},
+		{name: "job running", obj: `{"kind":"Job","status":{"active":1}}`, wantStatus: "Running", wantHealthy: true},
+		{name: "warning event", obj: `{"kind":"Event","type":"Warning","reason":"BackOff","count":7,"involvedObject":{"kind":"Pod","name":"web"}}`, wantStatus: "BackOff"},
+		{name: "normal event", obj: `{"kind":"Event","type":"Normal","reason":"Pulled","involvedObject":{"kind":"Pod","name":"web"}}`, wantStatus: "Pulled", wantHealthy: true},
+		{name: "generic", obj: `{"kind":"ConfigMap","metadata":{"name":"cm"}}`, wantStatus: "Present", wantHealthy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := mustParse(t, tt.obj)
+			classify, ok := kindClassifiers[obj["kind"].(string)]
+			if !ok {
+				classify = classifyGeneric
+			}
+			s := classify(obj)
+			if s.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, s.Status)
+			}
+			if s.Healthy != tt.wantHealthy {
+				t.Errorf("Expected healthy %v, got %v", tt.wantHealthy, s.Healthy)
+			}
+		})
+	}
+}
+
+func TestSummarizeLargePodList(t *testing.T) {
+	var items []string
+	for i := 0; i < 3000; i++ {
+		items = append(items, fmt.Sprintf(`{"kind":"Pod","metadata":{"name":"web-%d","namespace":"ns-%d","labels":{"pod-template-hash":"h1"},"ownerReferences":[{"kind":"ReplicaSet","name":"web-h1"}]},"status":{"phase":"Running","containerStatuses":[{"ready":true,"state":{"running":{}}}]}}`, i, i%40))
+	}
+	for i := 0; i < 20; i++ {
+		items = append(items, fmt.Sprintf(`{"kind":"Pod","metadata":{"name":"api-%d","namespace":"backend","labels":{"pod-template-hash":"h2"},"ownerReferences":[{"kind":"ReplicaSet","name":"api-h2"}]},"status":{"phase":"Running","containerStatuses":[{"ready":false,"state":{"waiting":{"reason":"CrashLoopBackOff"}}}]}}`, i))
+	}
+	list := mustParse(t, `{"kind":"List","items":[`+strings.Join(items, ",")+`]}`)
+
+	summary := summarize(list)
+	if summary.Total != 3020 || len(summary.Kinds) != 1 {
+		t.Fatalf("Expected 3020 pods of one kind, got %d items in %d kinds", summary.Total, len(summary.Kinds))
+	}
+
+	pods := summary.Kinds[0]
+	if pods.Healthy != 3000 {
+		t.Errorf("Expected 3000 healthy pods, got %d", pods.Healthy)
+	}
+	if pods.ByStatus["CrashLoopBackOff"] != 20 {
+		t.Errorf("Expected 20 CrashLoopBackOff pods, got %d", pods.ByStatus["CrashLoopBackOff"])
+	}
+	if len(pods.Unhealthy) != 1 {
+		t.Fatalf("Expected crash-looping pods grouped by owner, got %d groups", len(pods.Unhealthy))
+	}
+	group := pods.Unhealthy[0]
+	if group.Owner != "Deployment/api" || group.Count != 20 || len(group.Names) != maxGroupNames {
+		t.Errorf("Expected Deployment/api group of 20 with %d names, got %+v", maxGroupNames, group)
+	}
+	wantRollup := "3000/3020 Pod healthy (Running=3000) across 40 namespaces and 40 owners"
+	if pods.Rollup != wantRollup {
+		t.Errorf("Expected rollup %q, got %q", wantRollup, pods.Rollup)
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatalf("Expected summary to marshal, got %v", err)
+	}
+	if len(data) > 4096 {
+		t.Errorf("Expected a compact summary, got %d bytes", len(data))
+	}
+}
+
+func TestSummarizeEventsUsesCount(t *testing.T) {
+	list := mustParse(t, `{"kind":"List","items":[
+		{"kind":"Event","metadata":{"name":"e1","namespace":"default"},"type":"Warning","reason":"BackOff","count":5,"involvedObject":{"kind":"Pod","name":"web"}},
+		{"kind":"Event","metadata":{"name":"e2","namespace":"default"},"type":"Warning","reason":"BackOff","count":3,"involvedObject":{"kind":"Pod","name":"web"}}
+	]}`)
+
+	events := summarize(list).Kinds[0]
+	if len(events.Unhealthy) != 1 || events.Unhealthy[0].Count != 8 {
+		t.Errorf("Expected one BackOff group with count 8, got %+v", events.Unhealthy)
+	}
+}
@@ -0,0 +1,97 @@
+package kustomize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// RenderExecutor implements the CommandExecutor interface for kustomize_render
+type RenderExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures RenderExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*RenderExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*RenderExecutor)(nil)
+
+// NewExecutor creates a new RenderExecutor instance
+func NewExecutor() *RenderExecutor {
+	return &RenderExecutor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute renders the kustomization, optionally applies it, and returns the
+// report as JSON
+func (e *RenderExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts, err := parseOptions(params)
+	if err != nil {
+		return "", err
+	}
+	if opts.Apply != applyNone && cfg.SecurityConfig.AccessLevel == security.AccessLevelReadOnly {
+		return "", fmt.Errorf("apply requires readwrite or admin access level")
+	}
+
+	report, err := Render(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that kustomize_render always returns a JSON report
+func (e *RenderExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
+
+// parseOptions reads the parameters of a kustomize_render call. files is an
+// object of file names to contents, or the same object as a JSON string.
+func parseOptions(params map[string]interface{}) (Options, error) {
+	opts := Options{Files: map[string]string{}}
+	opts.Path, _ = params["path"].(string)
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.Apply, _ = params["apply"].(string)
+
+	files := map[string]interface{}{}
+	switch value := params["files"].(type) {
+	case nil:
+		return opts, fmt.Errorf("files is required")
+	case map[string]interface{}:
+		files = value
+	case string:
+		if err := json.Unmarshal([]byte(value), &files); err != nil {
+			return opts, fmt.Errorf("files must be a JSON object of file names to contents: %v", err)
+		}
+	default:
+		return opts, fmt.Errorf("files must be an object of file names to contents")
+	}
+	for name, content := range files {
+		text, ok := content.(string)
+		if !ok {
+			return opts, fmt.Errorf("content of file '%s' must be a string", name)
+		}
+		opts.Files[name] = text
+	}
+
+	switch opts.Apply {
+	case "":
+		opts.Apply = applyNone
+	case applyNone, applyDryRun, applyApply:
+	default:
+		return opts, fmt.Errorf("unsupported apply mode '%s' (supported: none, dry-run, apply)", opts.Apply)
+	}
+	if err := k8s.ValidateNamespace("namespace", opts.Namespace); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
@@ -0,0 +1,51 @@
+package kustomize
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterKustomizeRender registers the kustomize_render tool. Applying the
+// rendered objects is only offered at readwrite and admin access levels.
+func RegisterKustomizeRender(accessLevel string) mcp.Tool {
+	description := `Render an in-memory kustomization with kubectl kustomize and return the objects grouped by namespace and kind.
+
+The files are written to a temporary directory that is removed afterwards. Resources, bases and components must reference the supplied files or directories; remote references are rejected.
+With --allow-namespaces, every rendered object must be in an allowed namespace; cluster-scoped objects are reported as violations.`
+
+	opts := []mcp.ToolOption{
+		mcp.WithObject("files",
+			mcp.Required(),
+			mcp.Description(`Files of the kustomization as an object of relative paths to contents, e.g. {"kustomization.yaml": "resources:\n- deployment.yaml\n", "deployment.yaml": "..."}`),
+		),
+		mcp.WithString("path",
+			mcp.Description("Directory to build, relative to the root of the files (default: the root), e.g. overlays/prod"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace assumed for namespaced objects that render without one"),
+		),
+	}
+
+	readOnly := accessLevel == "readonly"
+	if !readOnly {
+		description += `
+Optionally sends the rendered objects to kubectl apply, as a server-side dry run or for real, one namespace at a time. Nothing is applied if any object violates the namespace allow-list.`
+		opts = append(opts,
+			mcp.WithString("apply",
+				mcp.Description("Apply the rendered objects: none (default), dry-run (server-side) or apply"),
+				mcp.Enum(applyNone, applyDryRun, applyApply),
+			),
+		)
+	}
+
+	opts = append([]mcp.ToolOption{mcp.WithDescription(description)}, opts...)
+	opts = append(opts, mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		Title:        "Render Kustomization",
+		ReadOnlyHint: boolPtr(readOnly),
+	}))
+	return mcp.NewTool("kustomize_render", opts...)
+}
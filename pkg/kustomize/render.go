@@ -0,0 +1,470 @@
+package kustomize
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"gopkg.in/yaml.v3"
+)
+
+// Limits on the in-memory kustomization
+const (
+	maxFiles     = 200
+	maxTotalSize = 2 << 20
+)
+
+// Apply modes of kustomize_render
+const (
+	applyNone   = "none"
+	applyDryRun = "dry-run"
+	applyApply  = "apply"
+)
+
+// kustomizationFileNames are the file names kustomize recognizes as the
+// kustomization of a directory
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// referenceFields are the kustomization fields that list files or
+// directories to load
+var referenceFields = []string{"resources", "bases", "components", "crds", "configurations", "generators", "transformers", "validators"}
+
+// inlineFields are the reference fields whose entries may also be inline
+// YAML configurations instead of file names
+var inlineFields = map[string]bool{"generators": true, "transformers": true, "validators": true}
+
+// Options are the parameters of a kustomize_render call
+type Options struct {
+	// Files maps paths relative to the kustomization root to file contents
+	Files map[string]string
+	// Path is the directory to build, relative to the root (default ".")
+	Path string
+	// Namespace is assumed for namespaced objects that render without one
+	Namespace string
+	// Apply is none, dry-run (server-side) or apply
+	Apply string
+}
+
+// Report is the result of kustomize_render
+type Report struct {
+	Path       string        `json:"path"`
+	Total      int           `json:"total"`
+	Groups     []ObjectGroup `json:"groups"`
+	Violations []string      `json:"violations,omitempty"`
+	Applied    []ApplyResult `json:"applied,omitempty"`
+	Manifest   string        `json:"manifest"`
+	Notes      []string      `json:"notes,omitempty"`
+}
+
+// ObjectGroup lists the rendered objects of one kind in one namespace.
+// Cluster-scoped objects have no namespace.
+type ObjectGroup struct {
+	Namespace     string   `json:"namespace,omitempty"`
+	Kind          string   `json:"kind"`
+	APIVersion    string   `json:"apiVersion"`
+	ClusterScoped bool     `json:"clusterScoped,omitempty"`
+	Names         []string `json:"names"`
+}
+
+// ApplyResult is the outcome of applying the objects of one namespace
+type ApplyResult struct {
+	Namespace string `json:"namespace,omitempty"`
+	Mode      string `json:"mode"`
+	Objects   int    `json:"objects"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// renderedObject is one object of the kustomize output
+type renderedObject struct {
+	APIVersion    string
+	Kind          string
+	Name          string
+	Namespace     string
+	ClusterScoped bool
+	Object        map[string]interface{}
+}
+
+// group returns the API group of the object's apiVersion
+func (o renderedObject) group() string {
+	if group, _, ok := strings.Cut(o.APIVersion, "/"); ok {
+		return group
+	}
+	return ""
+}
+
+// ref returns Kind/namespace/name, or Kind/name for cluster-scoped objects
+func (o renderedObject) ref() string {
+	if o.Namespace == "" {
+		return o.Kind + "/" + o.Name
+	}
+	return o.Kind + "/" + o.Namespace + "/" + o.Name
+}
+
+// Render writes the kustomization to a temporary directory, builds it with
+// kubectl kustomize and groups the rendered objects. With namespace
+// restrictions, every object is checked against the allowed namespaces, and
+// objects are only applied when none is rejected.
+func Render(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts Options) (*Report, error) {
+	dir, err := os.MkdirTemp("", "kustomize-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kustomization directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "src")
+	target, err := writeFiles(root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := runner.Run(ctx, "kustomize "+filepath.Join(root, filepath.FromSlash(target)))
+	if err != nil {
+		return nil, err
+	}
+	objects, err := parseObjects(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Path: target, Total: len(objects), Manifest: manifest}
+	clusterScoped, err := clusterScopedKinds(ctx, runner)
+	if err != nil {
+		report.Notes = append(report.Notes, "Could not list cluster-scoped API resources, so only well-known kinds are treated as cluster-scoped: "+err.Error())
+	}
+	for i := range objects {
+		o := &objects[i]
+		if clusterScoped != nil {
+			o.ClusterScoped = clusterScoped[o.group()+"/"+o.Kind]
+		} else {
+			o.ClusterScoped = security.IsClusterScopedResource(o.Kind)
+		}
+		if o.ClusterScoped {
+			o.Namespace = ""
+		} else if o.Namespace == "" {
+			o.Namespace = opts.Namespace
+		}
+	}
+	report.Groups = groupObjects(objects)
+	report.Violations = validateNamespaces(objects, secConfig)
+
+	if opts.Apply == applyDryRun || opts.Apply == applyApply {
+		if len(report.Violations) > 0 {
+			return nil, fmt.Errorf("rendered objects are not allowed by --allow-namespaces, nothing was applied: %s", strings.Join(report.Violations, "; "))
+		}
+		if err := applyObjects(ctx, runner, filepath.Join(dir, "out"), opts.Apply, objects, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// writeFiles validates the kustomization files, writes them below root and
+// returns the directory to build, relative to root
+func writeFiles(root string, opts Options) (string, error) {
+	if len(opts.Files) == 0 {
+		return "", fmt.Errorf("files is required")
+	}
+	if len(opts.Files) > maxFiles {
+		return "", fmt.Errorf("too many files: %d (limit %d)", len(opts.Files), maxFiles)
+	}
+
+	files := make(map[string]string, len(opts.Files))
+	size := 0
+	for name, content := range opts.Files {
+		clean, err := cleanPath(name)
+		if err != nil {
+			return "", err
+		}
+		if clean == "." {
+			return "", fmt.Errorf("invalid file name '%s'", name)
+		}
+		if _, ok := files[clean]; ok {
+			return "", fmt.Errorf("duplicate file '%s'", clean)
+		}
+		size += len(content)
+		if size > maxTotalSize {
+			return "", fmt.Errorf("files exceed the size limit of %d bytes", maxTotalSize)
+		}
+		files[clean] = content
+	}
+
+	target, err := cleanPath(opts.Path)
+	if err != nil {
+		return "", err
+	}
+	if kustomizationFile(files, target) == "" {
+		return "", fmt.Errorf("no kustomization.yaml, kustomization.yml or Kustomization in '%s'", target)
+	}
+	for name, content := range files {
+		if isKustomizationFile(name) {
+			if err := checkReferences(name, content, files); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o700); err != nil {
+			return "", fmt.Errorf("failed to write '%s': %w", name, err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			return "", fmt.Errorf("failed to write '%s': %w", name, err)
+		}
+	}
+	return target, nil
+}
+
+// cleanPath normalizes a slash-separated path relative to the kustomization
+// root and rejects paths that would leave it
+func cleanPath(name string) (string, error) {
+	if name == "" {
+		return ".", nil
+	}
+	if strings.ContainsAny(name, "\\\x00") || strings.HasPrefix(name, "/") || filepath.IsAbs(name) {
+		return "", fmt.Errorf("invalid path '%s': paths must be relative to the kustomization root", name)
+	}
+	clean := path.Clean(name)
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid path '%s': paths must stay inside the kustomization root", name)
+	}
+	if strings.ContainsAny(clean, " \t\n\"'") {
+		return "", fmt.Errorf("invalid path '%s': paths must not contain whitespace or quotes", name)
+	}
+	return clean, nil
+}
+
+// kustomizationFile returns the kustomization file of a directory, or an
+// empty string if it has none
+func kustomizationFile(files map[string]string, dir string) string {
+	for _, name := range kustomizationFileNames {
+		if _, ok := files[path.Join(dir, name)]; ok {
+			return path.Join(dir, name)
+		}
+	}
+	return ""
+}
+
+// isKustomizationFile reports whether a file is a kustomization
+func isKustomizationFile(name string) bool {
+	base := path.Base(name)
+	for _, n := range kustomizationFileNames {
+		if base == n {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReferences accepts only references to the supplied files and
+// directories. Anything else, such as a URL, a git repository or a path
+// outside the kustomization root, is rejected, so kustomize never loads
+// content that was not passed to the tool.
+func checkReferences(name, content string, files map[string]string) error {
+	var kustomization map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &kustomization); err != nil {
+		return fmt.Errorf("invalid kustomization '%s': %v", name, err)
+	}
+	for _, field := range referenceFields {
+		entries, _ := kustomization[field].([]interface{})
+		for _, entry := range entries {
+			ref, ok := entry.(string)
+			if !ok || (inlineFields[field] && strings.Contains(ref, "\n")) {
+				continue
+			}
+			if strings.HasPrefix(ref, "/") {
+				return fmt.Errorf("absolute %s '%s' in %s is not allowed", field, ref, name)
+			}
+			resolved := path.Join(path.Dir(name), ref)
+			if resolved == ".." || strings.HasPrefix(resolved, "../") {
+				return fmt.Errorf("%s '%s' in %s leaves the kustomization root", field, ref, name)
+			}
+			if !isSupplied(files, resolved) {
+				return fmt.Errorf("%s '%s' in %s is not a supplied file or directory; remote %s are not allowed, include the files instead", field, ref, name, field)
+			}
+		}
+	}
+	return nil
+}
+
+// isSupplied reports whether a cleaned path is one of the files or a
+// directory containing one of them
+func isSupplied(files map[string]string, name string) bool {
+	if _, ok := files[name]; ok {
+		return true
+	}
+	if name == "." {
+		return true
+	}
+	for file := range files {
+		if strings.HasPrefix(file, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseObjects decodes the YAML stream printed by kubectl kustomize
+func parseObjects(manifest string) ([]renderedObject, error) {
+	var objects []renderedObject
+	decoder := yaml.NewDecoder(bytes.NewBufferString(manifest))
+	for {
+		var object map[string]interface{}
+		err := decoder.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse rendered objects: %v", err)
+		}
+		kind, _ := object["kind"].(string)
+		if kind == "" {
+			continue
+		}
+		o := renderedObject{Kind: kind, Object: object}
+		o.APIVersion, _ = object["apiVersion"].(string)
+		if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+			o.Name, _ = metadata["name"].(string)
+			o.Namespace, _ = metadata["namespace"].(string)
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+// clusterScopedKinds returns the cluster-scoped kinds served by the API
+// server, keyed by group/kind
+func clusterScopedKinds(ctx context.Context, runner k8s.Runner) (map[string]bool, error) {
+	output, err := runner.Run(ctx, "api-resources --namespaced=false --no-headers")
+	if err != nil {
+		return nil, err
+	}
+	kinds := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		// NAME [SHORTNAMES] APIVERSION NAMESPACED KIND
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		group := ""
+		if g, _, ok := strings.Cut(fields[len(fields)-3], "/"); ok {
+			group = g
+		}
+		kinds[group+"/"+fields[len(fields)-1]] = true
+	}
+	return kinds, nil
+}
+
+// groupObjects groups objects by namespace and kind, sorted by namespace,
+// kind and name
+func groupObjects(objects []renderedObject) []ObjectGroup {
+	index := map[string]int{}
+	groups := []ObjectGroup{}
+	for _, o := range objects {
+		key := o.Namespace + "/" + o.APIVersion + "/" + o.Kind
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ObjectGroup{Namespace: o.Namespace, Kind: o.Kind, APIVersion: o.APIVersion, ClusterScoped: o.ClusterScoped})
+		}
+		groups[i].Names = append(groups[i].Names, o.Name)
+	}
+	for i := range groups {
+		sort.Strings(groups[i].Names)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Namespace != groups[j].Namespace {
+			return groups[i].Namespace < groups[j].Namespace
+		}
+		if groups[i].Kind != groups[j].Kind {
+			return groups[i].Kind < groups[j].Kind
+		}
+		return groups[i].APIVersion < groups[j].APIVersion
+	})
+	return groups
+}
+
+// validateNamespaces checks the objects against --allow-namespaces.
+// Cluster-scoped objects and namespaced objects without a namespace cannot
+// be confined to the allowed namespaces and are rejected.
+func validateNamespaces(objects []renderedObject, secConfig *security.SecurityConfig) []string {
+	if secConfig == nil || !secConfig.HasNamespaceRestrictions() {
+		return nil
+	}
+	var violations []string
+	for _, o := range objects {
+		switch {
+		case o.ClusterScoped:
+			violations = append(violations, fmt.Sprintf("%s is cluster-scoped", o.ref()))
+		case o.Namespace == "":
+			violations = append(violations, fmt.Sprintf("%s has no namespace; set namespace or the kustomization's namespace field", o.ref()))
+		case !secConfig.IsNamespaceAllowed(o.Namespace):
+			violations = append(violations, fmt.Sprintf("%s is in namespace '%s', which is not allowed", o.ref(), o.Namespace))
+		}
+	}
+	return violations
+}
+
+// applyObjects applies the rendered objects with kubectl apply, one command
+// per namespace so that each runs through the namespace validation. It
+// stops at the first failure and records it in the report.
+func applyObjects(ctx context.Context, runner k8s.Runner, dir, mode string, objects []renderedObject, report *Report) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create apply directory: %w", err)
+	}
+
+	byNamespace := map[string][]renderedObject{}
+	var namespaces []string
+	for _, o := range objects {
+		if _, ok := byNamespace[o.Namespace]; !ok {
+			namespaces = append(namespaces, o.Namespace)
+		}
+		byNamespace[o.Namespace] = append(byNamespace[o.Namespace], o)
+	}
+	sort.Strings(namespaces)
+
+	for i, namespace := range namespaces {
+		var buf bytes.Buffer
+		for _, o := range byNamespace[namespace] {
+			data, err := yaml.Marshal(o.Object)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s: %w", o.ref(), err)
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+		}
+		file := filepath.Join(dir, fmt.Sprintf("objects-%d.yaml", i))
+		if err := os.WriteFile(file, buf.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("failed to write rendered objects: %w", err)
+		}
+
+		command := "apply -f " + file
+		if namespace != "" {
+			command += " -n " + namespace
+		}
+		if mode == applyDryRun {
+			command += " --dry-run=server"
+		}
+		result := ApplyResult{Namespace: namespace, Mode: mode, Objects: len(byNamespace[namespace])}
+		output, err := runner.Run(ctx, command)
+		if err != nil {
+			result.Error = err.Error()
+			report.Applied = append(report.Applied, result)
+			return nil
+		}
+		result.Output = strings.TrimSpace(output)
+		report.Applied = append(report.Applied, result)
+	}
+	return nil
+}
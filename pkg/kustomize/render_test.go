@@ -0,0 +1,241 @@
+package kustomize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// newRunner returns a runner standing in for kubectl. kustomize checks that
+// the kustomization was written to the directory it is asked to build and
+// returns rendered; other commands match outputs by prefix.
+func newRunner(rendered string, outputs map[string]string) *k8stest.Runner {
+	return &k8stest.Runner{Outputs: outputs, MatchPrefix: true, Fallback: func(command string) (string, error) {
+		if dir, ok := strings.CutPrefix(command, "kustomize "); ok {
+			if _, err := os.Stat(filepath.Join(dir, "kustomization.yaml")); err != nil {
+				return "", fmt.Errorf("error: unable to find kustomization in %s", dir)
+			}
+			return rendered, nil
+		}
+		return k8stest.Unexpected(command)
+	}}
+}
+
+// renderedObjects is kubectl kustomize output with namespaced objects in
+// two namespaces, one without a namespace, and a cluster-scoped object
+const renderedObjects = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: team-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: team-a
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: team-a
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: team-a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: team-b
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: builder
+`
+
+// apiResources is "kubectl api-resources --namespaced=false --no-headers" output
+const apiResources = `namespaces      ns     v1                        false   Namespace
+clusterroles           rbac.authorization.k8s.io/v1   false   ClusterRole
+`
+
+// overlayFiles is a base and an overlay that uses it
+var overlayFiles = map[string]interface{}{
+	"base/kustomization.yaml":          "resources:\n- deployment.yaml\n",
+	"base/deployment.yaml":             "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+	"overlays/prod/kustomization.yaml": "namespace: team-a\nresources:\n- ../../base\n",
+}
+
+func TestWriteFilesRejectsUnsafeInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		path  string
+		err   string
+	}{
+		{"no files", nil, "", "files is required"},
+		{"absolute path", map[string]string{"/etc/kustomization.yaml": ""}, "", "must be relative"},
+		{"parent path", map[string]string{"../kustomization.yaml": ""}, "", "must stay inside"},
+		{"parent build path", map[string]string{"kustomization.yaml": ""}, "../x", "must stay inside"},
+		{"no kustomization", map[string]string{"deployment.yaml": ""}, "", "no kustomization.yaml"},
+		{"remote resource", map[string]string{"kustomization.yaml": "resources:\n- https://github.com/org/repo//deploy?ref=main\n"}, "", "remote resources"},
+		{"remote git base", map[string]string{"kustomization.yaml": "bases:\n- git@github.com:org/repo.git\n"}, "", "remote bases"},
+		{"escaping resource", map[string]string{"overlay/kustomization.yaml": "resources:\n- ../../secrets\n"}, "overlay", "leaves the kustomization root"},
+		{"absolute resource", map[string]string{"kustomization.yaml": "resources:\n- /etc/passwd\n"}, "", "absolute resources"},
+		{"unlisted remote host", map[string]string{"kustomization.yaml": "resources:\n- example.com/org/repo//deploy\n"}, "", "remote resources"},
+		{"remote component", map[string]string{"kustomization.yaml": "components:\n- ssh://git.example.com/org/repo\n"}, "", "remote components"},
+		{"missing resource", map[string]string{"kustomization.yaml": "resources:\n- deployment.yaml\n"}, "", "not a supplied file or directory"},
+		{"too large", map[string]string{"kustomization.yaml": strings.Repeat("#", maxTotalSize+1)}, "", "size limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := writeFiles(t.TempDir(), Options{Files: tt.files, Path: tt.path})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestWriteFilesAcceptsSuppliedReferences(t *testing.T) {
+	files := map[string]string{
+		"base/kustomization.yaml":              "resources:\n- deployment.yaml\n",
+		"base/deployment.yaml":                 "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+		"components/probes/kustomization.yaml": "kind: Component\n",
+		"overlays/prod/kustomization.yaml":     "resources:\n- ../../base\ncomponents:\n- ../../components/probes\ntransformers:\n- |-\n  apiVersion: builtin\n  kind: LabelTransformer\n  metadata:\n    name: env\n  labels:\n    env: prod\n",
+	}
+	if _, err := writeFiles(t.TempDir(), Options{Files: files, Path: "overlays/prod"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestRenderGroupsObjects(t *testing.T) {
+	runner := newRunner(renderedObjects, map[string]string{"api-resources": apiResources})
+	report, err := Render(context.Background(), runner, security.NewSecurityConfig(), Options{
+		Files: map[string]string{"base/kustomization.yaml": "resources: []\n", "overlays/prod/kustomization.yaml": "resources:\n- ../../base\n"},
+		Path:  "overlays/prod/",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Path != "overlays/prod" || report.Total != 6 || len(report.Violations) != 0 {
+		t.Errorf("Expected 6 objects without violations for overlays/prod, got %+v", report)
+	}
+	var groups []string
+	for _, g := range report.Groups {
+		groups = append(groups, fmt.Sprintf("%s/%s=%s", g.Namespace, g.Kind, strings.Join(g.Names, ",")))
+	}
+	expected := "/Namespace=team-a /ServiceAccount=builder team-a/ConfigMap=web-config team-a/Deployment=api,web team-b/Service=web"
+	if strings.Join(groups, " ") != expected {
+		t.Errorf("Expected groups %s, got %s", expected, strings.Join(groups, " "))
+	}
+	if !report.Groups[0].ClusterScoped || report.Groups[1].ClusterScoped {
+		t.Errorf("Expected only the Namespace to be cluster-scoped, got %+v", report.Groups)
+	}
+	if _, err := os.Stat(strings.TrimPrefix(runner.Commands()[0], "kustomize ")); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary directory to be removed, got %v", err)
+	}
+}
+
+func TestRenderNamespaceViolations(t *testing.T) {
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("team-a")
+	runner := newRunner(renderedObjects, map[string]string{})
+
+	report, err := Render(context.Background(), runner, secConfig, Options{Files: map[string]string{"kustomization.yaml": ""}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{
+		"Namespace/team-a is cluster-scoped",
+		"Service/team-b/web is in namespace 'team-b', which is not allowed",
+		"ServiceAccount/builder has no namespace; set namespace or the kustomization's namespace field",
+	}
+	if strings.Join(report.Violations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected violations %v, got %v", expected, report.Violations)
+	}
+	if len(report.Notes) != 1 {
+		t.Errorf("Expected a note about falling back to well-known cluster-scoped kinds, got %v", report.Notes)
+	}
+
+	_, err = Render(context.Background(), runner, secConfig, Options{Files: map[string]string{"kustomization.yaml": ""}, Apply: applyDryRun})
+	if err == nil || !strings.Contains(err.Error(), "nothing was applied") {
+		t.Errorf("Expected apply to be refused, got %v", err)
+	}
+	for _, command := range runner.Commands() {
+		if strings.HasPrefix(command, "apply") {
+			t.Errorf("Expected no apply command, got %s", command)
+		}
+	}
+}
+
+func TestExecutorApply(t *testing.T) {
+	runner := newRunner("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: team-a\n", map[string]string{
+		"api-resources": apiResources,
+		"apply":         "deployment.apps/web created (server dry run)\n",
+	})
+	executor := &RenderExecutor{newRunner: func(cfg *config.ConfigData) k8s.Runner { return runner }}
+	params := map[string]interface{}{"files": overlayFiles, "path": "overlays/prod", "apply": applyDryRun}
+
+	cfg := config.NewConfig()
+	cfg.SecurityConfig.AccessLevel = security.AccessLevelReadOnly
+	if _, err := executor.Execute(context.Background(), params, cfg); err == nil || !strings.Contains(err.Error(), "readwrite") {
+		t.Errorf("Expected apply to require readwrite, got %v", err)
+	}
+
+	cfg.SecurityConfig.AccessLevel = security.AccessLevelReadWrite
+	result, err := executor.Execute(context.Background(), params, cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(result), &report); err != nil {
+		t.Fatalf("Expected JSON report, got %v", err)
+	}
+	if len(report.Applied) != 1 || report.Applied[0].Namespace != "team-a" || report.Applied[0].Output != "deployment.apps/web created (server dry run)" {
+		t.Errorf("Expected one dry run for team-a, got %+v", report.Applied)
+	}
+	commands := runner.Commands()
+	last := commands[len(commands)-1]
+	if !strings.HasPrefix(last, "apply -f ") || !strings.HasSuffix(last, " -n team-a --dry-run=server") {
+		t.Errorf("Expected a server-side dry run in team-a, got %s", last)
+	}
+
+	params["apply"] = "force"
+	if _, err := executor.Execute(context.Background(), params, cfg); err == nil || !strings.Contains(err.Error(), "unsupported apply mode") {
+		t.Errorf("Expected an unsupported apply mode error, got %v", err)
+	}
+}
+
+func TestRegisterKustomizeRender(t *testing.T) {
+	readOnly := RegisterKustomizeRender("readonly")
+	if _, ok := readOnly.InputSchema.Properties["apply"]; ok {
+		t.Errorf("Expected no apply parameter at readonly")
+	}
+	if !*readOnly.Annotations.ReadOnlyHint {
+		t.Errorf("Expected the readonly tool to be marked read-only")
+	}
+
+	readWrite := RegisterKustomizeRender("readwrite")
+	if _, ok := readWrite.InputSchema.Properties["apply"]; !ok {
+		t.Errorf("Expected an apply parameter at readwrite")
+	}
+	if *readWrite.Annotations.ReadOnlyHint {
+		t.Errorf("Expected the readwrite tool not to be marked read-only")
+	}
+}
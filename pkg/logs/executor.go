@@ -0,0 +1,73 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// Executor implements the CommandExecutor interface for search_logs
+type Executor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures Executor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*Executor)(nil)
+var _ tools.StructuredOutputExecutor = (*Executor)(nil)
+
+// NewExecutor creates a new Executor instance
+func NewExecutor() *Executor {
+	return &Executor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute searches the logs of the selected pods and returns the report as JSON
+func (e *Executor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := Options{}
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.Kind, _ = params["kind"].(string)
+	opts.Name, _ = params["name"].(string)
+	opts.LabelSelector, _ = params["label_selector"].(string)
+	opts.Container, _ = params["container"].(string)
+	opts.Pattern, _ = params["pattern"].(string)
+	opts.Previous, _ = params["previous"].(bool)
+	if since, ok := params["since"].(string); ok && since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil || d <= 0 {
+			return "", fmt.Errorf("invalid since '%s': expected a positive duration such as 30m or 2h", since)
+		}
+		opts.Since = d
+	}
+	if tailLines, ok := params["tail_lines"].(float64); ok {
+		opts.TailLines = int(tailLines)
+	}
+	if maxMatches, ok := params["max_matches"].(float64); ok {
+		opts.MaxMatches = int(maxMatches)
+	}
+
+	if err := errors.Join(k8s.ValidateNamespace("namespace", opts.Namespace), k8s.ValidateName("name", opts.Name), k8s.ValidateNamespace("container", opts.Container)); err != nil {
+		return "", err
+	}
+
+	report, err := Search(ctx, e.newRunner(cfg), opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal log search report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that search_logs returns JSON
+func (e *Executor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
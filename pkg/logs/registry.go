@@ -0,0 +1,59 @@
+package logs
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterSearchLogs registers the search_logs tool
+func RegisterSearchLogs() mcp.Tool {
+	return mcp.NewTool("search_logs",
+		mcp.WithDescription(`Search the logs of every pod and container of a workload or label selector at once, instead of running kubectl logs one container at a time.
+
+Fetches the logs of the selected containers in parallel (at most 5 at a time, 50 containers in total) and returns:
+- the lines matching a regular expression, or lines that look like errors when no pattern is given, attributed to their pod and container
+- stack traces (Java, .NET, Node.js, Python, Go) clustered by error and innermost frame
+- the most repeated error messages, with numbers, IDs and addresses normalized
+
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the pods"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the workload: Deployment, StatefulSet, DaemonSet, ReplicaSet, Job or Pod (used with name)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of the workload"),
+		),
+		mcp.WithString("label_selector",
+			mcp.Description("Label selector of the pods instead of a workload, e.g. app=web,tier!=db"),
+		),
+		mcp.WithString("container",
+			mcp.Description("Only search this container"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Log window, e.g. 15m or 2h (default: 1h)"),
+		),
+		mcp.WithString("pattern",
+			mcp.Description("Regular expression (RE2 syntax) to match; by default lines that look like errors are matched"),
+		),
+		mcp.WithBoolean("previous",
+			mcp.Description("Search the logs of the previous, crashed container instances"),
+		),
+		mcp.WithNumber("tail_lines",
+			mcp.Description("Lines read per container (default: 1000, max: 10000)"),
+		),
+		mcp.WithNumber("max_matches",
+			mcp.Description("Maximum number of matching lines returned; the most recent are kept (default: 100, max: 1000)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Search Logs",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,387 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+const (
+	// defaultSince is the log window when since is not set
+	defaultSince = time.Hour
+	// defaultTailLines is the number of lines read per container
+	defaultTailLines = 1000
+	// maxTailLines caps tail_lines
+	maxTailLines = 10000
+	// defaultMaxMatches is the number of matches returned when max_matches is not set
+	defaultMaxMatches = 100
+	// maxMaxMatches caps max_matches
+	maxMaxMatches = 1000
+	// maxConcurrency is the number of log requests run at once
+	maxConcurrency = 5
+	// maxContainers caps the containers searched, so a large DaemonSet
+	// does not fetch the logs of every node
+	maxContainers = 50
+	// maxLineLength truncates long log lines in the report
+	maxLineLength = 500
+)
+
+// labelSelectorPattern restricts label selectors to equality and
+// inequality requirements, which kubectl takes as a single argument
+var labelSelectorPattern = regexp.MustCompile(`^[A-Za-z0-9._/=!,-]+$`)
+
+// workloadResources map the accepted kind names to the kind and resource of
+// each supported workload
+var workloadResources = map[string]struct{ Kind, Resource string }{
+	"deployment":  {"Deployment", "deployments.apps"},
+	"deploy":      {"Deployment", "deployments.apps"},
+	"statefulset": {"StatefulSet", "statefulsets.apps"},
+	"sts":         {"StatefulSet", "statefulsets.apps"},
+	"daemonset":   {"DaemonSet", "daemonsets.apps"},
+	"ds":          {"DaemonSet", "daemonsets.apps"},
+	"replicaset":  {"ReplicaSet", "replicasets.apps"},
+	"rs":          {"ReplicaSet", "replicasets.apps"},
+	"job":         {"Job", "jobs.batch"},
+	"pod":         {"Pod", "pods"},
+	"po":          {"Pod", "pods"},
+}
+
+// Options are the parameters of search_logs
+type Options struct {
+	Namespace string
+	// Kind and Name select a workload; LabelSelector selects pods by label
+	Kind          string
+	Name          string
+	LabelSelector string
+	// Container restricts the search to one container name
+	Container string
+	// Since is the log window (default: 1h)
+	Since time.Duration
+	// Pattern is a regular expression; without it, lines that look like
+	// errors are matched
+	Pattern string
+	// Previous searches the logs of the previous container instances
+	Previous   bool
+	TailLines  int
+	MaxMatches int
+}
+
+// Report is the result of search_logs
+type Report struct {
+	Namespace string   `json:"namespace"`
+	Target    string   `json:"target"`
+	Since     string   `json:"since"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Previous  bool     `json:"previous,omitempty"`
+	Sources   []Source `json:"sources"`
+	// TotalMatches counts every matching line; Matches holds the most
+	// recent ones
+	TotalMatches int         `json:"totalMatches"`
+	Matches      []Match     `json:"matches"`
+	Truncated    bool        `json:"truncated,omitempty"`
+	StackTraces  []Signature `json:"stackTraces"`
+	TopMessages  []Signature `json:"topMessages"`
+	Notes        []string    `json:"notes,omitempty"`
+}
+
+// Source is one container whose logs were searched
+type Source struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Lines     int    `json:"lines"`
+	Matches   int    `json:"matches"`
+	// Skipped explains why the logs were not fetched
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Match is a log line that matched the search
+type Match struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Line      string `json:"line"`
+
+	time time.Time
+}
+
+// line is a log line split from its kubectl timestamp
+type line struct {
+	time time.Time
+	text string
+}
+
+// Search fetches the logs of the containers of the selected pods in
+// parallel and returns the matching lines and the clustered error
+// signatures
+func Search(ctx context.Context, runner k8s.Runner, opts Options) (*Report, error) {
+	if err := opts.setDefaults(); err != nil {
+		return nil, err
+	}
+	var pattern *regexp.Regexp
+	if opts.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(opts.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	target, pods, err := selectPods(ctx, runner, opts)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{
+		Namespace:   opts.Namespace,
+		Target:      target,
+		Since:       opts.Since.String(),
+		Pattern:     opts.Pattern,
+		Previous:    opts.Previous,
+		Sources:     []Source{},
+		Matches:     []Match{},
+		StackTraces: []Signature{},
+		TopMessages: []Signature{},
+	}
+	if len(pods) == 0 {
+		report.Notes = append(report.Notes, "No pods match "+target)
+		return report, nil
+	}
+
+	report.Sources = containerSources(pods, opts)
+	if len(report.Sources) == 0 {
+		return nil, fmt.Errorf("container '%s' not found in the pods of %s", opts.Container, target)
+	}
+	fetched, capped := 0, false
+	for i := range report.Sources {
+		if report.Sources[i].Skipped != "" {
+			continue
+		}
+		if fetched == maxContainers {
+			report.Sources[i].Skipped = fmt.Sprintf("more than %d containers selected", maxContainers)
+			capped = true
+			continue
+		}
+		fetched++
+	}
+	if capped {
+		report.Notes = append(report.Notes, fmt.Sprintf("Only the first %d containers were searched; narrow the selection with container or label_selector", maxContainers))
+	}
+
+	logs := make([][]line, len(report.Sources))
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i := range report.Sources {
+		source := &report.Sources[i]
+		if source.Skipped != "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			command := fmt.Sprintf("logs %s -n %s -c %s --timestamps --since=%s --tail=%d", source.Pod, opts.Namespace, source.Container, opts.Since, opts.TailLines)
+			if opts.Previous {
+				command += " --previous"
+			}
+			output, err := runner.Run(ctx, command)
+			if err != nil {
+				source.Error = err.Error()
+				return
+			}
+			logs[i] = splitLines(output)
+		}()
+	}
+	wg.Wait()
+
+	signatures := newSignatureSet()
+	for i, source := range report.Sources {
+		lines := logs[i]
+		report.Sources[i].Lines = len(lines)
+		for _, l := range lines {
+			var matched bool
+			if pattern != nil {
+				matched = pattern.MatchString(l.text)
+			} else {
+				matched = isErrorLine(l.text)
+			}
+			if !matched {
+				continue
+			}
+			report.Sources[i].Matches++
+			report.Matches = append(report.Matches, Match{
+				Timestamp: formatTime(l.time),
+				Pod:       source.Pod,
+				Container: source.Container,
+				Line:      truncate(l.text, maxLineLength),
+				time:      l.time,
+			})
+		}
+		signatures.add(source.Pod+"/"+source.Container, lines)
+	}
+
+	report.TotalMatches = len(report.Matches)
+	sort.SliceStable(report.Matches, func(i, j int) bool {
+		return report.Matches[i].time.Before(report.Matches[j].time)
+	})
+	if len(report.Matches) > opts.MaxMatches {
+		report.Matches = report.Matches[len(report.Matches)-opts.MaxMatches:]
+		report.Truncated = true
+	}
+	report.StackTraces, report.TopMessages = signatures.top()
+	return report, nil
+}
+
+// setDefaults validates the options and fills in the defaults
+func (o *Options) setDefaults() error {
+	if o.Namespace == "" {
+		return fmt.Errorf("namespace is required")
+	}
+	if (o.Name == "") == (o.LabelSelector == "") {
+		return fmt.Errorf("either kind and name, or label_selector is required")
+	}
+	if o.Name != "" && o.Kind == "" {
+		return fmt.Errorf("kind is required with name")
+	}
+	if o.LabelSelector != "" && !labelSelectorPattern.MatchString(o.LabelSelector) {
+		return fmt.Errorf("invalid label_selector '%s': use equality requirements such as app=web,tier!=db", o.LabelSelector)
+	}
+	if o.Since == 0 {
+		o.Since = defaultSince
+	}
+	if o.Since < 0 {
+		return fmt.Errorf("since must be a positive duration")
+	}
+	switch {
+	case o.TailLines == 0:
+		o.TailLines = defaultTailLines
+	case o.TailLines < 0 || o.TailLines > maxTailLines:
+		return fmt.Errorf("tail_lines must be between 1 and %d", maxTailLines)
+	}
+	switch {
+	case o.MaxMatches == 0:
+		o.MaxMatches = defaultMaxMatches
+	case o.MaxMatches < 0 || o.MaxMatches > maxMaxMatches:
+		return fmt.Errorf("max_matches must be between 1 and %d", maxMaxMatches)
+	}
+	return nil
+}
+
+// selectPods returns a description of the selection and the selected pods,
+// sorted by name
+func selectPods(ctx context.Context, runner k8s.Runner, opts Options) (string, []k8s.Pod, error) {
+	var pods []k8s.Pod
+	var target string
+	if opts.LabelSelector != "" {
+		target = "pods with labels " + opts.LabelSelector
+		var list k8s.List[k8s.Pod]
+		if err := k8s.GetJSON(ctx, runner, fmt.Sprintf("get pods -n %s -l %s", opts.Namespace, opts.LabelSelector), &list); err != nil {
+			return "", nil, err
+		}
+		pods = list.Items
+	} else {
+		workload, ok := workloadResources[strings.ToLower(opts.Kind)]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported kind '%s': expected Deployment, StatefulSet, DaemonSet, ReplicaSet, Job or Pod", opts.Kind)
+		}
+		target = workload.Kind + "/" + opts.Name
+		if workload.Kind == "Pod" {
+			var pod k8s.Pod
+			if err := k8s.GetJSON(ctx, runner, fmt.Sprintf("get pod %s -n %s", opts.Name, opts.Namespace), &pod); err != nil {
+				return "", nil, err
+			}
+			pods = append(pods, pod)
+		} else {
+			var object struct {
+				Spec struct {
+					Selector *k8s.LabelSelector `json:"selector"`
+				} `json:"spec"`
+			}
+			if err := k8s.GetJSON(ctx, runner, fmt.Sprintf("get %s %s -n %s", workload.Resource, opts.Name, opts.Namespace), &object); err != nil {
+				return "", nil, err
+			}
+			if object.Spec.Selector == nil || object.Spec.Selector.IsEmpty() {
+				return "", nil, fmt.Errorf("%s has no pod selector", target)
+			}
+			var list k8s.List[k8s.Pod]
+			if err := k8s.GetJSON(ctx, runner, "get pods -n "+opts.Namespace, &list); err != nil {
+				return "", nil, err
+			}
+			for _, pod := range list.Items {
+				if object.Spec.Selector.Matches(pod.Metadata.Labels) {
+					pods = append(pods, pod)
+				}
+			}
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Metadata.Name < pods[j].Metadata.Name })
+	return target, pods, nil
+}
+
+// containerSources lists the init and regular containers of the pods.
+// Containers that never started, or that have no previous instance when
+// previous logs are requested, are skipped.
+func containerSources(pods []k8s.Pod, opts Options) []Source {
+	var sources []Source
+	for _, pod := range pods {
+		statuses := map[string]k8s.ContainerStatus{}
+		for _, status := range append(append([]k8s.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			statuses[status.Name] = status
+		}
+		for _, container := range append(append([]k8s.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+			if opts.Container != "" && container.Name != opts.Container {
+				continue
+			}
+			source := Source{Pod: pod.Metadata.Name, Container: container.Name}
+			status, ok := statuses[container.Name]
+			switch {
+			case opts.Previous && status.RestartCount == 0:
+				source.Skipped = "no previous instance"
+			case !ok || (status.State.Waiting != nil && status.RestartCount == 0):
+				source.Skipped = "not started"
+			}
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// splitLines splits kubectl --timestamps output into lines
+func splitLines(output string) []line {
+	var lines []line
+	for _, raw := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		raw = strings.TrimSuffix(raw, "\r")
+		if raw == "" {
+			continue
+		}
+		l := line{text: raw}
+		if stamp, text, ok := strings.Cut(raw, " "); ok {
+			if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+				l.time, l.text = t, text
+			}
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// formatTime renders a time as RFC 3339, or "" for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
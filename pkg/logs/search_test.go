@@ -0,0 +1,190 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+)
+
+const javaLogs = `2026-03-01T10:00:00.000000001Z INFO Started checkout in 3.2 seconds
+2026-03-01T10:01:00Z ERROR Request 1b4e28ba-2fa1-11d2-883f-0016d3cca427 failed: connection refused to 10.0.0.12:5432
+2026-03-01T10:02:00Z ERROR Unhandled exception
+2026-03-01T10:02:00Z java.lang.NullPointerException: Cannot invoke "Order.total()" because "order" is null
+2026-03-01T10:02:00Z     at com.shop.Checkout.price(Checkout.java:42)
+2026-03-01T10:02:00Z     at com.shop.Checkout.handle(Checkout.java:17)
+2026-03-01T10:03:00Z ERROR Request 9a1e28ba-2fa1-11d2-883f-0016d3cca427 failed: connection refused to 10.0.0.13:5432
+`
+
+const otherJavaLogs = `2026-03-01T10:04:00Z java.lang.NullPointerException: Cannot invoke "Order.total()" because "order" is null
+2026-03-01T10:04:00Z     at com.shop.Checkout.price(Checkout.java:42)
+2026-03-01T10:04:00Z     ... 12 more
+2026-03-01T10:05:00Z ERROR Request 0c1e28ba-2fa1-11d2-883f-0016d3cca427 failed: connection refused to 10.0.0.12:5432
+`
+
+func checkoutRunner() *k8stest.Runner {
+	return &k8stest.Runner{Outputs: map[string]string{
+		"get deployments.apps checkout -n shop -o json": `{"spec": {"selector": {"matchLabels": {"app": "checkout"}}}}`,
+		"get pods -n shop -o json": `{"items": [
+			{"metadata": {"name": "checkout-b", "labels": {"app": "checkout"}}, "spec": {"containers": [{"name": "app"}]},
+			 "status": {"containerStatuses": [{"name": "app", "restartCount": 2, "state": {"running": {}}}]}},
+			{"metadata": {"name": "checkout-a", "labels": {"app": "checkout"}}, "spec": {"initContainers": [{"name": "migrate"}], "containers": [{"name": "app"}, {"name": "proxy"}]},
+			 "status": {"initContainerStatuses": [{"name": "migrate", "state": {"terminated": {"exitCode": 0}}}],
+			            "containerStatuses": [{"name": "app", "state": {"running": {}}}, {"name": "proxy", "state": {"waiting": {"reason": "ContainerCreating"}}}]}},
+			{"metadata": {"name": "cart-a", "labels": {"app": "cart"}}, "spec": {"containers": [{"name": "app"}]}}]}`,
+		"logs checkout-a -n shop -c migrate --timestamps --since=1h0m0s --tail=1000": "2026-03-01T09:59:00Z migrations applied\n",
+		"logs checkout-a -n shop -c app --timestamps --since=1h0m0s --tail=1000":     javaLogs,
+		"logs checkout-b -n shop -c app --timestamps --since=1h0m0s --tail=1000":     otherJavaLogs,
+	}}
+}
+
+func TestSearchWorkload(t *testing.T) {
+	report, err := Search(context.Background(), checkoutRunner(), Options{Namespace: "shop", Kind: "deploy", Name: "checkout"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Target != "Deployment/checkout" || report.Since != "1h0m0s" {
+		t.Errorf("Expected Deployment/checkout over 1h0m0s, got %s over %s", report.Target, report.Since)
+	}
+
+	var sources []string
+	for _, s := range report.Sources {
+		sources = append(sources, fmt.Sprintf("%s/%s:%d:%s", s.Pod, s.Container, s.Lines, s.Skipped))
+	}
+	expected := "checkout-a/migrate:1:,checkout-a/app:7:,checkout-a/proxy:0:not started,checkout-b/app:4:"
+	if got := strings.Join(sources, ","); got != expected {
+		t.Errorf("Expected sources %s, got %s", expected, got)
+	}
+
+	if report.TotalMatches != 6 || len(report.Matches) != 6 {
+		t.Fatalf("Expected 6 error lines, got %d: %+v", report.TotalMatches, report.Matches)
+	}
+	last := report.Matches[5]
+	if last.Pod != "checkout-b" || last.Container != "app" || last.Timestamp != "2026-03-01T10:05:00Z" || !strings.HasPrefix(last.Line, "ERROR Request") {
+		t.Errorf("Expected the matches in time order with attribution, got %+v", last)
+	}
+
+	if len(report.StackTraces) != 1 {
+		t.Fatalf("Expected 1 stack trace signature, got %+v", report.StackTraces)
+	}
+	trace := report.StackTraces[0]
+	if trace.Count != 2 || len(trace.Sources) != 2 || !strings.Contains(trace.Signature, "NullPointerException") || !strings.Contains(trace.Signature, "Checkout.price") {
+		t.Errorf("Expected the NullPointerException clustered across both pods, got %+v", trace)
+	}
+	if !strings.Contains(trace.Sample, "at com.shop.Checkout.handle") {
+		t.Errorf("Expected the first trace as sample, got %s", trace.Sample)
+	}
+
+	if len(report.TopMessages) != 2 {
+		t.Fatalf("Expected 2 message signatures, got %+v", report.TopMessages)
+	}
+	top := report.TopMessages[0]
+	if top.Count != 3 || top.Signature != "ERROR Request <uuid> failed: connection refused to <ip>" || top.FirstSeen != "2026-03-01T10:01:00Z" || top.LastSeen != "2026-03-01T10:05:00Z" {
+		t.Errorf("Expected the normalized connection error 3 times, got %+v", top)
+	}
+}
+
+func TestSearchPatternPreviousAndLimits(t *testing.T) {
+	runner := checkoutRunner()
+	runner.Outputs["logs checkout-b -n shop -c app --timestamps --since=15m0s --tail=200 --previous"] = otherJavaLogs
+	report, err := Search(context.Background(), runner, Options{
+		Namespace: "shop", Kind: "Deployment", Name: "checkout", Container: "app", Pattern: `connection refused|Checkout\.java`,
+		Previous: true, Since: 15 * 60e9, TailLines: 200, MaxMatches: 2,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Sources) != 2 || report.Sources[0].Skipped != "no previous instance" || report.Sources[1].Lines != 4 {
+		t.Errorf("Expected only checkout-b to have previous logs, got %+v", report.Sources)
+	}
+	if report.TotalMatches != 2 || report.Truncated || report.Matches[0].Line != "    at com.shop.Checkout.price(Checkout.java:42)" {
+		t.Errorf("Expected 2 pattern matches, got %+v", report.Matches)
+	}
+
+	report, err = Search(context.Background(), checkoutRunner(), Options{Namespace: "shop", Kind: "deploy", Name: "checkout", MaxMatches: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.TotalMatches != 6 || !report.Truncated || len(report.Matches) != 2 || report.Matches[1].Timestamp != "2026-03-01T10:05:00Z" {
+		t.Errorf("Expected the 2 most recent of 6 matches, got %d %+v", report.TotalMatches, report.Matches)
+	}
+}
+
+func TestSearchLabelSelectorAndErrors(t *testing.T) {
+	runner := &k8stest.Runner{
+		Outputs: map[string]string{
+			"get pods -n shop -l app=cart -o json": `{"items": [{"metadata": {"name": "cart-a"}, "spec": {"containers": [{"name": "app"}]}, "status": {"containerStatuses": [{"name": "app", "state": {"running": {}}}]}}]}`,
+		},
+		Errors: map[string]error{
+			"logs cart-a -n shop -c app --timestamps --since=1h0m0s --tail=1000": fmt.Errorf("Error from server (Forbidden)"),
+		},
+	}
+	report, err := Search(context.Background(), runner, Options{Namespace: "shop", LabelSelector: "app=cart"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Sources) != 1 || report.Sources[0].Error == "" {
+		t.Errorf("Expected the log error on the source, got %+v", report.Sources)
+	}
+
+	testCases := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"missing namespace", Options{Kind: "pod", Name: "web"}, "namespace is required"},
+		{"no selection", Options{Namespace: "shop"}, "either kind and name"},
+		{"both selections", Options{Namespace: "shop", Kind: "pod", Name: "web", LabelSelector: "app=web"}, "either kind and name"},
+		{"unsafe selector", Options{Namespace: "shop", LabelSelector: "app=web --kubeconfig=/tmp/x"}, "invalid label_selector"},
+		{"invalid pattern", Options{Namespace: "shop", LabelSelector: "app=web", Pattern: "(unclosed"}, "invalid pattern"},
+		{"unsupported kind", Options{Namespace: "shop", Kind: "service", Name: "web"}, "unsupported kind"},
+		{"tail lines", Options{Namespace: "shop", LabelSelector: "app=web", TailLines: 50000}, "tail_lines"},
+		{"unknown container", Options{Namespace: "shop", Kind: "deploy", Name: "checkout", Container: "sidecar"}, "container 'sidecar' not found"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Search(context.Background(), checkoutRunner(), tc.opts)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected error containing '%s', got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestStackTraceDetection(t *testing.T) {
+	testCases := []struct {
+		name      string
+		logs      string
+		lines     int
+		signature string
+	}{
+		{
+			name:      "python",
+			logs:      "Traceback (most recent call last):\n  File \"/app/main.py\", line 10, in <module>\n    main()\n  File \"/app/main.py\", line 6, in main\n    raise ValueError(\"bad input 42\")\nValueError: bad input 42\nnext line",
+			lines:     6,
+			signature: "ValueError: bad input <n> | File \"/app/main.py\", line <n>, in main",
+		},
+		{
+			name:      "go panic",
+			logs:      "panic: runtime error: invalid memory address or nil pointer dereference\n[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4a2b1c]\ngoroutine 1 [running]:\nmain.(*Server).handle(0x0)\n\t/app/server.go:55 +0x1c\nmain.main()\n\t/app/main.go:12 +0x25\nexit status 2",
+			lines:     7,
+			signature: "panic: runtime error: invalid memory address or nil pointer dereference | main.(*Server).handle(<hex>)",
+		},
+		{
+			name:      "plain error",
+			logs:      "error: something broke\nnext line",
+			lines:     0,
+			signature: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, signature := stackTrace(splitLines(tc.logs), 0)
+			if n != tc.lines || signature != tc.signature {
+				t.Errorf("Expected %d lines with signature %q, got %d with %q", tc.lines, tc.signature, n, signature)
+			}
+		})
+	}
+}
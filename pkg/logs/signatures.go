@@ -0,0 +1,205 @@
+package logs
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSignatures caps the stack traces and messages reported
+	maxSignatures = 10
+	// maxSampleLines caps the lines of a stack trace sample
+	maxSampleLines = 20
+	// maxSignatureLength truncates normalized signatures
+	maxSignatureLength = 200
+)
+
+var (
+	errorLinePattern = regexp.MustCompile(`(?i)\b(error|exception|fatal|panic|critical|failed|failure|traceback)\b|level=(error|fatal|crit)|"level":\s*"(error|fatal|critical)"|(?-i:[a-z]\w*(Exception|Error))\b`)
+	javaFramePattern = regexp.MustCompile(`^\s+at\s`)
+	javaTailPattern  = regexp.MustCompile(`^\s*(Caused by:|Suppressed:|\.\.\. \d+ more)`)
+	goroutinePattern = regexp.MustCompile(`^goroutine \d+ \[`)
+	goFuncPattern    = regexp.MustCompile(`^[\w./*()\[\]-]+\(.*\)$`)
+
+	uuidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	ipPattern     = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`)
+	hexPattern    = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]{16,}\b`)
+	numberPattern = regexp.MustCompile(`\d+`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// Signature is a stack trace or error message that repeats in the logs,
+// with variable parts such as numbers, IDs and addresses normalized
+type Signature struct {
+	Signature string `json:"signature"`
+	Count     int    `json:"count"`
+	// Sources are the pod/container pairs the signature was seen in
+	Sources   []string `json:"sources"`
+	Sample    string   `json:"sample"`
+	FirstSeen string   `json:"firstSeen,omitempty"`
+	LastSeen  string   `json:"lastSeen,omitempty"`
+
+	sources     map[string]bool
+	first, last time.Time
+}
+
+// signatureSet clusters the stack traces and error messages of the logs
+type signatureSet struct {
+	stacks   map[string]*Signature
+	messages map[string]*Signature
+}
+
+func newSignatureSet() *signatureSet {
+	return &signatureSet{stacks: map[string]*Signature{}, messages: map[string]*Signature{}}
+}
+
+// add clusters the lines of one container. Lines that belong to a stack
+// trace are not counted as messages.
+func (s *signatureSet) add(source string, lines []line) {
+	for i := 0; i < len(lines); {
+		if n, key := stackTrace(lines, i); n > 0 {
+			sample := make([]string, 0, n)
+			for _, l := range lines[i : i+n] {
+				if len(sample) == maxSampleLines {
+					sample = append(sample, "...")
+					break
+				}
+				sample = append(sample, l.text)
+			}
+			record(s.stacks, key, source, strings.Join(sample, "\n"), lines[i].time)
+			i += n
+			continue
+		}
+		if isErrorLine(lines[i].text) {
+			record(s.messages, normalize(lines[i].text), source, truncate(lines[i].text, maxLineLength), lines[i].time)
+		}
+		i++
+	}
+}
+
+// top returns the most frequent stack traces and messages
+func (s *signatureSet) top() (stacks, messages []Signature) {
+	return ranked(s.stacks), ranked(s.messages)
+}
+
+// record counts an occurrence of a signature; the first sample is kept
+func record(set map[string]*Signature, key, source, sample string, t time.Time) {
+	sig, ok := set[key]
+	if !ok {
+		sig = &Signature{Signature: key, Sample: sample, sources: map[string]bool{}}
+		set[key] = sig
+	}
+	sig.Count++
+	sig.sources[source] = true
+	if !t.IsZero() && (sig.first.IsZero() || t.Before(sig.first)) {
+		sig.first = t
+	}
+	if t.After(sig.last) {
+		sig.last = t
+	}
+}
+
+// ranked sorts signatures by count and keeps the first maxSignatures
+func ranked(set map[string]*Signature) []Signature {
+	signatures := make([]Signature, 0, len(set))
+	for _, sig := range set {
+		for source := range sig.sources {
+			sig.Sources = append(sig.Sources, source)
+		}
+		sort.Strings(sig.Sources)
+		sig.FirstSeen, sig.LastSeen = formatTime(sig.first), formatTime(sig.last)
+		signatures = append(signatures, *sig)
+	}
+	sort.Slice(signatures, func(i, j int) bool {
+		if signatures[i].Count != signatures[j].Count {
+			return signatures[i].Count > signatures[j].Count
+		}
+		return signatures[i].Signature < signatures[j].Signature
+	})
+	if len(signatures) > maxSignatures {
+		signatures = signatures[:maxSignatures]
+	}
+	return signatures
+}
+
+// stackTrace detects a Python, Go or Java-style (Java, .NET, Node.js)
+// stack trace starting at lines[i]. It returns the number of lines of the
+// trace, or 0, and a signature made of the error and its innermost
+// application frame.
+func stackTrace(lines []line, i int) (int, string) {
+	text := lines[i].text
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(text), "Traceback (most recent call last)"):
+		j, frame := i+1, ""
+		for ; j < len(lines) && isIndented(lines[j].text); j++ {
+			if trimmed := strings.TrimSpace(lines[j].text); strings.HasPrefix(trimmed, "File ") {
+				frame = trimmed
+			}
+		}
+		headline := text
+		if j < len(lines) {
+			headline = lines[j].text
+			j++
+		}
+		return j - i, signature(headline, frame)
+
+	case strings.HasPrefix(text, "panic: ") || goroutinePattern.MatchString(text):
+		j, frame, inGoroutine := i+1, "", goroutinePattern.MatchString(text)
+		for ; j < len(lines); j++ {
+			l := lines[j].text
+			if goroutinePattern.MatchString(l) {
+				inGoroutine = true
+				continue
+			}
+			if !isIndented(l) && !goFuncPattern.MatchString(l) && !strings.HasPrefix(l, "created by ") && !strings.HasPrefix(l, "[signal ") {
+				break
+			}
+			if inGoroutine && frame == "" && goFuncPattern.MatchString(l) && !strings.HasPrefix(l, "panic(") && !strings.HasPrefix(l, "runtime.") {
+				frame = l
+			}
+		}
+		if j == i+1 && !strings.HasPrefix(text, "panic: ") {
+			return 0, ""
+		}
+		return j - i, signature(text, frame)
+
+	case !isIndented(text) && i+1 < len(lines) && javaFramePattern.MatchString(lines[i+1].text):
+		j := i + 1
+		for j < len(lines) && (javaFramePattern.MatchString(lines[j].text) || javaTailPattern.MatchString(lines[j].text)) {
+			j++
+		}
+		return j - i, signature(text, strings.TrimSpace(lines[i+1].text))
+	}
+	return 0, ""
+}
+
+// signature combines a normalized error and frame
+func signature(headline, frame string) string {
+	if frame == "" {
+		return normalize(headline)
+	}
+	return normalize(headline) + " | " + normalize(frame)
+}
+
+// normalize replaces the variable parts of a log line, so that repeated
+// messages about different requests or objects cluster together
+func normalize(s string) string {
+	s = uuidPattern.ReplaceAllString(s, "<uuid>")
+	s = ipPattern.ReplaceAllString(s, "<ip>")
+	s = hexPattern.ReplaceAllString(s, "<hex>")
+	s = numberPattern.ReplaceAllString(s, "<n>")
+	s = strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+	return truncate(s, maxSignatureLength)
+}
+
+// isErrorLine reports whether a log line looks like an error
+func isErrorLine(text string) bool {
+	return errorLinePattern.MatchString(text)
+}
+
+// isIndented reports whether a line starts with whitespace
+func isIndented(text string) bool {
+	return strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")
+}
@@ -0,0 +1,486 @@
+package netpol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+const (
+	// maxPairs caps the distinct source/destination endpoint pairs evaluated
+	maxPairs = 10
+	// missingResourceType is the kubectl error for resource types whose CRD
+	// is not installed
+	missingResourceType = "the server doesn't have a resource type"
+)
+
+// Verdicts
+const (
+	VerdictAllowed   = "allowed"
+	VerdictDenied    = "denied"
+	VerdictPartial   = "partially-allowed"
+	VerdictNoTraffic = "no-endpoints"
+)
+
+var (
+	// labelsPattern restricts label selectors to comma-separated key=value pairs
+	labelsPattern = regexp.MustCompile(`^[A-Za-z0-9./_-]+=[A-Za-z0-9._-]*(,[A-Za-z0-9./_-]+=[A-Za-z0-9._-]*)*$`)
+)
+
+// Options selects the traffic to analyze
+type Options struct {
+	SourceNamespace string
+	// SourcePod or SourceLabels selects the client pods
+	SourcePod    string
+	SourceLabels string
+
+	// DestinationNamespace defaults to SourceNamespace
+	DestinationNamespace string
+	// DestinationPod, DestinationLabels or DestinationService selects the server pods
+	DestinationPod     string
+	DestinationLabels  string
+	DestinationService string
+
+	// Port is the pod port, or the service port with DestinationService
+	Port     int
+	Protocol string
+}
+
+// Report is the structured result of netpol_reachability
+type Report struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Port        int    `json:"port"`
+	Protocol    string `json:"protocol"`
+	Allowed     bool   `json:"allowed"`
+	Verdict     string `json:"verdict"`
+	// Pairs are the evaluated source/destination endpoint pairs
+	Pairs            []PairResult `json:"pairs"`
+	Policies         PolicyCounts `json:"policiesEvaluated"`
+	Notes            []string     `json:"notes,omitempty"`
+	CollectionErrors []string     `json:"collectionErrors,omitempty"`
+}
+
+// PairResult is the verdict for traffic between two endpoints. Traffic is
+// allowed only when the source may send (egress) and the destination may
+// receive (ingress).
+type PairResult struct {
+	Source          string          `json:"source"`
+	Destination     string          `json:"destination"`
+	DestinationPort string          `json:"destinationPort"`
+	Allowed         bool            `json:"allowed"`
+	Egress          DirectionResult `json:"egress"`
+	Ingress         DirectionResult `json:"ingress"`
+}
+
+// PolicyCounts counts the policies loaded per engine
+type PolicyCounts struct {
+	Kubernetes int `json:"kubernetes"`
+	Calico     int `json:"calico"`
+	Cilium     int `json:"cilium"`
+}
+
+// clusterData holds the objects loaded for an analysis
+type clusterData struct {
+	namespaceLabels map[string]map[string]string
+	sources         []k8s.Pod
+	destinations    []k8s.Pod
+	service         *k8s.Service
+	policies        []k8s.NetworkPolicy
+	calico          []calicoPolicy
+	cilium          []ciliumPolicy
+	errors          []string
+}
+
+// Validate checks the options and applies defaults
+func (o *Options) Validate() error {
+	if o.SourceNamespace == "" {
+		return fmt.Errorf("source_namespace is required")
+	}
+	if o.DestinationNamespace == "" {
+		o.DestinationNamespace = o.SourceNamespace
+	}
+	if err := errors.Join(
+		k8s.ValidateNamespace("source_namespace", o.SourceNamespace),
+		k8s.ValidateNamespace("destination_namespace", o.DestinationNamespace),
+		k8s.ValidateName("source_pod", o.SourcePod),
+		k8s.ValidateName("destination_pod", o.DestinationPod),
+		k8s.ValidateNamespace("destination_service", o.DestinationService),
+	); err != nil {
+		return err
+	}
+	for _, labels := range []string{o.SourceLabels, o.DestinationLabels} {
+		if labels != "" && !labelsPattern.MatchString(labels) {
+			return fmt.Errorf("invalid labels '%s': expected comma-separated key=value pairs", labels)
+		}
+	}
+	if countSet(o.SourcePod, o.SourceLabels) != 1 {
+		return fmt.Errorf("exactly one of source_pod or source_labels is required")
+	}
+	if countSet(o.DestinationPod, o.DestinationLabels, o.DestinationService) != 1 {
+		return fmt.Errorf("exactly one of destination_pod, destination_labels or destination_service is required")
+	}
+	if o.Port < 1 || o.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	o.Protocol = protocolOrDefault(o.Protocol)
+	switch o.Protocol {
+	case "TCP", "UDP", "SCTP":
+	default:
+		return fmt.Errorf("unsupported protocol '%s'", o.Protocol)
+	}
+	return nil
+}
+
+// countSet counts the non-empty values
+func countSet(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Analyze loads pods, namespaces, services and network policies and
+// computes whether the selected traffic is allowed
+func Analyze(ctx context.Context, runner k8s.Runner, opts Options) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	data := collect(ctx, runner, opts)
+	if data.sources == nil && opts.SourcePod != "" {
+		return nil, fmt.Errorf("failed to get source pod: %s", strings.Join(data.errors, "; "))
+	}
+	if data.destinations == nil && opts.DestinationPod != "" {
+		return nil, fmt.Errorf("failed to get destination pod: %s", strings.Join(data.errors, "; "))
+	}
+	if opts.DestinationService != "" && data.service == nil {
+		return nil, fmt.Errorf("failed to get destination service: %s", strings.Join(data.errors, "; "))
+	}
+	return analyze(data, opts), nil
+}
+
+// collect loads the objects needed for the analysis. Policy CRDs that are
+// not installed are skipped.
+func collect(ctx context.Context, runner k8s.Runner, opts Options) *clusterData {
+	data := &clusterData{namespaceLabels: map[string]map[string]string{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	fetch := func(label, command string, out interface{}, store func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := k8s.GetJSON(ctx, runner, command, out)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if !strings.Contains(err.Error(), missingResourceType) {
+					data.errors = append(data.errors, fmt.Sprintf("%s: %v", label, err))
+				}
+				return
+			}
+			store()
+		}()
+	}
+
+	namespaces := []string{opts.SourceNamespace}
+	if opts.DestinationNamespace != opts.SourceNamespace {
+		namespaces = append(namespaces, opts.DestinationNamespace)
+	}
+	for _, ns := range namespaces {
+		var namespace k8s.Namespace
+		fetch("namespace "+ns, "get namespace "+ns, &namespace,
+			func() { data.namespaceLabels[ns] = namespace.Metadata.Labels })
+		var policies k8s.List[k8s.NetworkPolicy]
+		fetch("network policies in "+ns, "get networkpolicies -n "+ns, &policies,
+			func() { data.policies = append(data.policies, policies.Items...) })
+		var calico k8s.List[calicoPolicy]
+		fetch("calico network policies in "+ns, "get networkpolicies.crd.projectcalico.org -n "+ns, &calico,
+			func() { data.calico = append(data.calico, calico.Items...) })
+		var cilium k8s.List[ciliumPolicy]
+		fetch("cilium network policies in "+ns, "get ciliumnetworkpolicies.cilium.io -n "+ns, &cilium,
+			func() { data.cilium = append(data.cilium, cilium.Items...) })
+	}
+	var calicoGlobal k8s.List[calicoPolicy]
+	fetch("calico global network policies", "get globalnetworkpolicies.crd.projectcalico.org", &calicoGlobal,
+		func() { data.calico = append(data.calico, calicoGlobal.Items...) })
+	var ciliumClusterwide k8s.List[ciliumPolicy]
+	fetch("cilium clusterwide network policies", "get ciliumclusterwidenetworkpolicies.cilium.io", &ciliumClusterwide,
+		func() { data.cilium = append(data.cilium, ciliumClusterwide.Items...) })
+
+	fetchPods := func(label, namespace, name, labels string, store func([]k8s.Pod)) {
+		if name != "" {
+			var pod k8s.Pod
+			fetch(label, fmt.Sprintf("get pod %s -n %s", name, namespace), &pod, func() { store([]k8s.Pod{pod}) })
+			return
+		}
+		var pods k8s.List[k8s.Pod]
+		fetch(label, fmt.Sprintf("get pods -n %s -l %s", namespace, labels), &pods, func() { store(pods.Items) })
+	}
+	fetchPods("source pods", opts.SourceNamespace, opts.SourcePod, opts.SourceLabels,
+		func(pods []k8s.Pod) { data.sources = pods })
+	if opts.DestinationService == "" {
+		fetchPods("destination pods", opts.DestinationNamespace, opts.DestinationPod, opts.DestinationLabels,
+			func(pods []k8s.Pod) { data.destinations = pods })
+	} else {
+		var service k8s.Service
+		fetch("destination service", fmt.Sprintf("get service %s -n %s", opts.DestinationService, opts.DestinationNamespace), &service,
+			func() { data.service = &service })
+	}
+	wg.Wait()
+
+	if data.service != nil && len(data.service.Spec.Selector) > 0 {
+		fetchPods("service pods", opts.DestinationNamespace, "", selectorString(data.service.Spec.Selector),
+			func(pods []k8s.Pod) { data.destinations = pods })
+		wg.Wait()
+	}
+
+	sort.Strings(data.errors)
+	return data
+}
+
+// selectorString renders labels as a kubectl selector in key order
+func selectorString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+labels[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseLabels parses "k1=v1,k2=v2"
+func parseLabels(selector string) map[string]string {
+	labels := map[string]string{}
+	for _, pair := range strings.Split(selector, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// analyze evaluates every distinct source/destination pair
+func analyze(data *clusterData, opts Options) *Report {
+	report := &Report{
+		Source:           describeSide(opts.SourceNamespace, opts.SourcePod, opts.SourceLabels, ""),
+		Destination:      describeSide(opts.DestinationNamespace, opts.DestinationPod, opts.DestinationLabels, opts.DestinationService),
+		Port:             opts.Port,
+		Protocol:         opts.Protocol,
+		Policies:         PolicyCounts{Kubernetes: len(data.policies), Calico: len(data.calico), Cilium: len(data.cilium)},
+		CollectionErrors: data.errors,
+	}
+
+	sources := endpoints(data.sources, opts.SourceNamespace, parseLabels(opts.SourceLabels), data.namespaceLabels)
+	dstLabels := parseLabels(opts.DestinationLabels)
+	if data.service != nil {
+		dstLabels = data.service.Spec.Selector
+	}
+	destinations := endpoints(data.destinations, opts.DestinationNamespace, dstLabels, data.namespaceLabels)
+	if len(data.sources) == 0 {
+		report.Notes = append(report.Notes, "No source pods matched; evaluated a hypothetical pod with the given labels")
+	}
+	if len(data.destinations) == 0 {
+		report.Notes = append(report.Notes, "No destination pods matched; evaluated a hypothetical pod with the selector labels")
+	}
+	if data.service != nil && len(data.service.Spec.Selector) == 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("Service %s has no selector; its endpoints are managed manually and were not evaluated", opts.DestinationService))
+		report.Verdict = VerdictNoTraffic
+		return report
+	}
+
+	seen := map[string]bool{}
+	for _, src := range sources {
+		for _, dst := range destinations {
+			port, note := resolvePort(data.service, dst, opts)
+			if note != "" && !containsString(report.Notes, note) {
+				report.Notes = append(report.Notes, note)
+			}
+			key := identity(src) + "|" + identity(dst) + "|" + port.Name + port.String()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if len(report.Pairs) == maxPairs {
+				report.Notes = append(report.Notes, fmt.Sprintf("Evaluated the first %d distinct endpoint pairs only", maxPairs))
+				break
+			}
+			pair, errs := evaluatePair(data, src, dst, port)
+			for _, err := range errs {
+				if !containsString(report.CollectionErrors, err) {
+					report.CollectionErrors = append(report.CollectionErrors, err)
+				}
+			}
+			report.Pairs = append(report.Pairs, pair)
+		}
+		if len(report.Pairs) == maxPairs {
+			break
+		}
+	}
+
+	for _, p := range data.cilium {
+		if p.hasL7Rules() {
+			report.Notes = append(report.Notes, fmt.Sprintf("%s %s has L7 rules, which were not evaluated; allowed traffic may still be filtered at L7", p.kind(), p.Metadata.Name))
+		}
+	}
+
+	allowed := 0
+	for _, p := range report.Pairs {
+		if p.Allowed {
+			allowed++
+		}
+	}
+	switch {
+	case len(report.Pairs) == 0:
+		report.Verdict = VerdictNoTraffic
+	case allowed == len(report.Pairs):
+		report.Allowed = true
+		report.Verdict = VerdictAllowed
+	case allowed == 0:
+		report.Verdict = VerdictDenied
+	default:
+		report.Verdict = VerdictPartial
+	}
+	return report
+}
+
+// evaluatePair evaluates egress from src and ingress to dst
+func evaluatePair(data *clusterData, src, dst endpoint, port portSpec) (PairResult, []string) {
+	var errs []string
+	evaluate := func(direction string) DirectionResult {
+		var results []policyResult
+		for _, p := range data.policies {
+			results = append(results, evaluateKubernetes(p, direction, src, dst, port))
+		}
+		for _, p := range data.calico {
+			r, err := evaluateCalico(p, direction, src, dst, port)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			results = append(results, r)
+		}
+		for _, p := range data.cilium {
+			results = append(results, evaluateCilium(p, direction, src, dst, port))
+		}
+		return decide(results, direction)
+	}
+
+	pair := PairResult{
+		Source:          src.String(),
+		Destination:     dst.String(),
+		DestinationPort: port.String(),
+		Egress:          evaluate(directionEgress),
+		Ingress:         evaluate(directionIngress),
+	}
+	if port.Name != "" {
+		pair.DestinationPort += " (" + port.Name + ")"
+	}
+	pair.Allowed = pair.Egress.Allowed && pair.Ingress.Allowed
+	return pair, errs
+}
+
+// endpoints converts pods to endpoints, or returns a hypothetical endpoint
+// with the given labels when no pods matched
+func endpoints(pods []k8s.Pod, namespace string, labels map[string]string, namespaceLabels map[string]map[string]string) []endpoint {
+	nsLabels := namespaceLabels[namespace]
+	if nsLabels == nil {
+		// Namespaces always carry their name label
+		nsLabels = map[string]string{"kubernetes.io/metadata.name": namespace}
+	}
+	if len(pods) == 0 {
+		return []endpoint{{Namespace: namespace, Name: "<" + selectorString(labels) + ">", Labels: labels, NamespaceLabels: nsLabels}}
+	}
+	var out []endpoint
+	for _, pod := range pods {
+		e := endpoint{Namespace: namespace, Name: pod.Metadata.Name, Labels: pod.Metadata.Labels, NamespaceLabels: nsLabels, IP: pod.Status.PodIP}
+		for _, c := range pod.Spec.Containers {
+			e.Ports = append(e.Ports, c.Ports...)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// identity groups endpoints that label-based rules cannot tell apart. Pods
+// with the same namespace and labels are evaluated once, so ipBlock and CIDR
+// rules are checked against the first pod's IP.
+func identity(e endpoint) string {
+	return e.Namespace + "/" + selectorString(e.Labels)
+}
+
+// resolvePort maps the requested port to the destination pod port. With a
+// service the service port is translated to its targetPort; named ports
+// are resolved against the pod's container ports.
+func resolvePort(service *k8s.Service, dst endpoint, opts Options) (portSpec, string) {
+	port := portSpec{Port: opts.Port, Protocol: opts.Protocol}
+	if service != nil {
+		var servicePort *k8s.ServicePort
+		for i, sp := range service.Spec.Ports {
+			if sp.Port == opts.Port && protocolOrDefault(sp.Protocol) == opts.Protocol {
+				servicePort = &service.Spec.Ports[i]
+				break
+			}
+		}
+		if servicePort == nil {
+			return port, fmt.Sprintf("Service %s does not expose %d/%s; evaluated the port as a pod port", service.Metadata.Name, opts.Port, opts.Protocol)
+		}
+		target := servicePort.TargetPort
+		switch {
+		case target.IsStr:
+			port.Name = target.StrVal
+			port.Port = 0
+			for _, cp := range dst.Ports {
+				if cp.Name == target.StrVal && protocolOrDefault(cp.Protocol) == opts.Protocol {
+					port.Port = cp.ContainerPort
+				}
+			}
+			if port.Port == 0 {
+				return port, fmt.Sprintf("Named targetPort '%s' could not be resolved on %s; only rules naming the port can match", target.StrVal, dst)
+			}
+			return port, ""
+		case target.IntVal != 0:
+			port.Port = target.IntVal
+		}
+	}
+	for _, cp := range dst.Ports {
+		if cp.ContainerPort == port.Port && protocolOrDefault(cp.Protocol) == opts.Protocol {
+			port.Name = cp.Name
+		}
+	}
+	return port, ""
+}
+
+// describeSide renders the user's selection of one side of the connection
+func describeSide(namespace, pod, labels, service string) string {
+	switch {
+	case pod != "":
+		return namespace + "/" + pod
+	case service != "":
+		return namespace + "/service/" + service
+	default:
+		return namespace + " pods with " + labels
+	}
+}
+
+// containsString reports whether values contains v
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
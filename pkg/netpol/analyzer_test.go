@@ -0,0 +1,273 @@
+package netpol
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+)
+
+// newRunner returns a runner that matches commands by longest prefix.
+// Policy CRDs without canned output are reported as not installed.
+func newRunner(outputs map[string]string) *k8stest.Runner {
+	return &k8stest.Runner{Outputs: outputs, MatchPrefix: true, Fallback: func(command string) (string, error) {
+		if strings.Contains(command, "projectcalico.org") || strings.Contains(command, "cilium.io") {
+			return "", fmt.Errorf("error: the server doesn't have a resource type %q", strings.Fields(command)[1])
+		}
+		return k8stest.NotFound(command)
+	}}
+}
+
+// calicoScenario mirrors example/test_data/2.calico-network-policy-issue.yaml
+var calicoScenario = map[string]string{
+	"get namespace calico-test-worker": `{"metadata": {"name": "calico-test-worker", "labels": {"kubernetes.io/metadata.name": "calico-test-worker"}}}`,
+	"get namespace calico-test-server": `{"metadata": {"name": "calico-test-server", "labels": {"kubernetes.io/metadata.name": "calico-test-server"}}}`,
+	"get namespace kube-system":        `{"metadata": {"name": "kube-system", "labels": {"kubernetes.io/metadata.name": "kube-system"}}}`,
+	"get pods -n calico-test-worker -l app=worker": `{"items": [
+		{"metadata": {"name": "worker-1", "labels": {"app": "worker", "pod-template-hash": "abc"}}, "status": {"podIP": "10.244.1.10"}},
+		{"metadata": {"name": "worker-2", "labels": {"app": "worker", "pod-template-hash": "abc"}}, "status": {"podIP": "10.244.1.11"}}]}`,
+	"get pod worker-1 -n calico-test-worker":       `{"metadata": {"name": "worker-1", "labels": {"app": "worker"}}, "status": {"podIP": "10.244.1.10"}}`,
+	"get service my-service -n calico-test-server": `{"metadata": {"name": "my-service"}, "spec": {"selector": {"app": "my-service"}, "ports": [{"protocol": "TCP", "port": 80, "targetPort": 80}]}}`,
+	"get pods -n calico-test-server -l app=my-service": `{"items": [
+		{"metadata": {"name": "my-service-0", "labels": {"app": "my-service"}}, "spec": {"containers": [{"name": "my-service", "ports": [{"containerPort": 80}]}]}, "status": {"podIP": "10.244.2.20"}},
+		{"metadata": {"name": "my-service-1", "labels": {"app": "my-service"}}, "spec": {"containers": [{"name": "my-service", "ports": [{"containerPort": 80}]}]}, "status": {"podIP": "10.244.2.21"}}]}`,
+	"get pods -n kube-system -l k8s-app=kube-dns": `{"items": [{"metadata": {"name": "coredns-1", "labels": {"k8s-app": "kube-dns"}}, "status": {"podIP": "10.244.0.5"}}]}`,
+	"get networkpolicies -n calico-test-worker": `{"items": [{"metadata": {"name": "default-deny-all", "namespace": "calico-test-worker"}, "spec": {
+		"podSelector": {}, "policyTypes": ["Egress"],
+		"egress": [{"to": [{"podSelector": {"matchLabels": {"app": "my-service"}}, "namespaceSelector": {"matchLabels": {"kubernetes.io/metadata.name": "calico-test-server"}}}]}]}}]}`,
+	"get networkpolicies -n calico-test-server": `{"items": []}`,
+	"get networkpolicies -n kube-system":        `{"items": []}`,
+}
+
+func withOutputs(extra map[string]string) map[string]string {
+	outputs := make(map[string]string)
+	for k, v := range calicoScenario {
+		outputs[k] = v
+	}
+	for k, v := range extra {
+		outputs[k] = v
+	}
+	return outputs
+}
+
+func TestAnalyzeWorkerToService(t *testing.T) {
+	runner := newRunner(withOutputs(nil))
+	report, err := Analyze(context.Background(), runner, Options{
+		SourceNamespace:      "calico-test-worker",
+		SourceLabels:         "app=worker",
+		DestinationNamespace: "calico-test-server",
+		DestinationService:   "my-service",
+		Port:                 80,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !report.Allowed || report.Verdict != VerdictAllowed {
+		t.Fatalf("Expected worker -> my-service:80 to be allowed, got %s: %+v", report.Verdict, report.Pairs)
+	}
+	// Both workers and both servers share labels, so one pair is evaluated
+	if len(report.Pairs) != 1 {
+		t.Fatalf("Expected 1 distinct pair, got %d", len(report.Pairs))
+	}
+	egress := report.Pairs[0].Egress
+	if len(egress.AllowedBy) != 1 || egress.AllowedBy[0].Name != "default-deny-all" || egress.AllowedBy[0].Rule != "egress[0]" {
+		t.Errorf("Expected egress allowed by default-deny-all egress[0], got %+v", egress.AllowedBy)
+	}
+	if !report.Pairs[0].Ingress.Allowed || len(report.Pairs[0].Ingress.SelectingPolicies) != 0 {
+		t.Errorf("Expected ingress allowed with no selecting policies, got %+v", report.Pairs[0].Ingress)
+	}
+	if len(report.CollectionErrors) != 0 {
+		t.Errorf("Expected missing CRDs to be skipped, got %v", report.CollectionErrors)
+	}
+}
+
+func TestAnalyzeWorkerToDNSDenied(t *testing.T) {
+	runner := newRunner(withOutputs(nil))
+	report, err := Analyze(context.Background(), runner, Options{
+		SourceNamespace:      "calico-test-worker",
+		SourceLabels:         "app=worker",
+		DestinationNamespace: "kube-system",
+		DestinationLabels:    "k8s-app=kube-dns",
+		Port:                 53,
+		Protocol:             "udp",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Allowed || report.Verdict != VerdictDenied {
+		t.Fatalf("Expected worker -> kube-dns:53/UDP to be denied, got %s", report.Verdict)
+	}
+	egress := report.Pairs[0].Egress
+	if egress.Allowed || len(egress.SelectingPolicies) != 1 || !strings.Contains(egress.SelectingPolicies[0], "default-deny-all") {
+		t.Errorf("Expected egress denied by default-deny-all isolation, got %+v", egress)
+	}
+	if report.Protocol != "UDP" {
+		t.Errorf("Expected protocol to be normalized to UDP, got %s", report.Protocol)
+	}
+}
+
+func TestAnalyzeCalicoOrder(t *testing.T) {
+	runner := newRunner(withOutputs(map[string]string{
+		"get networkpolicies -n calico-test-worker": `{"items": []}`,
+		"get networkpolicies.crd.projectcalico.org -n calico-test-server": `{"items": [
+			{"metadata": {"name": "default.allow-worker", "namespace": "calico-test-server"}, "spec": {"order": 200, "selector": "app == 'my-service'",
+				"ingress": [{"action": "Allow", "protocol": "TCP", "source": {"namespaceSelector": "kubernetes.io/metadata.name == 'calico-test-worker'"}, "destination": {"ports": [80]}}]}}]}`,
+		"get globalnetworkpolicies.crd.projectcalico.org": `{"items": [
+			{"metadata": {"name": "default.deny-worker"}, "spec": {"order": 100, "selector": "all()", "types": ["Ingress"],
+				"ingress": [{"action": "Log"}, {"action": "Deny", "source": {"selector": "app in {'worker', 'batch'}"}}]}}]}`,
+	}))
+	opts := Options{
+		SourceNamespace:      "calico-test-worker",
+		SourceLabels:         "app=worker",
+		DestinationNamespace: "calico-test-server",
+		DestinationService:   "my-service",
+		Port:                 80,
+	}
+	report, err := Analyze(context.Background(), runner, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ingress := report.Pairs[0].Ingress
+	if ingress.Allowed || len(ingress.DeniedBy) != 1 || ingress.DeniedBy[0].Name != "default.deny-worker" || ingress.DeniedBy[0].Rule != "ingress[1]" {
+		t.Fatalf("Expected lower-order global deny to win, got %+v", ingress)
+	}
+	if report.Policies.Calico != 2 {
+		t.Errorf("Expected 2 Calico policies, got %d", report.Policies.Calico)
+	}
+
+	// Raising the deny policy's order lets the allow rule match first
+	runner.Outputs["get globalnetworkpolicies.crd.projectcalico.org"] = strings.Replace(runner.Outputs["get globalnetworkpolicies.crd.projectcalico.org"], `"order": 100`, `"order": 300`, 1)
+	report, err = Analyze(context.Background(), runner, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ingress = report.Pairs[0].Ingress
+	if !ingress.Allowed || ingress.AllowedBy[0].Kind != KindCalicoNetworkPolicy || ingress.AllowedBy[0].Name != "default.allow-worker" {
+		t.Errorf("Expected allow-worker to win, got %+v", ingress)
+	}
+}
+
+func TestAnalyzeCilium(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies string
+		allowed  bool
+		rule     string
+	}{
+		{
+			name: "allowed from namespace",
+			policies: `{"items": [{"metadata": {"name": "allow-worker", "namespace": "calico-test-server"}, "spec": {
+				"endpointSelector": {"matchLabels": {"app": "my-service"}},
+				"ingress": [{"fromEndpoints": [{"matchLabels": {"k8s:io.kubernetes.pod.namespace": "calico-test-worker", "app": "worker"}}],
+					"toPorts": [{"ports": [{"port": "80", "protocol": "TCP"}]}]}]}}]}`,
+			allowed: true,
+			rule:    "ingress[0]",
+		},
+		{
+			name: "selector implicitly scoped to policy namespace",
+			policies: `{"items": [{"metadata": {"name": "allow-local", "namespace": "calico-test-server"}, "spec": {
+				"endpointSelector": {"matchLabels": {"app": "my-service"}},
+				"ingress": [{"fromEndpoints": [{"matchLabels": {"app": "worker"}}]}]}}]}`,
+			allowed: false,
+		},
+		{
+			name: "deny wins over allow",
+			policies: `{"items": [{"metadata": {"name": "mixed", "namespace": "calico-test-server"}, "spec": {
+				"endpointSelector": {},
+				"ingress": [{"fromEntities": ["cluster"]}],
+				"ingressDeny": [{"fromEndpoints": [{"matchLabels": {"io.cilium.k8s.namespace.labels.kubernetes.io/metadata.name": "calico-test-worker"}}]}]}}]}`,
+			allowed: false,
+			rule:    "ingressDeny[0]",
+		},
+		{
+			name: "wrong port",
+			policies: `{"items": [{"metadata": {"name": "allow-8080", "namespace": "calico-test-server"}, "spec": {
+				"endpointSelector": {"matchLabels": {"app": "my-service"}},
+				"ingress": [{"fromEntities": ["all"], "toPorts": [{"ports": [{"port": "8080"}]}]}]}}]}`,
+			allowed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newRunner(withOutputs(map[string]string{
+				"get networkpolicies -n calico-test-worker":                 `{"items": []}`,
+				"get ciliumnetworkpolicies.cilium.io -n calico-test-server": tt.policies,
+			}))
+			report, err := Analyze(context.Background(), runner, Options{
+				SourceNamespace:      "calico-test-worker",
+				SourcePod:            "worker-1",
+				DestinationNamespace: "calico-test-server",
+				DestinationLabels:    "app=my-service",
+				Port:                 80,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			ingress := report.Pairs[0].Ingress
+			if ingress.Allowed != tt.allowed {
+				t.Fatalf("Expected allowed=%v, got %+v", tt.allowed, ingress)
+			}
+			refs := append(ingress.AllowedBy, ingress.DeniedBy...)
+			if tt.rule != "" && (len(refs) != 1 || refs[0].Rule != tt.rule) {
+				t.Errorf("Expected rule %s, got %+v", tt.rule, refs)
+			}
+		})
+	}
+}
+
+func TestAnalyzeNoMatchingPods(t *testing.T) {
+	runner := newRunner(withOutputs(map[string]string{
+		"get pods -n calico-test-worker -l app=batch": `{"items": []}`,
+	}))
+	report, err := Analyze(context.Background(), runner, Options{
+		SourceNamespace:      "calico-test-worker",
+		SourceLabels:         "app=batch",
+		DestinationNamespace: "calico-test-server",
+		DestinationService:   "my-service",
+		Port:                 80,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// default-deny-all selects every pod in the namespace, including new ones
+	if !report.Allowed || report.Pairs[0].Source != "calico-test-worker/<app=batch>" {
+		t.Errorf("Expected a hypothetical app=batch pod to be allowed by default-deny-all, got %+v", report.Pairs)
+	}
+	if len(report.Notes) == 0 || !strings.Contains(report.Notes[0], "hypothetical") {
+		t.Errorf("Expected a note about the hypothetical source, got %v", report.Notes)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		err  string
+	}{
+		{"missing namespace", Options{SourcePod: "a", DestinationPod: "b", Port: 80}, "source_namespace"},
+		{"no source", Options{SourceNamespace: "ns", DestinationPod: "b", Port: 80}, "source_pod"},
+		{"two destinations", Options{SourceNamespace: "ns", SourcePod: "a", DestinationPod: "b", DestinationService: "c", Port: 80}, "exactly one of destination"},
+		{"bad port", Options{SourceNamespace: "ns", SourcePod: "a", DestinationPod: "b", Port: 70000}, "port"},
+		{"injected labels", Options{SourceNamespace: "ns", SourceLabels: "app=a --all-namespaces", DestinationPod: "b", Port: 80}, "invalid labels"},
+		{"bad protocol", Options{SourceNamespace: "ns", SourcePod: "a", DestinationPod: "b", Port: 80, Protocol: "icmp"}, "protocol"},
+		{"valid", Options{SourceNamespace: "ns", SourcePod: "a", DestinationPod: "b", Port: 80}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing '%s', got %v", tt.err, err)
+			}
+		})
+	}
+}
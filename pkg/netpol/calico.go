@@ -0,0 +1,273 @@
+package netpol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Labels Calico adds to endpoints and namespaces for selector matching
+const (
+	calicoNamespaceLabel     = "projectcalico.org/namespace"
+	calicoNamespaceNameLabel = "projectcalico.org/name"
+)
+
+// calicoPolicy is a crd.projectcalico.org/v1 NetworkPolicy or GlobalNetworkPolicy
+type calicoPolicy struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace,omitempty"`
+	} `json:"metadata"`
+	Spec calicoPolicySpec `json:"spec"`
+}
+
+// calicoPolicySpec is the spec shared by namespaced and global Calico policies
+type calicoPolicySpec struct {
+	Order    *float64     `json:"order,omitempty"`
+	Selector string       `json:"selector,omitempty"`
+	Types    []string     `json:"types,omitempty"`
+	Ingress  []calicoRule `json:"ingress,omitempty"`
+	Egress   []calicoRule `json:"egress,omitempty"`
+}
+
+// calicoRule is a Calico policy rule
+type calicoRule struct {
+	Action      string           `json:"action"`
+	Protocol    *calicoProtocol  `json:"protocol,omitempty"`
+	Source      calicoEntityRule `json:"source,omitempty"`
+	Destination calicoEntityRule `json:"destination,omitempty"`
+}
+
+// calicoEntityRule matches the source or destination of traffic
+type calicoEntityRule struct {
+	Selector          string       `json:"selector,omitempty"`
+	NotSelector       string       `json:"notSelector,omitempty"`
+	NamespaceSelector string       `json:"namespaceSelector,omitempty"`
+	Nets              []string     `json:"nets,omitempty"`
+	NotNets           []string     `json:"notNets,omitempty"`
+	Ports             []calicoPort `json:"ports,omitempty"`
+	NotPorts          []calicoPort `json:"notPorts,omitempty"`
+}
+
+// calicoProtocol is a protocol name or number
+type calicoProtocol string
+
+// UnmarshalJSON accepts a protocol name ("TCP") or number (6)
+func (p *calicoProtocol) UnmarshalJSON(data []byte) error {
+	*p = calicoProtocol(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// name returns the protocol as an upper-case name
+func (p calicoProtocol) name() string {
+	switch strings.ToUpper(string(p)) {
+	case "6":
+		return "TCP"
+	case "17":
+		return "UDP"
+	case "132":
+		return "SCTP"
+	}
+	return strings.ToUpper(string(p))
+}
+
+// calicoPort is a port number, a "min:max" range or a named port
+type calicoPort string
+
+// UnmarshalJSON accepts a JSON number or string
+func (p *calicoPort) UnmarshalJSON(data []byte) error {
+	*p = calicoPort(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// matches reports whether the port covers the destination port
+func (p calicoPort) matches(port portSpec) bool {
+	s := string(p)
+	if lo, hi, ok := strings.Cut(s, ":"); ok {
+		min, err1 := strconv.Atoi(lo)
+		max, err2 := strconv.Atoi(hi)
+		return err1 == nil && err2 == nil && port.Port >= min && port.Port <= max
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n == port.Port
+	}
+	return port.Name != "" && s == port.Name
+}
+
+// kind returns the rule reference kind of the policy
+func (p calicoPolicy) kind() string {
+	if p.Metadata.Namespace == "" {
+		return KindCalicoGlobalNetworkPolicy
+	}
+	return KindCalicoNetworkPolicy
+}
+
+// affects reports whether the policy applies to the direction. Without
+// types Calico applies ingress, plus egress when egress rules exist.
+func (p calicoPolicy) affects(direction string) bool {
+	if len(p.Spec.Types) == 0 {
+		if direction == directionEgress {
+			return len(p.Spec.Egress) > 0
+		}
+		return true
+	}
+	for _, t := range p.Spec.Types {
+		if strings.EqualFold(t, direction) {
+			return true
+		}
+	}
+	return false
+}
+
+// calicoLabels returns the endpoint labels including Calico's namespace label
+func calicoLabels(e endpoint) map[string]string {
+	labels := map[string]string{calicoNamespaceLabel: e.Namespace}
+	for k, v := range e.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// calicoNamespaceLabels returns the namespace labels including Calico's name label
+func calicoNamespaceLabels(e endpoint) map[string]string {
+	labels := map[string]string{calicoNamespaceNameLabel: e.Namespace}
+	for k, v := range e.NamespaceLabels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// evaluateCalico evaluates a Calico policy for traffic from src to dst. Rules
+// are evaluated in order and the first rule with an Allow, Deny or Pass
+// action that matches decides; Log rules do not.
+func evaluateCalico(policy calicoPolicy, direction string, src, dst endpoint, port portSpec) (policyResult, error) {
+	ref := RuleRef{Kind: policy.kind(), Namespace: policy.Metadata.Namespace, Name: policy.Metadata.Name}
+	result := policyResult{policy: ref, order: calicoKubernetesPolicyOrder, ordered: true}
+	if policy.Spec.Order != nil {
+		result.order = *policy.Spec.Order
+	}
+
+	subject := src
+	rules := policy.Spec.Egress
+	if direction == directionIngress {
+		subject = dst
+		rules = policy.Spec.Ingress
+	}
+	if !policy.affects(direction) {
+		return result, nil
+	}
+	if policy.Metadata.Namespace != "" && subject.Namespace != policy.Metadata.Namespace {
+		return result, nil
+	}
+	selector, err := parseCalicoSelector(policy.Spec.Selector)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", ref, err)
+	}
+	if !selector(calicoLabels(subject)) {
+		return result, nil
+	}
+	result.selects = true
+
+	for i, rule := range rules {
+		action := strings.ToLower(rule.Action)
+		if action != actionAllow && action != actionDeny && action != actionPass {
+			continue
+		}
+		matched, err := calicoRuleMatches(rule, policy.Metadata.Namespace, src, dst, port)
+		if err != nil {
+			return result, fmt.Errorf("%s %s[%d]: %w", ref, direction, i, err)
+		}
+		if matched {
+			result.match = &RuleRef{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name, Rule: fmt.Sprintf("%s[%d]", direction, i)}
+			result.action = action
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// calicoRuleMatches reports whether a rule matches the traffic
+func calicoRuleMatches(rule calicoRule, policyNamespace string, src, dst endpoint, port portSpec) (bool, error) {
+	if rule.Protocol != nil && rule.Protocol.name() != port.Protocol {
+		return false, nil
+	}
+	ok, err := calicoEntityMatches(rule.Source, policyNamespace, src, nil)
+	if err != nil || !ok {
+		return false, err
+	}
+	return calicoEntityMatches(rule.Destination, policyNamespace, dst, &port)
+}
+
+// calicoEntityMatches checks an entity rule against an endpoint. Selectors
+// in namespaced policies only match the policy's namespace unless a
+// namespaceSelector is given; ports are only checked for the destination.
+func calicoEntityMatches(rule calicoEntityRule, policyNamespace string, e endpoint, port *portSpec) (bool, error) {
+	if rule.NamespaceSelector != "" {
+		nsSelector, err := parseCalicoSelector(rule.NamespaceSelector)
+		if err != nil {
+			return false, err
+		}
+		if !nsSelector(calicoNamespaceLabels(e)) {
+			return false, nil
+		}
+	} else if rule.Selector != "" && policyNamespace != "" && e.Namespace != policyNamespace {
+		return false, nil
+	}
+
+	labels := calicoLabels(e)
+	if rule.Selector != "" {
+		selector, err := parseCalicoSelector(rule.Selector)
+		if err != nil {
+			return false, err
+		}
+		if !selector(labels) {
+			return false, nil
+		}
+	}
+	if rule.NotSelector != "" {
+		selector, err := parseCalicoSelector(rule.NotSelector)
+		if err != nil {
+			return false, err
+		}
+		if selector(labels) {
+			return false, nil
+		}
+	}
+
+	if len(rule.Nets) > 0 && !anyNetContains(rule.Nets, e.IP) {
+		return false, nil
+	}
+	if len(rule.NotNets) > 0 && anyNetContains(rule.NotNets, e.IP) {
+		return false, nil
+	}
+
+	if port != nil {
+		if len(rule.Ports) > 0 && !anyPortMatches(rule.Ports, *port) {
+			return false, nil
+		}
+		if len(rule.NotPorts) > 0 && anyPortMatches(rule.NotPorts, *port) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// anyNetContains reports whether any CIDR contains the IP
+func anyNetContains(nets []string, ip string) bool {
+	for _, n := range nets {
+		if ipBlockContains(n, nil, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyPortMatches reports whether any Calico port covers the destination port
+func anyPortMatches(ports []calicoPort, port portSpec) bool {
+	for _, p := range ports {
+		if p.matches(port) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+package netpol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// Labels Cilium derives for endpoints
+const (
+	ciliumNamespaceLabel       = "io.kubernetes.pod.namespace"
+	ciliumNamespaceLabelPrefix = "io.cilium.k8s.namespace.labels."
+)
+
+// ciliumPolicy is a cilium.io/v2 CiliumNetworkPolicy or
+// CiliumClusterwideNetworkPolicy; either spec or specs is set
+type ciliumPolicy struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace,omitempty"`
+	} `json:"metadata"`
+	Spec  *ciliumRule  `json:"spec,omitempty"`
+	Specs []ciliumRule `json:"specs,omitempty"`
+}
+
+// ciliumRule is one policy rule set
+type ciliumRule struct {
+	EndpointSelector  *k8s.LabelSelector `json:"endpointSelector,omitempty"`
+	Ingress           []ciliumPeerRule   `json:"ingress"`
+	IngressDeny       []ciliumPeerRule   `json:"ingressDeny"`
+	Egress            []ciliumPeerRule   `json:"egress"`
+	EgressDeny        []ciliumPeerRule   `json:"egressDeny"`
+	EnableDefaultDeny struct {
+		Ingress *bool `json:"ingress,omitempty"`
+		Egress  *bool `json:"egress,omitempty"`
+	} `json:"enableDefaultDeny,omitempty"`
+}
+
+// ciliumPeerRule is an ingress or egress rule; from* fields are used for
+// ingress and to* fields for egress
+type ciliumPeerRule struct {
+	FromEndpoints []k8s.LabelSelector `json:"fromEndpoints,omitempty"`
+	ToEndpoints   []k8s.LabelSelector `json:"toEndpoints,omitempty"`
+	FromEntities  []string            `json:"fromEntities,omitempty"`
+	ToEntities    []string            `json:"toEntities,omitempty"`
+	FromCIDR      []string            `json:"fromCIDR,omitempty"`
+	ToCIDR        []string            `json:"toCIDR,omitempty"`
+	FromCIDRSet   []ciliumCIDRRule    `json:"fromCIDRSet,omitempty"`
+	ToCIDRSet     []ciliumCIDRRule    `json:"toCIDRSet,omitempty"`
+	ToFQDNs       []interface{}       `json:"toFQDNs,omitempty"`
+	ToServices    []interface{}       `json:"toServices,omitempty"`
+	ToPorts       []ciliumPortRule    `json:"toPorts,omitempty"`
+}
+
+// ciliumCIDRRule is a CIDR with exceptions
+type ciliumCIDRRule struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+// ciliumPortRule lists ports; L7 rules are not evaluated
+type ciliumPortRule struct {
+	Ports []struct {
+		Port     string `json:"port"`
+		EndPort  int    `json:"endPort,omitempty"`
+		Protocol string `json:"protocol,omitempty"`
+	} `json:"ports,omitempty"`
+	Rules interface{} `json:"rules,omitempty"`
+}
+
+// kind returns the rule reference kind of the policy
+func (p ciliumPolicy) kind() string {
+	if p.Metadata.Namespace == "" {
+		return KindCiliumClusterwideNetworkPolicy
+	}
+	return KindCiliumNetworkPolicy
+}
+
+// rules returns the policy's rule sets
+func (p ciliumPolicy) rules() []ciliumRule {
+	if p.Spec != nil {
+		return append([]ciliumRule{*p.Spec}, p.Specs...)
+	}
+	return p.Specs
+}
+
+// ciliumLabels returns the identity labels Cilium derives for an endpoint
+func ciliumLabels(e endpoint) map[string]string {
+	labels := map[string]string{ciliumNamespaceLabel: e.Namespace}
+	for k, v := range e.Labels {
+		labels[k] = v
+	}
+	for k, v := range e.NamespaceLabels {
+		labels[ciliumNamespaceLabelPrefix+k] = v
+	}
+	return labels
+}
+
+// ciliumSelector strips the "k8s:" and "any:" source prefixes from selector
+// keys. In namespaced policies, selectors without a namespace key are
+// scoped to the policy's namespace.
+func ciliumSelector(selector k8s.LabelSelector, policyNamespace string) k8s.LabelSelector {
+	out := k8s.LabelSelector{MatchLabels: map[string]string{}}
+	for k, v := range selector.MatchLabels {
+		out.MatchLabels[stripLabelSource(k)] = v
+	}
+	for _, req := range selector.MatchExpressions {
+		req.Key = stripLabelSource(req.Key)
+		out.MatchExpressions = append(out.MatchExpressions, req)
+	}
+	if policyNamespace != "" && !selectsNamespace(out) {
+		out.MatchLabels[ciliumNamespaceLabel] = policyNamespace
+	}
+	return out
+}
+
+// selectsNamespace reports whether the selector already constrains the
+// namespace, by name or by namespace labels
+func selectsNamespace(selector k8s.LabelSelector) bool {
+	isNamespaceKey := func(key string) bool {
+		return key == ciliumNamespaceLabel || strings.HasPrefix(key, ciliumNamespaceLabelPrefix)
+	}
+	for key := range selector.MatchLabels {
+		if isNamespaceKey(key) {
+			return true
+		}
+	}
+	for _, req := range selector.MatchExpressions {
+		if isNamespaceKey(req.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripLabelSource removes a "k8s:" or "any:" label source prefix
+func stripLabelSource(key string) string {
+	for _, prefix := range []string{"k8s:", "any:"} {
+		key = strings.TrimPrefix(key, prefix)
+	}
+	return key
+}
+
+// evaluateCilium evaluates a Cilium policy for traffic from src to dst. A
+// direction is enforced when the rule set has allow or deny rules for it;
+// deny rules take precedence over allow rules.
+func evaluateCilium(policy ciliumPolicy, direction string, src, dst endpoint, port portSpec) policyResult {
+	ref := RuleRef{Kind: policy.kind(), Namespace: policy.Metadata.Namespace, Name: policy.Metadata.Name}
+	result := policyResult{policy: ref}
+
+	subject, peer := src, dst
+	if direction == directionIngress {
+		subject, peer = dst, src
+	}
+	if policy.Metadata.Namespace != "" && subject.Namespace != policy.Metadata.Namespace {
+		return result
+	}
+
+	var allow, deny *RuleRef
+	for i, rs := range policy.rules() {
+		if rs.EndpointSelector == nil || !ciliumSelector(*rs.EndpointSelector, policy.Metadata.Namespace).Matches(ciliumLabels(subject)) {
+			continue
+		}
+
+		allowRules, denyRules, defaultDeny := rs.Egress, rs.EgressDeny, rs.EnableDefaultDeny.Egress
+		if direction == directionIngress {
+			allowRules, denyRules, defaultDeny = rs.Ingress, rs.IngressDeny, rs.EnableDefaultDeny.Ingress
+		}
+		if allowRules == nil && denyRules == nil {
+			continue
+		}
+		if defaultDeny == nil || *defaultDeny {
+			result.selects = true
+		}
+
+		prefix := ""
+		if len(policy.rules()) > 1 {
+			prefix = fmt.Sprintf("specs[%d].", i)
+		}
+		for j, rule := range denyRules {
+			if deny == nil && ciliumRuleMatches(rule, direction, policy.Metadata.Namespace, peer, port) {
+				deny = &RuleRef{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name, Rule: fmt.Sprintf("%s%sDeny[%d]", prefix, direction, j)}
+			}
+		}
+		for j, rule := range allowRules {
+			if allow == nil && ciliumRuleMatches(rule, direction, policy.Metadata.Namespace, peer, port) {
+				allow = &RuleRef{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name, Rule: fmt.Sprintf("%s%s[%d]", prefix, direction, j)}
+			}
+		}
+	}
+
+	switch {
+	case deny != nil:
+		// Deny rules apply even when default deny is disabled
+		result.selects = true
+		result.match, result.action = deny, actionDeny
+	case allow != nil:
+		result.match, result.action = allow, actionAllow
+	}
+	return result
+}
+
+// ciliumRuleMatches reports whether a rule matches the peer and port. A rule
+// without L3 peers matches any peer; a rule without toPorts matches any port.
+func ciliumRuleMatches(rule ciliumPeerRule, direction, policyNamespace string, peer endpoint, port portSpec) bool {
+	return ciliumPeerMatches(rule, direction, policyNamespace, peer) && ciliumPortsMatch(rule.ToPorts, port)
+}
+
+// ciliumPeerMatches checks the L3 part of a rule
+func ciliumPeerMatches(rule ciliumPeerRule, direction, policyNamespace string, peer endpoint) bool {
+	endpoints, entities, cidrs, cidrSets := rule.ToEndpoints, rule.ToEntities, rule.ToCIDR, rule.ToCIDRSet
+	if direction == directionIngress {
+		endpoints, entities, cidrs, cidrSets = rule.FromEndpoints, rule.FromEntities, rule.FromCIDR, rule.FromCIDRSet
+	}
+	hasL3 := endpoints != nil || entities != nil || cidrs != nil || cidrSets != nil
+	if direction == directionEgress {
+		hasL3 = hasL3 || rule.ToFQDNs != nil || rule.ToServices != nil
+	}
+	if !hasL3 {
+		return true
+	}
+
+	labels := ciliumLabels(peer)
+	for _, selector := range endpoints {
+		if ciliumSelector(selector, policyNamespace).Matches(labels) {
+			return true
+		}
+	}
+	for _, entity := range entities {
+		if entity == "all" || entity == "cluster" {
+			return true
+		}
+	}
+	for _, cidr := range cidrs {
+		if ipBlockContains(cidr, nil, peer.IP) {
+			return true
+		}
+	}
+	for _, set := range cidrSets {
+		if ipBlockContains(set.CIDR, set.Except, peer.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// ciliumPortsMatch reports whether the port rules include the destination port
+func ciliumPortsMatch(rules []ciliumPortRule, port portSpec) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	for _, rule := range rules {
+		if len(rule.Ports) == 0 {
+			return true
+		}
+		for _, p := range rule.Ports {
+			protocol := protocolOrDefault(p.Protocol)
+			if protocol != "ANY" && protocol != port.Protocol {
+				continue
+			}
+			if p.Port == "" || p.Port == "0" {
+				return true
+			}
+			n, err := strconv.Atoi(p.Port)
+			if err != nil {
+				if port.Name != "" && p.Port == port.Name {
+					return true
+				}
+				continue
+			}
+			if n == port.Port || (p.EndPort > 0 && port.Port >= n && port.Port <= p.EndPort) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasL7Rules reports whether any port rule of the policy carries L7 rules,
+// which this analyzer does not evaluate
+func (p ciliumPolicy) hasL7Rules() bool {
+	for _, rs := range p.rules() {
+		for _, rules := range [][]ciliumPeerRule{rs.Ingress, rs.Egress} {
+			for _, rule := range rules {
+				for _, pr := range rule.ToPorts {
+					if pr.Rules != nil {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
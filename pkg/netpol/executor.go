@@ -0,0 +1,58 @@
+package netpol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// ReachabilityExecutor implements the CommandExecutor interface for netpol_reachability
+type ReachabilityExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures ReachabilityExecutor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*ReachabilityExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*ReachabilityExecutor)(nil)
+
+// NewExecutor creates a new ReachabilityExecutor instance
+func NewExecutor() *ReachabilityExecutor {
+	return &ReachabilityExecutor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute analyzes network policy reachability and returns the report as JSON
+func (e *ReachabilityExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := Options{}
+	opts.SourceNamespace, _ = params["source_namespace"].(string)
+	opts.SourcePod, _ = params["source_pod"].(string)
+	opts.SourceLabels, _ = params["source_labels"].(string)
+	opts.DestinationNamespace, _ = params["destination_namespace"].(string)
+	opts.DestinationPod, _ = params["destination_pod"].(string)
+	opts.DestinationLabels, _ = params["destination_labels"].(string)
+	opts.DestinationService, _ = params["destination_service"].(string)
+	opts.Protocol, _ = params["protocol"].(string)
+	if port, ok := params["port"].(float64); ok {
+		opts.Port = int(port)
+	}
+
+	report, err := Analyze(ctx, e.newRunner(cfg), opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that netpol_reachability always returns a JSON report
+func (e *ReachabilityExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
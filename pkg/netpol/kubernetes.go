@@ -0,0 +1,126 @@
+package netpol
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// evaluateKubernetes evaluates a networking.k8s.io/v1 NetworkPolicy for
+// traffic from src to dst. For egress the subject is src and the peer dst;
+// for ingress the subject is dst and the peer src.
+func evaluateKubernetes(policy k8s.NetworkPolicy, direction string, src, dst endpoint, port portSpec) policyResult {
+	ref := RuleRef{Kind: KindNetworkPolicy, Namespace: policy.Metadata.Namespace, Name: policy.Metadata.Name}
+	result := policyResult{policy: ref, order: calicoKubernetesPolicyOrder}
+
+	subject, peer := src, dst
+	affects := policy.AffectsEgress()
+	if direction == directionIngress {
+		subject, peer = dst, src
+		affects = policy.AffectsIngress()
+	}
+	if !affects || subject.Namespace != policy.Metadata.Namespace || !policy.Spec.PodSelector.Matches(subject.Labels) {
+		return result
+	}
+	result.selects = true
+
+	if direction == directionEgress {
+		for i, rule := range policy.Spec.Egress {
+			if kubernetesPortsMatch(rule.Ports, port) && kubernetesPeersMatch(rule.To, policy.Metadata.Namespace, peer) {
+				result.match = &RuleRef{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name, Rule: fmt.Sprintf("egress[%d]", i)}
+				result.action = actionAllow
+				return result
+			}
+		}
+		return result
+	}
+
+	for i, rule := range policy.Spec.Ingress {
+		if kubernetesPortsMatch(rule.Ports, port) && kubernetesPeersMatch(rule.From, policy.Metadata.Namespace, peer) {
+			result.match = &RuleRef{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name, Rule: fmt.Sprintf("ingress[%d]", i)}
+			result.action = actionAllow
+			return result
+		}
+	}
+	return result
+}
+
+// kubernetesPeersMatch reports whether any peer selects the endpoint. An
+// empty peer list matches everything.
+func kubernetesPeersMatch(peers []k8s.NetworkPolicyPeer, policyNamespace string, e endpoint) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if kubernetesPeerMatches(peer, policyNamespace, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// kubernetesPeerMatches applies NetworkPolicyPeer semantics: an ipBlock
+// matches by pod IP, a bare podSelector selects pods in the policy's
+// namespace, and a namespaceSelector (optionally combined with a
+// podSelector) selects pods in matching namespaces
+func kubernetesPeerMatches(peer k8s.NetworkPolicyPeer, policyNamespace string, e endpoint) bool {
+	if peer.IPBlock != nil {
+		return ipBlockContains(peer.IPBlock.CIDR, peer.IPBlock.Except, e.IP)
+	}
+	if peer.NamespaceSelector == nil {
+		if e.Namespace != policyNamespace {
+			return false
+		}
+	} else if !peer.NamespaceSelector.Matches(e.NamespaceLabels) {
+		return false
+	}
+	return peer.PodSelector == nil || peer.PodSelector.Matches(e.Labels)
+}
+
+// kubernetesPortsMatch reports whether the ports include the destination
+// port. An empty list matches all ports; named ports match the destination
+// container port name.
+func kubernetesPortsMatch(ports []k8s.NetworkPolicyPort, port portSpec) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		if protocolOrDefault(p.Protocol) != port.Protocol {
+			continue
+		}
+		switch {
+		case p.Port == nil:
+			return true
+		case p.Port.IsStr:
+			if port.Name != "" && p.Port.StrVal == port.Name {
+				return true
+			}
+		case p.EndPort != nil:
+			if port.Port >= p.Port.IntVal && port.Port <= *p.EndPort {
+				return true
+			}
+		case p.Port.IntVal == port.Port:
+			return true
+		}
+	}
+	return false
+}
+
+// ipBlockContains reports whether the IP is in the CIDR and not excepted
+func ipBlockContains(cidr string, except []string, ipString string) bool {
+	ip := net.ParseIP(ipString)
+	if ip == nil {
+		return false
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil || !network.Contains(ip) {
+		return false
+	}
+	for _, e := range except {
+		if _, excluded, err := net.ParseCIDR(e); err == nil && excluded.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package netpol
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+// Policy kinds reported in rule references
+const (
+	KindNetworkPolicy                  = "NetworkPolicy"
+	KindCalicoNetworkPolicy            = "CalicoNetworkPolicy"
+	KindCalicoGlobalNetworkPolicy      = "CalicoGlobalNetworkPolicy"
+	KindCiliumNetworkPolicy            = "CiliumNetworkPolicy"
+	KindCiliumClusterwideNetworkPolicy = "CiliumClusterwideNetworkPolicy"
+)
+
+// Traffic directions
+const (
+	directionIngress = "ingress"
+	directionEgress  = "egress"
+)
+
+// calicoKubernetesPolicyOrder is the order Calico gives Kubernetes
+// NetworkPolicies within its default tier
+const calicoKubernetesPolicyOrder = 1000.0
+
+// Rule actions
+const (
+	actionAllow = "allow"
+	actionDeny  = "deny"
+	actionPass  = "pass"
+)
+
+// endpoint is a pod (or a stand-in for pods matching a label selector)
+// described by what network policies select on
+type endpoint struct {
+	Namespace       string
+	Name            string
+	Labels          map[string]string
+	NamespaceLabels map[string]string
+	IP              string
+	Ports           []k8s.ContainerPort
+}
+
+// String renders the endpoint as namespace/name
+func (e endpoint) String() string {
+	return e.Namespace + "/" + e.Name
+}
+
+// portSpec is the destination port traffic is sent to
+type portSpec struct {
+	// Port is the destination pod port
+	Port int
+	// Name is the destination container port name, if the port is named
+	Name     string
+	Protocol string
+}
+
+// String renders the port as port/protocol
+func (p portSpec) String() string {
+	return fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+}
+
+// RuleRef identifies the policy rule responsible for a decision
+type RuleRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Rule is the rule position, e.g. "egress[0]"; empty when the policy
+	// itself (not a rule) is responsible
+	Rule string `json:"rule,omitempty"`
+}
+
+// String renders the reference as kind/namespace/name[rule]
+func (r RuleRef) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+	if r.Rule != "" {
+		name += " " + r.Rule
+	}
+	return r.Kind + " " + name
+}
+
+// policyResult is how one policy treats traffic in one direction
+type policyResult struct {
+	policy RuleRef
+	// selects is true when the policy applies to the subject endpoint in this direction
+	selects bool
+	// match is the rule that decided, with its action; nil when no rule matched
+	match  *RuleRef
+	action string
+	// order is the Calico evaluation order (lower first)
+	order float64
+	// ordered marks Calico policies, which use first-match evaluation
+	ordered bool
+}
+
+// DirectionResult is the verdict for one direction of a connection
+type DirectionResult struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+	// SelectingPolicies are the policies that isolate the endpoint in this direction
+	SelectingPolicies []string  `json:"selectingPolicies,omitempty"`
+	AllowedBy         []RuleRef `json:"allowedBy,omitempty"`
+	DeniedBy          []RuleRef `json:"deniedBy,omitempty"`
+}
+
+// decide combines per-policy results into a verdict. Without selecting
+// policies traffic is allowed. When Calico policies are involved, policies
+// are evaluated by order and the first Allow or Deny wins, with Kubernetes
+// policies at Calico's order 1000. Otherwise (Kubernetes and Cilium) any
+// deny wins, then any allow, and isolated endpoints deny by default.
+func decide(results []policyResult, direction string) DirectionResult {
+	var selecting []policyResult
+	ordered := false
+	for _, r := range results {
+		if r.selects {
+			selecting = append(selecting, r)
+			ordered = ordered || r.ordered
+		}
+	}
+
+	result := DirectionResult{}
+	if len(selecting) == 0 {
+		result.Allowed = true
+		result.Reason = fmt.Sprintf("no policy selects the endpoint for %s, so all %s traffic is allowed", direction, direction)
+		return result
+	}
+	for _, r := range selecting {
+		result.SelectingPolicies = append(result.SelectingPolicies, r.policy.String())
+	}
+
+	if ordered {
+		sort.SliceStable(selecting, func(i, j int) bool { return selecting[i].order < selecting[j].order })
+		for _, r := range selecting {
+			if r.match == nil {
+				continue
+			}
+			switch r.action {
+			case actionAllow:
+				result.Allowed = true
+				result.AllowedBy = []RuleRef{*r.match}
+				result.Reason = "first matching rule in policy order allows the traffic"
+				return result
+			case actionDeny:
+				result.DeniedBy = []RuleRef{*r.match}
+				result.Reason = "first matching rule in policy order denies the traffic"
+				return result
+			case actionPass:
+				result.Allowed = true
+				result.AllowedBy = []RuleRef{*r.match}
+				result.Reason = "a Pass rule skips the remaining policies and the default profile allows the traffic"
+				return result
+			}
+		}
+		result.Reason = "policies select the endpoint but no rule matches, so the traffic is denied by default"
+		return result
+	}
+
+	for _, r := range selecting {
+		if r.match != nil && r.action == actionDeny {
+			result.DeniedBy = append(result.DeniedBy, *r.match)
+		}
+	}
+	if len(result.DeniedBy) > 0 {
+		result.Reason = "a deny rule matches the traffic; deny rules take precedence over allow rules"
+		return result
+	}
+	for _, r := range selecting {
+		if r.match != nil && r.action == actionAllow {
+			result.AllowedBy = append(result.AllowedBy, *r.match)
+		}
+	}
+	if len(result.AllowedBy) > 0 {
+		result.Allowed = true
+		result.Reason = "at least one rule of the selecting policies allows the traffic"
+		return result
+	}
+	result.Reason = "policies select the endpoint but none of their rules allow the traffic, so it is denied by default"
+	return result
+}
+
+// protocolOrDefault returns the protocol, defaulting to TCP
+func protocolOrDefault(protocol string) string {
+	if protocol == "" {
+		return "TCP"
+	}
+	return strings.ToUpper(protocol)
+}
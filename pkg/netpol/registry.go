@@ -0,0 +1,58 @@
+package netpol
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterNetpolReachability registers the netpol_reachability tool
+func RegisterNetpolReachability() mcp.Tool {
+	return mcp.NewTool("netpol_reachability",
+		mcp.WithDescription(`Compute whether network policies allow traffic from source pods to destination pods on a port, and name the policies and rules responsible.
+
+Loads the pods, namespaces and service involved plus networking.k8s.io/v1 NetworkPolicies, Calico NetworkPolicies and GlobalNetworkPolicies, and Cilium CiliumNetworkPolicies and CiliumClusterwideNetworkPolicies when those CRDs are installed.
+Evaluates egress from the source and ingress to the destination for each distinct pair of pods. Calico policies are evaluated in order (first matching Allow/Deny/Pass wins); for Kubernetes and Cilium policies deny rules win over allow rules.
+With destination_service, the service port is translated to the pods' targetPort.
+
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("source_namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the client pods"),
+		),
+		mcp.WithString("source_pod",
+			mcp.Description("Name of the client pod (or use source_labels)"),
+		),
+		mcp.WithString("source_labels",
+			mcp.Description("Labels of the client pods, e.g. 'app=worker' (or use source_pod)"),
+		),
+		mcp.WithString("destination_namespace",
+			mcp.Description("Namespace of the server pods (default: source_namespace)"),
+		),
+		mcp.WithString("destination_pod",
+			mcp.Description("Name of the server pod (or use destination_labels or destination_service)"),
+		),
+		mcp.WithString("destination_labels",
+			mcp.Description("Labels of the server pods, e.g. 'app=api,tier=backend'"),
+		),
+		mcp.WithString("destination_service",
+			mcp.Description("Service in front of the server pods; port is then the service port"),
+		),
+		mcp.WithNumber("port",
+			mcp.Required(),
+			mcp.Description("Destination port (pod port, or service port with destination_service)"),
+		),
+		mcp.WithString("protocol",
+			mcp.Description("Protocol of the traffic"),
+			mcp.Enum("TCP", "UDP", "SCTP"),
+			mcp.DefaultString("TCP"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "NetworkPolicy Reachability",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,264 @@
+package netpol
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// calicoSelector is a compiled Calico selector expression
+type calicoSelector func(labels map[string]string) bool
+
+// selectAll matches every endpoint
+func selectAll(map[string]string) bool { return true }
+
+// parseCalicoSelector compiles a Calico selector such as
+// "app == 'web' && has(tier) && env in {'prod', 'staging'}". It supports
+// ==, !=, in, not in, has(), contains, starts with, ends with, all(),
+// global(), !, &&, || and parentheses. An empty selector matches everything.
+func parseCalicoSelector(expr string) (calicoSelector, error) {
+	if strings.TrimSpace(expr) == "" {
+		return selectAll, nil
+	}
+	tokens, err := tokenizeSelector(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &selectorParser{tokens: tokens}
+	sel, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected '%s' in selector %q", p.peek(), expr)
+	}
+	return sel, nil
+}
+
+// selectorToken is a lexical token; quoted strings keep their quote prefix
+// so they can be told apart from label keys
+type selectorToken string
+
+func (t selectorToken) isString() bool {
+	return strings.HasPrefix(string(t), "'")
+}
+
+func (t selectorToken) value() string {
+	return strings.TrimPrefix(string(t), "'")
+}
+
+// tokenizeSelector splits a selector into operators, keys and strings
+func tokenizeSelector(expr string) ([]selectorToken, error) {
+	var tokens []selectorToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in selector %q", expr)
+			}
+			tokens = append(tokens, selectorToken("'"+expr[i+1:i+1+end]))
+			i += end + 2
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="):
+			tokens = append(tokens, selectorToken(expr[i:i+2]))
+			i += 2
+		case strings.ContainsRune("(){},!", rune(c)):
+			tokens = append(tokens, selectorToken(expr[i:i+1]))
+			i++
+		case isKeyChar(rune(c)):
+			start := i
+			for i < len(expr) && isKeyChar(rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, selectorToken(expr[start:i]))
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' in selector %q", c, expr)
+		}
+	}
+	return tokens, nil
+}
+
+// isKeyChar reports whether r may appear in a label key
+func isKeyChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./", r)
+}
+
+// selectorParser is a recursive-descent parser over selector tokens
+type selectorParser struct {
+	tokens []selectorToken
+	pos    int
+}
+
+func (p *selectorParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *selectorParser) peek() selectorToken {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *selectorParser) next() selectorToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *selectorParser) expect(want string) error {
+	if got := p.next(); string(got) != want {
+		return fmt.Errorf("expected '%s' in selector, got '%s'", want, got)
+	}
+	return nil
+}
+
+func (p *selectorParser) parseOr() (calicoSelector, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(labels map[string]string) bool { return l(labels) || right(labels) }
+	}
+	return left, nil
+}
+
+func (p *selectorParser) parseAnd() (calicoSelector, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(labels map[string]string) bool { return l(labels) && right(labels) }
+	}
+	return left, nil
+}
+
+func (p *selectorParser) parseUnary() (calicoSelector, error) {
+	if p.peek() == "!" {
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(labels map[string]string) bool { return !inner(labels) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *selectorParser) parsePrimary() (calicoSelector, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of selector")
+	case tok == "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case tok == "all" || tok == "global":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		return selectAll, p.expect(")")
+	case tok == "has":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		key := string(p.next())
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(labels map[string]string) bool { _, ok := labels[key]; return ok }, nil
+	case tok.isString():
+		return nil, fmt.Errorf("unexpected string '%s' in selector", tok.value())
+	}
+
+	key := string(tok)
+	switch op := p.next(); op {
+	case "==", "!=":
+		val := p.next()
+		if !val.isString() {
+			return nil, fmt.Errorf("expected a quoted value after '%s %s'", key, op)
+		}
+		want := val.value()
+		if op == "==" {
+			return func(labels map[string]string) bool { v, ok := labels[key]; return ok && v == want }, nil
+		}
+		return func(labels map[string]string) bool { v, ok := labels[key]; return !ok || v != want }, nil
+	case "in":
+		set, err := p.parseSet()
+		if err != nil {
+			return nil, err
+		}
+		return func(labels map[string]string) bool { v, ok := labels[key]; return ok && set[v] }, nil
+	case "not":
+		if err := p.expect("in"); err != nil {
+			return nil, err
+		}
+		set, err := p.parseSet()
+		if err != nil {
+			return nil, err
+		}
+		return func(labels map[string]string) bool { v, ok := labels[key]; return !ok || !set[v] }, nil
+	case "contains", "starts", "ends":
+		if op != "contains" {
+			if err := p.expect("with"); err != nil {
+				return nil, err
+			}
+		}
+		val := p.next()
+		if !val.isString() {
+			return nil, fmt.Errorf("expected a quoted value after '%s %s'", key, op)
+		}
+		want := val.value()
+		match := strings.Contains
+		switch op {
+		case "starts":
+			match = strings.HasPrefix
+		case "ends":
+			match = strings.HasSuffix
+		}
+		return func(labels map[string]string) bool { v, ok := labels[key]; return ok && match(v, want) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator '%s' after '%s' in selector", op, key)
+	}
+}
+
+// parseSet parses "{'a', 'b'}"
+func (p *selectorParser) parseSet() (map[string]bool, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	set := map[string]bool{}
+	for p.peek() != "}" {
+		val := p.next()
+		if !val.isString() {
+			return nil, fmt.Errorf("expected a quoted value in set, got '%s'", val)
+		}
+		set[val.value()] = true
+		if p.peek() == "," {
+			p.next()
+		}
+	}
+	p.next()
+	return set, nil
+}
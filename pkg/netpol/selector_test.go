@@ -0,0 +1,50 @@
+package netpol
+
+import (
+	"testing"
+)
+
+func TestParseCalicoSelector(t *testing.T) {
+	labels := map[string]string{"app": "web", "tier": "frontend", "env": "prod"}
+
+	tests := []struct {
+		selector string
+		expected bool
+	}{
+		{"", true},
+		{"all()", true},
+		{"app == 'web'", true},
+		{"app == \"api\"", false},
+		{"app != 'api'", true},
+		{"missing != 'x'", true},
+		{"has(tier)", true},
+		{"!has(tier)", false},
+		{"env in {'prod', 'staging'}", true},
+		{"env not in {'prod'}", false},
+		{"app == 'web' && tier == 'backend'", false},
+		{"app == 'web' && (tier == 'backend' || env == 'prod')", true},
+		{"!(app == 'web')", false},
+		{"tier starts with 'front'", true},
+		{"tier ends with 'end'", true},
+		{"tier contains 'ron'", true},
+	}
+
+	for _, tt := range tests {
+		sel, err := parseCalicoSelector(tt.selector)
+		if err != nil {
+			t.Errorf("Expected %q to parse, got %v", tt.selector, err)
+			continue
+		}
+		if got := sel(labels); got != tt.expected {
+			t.Errorf("Expected %q to be %v, got %v", tt.selector, tt.expected, got)
+		}
+	}
+}
+
+func TestParseCalicoSelectorErrors(t *testing.T) {
+	for _, selector := range []string{"app ==", "app = 'web'", "has(app", "app == 'web' &&", "'web'", "app in {web}", "app == 'web"} {
+		if _, err := parseCalicoSelector(selector); err == nil {
+			t.Errorf("Expected %q to fail to parse", selector)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	namePattern     = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+	toolNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	flagPattern     = regexp.MustCompile(`^--?[A-Za-z0-9][A-Za-z0-9-]*$`)
+)
+
+// reservedToolNames are the names of the built-in CLI tools
+var reservedToolNames = map[string]bool{
+	"call_kubectl":  true,
+	"call_helm":     true,
+	"call_cilium":   true,
+	"call_hubble":   true,
+	"call_flux":     true,
+	"call_argocd":   true,
+	"call_istioctl": true,
+}
+
+// Descriptor declares a CLI that is exposed as an MCP tool. It is loaded
+// from a YAML file, for example:
+//
+//	name: kubelogin
+//	description: Run kubelogin commands to manage Azure AD kubeconfig credentials
+//	operations:
+//	  read: [version, help]
+//	  readwrite: [convert-kubeconfig]
+//	blocked_flags: [--kubeconfig]
+type Descriptor struct {
+	// Name identifies the CLI in validation errors and cache keys, and is the
+	// word commands start with (e.g. "kubelogin version")
+	Name string `yaml:"name"`
+	// Binary is the executable to run (default: Name)
+	Binary string `yaml:"binary"`
+	// Tool is the MCP tool name (default: call_<name>)
+	Tool string `yaml:"tool"`
+	// Title is the human readable tool title (default: Call <name>)
+	Title string `yaml:"title"`
+	// Description is the tool description
+	Description string `yaml:"description"`
+	// CommandDescription describes the command parameter
+	CommandDescription string `yaml:"command_description"`
+	// Operations are the verbs allowed at each access level. Verbs may span
+	// several words, e.g. "app list".
+	Operations Operations `yaml:"operations"`
+	// BlockedFlags are rejected at every access level, e.g. flags that
+	// redirect API traffic or inject credentials
+	BlockedFlags []string `yaml:"blocked_flags"`
+	// Namespace describes how the CLI selects namespaces
+	Namespace NamespaceFlags `yaml:"namespace"`
+}
+
+// Operations are the verbs of a CLI by the access level that allows them
+type Operations struct {
+	Read      []string `yaml:"read"`
+	ReadWrite []string `yaml:"readwrite"`
+	Admin     []string `yaml:"admin"`
+}
+
+// NamespaceFlags describe the namespace flags of a CLI, used to enforce
+// --allow-namespaces
+type NamespaceFlags struct {
+	// Flags take a namespace as their value, e.g. [-n, --namespace]
+	Flags []string `yaml:"flags"`
+	// AllNamespacesFlags select every namespace, e.g. [-A, --all-namespaces]
+	AllNamespacesFlags []string `yaml:"all_namespaces_flags"`
+	// ExemptOperations may run without a namespace flag, e.g. [version]
+	ExemptOperations []string `yaml:"exempt_operations"`
+}
+
+// Load reads the descriptors in the given files and directories. Every
+// *.yaml and *.yml file of a directory is loaded, in name order.
+func Load(paths []string) ([]*Descriptor, error) {
+	var descriptors []*Descriptor
+	tools := map[string]string{}
+	names := map[string]string{}
+	for _, path := range paths {
+		files, err := descriptorFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			d, err := LoadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if other, ok := names[d.Name]; ok {
+				return nil, fmt.Errorf("plugin '%s' in %s is already defined in %s", d.Name, file, other)
+			}
+			if other, ok := tools[d.Tool]; ok {
+				return nil, fmt.Errorf("tool '%s' in %s is already defined in %s", d.Tool, file, other)
+			}
+			names[d.Name], tools[d.Tool] = file, file
+			descriptors = append(descriptors, d)
+		}
+	}
+	return descriptors, nil
+}
+
+// descriptorFiles returns path itself, or the YAML files of a directory
+func descriptorFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin descriptors: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin descriptors: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// LoadFile reads and validates a single descriptor
+func LoadFile(file string) (*Descriptor, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin descriptor: %w", err)
+	}
+	d, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin descriptor %s: %w", file, err)
+	}
+	return d, nil
+}
+
+// Parse decodes and validates a descriptor. Unknown fields are rejected so
+// that a misspelled verb list is not silently ignored.
+func Parse(data []byte) (*Descriptor, error) {
+	d := &Descriptor{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(d); err != nil {
+		return nil, err
+	}
+	d.setDefaults()
+	return d, d.validate()
+}
+
+// setDefaults fills in the binary, tool name and title, and normalizes the
+// spacing of multi-word operations
+func (d *Descriptor) setDefaults() {
+	for _, list := range []*[]string{&d.Operations.Read, &d.Operations.ReadWrite, &d.Operations.Admin, &d.Namespace.ExemptOperations} {
+		for i, op := range *list {
+			(*list)[i] = strings.Join(strings.Fields(op), " ")
+		}
+	}
+	if d.Binary == "" {
+		d.Binary = d.Name
+	}
+	if d.Tool == "" {
+		d.Tool = "call_" + strings.ReplaceAll(d.Name, "-", "_")
+	}
+	if d.Title == "" {
+		d.Title = "Call " + d.Name
+	}
+	if d.CommandDescription == "" {
+		d.CommandDescription = fmt.Sprintf("Full %s command to execute (e.g., '%s %s')", d.Name, d.Name, firstOperation(d.Operations))
+	}
+}
+
+// validate checks the descriptor
+func (d *Descriptor) validate() error {
+	if !namePattern.MatchString(d.Name) {
+		return fmt.Errorf("name must match %s", namePattern)
+	}
+	if security.IsBuiltinCommandType(d.Name) {
+		return fmt.Errorf("name '%s' is a built-in tool", d.Name)
+	}
+	if strings.ContainsAny(d.Binary, " \t\n\"'") {
+		return fmt.Errorf("binary must not contain whitespace or quotes")
+	}
+	if !toolNamePattern.MatchString(d.Tool) || len(d.Tool) > 64 {
+		return fmt.Errorf("tool must match %s and be at most 64 characters", toolNamePattern)
+	}
+	if reservedToolNames[d.Tool] {
+		return fmt.Errorf("tool '%s' is a built-in tool", d.Tool)
+	}
+	if strings.TrimSpace(d.Description) == "" {
+		return fmt.Errorf("description is required")
+	}
+	if len(d.Operations.Read)+len(d.Operations.ReadWrite)+len(d.Operations.Admin) == 0 {
+		return fmt.Errorf("at least one operation is required")
+	}
+
+	levels := map[string]string{}
+	for level, list := range map[string][]string{"read": d.Operations.Read, "readwrite": d.Operations.ReadWrite, "admin": d.Operations.Admin} {
+		for _, op := range list {
+			if op == "" || strings.HasPrefix(op, "-") {
+				return fmt.Errorf("invalid %s operation '%s'", level, op)
+			}
+			if other, ok := levels[op]; ok {
+				return fmt.Errorf("operation '%s' is listed as both %s and %s", op, other, level)
+			}
+			levels[op] = level
+		}
+	}
+	for _, op := range d.Namespace.ExemptOperations {
+		if _, ok := levels[op]; !ok {
+			return fmt.Errorf("namespace exempt operation '%s' is not a listed operation", op)
+		}
+	}
+	for _, list := range [][]string{d.BlockedFlags, d.Namespace.Flags, d.Namespace.AllNamespacesFlags} {
+		for _, flag := range list {
+			if !flagPattern.MatchString(flag) {
+				return fmt.Errorf("invalid flag '%s'", flag)
+			}
+		}
+	}
+	return nil
+}
+
+// Policy returns the validation rules of the CLI
+func (d *Descriptor) Policy() *security.CommandPolicy {
+	return &security.CommandPolicy{
+		ReadOperations:            d.Operations.Read,
+		ReadWriteOperations:       d.Operations.ReadWrite,
+		AdminOperations:           d.Operations.Admin,
+		BlockedGlobalFlags:        d.BlockedFlags,
+		NamespaceFlags:            d.Namespace.Flags,
+		AllNamespacesFlags:        d.Namespace.AllNamespacesFlags,
+		NamespaceExemptOperations: d.Namespace.ExemptOperations,
+	}
+}
+
+// firstOperation returns the first listed operation, for examples
+func firstOperation(ops Operations) string {
+	for _, list := range [][]string{ops.Read, ops.ReadWrite, ops.Admin} {
+		if len(list) > 0 {
+			return list[0]
+		}
+	}
+	return "help"
+}
@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+)
+
+// deployctlDescriptor is a descriptor with multi-word operations and namespace flags
+const deployctlDescriptor = `name: deployctl
+description: Run deployctl commands
+operations:
+  read: [version, app list, "app  get"]
+  readwrite: [app sync]
+blocked_flags: [--server, --auth-token]
+namespace:
+  flags: [-N, --app-namespace]
+  exempt_operations: [version]
+`
+
+func TestParseDescriptor(t *testing.T) {
+	d, err := Parse([]byte(deployctlDescriptor))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if d.Binary != "deployctl" || d.Tool != "call_deployctl" || d.Title != "Call deployctl" {
+		t.Errorf("Expected defaults for binary, tool and title, got %+v", d)
+	}
+	if d.Operations.Read[2] != "app get" {
+		t.Errorf("Expected operation spacing to be normalized, got %q", d.Operations.Read[2])
+	}
+	if !strings.Contains(d.CommandDescription, "deployctl version") {
+		t.Errorf("Expected an example command in the command description, got %q", d.CommandDescription)
+	}
+
+	tests := []struct {
+		name       string
+		descriptor string
+		err        string
+	}{
+		{"unknown field", "name: x\ndescription: x\noperations: {read: [a]}\nblockedflags: [--x]\n", "field blockedflags not found"},
+		{"invalid name", "name: X y\ndescription: x\noperations: {read: [a]}\n", "name must match"},
+		{"built-in name", "name: helm\ndescription: x\noperations: {read: [a]}\n", "built-in tool"},
+		{"built-in tool", "name: x\ntool: call_kubectl\ndescription: x\noperations: {read: [a]}\n", "built-in tool"},
+		{"binary with arguments", "name: x\nbinary: x --flag\ndescription: x\noperations: {read: [a]}\n", "binary must not contain"},
+		{"no description", "name: x\noperations: {read: [a]}\n", "description is required"},
+		{"no operations", "name: x\ndescription: x\n", "at least one operation"},
+		{"duplicate operation", "name: x\ndescription: x\noperations: {read: [a], admin: [a]}\n", "listed as both"},
+		{"unlisted exempt operation", "name: x\ndescription: x\noperations: {read: [a]}\nnamespace: {exempt_operations: [b]}\n", "not a listed operation"},
+		{"invalid flag", "name: x\ndescription: x\noperations: {read: [a]}\nblocked_flags: [server]\n", "invalid flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.descriptor))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestLoadDescriptors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"deployctl.yaml": deployctlDescriptor,
+		"echo.yml":       "name: echo\ndescription: Echo\noperations: {read: [hello]}\n",
+		"README.md":      "not a descriptor",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	descriptors, err := Load([]string{dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(descriptors) != 2 || descriptors[0].Name != "deployctl" || descriptors[1].Name != "echo" {
+		t.Errorf("Expected deployctl and echo in name order, got %+v", descriptors)
+	}
+
+	if _, err := Load([]string{dir, filepath.Join(dir, "echo.yml")}); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("Expected a duplicate plugin error, got %v", err)
+	}
+	if _, err := Load([]string{filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Errorf("Expected an error for a missing descriptor")
+	}
+}
+
+func TestExecutor(t *testing.T) {
+	d, err := Parse([]byte("name: greet\nbinary: echo\ndescription: Greet\noperations: {read: [hello], readwrite: [wave]}\nnamespace: {flags: [-n]}\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cfg := config.NewConfig()
+	if err := cfg.SecurityConfig.SetCommandPolicy(d.Name, d.Policy()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	executor := NewExecutor(d)
+
+	for _, command := range []string{"greet hello -n team-a", "echo hello -n team-a", "hello -n team-a"} {
+		output, err := executor.Execute(context.Background(), map[string]interface{}{"command": command}, cfg)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got %v", command, err)
+		}
+		if strings.TrimSpace(output) != "hello -n team-a" {
+			t.Errorf("Expected the binary to run with the arguments of %q, got %q", command, output)
+		}
+	}
+
+	if _, err := executor.Execute(context.Background(), map[string]interface{}{"command": "greet wave"}, cfg); err == nil {
+		t.Errorf("Expected a write operation to be rejected at readonly")
+	}
+	cfg.SecurityConfig.SetAllowedNamespaces("team-a")
+	if _, err := executor.Execute(context.Background(), map[string]interface{}{"command": "greet hello -n team-b"}, cfg); err == nil {
+		t.Errorf("Expected a denied namespace to be rejected")
+	}
+
+	commandType, command, ok := executor.DescribeCommand(map[string]interface{}{"command": "echo hello"})
+	if !ok || commandType != "greet" || command != "greet hello" {
+		t.Errorf("Expected greet hello, got %s %q %v", commandType, command, ok)
+	}
+}
+
+func TestRegisterTool(t *testing.T) {
+	d, err := Parse([]byte(deployctlDescriptor))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		accessLevel string
+		readOnly    bool
+	}{
+		{"readonly", true},
+		{"readwrite", false},
+		{"admin", false},
+	}
+	for _, tt := range tests {
+		tool := RegisterTool(d, tt.accessLevel)
+		if tool.Name != "call_deployctl" || *tool.Annotations.ReadOnlyHint != tt.readOnly {
+			t.Errorf("Expected call_deployctl with read-only %v at %s, got %s %v", tt.readOnly, tt.accessLevel, tool.Name, *tool.Annotations.ReadOnlyHint)
+		}
+	}
+	if _, ok := RegisterTool(d, "readonly").InputSchema.Properties["command"]; !ok {
+		t.Errorf("Expected a command parameter")
+	}
+}
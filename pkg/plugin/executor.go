@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// Executor implements the CommandExecutor interface for a plugin CLI
+type Executor struct {
+	descriptor *Descriptor
+}
+
+// This line ensures Executor implements the CommandExecutor and CommandDescriber interfaces
+var _ tools.CommandExecutor = (*Executor)(nil)
+var _ tools.CommandDescriber = (*Executor)(nil)
+
+// NewExecutor creates a new Executor for the CLI of a descriptor
+func NewExecutor(descriptor *Descriptor) *Executor {
+	return &Executor{descriptor: descriptor}
+}
+
+// Execute validates the command against the descriptor's rules and runs it
+func (e *Executor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	cmd, ok := e.command(params)
+	if !ok {
+		return "", fmt.Errorf("invalid command parameter")
+	}
+
+	// Validate the command against security settings
+	validator := security.NewValidator(cfg.SecurityConfig)
+	if err := validator.ValidateCommand(cmd, e.descriptor.Name); err != nil {
+		return "", err
+	}
+
+	// Execute the command with the configured binary in place of the name
+	process := command.NewShellProcess(e.descriptor.Binary, cfg.Timeout)
+	process.Limiter = cfg.ProcessLimiter
+	return process.Run(e.descriptor.Binary + strings.TrimPrefix(cmd, e.descriptor.Name))
+}
+
+// DescribeCommand returns the command a call would run
+func (e *Executor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	cmd, ok := e.command(params)
+	return e.descriptor.Name, cmd, ok
+}
+
+// command returns the command parameter, starting with the plugin name. A
+// leading name or binary is replaced by the name, and commands without one
+// get it prepended, so the validator and the executor see the same argv.
+func (e *Executor) command(params map[string]interface{}) (string, bool) {
+	cmd, ok := params["command"].(string)
+	if !ok {
+		return "", false
+	}
+	cmd = strings.TrimSpace(cmd)
+	if fields := strings.Fields(cmd); len(fields) > 0 && (fields[0] == e.descriptor.Name || fields[0] == e.descriptor.Binary) {
+		cmd = strings.TrimSpace(strings.TrimPrefix(cmd, fields[0]))
+	}
+	return strings.TrimSpace(e.descriptor.Name + " " + cmd), true
+}
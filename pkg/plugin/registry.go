@@ -0,0 +1,31 @@
+package plugin
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterTool registers the tool of a plugin CLI. It is marked read-only
+// when the access level only admits the CLI's read operations.
+func RegisterTool(descriptor *Descriptor, accessLevel string) mcp.Tool {
+	writable := (accessLevel != "readonly" && len(descriptor.Operations.ReadWrite) > 0) ||
+		(accessLevel == "admin" && len(descriptor.Operations.Admin) > 0)
+	readOnly := !writable
+
+	return mcp.NewTool(descriptor.Tool,
+		mcp.WithDescription(descriptor.Description),
+		mcp.WithString("command",
+			mcp.Required(),
+			mcp.Description(descriptor.CommandDescription),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           descriptor.Title,
+			ReadOnlyHint:    boolPtr(readOnly),
+			DestructiveHint: boolPtr(!readOnly),
+		}),
+	)
+}
@@ -0,0 +1,318 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope identifies which limit rejected a request
+const (
+	ScopeGlobal = "global"
+	ScopeClient = "client"
+	ScopeTool   = "tool"
+)
+
+// idleBucketTTL is how long a per-client limiter may sit unused before it is pruned
+const idleBucketTTL = 10 * time.Minute
+
+// Limit describes a token-bucket rate and a concurrency cap.
+// Zero values disable the respective check.
+type Limit struct {
+	// Rate is the sustained number of requests per second
+	Rate float64
+	// Burst is the maximum number of requests allowed at once (defaults to ceil(Rate))
+	Burst int
+	// MaxConcurrent is the maximum number of in-flight requests
+	MaxConcurrent int
+}
+
+// IsZero reports whether the limit enforces nothing
+func (l Limit) IsZero() bool {
+	return l.Rate <= 0 && l.MaxConcurrent <= 0
+}
+
+// Config holds the rate limiting configuration
+type Config struct {
+	// Global applies to all requests handled by the server
+	Global Limit
+	// PerClient applies to each client session independently
+	PerClient Limit
+	// PerTool applies to each tool by name, shared across clients
+	PerTool map[string]Limit
+	// MaxProcesses caps the CLI subprocesses running at once across all
+	// tool calls, including those started by composite tools (0 means unlimited)
+	MaxProcesses int
+}
+
+// NewConfig creates an empty configuration with no limits
+func NewConfig() *Config {
+	return &Config{
+		PerTool: make(map[string]Limit),
+	}
+}
+
+// IsEnabled reports whether any limit is configured
+func (c *Config) IsEnabled() bool {
+	if !c.Global.IsZero() || !c.PerClient.IsZero() {
+		return true
+	}
+	for _, l := range c.PerTool {
+		if !l.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseToolLimits parses a comma-separated list of per-tool limits in the
+// form "tool=rate[:burst[:concurrency]]", e.g. "call_kubectl=5:10:4,call_helm=1".
+func ParseToolLimits(spec string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid tool limit '%s': expected tool=rate[:burst[:concurrency]]", entry)
+		}
+
+		parts := strings.Split(value, ":")
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("invalid tool limit '%s': expected tool=rate[:burst[:concurrency]]", entry)
+		}
+
+		var limit Limit
+		rate, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid rate in tool limit '%s'", entry)
+		}
+		limit.Rate = rate
+
+		if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+			burst, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err != nil || burst < 0 {
+				return nil, fmt.Errorf("invalid burst in tool limit '%s'", entry)
+			}
+			limit.Burst = burst
+		}
+
+		if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+			concurrency, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+			if err != nil || concurrency < 0 {
+				return nil, fmt.Errorf("invalid concurrency in tool limit '%s'", entry)
+			}
+			limit.MaxConcurrent = concurrency
+		}
+
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// RejectionError is returned when a request exceeds a configured limit
+type RejectionError struct {
+	// Scope is the limit that rejected the request (global, client or tool)
+	Scope string
+	// Reason describes whether the rate or the concurrency cap was exceeded
+	Reason string
+	// RetryAfter is a hint for how long the caller should wait before retrying
+	RetryAfter time.Duration
+}
+
+func (e *RejectionError) Error() string {
+	return fmt.Sprintf("Error: %s limit exceeded (%s); retry after %.1fs",
+		e.Scope, e.Reason, e.RetryAfter.Seconds())
+}
+
+// bucket is a token bucket combined with an in-flight counter
+type bucket struct {
+	limit    Limit
+	tokens   float64
+	last     time.Time
+	inFlight int
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	if limit.Rate > 0 && limit.Burst <= 0 {
+		limit.Burst = int(math.Max(1, math.Ceil(limit.Rate)))
+	}
+	return &bucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+}
+
+// check reports whether the bucket can admit a request at the given time
+// without consuming anything. The returned reason is empty on success.
+func (b *bucket) check(now time.Time) (reason string, retryAfter time.Duration) {
+	if b.limit.MaxConcurrent > 0 && b.inFlight >= b.limit.MaxConcurrent {
+		return "too many concurrent requests", time.Second
+	}
+	if b.limit.Rate > 0 {
+		b.refill(now)
+		if b.tokens < 1 {
+			wait := (1 - b.tokens) / b.limit.Rate
+			return "request rate too high", time.Duration(wait * float64(time.Second))
+		}
+	}
+	return "", 0
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.last = now
+	}
+}
+
+func (b *bucket) take() {
+	if b.limit.Rate > 0 {
+		b.tokens--
+	}
+	b.inFlight++
+}
+
+func (b *bucket) done() {
+	if b.inFlight > 0 {
+		b.inFlight--
+	}
+}
+
+// Limiter enforces global, per-client and per-tool limits
+type Limiter struct {
+	config  *Config
+	mu      sync.Mutex
+	global  *bucket
+	tools   map[string]*bucket
+	clients map[string]*bucket
+	seen    map[string]time.Time
+	now     func() time.Time
+}
+
+// NewLimiter creates a new Limiter from the given configuration
+func NewLimiter(config *Config) *Limiter {
+	return &Limiter{
+		config:  config,
+		tools:   make(map[string]*bucket),
+		clients: make(map[string]*bucket),
+		seen:    make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Acquire admits a request for the given client and tool. On success it
+// returns a release function that must be called when the request finishes.
+// On rejection it returns a *RejectionError describing the limit hit.
+func (l *Limiter) Acquire(clientID, toolName string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.pruneIdleClients(now)
+
+	type scoped struct {
+		scope string
+		b     *bucket
+	}
+	var buckets []scoped
+
+	if !l.config.Global.IsZero() {
+		if l.global == nil {
+			l.global = newBucket(l.config.Global, now)
+		}
+		buckets = append(buckets, scoped{ScopeGlobal, l.global})
+	}
+	if !l.config.PerClient.IsZero() {
+		b, ok := l.clients[clientID]
+		if !ok {
+			b = newBucket(l.config.PerClient, now)
+			l.clients[clientID] = b
+		}
+		l.seen[clientID] = now
+		buckets = append(buckets, scoped{ScopeClient, b})
+	}
+	if limit, ok := l.config.PerTool[toolName]; ok && !limit.IsZero() {
+		b, ok := l.tools[toolName]
+		if !ok {
+			b = newBucket(limit, now)
+			l.tools[toolName] = b
+		}
+		buckets = append(buckets, scoped{ScopeTool, b})
+	}
+
+	// Check every bucket before consuming from any, so a rejection by one
+	// scope does not burn tokens in another.
+	for _, s := range buckets {
+		if reason, retryAfter := s.b.check(now); reason != "" {
+			return nil, &RejectionError{Scope: s.scope, Reason: reason, RetryAfter: retryAfter}
+		}
+	}
+	for _, s := range buckets {
+		s.b.take()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for _, s := range buckets {
+				s.b.done()
+			}
+		})
+	}, nil
+}
+
+// pruneIdleClients drops per-client buckets that have no in-flight requests
+// and have not been used recently, so long-running servers with many
+// short-lived sessions do not accumulate state.
+func (l *Limiter) pruneIdleClients(now time.Time) {
+	for id, last := range l.seen {
+		if now.Sub(last) > idleBucketTTL && l.clients[id].inFlight == 0 {
+			delete(l.clients, id)
+			delete(l.seen, id)
+		}
+	}
+}
+
+// ProcessLimiter caps the number of CLI subprocesses running at once. Unlike
+// Limiter, which admits or rejects whole tool calls, it makes each process
+// wait for a free slot, so a composite tool that fans out into many kubectl
+// commands is throttled instead of failing part way through.
+type ProcessLimiter struct {
+	slots chan struct{}
+}
+
+// NewProcessLimiter creates a ProcessLimiter allowing max concurrent processes
+func NewProcessLimiter(max int) *ProcessLimiter {
+	return &ProcessLimiter{slots: make(chan struct{}, max)}
+}
+
+// Acquire waits for a free process slot and returns a release function that
+// must be called when the process exits. It fails if ctx is done first. A nil
+// ProcessLimiter admits every process.
+func (p *ProcessLimiter) Acquire(ctx context.Context) (func(), error) {
+	if p == nil {
+		return func() {}, nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-p.slots })
+	}, nil
+}
@@ -0,0 +1,175 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(cfg *Config, now *time.Time) *Limiter {
+	l := NewLimiter(cfg)
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestLimiterGlobalRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	cfg := NewConfig()
+	cfg.Global = Limit{Rate: 1, Burst: 2}
+	l := newTestLimiter(cfg, &now)
+
+	for i := 0; i < 2; i++ {
+		release, err := l.Acquire("a", "call_kubectl")
+		if err != nil {
+			t.Fatalf("Expected request %d within burst to be admitted, got %v", i, err)
+		}
+		release()
+	}
+
+	_, err := l.Acquire("a", "call_kubectl")
+	var rejection *RejectionError
+	if !errors.As(err, &rejection) {
+		t.Fatalf("Expected RejectionError after burst, got %v", err)
+	}
+	if rejection.Scope != ScopeGlobal {
+		t.Errorf("Expected scope %q, got %q", ScopeGlobal, rejection.Scope)
+	}
+	if rejection.RetryAfter <= 0 || rejection.RetryAfter > time.Second {
+		t.Errorf("Expected retry-after in (0, 1s], got %v", rejection.RetryAfter)
+	}
+
+	now = now.Add(time.Second)
+	if _, err := l.Acquire("a", "call_kubectl"); err != nil {
+		t.Errorf("Expected request to be admitted after refill, got %v", err)
+	}
+}
+
+func TestLimiterPerClientIsolation(t *testing.T) {
+	now := time.Unix(0, 0)
+	cfg := NewConfig()
+	cfg.PerClient = Limit{Rate: 1, Burst: 1}
+	l := newTestLimiter(cfg, &now)
+
+	if _, err := l.Acquire("a", "call_kubectl"); err != nil {
+		t.Fatalf("Expected first request for client a to be admitted, got %v", err)
+	}
+	if _, err := l.Acquire("a", "call_kubectl"); err == nil {
+		t.Error("Expected second request for client a to be rejected")
+	}
+	if _, err := l.Acquire("b", "call_kubectl"); err != nil {
+		t.Errorf("Expected client b to have its own bucket, got %v", err)
+	}
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	now := time.Unix(0, 0)
+	cfg := NewConfig()
+	cfg.PerTool["call_helm"] = Limit{MaxConcurrent: 1}
+	l := newTestLimiter(cfg, &now)
+
+	release, err := l.Acquire("a", "call_helm")
+	if err != nil {
+		t.Fatalf("Expected first request to be admitted, got %v", err)
+	}
+
+	_, err = l.Acquire("b", "call_helm")
+	var rejection *RejectionError
+	if !errors.As(err, &rejection) || rejection.Scope != ScopeTool {
+		t.Fatalf("Expected tool-scoped rejection while at concurrency cap, got %v", err)
+	}
+
+	if _, err := l.Acquire("a", "call_kubectl"); err != nil {
+		t.Errorf("Expected other tools to be unaffected, got %v", err)
+	}
+
+	release()
+	release() // releasing twice must not free an extra slot
+	if _, err := l.Acquire("b", "call_helm"); err != nil {
+		t.Errorf("Expected request to be admitted after release, got %v", err)
+	}
+	if _, err := l.Acquire("c", "call_helm"); err == nil {
+		t.Error("Expected double release to be ignored")
+	}
+}
+
+func TestLimiterRejectionDoesNotConsumeOtherScopes(t *testing.T) {
+	now := time.Unix(0, 0)
+	cfg := NewConfig()
+	cfg.Global = Limit{Rate: 1, Burst: 1}
+	cfg.PerTool["call_kubectl"] = Limit{MaxConcurrent: 1}
+	l := newTestLimiter(cfg, &now)
+
+	release, err := l.Acquire("a", "call_kubectl")
+	if err != nil {
+		t.Fatalf("Expected first request to be admitted, got %v", err)
+	}
+	defer release()
+
+	now = now.Add(time.Second)
+	if _, err := l.Acquire("a", "call_kubectl"); err == nil {
+		t.Fatal("Expected rejection by tool concurrency cap")
+	}
+	if _, err := l.Acquire("a", "call_helm"); err != nil {
+		t.Errorf("Expected global token to be preserved after tool rejection, got %v", err)
+	}
+}
+
+func TestParseToolLimits(t *testing.T) {
+	limits, err := ParseToolLimits("call_kubectl=5:10:4, call_helm=1,call_cilium=0.5::2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]Limit{
+		"call_kubectl": {Rate: 5, Burst: 10, MaxConcurrent: 4},
+		"call_helm":    {Rate: 1},
+		"call_cilium":  {Rate: 0.5, MaxConcurrent: 2},
+	}
+	for name, want := range expected {
+		if got := limits[name]; got != want {
+			t.Errorf("Expected %s limit %+v, got %+v", name, want, got)
+		}
+	}
+
+	invalid := []string{"call_kubectl", "=1", "call_kubectl=abc", "call_kubectl=1:x", "call_kubectl=1:2:3:4", "call_kubectl=-1"}
+	for _, spec := range invalid {
+		if _, err := ParseToolLimits(spec); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestProcessLimiter(t *testing.T) {
+	p := NewProcessLimiter(1)
+
+	release, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The only slot is taken, so a second process waits until its context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded while the slot is held, got %v", err)
+	}
+
+	release()
+	release() // releasing twice must not free a second slot
+	second, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected a slot after release, got %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.Acquire(ctx); err == nil {
+		t.Fatal("Expected the limiter to stay at one process after a double release")
+	}
+	second()
+
+	var unlimited *ProcessLimiter
+	if _, err := unlimited.Acquire(context.Background()); err != nil {
+		t.Errorf("Expected a nil limiter to admit every process, got %v", err)
+	}
+}
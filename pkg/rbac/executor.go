@@ -0,0 +1,109 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// WhoCanExecutor implements the CommandExecutor interface for rbac_who_can
+type WhoCanExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// SubjectPermissionsExecutor implements the CommandExecutor interface for
+// rbac_subject_permissions
+type SubjectPermissionsExecutor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures the executors implement the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*WhoCanExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*WhoCanExecutor)(nil)
+var _ tools.CommandExecutor = (*SubjectPermissionsExecutor)(nil)
+var _ tools.StructuredOutputExecutor = (*SubjectPermissionsExecutor)(nil)
+
+// newClientRunner runs commands through the validated kubectl client
+func newClientRunner(cfg *config.ConfigData) k8s.Runner {
+	return k8s.NewClient(cfg)
+}
+
+// NewWhoCanExecutor creates a new WhoCanExecutor instance
+func NewWhoCanExecutor() *WhoCanExecutor {
+	return &WhoCanExecutor{newRunner: newClientRunner}
+}
+
+// NewSubjectPermissionsExecutor creates a new SubjectPermissionsExecutor instance
+func NewSubjectPermissionsExecutor() *SubjectPermissionsExecutor {
+	return &SubjectPermissionsExecutor{newRunner: newClientRunner}
+}
+
+// Execute lists the subjects allowed to perform the request and returns the report as JSON
+func (e *WhoCanExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := WhoCanOptions{}
+	var ok bool
+	if opts.Verb, ok = params["verb"].(string); !ok || opts.Verb == "" {
+		return "", fmt.Errorf("verb parameter is required and must be a string")
+	}
+	if opts.Resource, ok = params["resource"].(string); !ok || opts.Resource == "" {
+		return "", fmt.Errorf("resource parameter is required and must be a string")
+	}
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.Name, _ = params["name"].(string)
+
+	report, err := WhoCan(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	return marshalReport(report)
+}
+
+// ReturnsStructuredOutput reports that rbac_who_can returns JSON
+func (e *WhoCanExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
+
+// Execute computes the effective rules of a subject and returns the report as JSON
+func (e *SubjectPermissionsExecutor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := SubjectOptions{}
+	var ok bool
+	if opts.Kind, ok = params["kind"].(string); !ok || opts.Kind == "" {
+		return "", fmt.Errorf("kind parameter is required and must be a string")
+	}
+	if opts.Name, ok = params["name"].(string); !ok || opts.Name == "" {
+		return "", fmt.Errorf("name parameter is required and must be a string")
+	}
+	opts.Namespace, _ = params["namespace"].(string)
+	if groups, ok := params["groups"].(string); ok {
+		for _, group := range strings.Split(groups, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				opts.Groups = append(opts.Groups, group)
+			}
+		}
+	}
+
+	report, err := SubjectPermissions(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	return marshalReport(report)
+}
+
+// ReturnsStructuredOutput reports that rbac_subject_permissions returns JSON
+func (e *SubjectPermissionsExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
+
+// marshalReport renders a report as compact JSON
+func marshalReport(report interface{}) (string, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
+}
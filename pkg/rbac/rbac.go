@@ -0,0 +1,326 @@
+package rbac
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// ScopeCluster is the scope of grants made by ClusterRoleBindings; grants
+// made by RoleBindings are scoped to the binding's namespace
+const ScopeCluster = "cluster"
+
+// namePattern accepts the names of roles and subjects, including "system:"
+// users and groups and e-mail style user names
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9:@._-]*$`)
+
+// PolicyRule is an RBAC rule
+type PolicyRule struct {
+	Verbs           []string `json:"verbs"`
+	APIGroups       []string `json:"apiGroups,omitempty"`
+	Resources       []string `json:"resources,omitempty"`
+	ResourceNames   []string `json:"resourceNames,omitempty"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+}
+
+// role is a Role or ClusterRole
+type role struct {
+	Metadata        k8s.ObjectMeta `json:"metadata"`
+	Rules           []PolicyRule   `json:"rules"`
+	AggregationRule *struct {
+		ClusterRoleSelectors []k8s.LabelSelector `json:"clusterRoleSelectors"`
+	} `json:"aggregationRule,omitempty"`
+}
+
+// Subject is a user, group or service account named in a binding
+type Subject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// binding is a RoleBinding or ClusterRoleBinding
+type binding struct {
+	Metadata k8s.ObjectMeta `json:"metadata"`
+	RoleRef  struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"roleRef"`
+	Subjects []Subject `json:"subjects"`
+}
+
+// Grant is a binding that gives its subjects the rules of a role
+type Grant struct {
+	// Binding is "ClusterRoleBinding/<name>" or "RoleBinding/<namespace>/<name>"
+	Binding string `json:"binding"`
+	// Role is "ClusterRole/<name>" or "Role/<namespace>/<name>"
+	Role string `json:"role"`
+	// Scope is "cluster" or the namespace of a RoleBinding
+	Scope string `json:"scope"`
+}
+
+// policy holds the RBAC objects visible to the server
+type policy struct {
+	clusterRoles        map[string]*role
+	roles               map[string]*role
+	clusterRoleBindings []binding
+	roleBindings        []binding
+	// aggregatedFrom lists, per aggregated ClusterRole, the ClusterRoles
+	// whose rules it includes
+	aggregatedFrom map[string][]string
+	notes          []string
+}
+
+// boundRules pairs the rules of a role with the binding that grants them
+type boundRules struct {
+	grant    Grant
+	subjects []Subject
+	rules    []PolicyRule
+}
+
+// loadPolicy reads the ClusterRoles, ClusterRoleBindings and the Roles and
+// RoleBindings of the allowed namespaces (or of namespace when set)
+func loadPolicy(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, namespace string) (*policy, error) {
+	if err := k8s.ValidateNamespace("namespace", namespace); err != nil {
+		return nil, err
+	}
+	namespaces, err := k8s.ScanNamespaces(ctx, runner, secConfig, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &policy{clusterRoles: map[string]*role{}, roles: map[string]*role{}, aggregatedFrom: map[string][]string{}}
+	var clusterRoles k8s.List[role]
+	if err := k8s.GetJSON(ctx, runner, "get clusterroles", &clusterRoles); err != nil {
+		return nil, err
+	}
+	for i := range clusterRoles.Items {
+		p.clusterRoles[clusterRoles.Items[i].Metadata.Name] = &clusterRoles.Items[i]
+	}
+	var clusterRoleBindings k8s.List[binding]
+	if err := k8s.GetJSON(ctx, runner, "get clusterrolebindings", &clusterRoleBindings); err != nil {
+		return nil, err
+	}
+	p.clusterRoleBindings = clusterRoleBindings.Items
+
+	for _, ns := range namespaces {
+		scope := "-n " + ns
+		if ns == k8s.AllNamespaces {
+			scope = "-A"
+		}
+		var roles k8s.List[role]
+		if err := k8s.GetJSON(ctx, runner, "get roles "+scope, &roles); err != nil {
+			return nil, err
+		}
+		for i := range roles.Items {
+			r := &roles.Items[i]
+			p.roles[r.Metadata.Namespace+"/"+r.Metadata.Name] = r
+		}
+		var roleBindings k8s.List[binding]
+		if err := k8s.GetJSON(ctx, runner, "get rolebindings "+scope, &roleBindings); err != nil {
+			return nil, err
+		}
+		p.roleBindings = append(p.roleBindings, roleBindings.Items...)
+	}
+	if namespace == "" && secConfig != nil && secConfig.HasNamespaceRestrictions() {
+		p.notes = append(p.notes, "Roles and RoleBindings are only read from the allowed namespaces: "+strings.Join(namespaces, ", "))
+	}
+	p.notes = append(p.notes, "Members of the system:masters group bypass RBAC and are allowed everything; other authorizers (Node, webhooks) are not evaluated")
+
+	p.aggregate()
+	return p, nil
+}
+
+// aggregate adds to each ClusterRole with an aggregation rule the rules of
+// the ClusterRoles its selectors match, as the aggregation controller does.
+// It repeats until nothing changes so that chained aggregation is resolved.
+func (p *policy) aggregate() {
+	names := make([]string, 0, len(p.clusterRoles))
+	for name := range p.clusterRoles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for changed := true; changed; {
+		changed = false
+		for _, name := range names {
+			target := p.clusterRoles[name]
+			if target.AggregationRule == nil {
+				continue
+			}
+			for _, sourceName := range names {
+				source := p.clusterRoles[sourceName]
+				if sourceName == name || !matchesAny(target.AggregationRule.ClusterRoleSelectors, source.Metadata.Labels) {
+					continue
+				}
+				if !containsString(p.aggregatedFrom[name], sourceName) {
+					p.aggregatedFrom[name] = append(p.aggregatedFrom[name], sourceName)
+				}
+				for _, rule := range source.Rules {
+					if !containsRule(target.Rules, rule) {
+						target.Rules = append(target.Rules, rule)
+						changed = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// matchesAny reports whether the labels match one of the selectors; empty
+// selectors match nothing, as in the aggregation controller
+func matchesAny(selectors []k8s.LabelSelector, labels map[string]string) bool {
+	for _, selector := range selectors {
+		if !selector.IsEmpty() && selector.Matches(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// bindings returns the subjects and rules of every binding whose role
+// exists. RoleBindings are limited to namespace when it is set.
+func (p *policy) bindings(namespace string) []boundRules {
+	var result []boundRules
+	for _, b := range p.clusterRoleBindings {
+		if r, ok := p.clusterRoles[b.RoleRef.Name]; ok && b.RoleRef.Kind == "ClusterRole" {
+			result = append(result, boundRules{
+				grant:    Grant{Binding: "ClusterRoleBinding/" + b.Metadata.Name, Role: "ClusterRole/" + r.Metadata.Name, Scope: ScopeCluster},
+				subjects: b.Subjects,
+				rules:    r.Rules,
+			})
+		}
+	}
+	for _, b := range p.roleBindings {
+		ns := b.Metadata.Namespace
+		if namespace != "" && ns != namespace {
+			continue
+		}
+		bound := boundRules{grant: Grant{Binding: "RoleBinding/" + ns + "/" + b.Metadata.Name, Scope: ns}}
+		switch b.RoleRef.Kind {
+		case "ClusterRole":
+			r, ok := p.clusterRoles[b.RoleRef.Name]
+			if !ok {
+				continue
+			}
+			bound.grant.Role, bound.rules = "ClusterRole/"+r.Metadata.Name, r.Rules
+		case "Role":
+			r, ok := p.roles[ns+"/"+b.RoleRef.Name]
+			if !ok {
+				continue
+			}
+			bound.grant.Role, bound.rules = "Role/"+ns+"/"+r.Metadata.Name, r.Rules
+		default:
+			continue
+		}
+		for _, s := range b.Subjects {
+			// A service account subject without namespace is in the binding's namespace
+			if s.Kind == "ServiceAccount" && s.Namespace == "" {
+				s.Namespace = ns
+			}
+			bound.subjects = append(bound.subjects, s)
+		}
+		result = append(result, bound)
+	}
+	return result
+}
+
+// groupOf splits a resource given as "deployments.apps" or a well-known
+// plural such as "deployments" into its API group and resource
+func groupOf(resource string) (string, string) {
+	base, subresource, hasSub := strings.Cut(resource, "/")
+	if name, group, ok := strings.Cut(base, "."); ok {
+		if hasSub {
+			name += "/" + subresource
+		}
+		return group, name
+	}
+	return wellKnownGroups[base], resource
+}
+
+// wellKnownGroups maps common resources outside the core group to their API group
+var wellKnownGroups = map[string]string{
+	"deployments": "apps", "replicasets": "apps", "statefulsets": "apps", "daemonsets": "apps", "controllerrevisions": "apps",
+	"jobs": "batch", "cronjobs": "batch",
+	"ingresses": "networking.k8s.io", "networkpolicies": "networking.k8s.io", "ingressclasses": "networking.k8s.io",
+	"roles": "rbac.authorization.k8s.io", "rolebindings": "rbac.authorization.k8s.io",
+	"clusterroles": "rbac.authorization.k8s.io", "clusterrolebindings": "rbac.authorization.k8s.io",
+	"horizontalpodautoscalers": "autoscaling", "poddisruptionbudgets": "policy",
+	"storageclasses": "storage.k8s.io", "volumeattachments": "storage.k8s.io", "csidrivers": "storage.k8s.io",
+	"customresourcedefinitions": "apiextensions.k8s.io", "certificatesigningrequests": "certificates.k8s.io",
+	"leases": "coordination.k8s.io", "endpointslices": "discovery.k8s.io", "priorityclasses": "scheduling.k8s.io",
+	"mutatingwebhookconfigurations": "admissionregistration.k8s.io", "validatingwebhookconfigurations": "admissionregistration.k8s.io",
+}
+
+// allows reports whether a rule allows a verb on a resource (optionally
+// "resource/subresource") of an API group. Without a name, rules limited
+// to resourceNames match and the names are returned so that the caller can
+// report the limitation.
+func (r PolicyRule) allows(verb, group, resource, name string) (bool, []string) {
+	if !containsString(r.Verbs, verb) && !containsString(r.Verbs, "*") {
+		return false, nil
+	}
+	if !containsString(r.APIGroups, group) && !containsString(r.APIGroups, "*") {
+		return false, nil
+	}
+	if !resourceMatches(r.Resources, resource) {
+		return false, nil
+	}
+	if len(r.ResourceNames) == 0 {
+		return true, nil
+	}
+	if name == "" {
+		return true, r.ResourceNames
+	}
+	return containsString(r.ResourceNames, name), nil
+}
+
+// resourceMatches applies the RBAC resource wildcards "*" and "*/<subresource>"
+func resourceMatches(resources []string, resource string) bool {
+	_, subresource, hasSub := strings.Cut(resource, "/")
+	for _, r := range resources {
+		if r == "*" || r == resource || hasSub && r == "*/"+subresource {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsRule reports whether rules contains an identical rule
+func containsRule(rules []PolicyRule, rule PolicyRule) bool {
+	for _, r := range rules {
+		if r.String() == rule.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders a rule compactly, e.g. "get,list pods,services (apps)"
+func (r PolicyRule) String() string {
+	if len(r.NonResourceURLs) > 0 {
+		return strings.Join(r.Verbs, ",") + " " + strings.Join(r.NonResourceURLs, ",")
+	}
+	s := strings.Join(r.Verbs, ",") + " " + strings.Join(r.Resources, ",")
+	if groups := strings.Join(r.APIGroups, ","); groups != "" {
+		s += " (" + groups + ")"
+	}
+	if len(r.ResourceNames) > 0 {
+		s += " [" + strings.Join(r.ResourceNames, ",") + "]"
+	}
+	return s
+}
@@ -0,0 +1,215 @@
+package rbac
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const clusterRoles = `{"items": [
+  {"metadata": {"name": "cluster-admin"}, "rules": [{"apiGroups": ["*"], "resources": ["*"], "verbs": ["*"]}]},
+  {"metadata": {"name": "view"}, "aggregationRule": {"clusterRoleSelectors": [{"matchLabels": {"rbac.example.com/aggregate-to-view": "true"}}]},
+   "rules": [{"apiGroups": [""], "resources": ["pods"], "verbs": ["get", "list"]}]},
+  {"metadata": {"name": "secret-reader", "labels": {"rbac.example.com/aggregate-to-view": "true"}},
+   "rules": [{"apiGroups": [""], "resources": ["secrets"], "verbs": ["get"]}]},
+  {"metadata": {"name": "exec"}, "rules": [{"apiGroups": [""], "resources": ["pods/exec"], "verbs": ["create"]}]},
+  {"metadata": {"name": "discovery"}, "rules": [{"nonResourceURLs": ["/api", "/apis"], "verbs": ["get"]}]}
+]}`
+
+const clusterRoleBindings = `{"items": [
+  {"metadata": {"name": "admins"}, "roleRef": {"kind": "ClusterRole", "name": "cluster-admin"}, "subjects": [{"kind": "Group", "name": "platform-admins"}]},
+  {"metadata": {"name": "auditors"}, "roleRef": {"kind": "ClusterRole", "name": "view"}, "subjects": [{"kind": "Group", "name": "auditors"}]},
+  {"metadata": {"name": "discovery"}, "roleRef": {"kind": "ClusterRole", "name": "discovery"}, "subjects": [{"kind": "Group", "name": "system:authenticated"}]}
+]}`
+
+const shopRoles = `{"items": [
+  {"metadata": {"name": "deployer", "namespace": "shop"}, "rules": [
+    {"apiGroups": ["apps"], "resources": ["deployments"], "verbs": ["create", "update"]},
+    {"apiGroups": [""], "resources": ["pods"], "resourceNames": ["web-1"], "verbs": ["get"]}]}
+]}`
+
+const shopRoleBindings = `{"items": [
+  {"metadata": {"name": "debuggers", "namespace": "shop"}, "roleRef": {"kind": "ClusterRole", "name": "exec"}, "subjects": [{"kind": "User", "name": "alice@example.com"}]},
+  {"metadata": {"name": "ci", "namespace": "shop"}, "roleRef": {"kind": "Role", "name": "deployer"}, "subjects": [{"kind": "ServiceAccount", "name": "ci"}]}
+]}`
+
+func rbacRunner() *k8stest.Runner {
+	return &k8stest.Runner{Outputs: map[string]string{
+		"get clusterroles -o json":         clusterRoles,
+		"get clusterrolebindings -o json":  clusterRoleBindings,
+		"get roles -A -o json":             shopRoles,
+		"get rolebindings -A -o json":      shopRoleBindings,
+		"get roles -n shop -o json":        shopRoles,
+		"get rolebindings -n shop -o json": shopRoleBindings,
+	}}
+}
+
+func subjectNames(report *WhoCanReport) []string {
+	var names []string
+	for _, s := range report.Subjects {
+		names = append(names, s.Kind+"/"+s.Name)
+	}
+	return names
+}
+
+func TestWhoCan(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     WhoCanOptions
+		expected string
+	}{
+		{"aggregated secrets read", WhoCanOptions{Verb: "get", Resource: "secrets", Namespace: "shop"}, "Group/auditors,Group/platform-admins"},
+		{"exec subresource", WhoCanOptions{Verb: "create", Resource: "pods/exec", Namespace: "shop"}, "Group/platform-admins,User/alice@example.com"},
+		{"well-known API group", WhoCanOptions{Verb: "update", Resource: "deployments", Namespace: "shop"}, "Group/platform-admins,ServiceAccount/ci"},
+		{"explicit API group", WhoCanOptions{Verb: "update", Resource: "deployments.apps"}, "Group/platform-admins,ServiceAccount/ci"},
+		{"other resource name", WhoCanOptions{Verb: "get", Resource: "pods", Namespace: "shop", Name: "web-2"}, "Group/auditors,Group/platform-admins"},
+		{"listed resource name", WhoCanOptions{Verb: "get", Resource: "pods", Namespace: "shop", Name: "web-1"}, "Group/auditors,Group/platform-admins,ServiceAccount/ci"},
+		{"nobody but admins", WhoCanOptions{Verb: "delete", Resource: "nodes"}, "Group/platform-admins"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := WhoCan(context.Background(), rbacRunner(), nil, tc.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := strings.Join(subjectNames(report), ","); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWhoCanGrantsAndRisks(t *testing.T) {
+	report, err := WhoCan(context.Background(), rbacRunner(), nil, WhoCanOptions{Verb: "get", Resource: "pods"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, s := range report.Subjects {
+		if s.Kind != "ServiceAccount" {
+			continue
+		}
+		grant := s.Grants[0]
+		if s.Namespace != "shop" || grant.Scope != "shop" || grant.Role != "Role/shop/deployer" || strings.Join(grant.ResourceNames, ",") != "web-1" {
+			t.Errorf("Expected ci in shop limited to web-1 via Role/shop/deployer, got %+v", s)
+		}
+	}
+	if len(report.Risks) == 0 || report.Risks[0].Severity != SeverityCritical || report.Risks[0].Grant.Binding != "ClusterRoleBinding/admins" {
+		t.Errorf("Expected the cluster-admin grant first among the risks, got %+v", report.Risks)
+	}
+
+	for _, opts := range []WhoCanOptions{
+		{Verb: "get pods", Resource: "pods"},
+		{Verb: "get", Resource: "pods --all"},
+		{Verb: "get", Resource: "pods", Namespace: "shop;ls"},
+	} {
+		if _, err := WhoCan(context.Background(), rbacRunner(), nil, opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestWhoCanAllowedNamespaces(t *testing.T) {
+	runner := rbacRunner()
+	runner.Outputs["get namespaces -o json"] = `{"items": [{"metadata": {"name": "shop"}}, {"metadata": {"name": "kube-system"}}]}`
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("shop")
+
+	report, err := WhoCan(context.Background(), runner, secConfig, WhoCanOptions{Verb: "create", Resource: "pods/exec"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, command := range runner.Commands() {
+		if strings.Contains(command, " -A") || strings.Contains(command, "kube-system") {
+			t.Errorf("Expected only allowed namespaces to be read, got %q", command)
+		}
+	}
+	if len(report.Subjects) != 2 || !strings.Contains(report.Notes[0], "allowed namespaces: shop") {
+		t.Errorf("Expected 2 subjects and a note about the allowed namespaces, got %+v", report)
+	}
+}
+
+func TestSubjectPermissions(t *testing.T) {
+	report, err := SubjectPermissions(context.Background(), rbacRunner(), nil, SubjectOptions{
+		Kind: "User", Name: "alice@example.com", Groups: []string{"auditors"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var viaView *EffectiveRule
+	for i, rule := range report.ClusterRules {
+		if rule.Via.Role == "ClusterRole/view" && containsString(rule.Resources, "secrets") {
+			viaView = &report.ClusterRules[i]
+		}
+	}
+	if viaView == nil || viaView.BoundTo != "Group/auditors" || strings.Join(viaView.AggregatedFrom, ",") != "secret-reader" {
+		t.Errorf("Expected secrets read through the aggregated view role bound to auditors, got %+v", report.ClusterRules)
+	}
+	if len(report.NamespaceRules["shop"]) != 1 || report.NamespaceRules["shop"][0].Via.Binding != "RoleBinding/shop/debuggers" {
+		t.Errorf("Expected the exec rule in shop, got %+v", report.NamespaceRules)
+	}
+
+	reasons := make(map[string]bool)
+	for _, risk := range report.Risks {
+		reasons[risk.Reason] = true
+	}
+	if !reasons["Can read secrets, including service account tokens"] || !reasons["Can exec or attach into pods and run commands with their credentials"] {
+		t.Errorf("Expected secrets and exec risks, got %+v", report.Risks)
+	}
+}
+
+func TestSubjectPermissionsServiceAccount(t *testing.T) {
+	report, err := SubjectPermissions(context.Background(), rbacRunner(), nil, SubjectOptions{Kind: "ServiceAccount", Name: "ci", Namespace: "shop"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.ClusterRules) != 1 || report.ClusterRules[0].BoundTo != "Group/system:authenticated" {
+		t.Errorf("Expected only the discovery rule through system:authenticated, got %+v", report.ClusterRules)
+	}
+	if len(report.NamespaceRules["shop"]) != 2 || len(report.Risks) != 0 {
+		t.Errorf("Expected the 2 deployer rules without risks, got %+v %+v", report.NamespaceRules, report.Risks)
+	}
+
+	tests := []SubjectOptions{
+		{Kind: "ServiceAccount", Name: "ci"},
+		{Kind: "Robot", Name: "ci"},
+		{Kind: "User", Name: "alice bob"},
+	}
+	for _, opts := range tests {
+		if _, err := SubjectPermissions(context.Background(), rbacRunner(), nil, opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestRisksOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     PolicyRule
+		expected []string
+	}{
+		{"read-only pods", PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}}, nil},
+		{"full wildcard", PolicyRule{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}, []string{SeverityCritical}},
+		{"escalate", PolicyRule{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, Verbs: []string{"escalate"}}, []string{SeverityCritical}},
+		{"impersonate", PolicyRule{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"impersonate"}}, []string{SeverityCritical}},
+		{"secrets wildcard verbs", PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"}}, []string{SeverityWarning, SeverityWarning}},
+		{"subresource wildcard", PolicyRule{APIGroups: []string{""}, Resources: []string{"*/exec"}, Verbs: []string{"create"}}, []string{SeverityWarning}},
+		{"non-resource URLs", PolicyRule{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}}, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var severities []string
+			for _, risk := range risksOf(tc.rule) {
+				severities = append(severities, risk.Severity)
+			}
+			if strings.Join(severities, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v, got %v", tc.expected, severities)
+			}
+		})
+	}
+}
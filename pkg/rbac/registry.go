@@ -0,0 +1,70 @@
+package rbac
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterWhoCan registers the rbac_who_can tool
+func RegisterWhoCan() mcp.Tool {
+	return mcp.NewTool("rbac_who_can",
+		mcp.WithDescription(`List the users, groups and service accounts allowed to perform a verb on a resource, unlike "kubectl auth can-i" which only answers for the server's own identity.
+
+Computed from ClusterRoles (including aggregated ClusterRoles), Roles, ClusterRoleBindings and RoleBindings. Each subject is listed with the bindings, roles and rules that grant the access and their scope (cluster or a namespace). Risky rules among them are flagged: wildcard verbs or resources, reading secrets, pods/exec and pods/attach, and escalate, bind and impersonate.
+
+Roles and RoleBindings are limited to the allowed namespaces.
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("verb",
+			mcp.Required(),
+			mcp.Description("API verb, e.g. get, list, create, delete, escalate or impersonate"),
+		),
+		mcp.WithString("resource",
+			mcp.Required(),
+			mcp.Description("Plural resource, optionally with API group and subresource, e.g. secrets, deployments.apps or pods/exec"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the request (default: list grants in every allowed namespace with their scope)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of the object, to evaluate rules limited to resourceNames"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "RBAC: Who Can",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
+
+// RegisterSubjectPermissions registers the rbac_subject_permissions tool
+func RegisterSubjectPermissions() mcp.Tool {
+	return mcp.NewTool("rbac_subject_permissions",
+		mcp.WithDescription(`List the effective RBAC rules of a user, group or service account.
+
+Collects the rules of every ClusterRoleBinding and RoleBinding naming the subject or one of its groups (system:authenticated, and system:serviceaccounts[:<namespace>] for service accounts), resolving aggregated ClusterRoles. Cluster-wide rules and per-namespace rules are listed with the binding and role they come from, and risky grants are flagged: wildcard verbs or resources, reading secrets, pods/exec and pods/attach, and escalate, bind and impersonate.
+
+Roles and RoleBindings are limited to the allowed namespaces.
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Kind of the subject: User, Group or ServiceAccount"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the subject"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the ServiceAccount (required for ServiceAccount)"),
+		),
+		mcp.WithString("groups",
+			mcp.Description("Comma-separated groups of a User, whose bindings are included too"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "RBAC: Subject Permissions",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
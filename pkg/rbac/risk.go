@@ -0,0 +1,67 @@
+package rbac
+
+// Risk severities
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+)
+
+// Risk is a grant that allows more than it appears to, or that allows
+// reading credentials, running code in pods or escalating privileges
+type Risk struct {
+	Severity string `json:"severity"`
+	Reason   string `json:"reason"`
+	// Rule is the risky rule, e.g. "get,list secrets"
+	Rule  string `json:"rule"`
+	Grant Grant  `json:"grant"`
+	// Subjects are the subjects of the grant, in rbac_who_can only
+	Subjects []Subject `json:"subjects,omitempty"`
+}
+
+// risksOf returns the risky permissions of a rule: full wildcards,
+// wildcard verbs or resources, reading secrets, exec/attach into pods and
+// the escalate, bind and impersonate verbs
+func risksOf(rule PolicyRule) []Risk {
+	if len(rule.Resources) == 0 {
+		return nil
+	}
+	var risks []Risk
+	add := func(severity, reason string) {
+		risks = append(risks, Risk{Severity: severity, Reason: reason, Rule: rule.String()})
+	}
+	allows := func(verbs []string, group string, resources ...string) bool {
+		for _, verb := range verbs {
+			for _, resource := range resources {
+				if ok, _ := rule.allows(verb, group, resource, ""); ok {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	anyVerb, anyResource := containsString(rule.Verbs, "*"), containsString(rule.Resources, "*")
+	if anyVerb && anyResource && containsString(rule.APIGroups, "*") {
+		add(SeverityCritical, "All verbs on all resources (cluster-admin equivalent within the scope)")
+		return risks
+	}
+	if allows([]string{"escalate", "bind"}, "rbac.authorization.k8s.io", "roles", "clusterroles") {
+		add(SeverityCritical, "Can escalate or bind roles, granting permissions it does not hold")
+	}
+	if allows([]string{"impersonate"}, "", "users", "groups", "serviceaccounts") {
+		add(SeverityCritical, "Can impersonate other users, groups or service accounts")
+	}
+	if allows([]string{"get", "list", "watch"}, "", "secrets") {
+		add(SeverityWarning, "Can read secrets, including service account tokens")
+	}
+	if allows([]string{"create", "get"}, "", "pods/exec", "pods/attach") {
+		add(SeverityWarning, "Can exec or attach into pods and run commands with their credentials")
+	}
+	if anyVerb {
+		add(SeverityWarning, "Wildcard verbs include escalate, bind, impersonate and verbs added in the future")
+	}
+	if anyResource {
+		add(SeverityWarning, "Wildcard resources also grant resources and subresources added in the future")
+	}
+	return risks
+}
@@ -0,0 +1,141 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// SubjectOptions are the parameters of rbac_subject_permissions
+type SubjectOptions struct {
+	// Kind is User, Group or ServiceAccount
+	Kind string
+	Name string
+	// Namespace is the namespace of a ServiceAccount
+	Namespace string
+	// Groups are additional groups of a User, which RBAC cannot list
+	Groups []string
+}
+
+// SubjectReport is the result of rbac_subject_permissions
+type SubjectReport struct {
+	Subject Subject `json:"subject"`
+	// Groups are the groups whose bindings were included, the implicit
+	// system groups and the given ones
+	Groups []string `json:"groups,omitempty"`
+	// ClusterRules are granted by ClusterRoleBindings in every namespace
+	ClusterRules []EffectiveRule `json:"clusterRules"`
+	// NamespaceRules are granted by RoleBindings, per namespace
+	NamespaceRules map[string][]EffectiveRule `json:"namespaceRules"`
+	Risks          []Risk                     `json:"risks,omitempty"`
+	Notes          []string                   `json:"notes,omitempty"`
+}
+
+// EffectiveRule is a rule granted to the subject and the grant it comes from
+type EffectiveRule struct {
+	PolicyRule
+	Via Grant `json:"via"`
+	// BoundTo is the subject of the binding when it is one of the groups
+	BoundTo string `json:"boundTo,omitempty"`
+	// AggregatedFrom lists the ClusterRoles aggregated into the role
+	AggregatedFrom []string `json:"aggregatedFrom,omitempty"`
+}
+
+// SubjectPermissions computes the effective rules of a user, group or
+// service account from the Roles, ClusterRoles (with aggregation) and bindings
+func SubjectPermissions(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts SubjectOptions) (*SubjectReport, error) {
+	if !namePattern.MatchString(opts.Name) {
+		return nil, fmt.Errorf("invalid name: %s", opts.Name)
+	}
+	subject := Subject{Kind: opts.Kind, Name: opts.Name}
+	var groups []string
+	switch opts.Kind {
+	case "User":
+		groups = append(groups, "system:authenticated")
+	case "Group":
+		groups = append(groups, opts.Name)
+	case "ServiceAccount":
+		if opts.Namespace == "" {
+			return nil, fmt.Errorf("namespace is required for a ServiceAccount")
+		}
+		if err := k8s.ValidateNamespace("namespace", opts.Namespace); err != nil {
+			return nil, err
+		}
+		subject.Namespace = opts.Namespace
+		groups = append(groups, "system:authenticated", "system:serviceaccounts", "system:serviceaccounts:"+opts.Namespace)
+	default:
+		return nil, fmt.Errorf("invalid kind '%s': must be User, Group or ServiceAccount", opts.Kind)
+	}
+	for _, group := range opts.Groups {
+		if !namePattern.MatchString(group) {
+			return nil, fmt.Errorf("invalid group: %s", group)
+		}
+		if !containsString(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+
+	p, err := loadPolicy(ctx, runner, secConfig, "")
+	if err != nil {
+		return nil, err
+	}
+	report := &SubjectReport{
+		Subject:        subject,
+		Groups:         groups,
+		ClusterRules:   []EffectiveRule{},
+		NamespaceRules: map[string][]EffectiveRule{},
+		Notes:          p.notes,
+	}
+	if opts.Kind == "User" && len(opts.Groups) == 0 {
+		report.Notes = append(report.Notes, "Group memberships of users come from the authenticator and are not visible in RBAC; pass groups to include their bindings")
+	}
+
+	for _, bound := range p.bindings("") {
+		boundTo, ok := matchSubject(bound.subjects, subject, groups)
+		if !ok {
+			continue
+		}
+		roleName := strings.TrimPrefix(bound.grant.Role, "ClusterRole/")
+		for _, rule := range bound.rules {
+			effective := EffectiveRule{PolicyRule: rule, Via: bound.grant, BoundTo: boundTo}
+			if roleName != bound.grant.Role {
+				effective.AggregatedFrom = p.aggregatedFrom[roleName]
+			}
+			if bound.grant.Scope == ScopeCluster {
+				report.ClusterRules = append(report.ClusterRules, effective)
+			} else {
+				report.NamespaceRules[bound.grant.Scope] = append(report.NamespaceRules[bound.grant.Scope], effective)
+			}
+			for _, risk := range risksOf(rule) {
+				risk.Grant = bound.grant
+				report.Risks = append(report.Risks, risk)
+			}
+		}
+	}
+	sortRisks(report.Risks)
+	return report, nil
+}
+
+// matchSubject reports whether a binding's subjects include the subject or
+// one of its groups, and names the group when the match is through one
+func matchSubject(subjects []Subject, subject Subject, groups []string) (string, bool) {
+	boundTo, matched := "", false
+	for _, s := range subjects {
+		switch {
+		case s.Kind == subject.Kind && s.Name == subject.Name && s.Namespace == subject.Namespace && s.Kind != "Group":
+			return "", true
+		case subject.Kind == "ServiceAccount" && s.Kind == "User" && s.Name == "system:serviceaccount:"+subject.Namespace+":"+subject.Name:
+			return "", true
+		case s.Kind == "Group" && containsString(groups, s.Name) && !matched:
+			boundTo, matched = "Group/"+s.Name, true
+		}
+	}
+	if subject.Kind == "Group" {
+		// The group itself is the subject
+		boundTo = ""
+	}
+	return boundTo, matched
+}
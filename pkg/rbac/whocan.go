@@ -0,0 +1,136 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+var (
+	// verbPattern accepts API verbs and "*"
+	verbPattern = regexp.MustCompile(`^([a-z]+|\*)$`)
+	// resourcePattern accepts "pods", "deployments.apps" and "pods/exec"
+	resourcePattern = regexp.MustCompile(`^[a-z0-9*][a-z0-9.*-]*(/[a-z0-9*-]+)?$`)
+)
+
+// WhoCanOptions are the parameters of rbac_who_can
+type WhoCanOptions struct {
+	Verb string
+	// Resource is a plural resource, optionally with its API group and a
+	// subresource: "pods", "deployments.apps", "pods/exec"
+	Resource string
+	// Namespace limits RoleBindings to one namespace; without it, grants in
+	// every allowed namespace are listed with their scope
+	Namespace string
+	// Name is the name of the object; rules limited to other resourceNames
+	// do not match
+	Name string
+}
+
+// WhoCanReport is the result of rbac_who_can
+type WhoCanReport struct {
+	Verb      string          `json:"verb"`
+	Resource  string          `json:"resource"`
+	APIGroup  string          `json:"apiGroup"`
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Subjects  []SubjectAccess `json:"subjects"`
+	// Risks are the risky rules among the matching grants
+	Risks []Risk   `json:"risks,omitempty"`
+	Notes []string `json:"notes,omitempty"`
+}
+
+// SubjectAccess is a subject and the grants that allow the request
+type SubjectAccess struct {
+	Subject
+	Grants []MatchedGrant `json:"grants"`
+}
+
+// MatchedGrant is a grant with the rule that matched
+type MatchedGrant struct {
+	Grant
+	Rule string `json:"rule"`
+	// ResourceNames limits the grant to these objects
+	ResourceNames []string `json:"resourceNames,omitempty"`
+}
+
+// WhoCan lists the subjects allowed to perform a verb on a resource,
+// computed from the Roles, ClusterRoles (with aggregation) and bindings
+func WhoCan(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts WhoCanOptions) (*WhoCanReport, error) {
+	if !verbPattern.MatchString(opts.Verb) {
+		return nil, fmt.Errorf("invalid verb: %s", opts.Verb)
+	}
+	if !resourcePattern.MatchString(opts.Resource) {
+		return nil, fmt.Errorf("invalid resource: %s", opts.Resource)
+	}
+	if opts.Name != "" && !namePattern.MatchString(opts.Name) {
+		return nil, fmt.Errorf("invalid name: %s", opts.Name)
+	}
+	p, err := loadPolicy(ctx, runner, secConfig, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	group, resource := groupOf(opts.Resource)
+	report := &WhoCanReport{
+		Verb: opts.Verb, Resource: resource, APIGroup: group, Namespace: opts.Namespace, Name: opts.Name,
+		Subjects: []SubjectAccess{}, Notes: p.notes,
+	}
+	bySubject := make(map[Subject]*SubjectAccess)
+	var order []Subject
+	for _, bound := range p.bindings(opts.Namespace) {
+		// One matching rule per binding is enough; prefer one that is not
+		// limited to resourceNames
+		var matched *PolicyRule
+		var matchedNames []string
+		for i, rule := range bound.rules {
+			ok, names := rule.allows(opts.Verb, group, resource, opts.Name)
+			if ok && (matched == nil || matchedNames != nil) {
+				matched, matchedNames = &bound.rules[i], names
+			}
+		}
+		if matched == nil {
+			continue
+		}
+		match := MatchedGrant{Grant: bound.grant, Rule: matched.String(), ResourceNames: matchedNames}
+		for _, subject := range bound.subjects {
+			access, seen := bySubject[subject]
+			if !seen {
+				access = &SubjectAccess{Subject: subject}
+				bySubject[subject] = access
+				order = append(order, subject)
+			}
+			access.Grants = append(access.Grants, match)
+		}
+		for _, risk := range risksOf(*matched) {
+			risk.Grant, risk.Subjects = bound.grant, bound.subjects
+			report.Risks = append(report.Risks, risk)
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool { return subjectKey(order[i]) < subjectKey(order[j]) })
+	for _, subject := range order {
+		report.Subjects = append(report.Subjects, *bySubject[subject])
+	}
+	sortRisks(report.Risks)
+	return report, nil
+}
+
+// subjectKey orders subjects by kind, namespace and name
+func subjectKey(s Subject) string {
+	return s.Kind + "/" + s.Namespace + "/" + s.Name
+}
+
+// sortRisks orders risks critical first, then by grant
+func sortRisks(risks []Risk) {
+	sort.SliceStable(risks, func(i, j int) bool {
+		if risks[i].Severity != risks[j].Severity {
+			return risks[i].Severity == SeverityCritical
+		}
+		return risks[i].Grant.Binding < risks[j].Grant.Binding
+	})
+}
@@ -0,0 +1,77 @@
+package rightsizing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// Executor implements the CommandExecutor interface for rightsizing
+type Executor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures Executor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*Executor)(nil)
+var _ tools.StructuredOutputExecutor = (*Executor)(nil)
+
+// NewExecutor creates a new Executor instance
+func NewExecutor() *Executor {
+	return &Executor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute builds the rightsizing report and returns it as JSON
+func (e *Executor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := Options{}
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.LabelSelector, _ = params["label_selector"].(string)
+	if production, ok := params["production_namespaces"].(string); ok {
+		for _, namespace := range strings.Split(production, ",") {
+			if namespace = strings.TrimSpace(namespace); namespace != "" {
+				opts.ProductionNamespaces = append(opts.ProductionNamespaces, namespace)
+			}
+		}
+	}
+	if window, ok := params["oom_window"].(string); ok && window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			return "", fmt.Errorf("invalid oom_window '%s': expected a positive duration such as 6h or 24h", window)
+		}
+		opts.OOMWindow = d
+	}
+	if v, ok := params["max_workloads"].(float64); ok {
+		opts.MaxWorkloads = int(v)
+	}
+
+	if err := k8s.ValidateNamespace("namespace", opts.Namespace); err != nil {
+		return "", err
+	}
+	for _, namespace := range opts.ProductionNamespaces {
+		if err := k8s.ValidateNamespace("production_namespaces", namespace); err != nil {
+			return "", err
+		}
+	}
+
+	report, err := Analyze(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rightsizing report: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that rightsizing returns JSON
+func (e *Executor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
@@ -0,0 +1,46 @@
+package rightsizing
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterRightsizing registers the rightsizing tool
+func RegisterRightsizing() mcp.Tool {
+	return mcp.NewTool("rightsizing",
+		mcp.WithDescription(`Compare container requests and limits with live usage from metrics-server (kubectl top) and suggest new values, per workload.
+
+Groups pods by Deployment, StatefulSet, DaemonSet, Job or bare pod and reports for each container the requests, limits, peak and average usage and suggested requests (peak usage plus 20%) and memory limit (peak usage plus 50%). Flags:
+- over-provisioned containers (usage below 30% of the request) and under-provisioned ones (usage above the request, or no request)
+- containers close to their CPU or memory limit
+- containers without CPU or memory limits
+- BestEffort pods in production namespaces
+- containers OOMKilled within the OOM window
+
+Usage is a single sample, so suggestions should be confirmed against longer-term metrics. Without metrics-server only limits, QoS and OOM kills are checked.
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to analyze (default: all allowed namespaces)"),
+		),
+		mcp.WithString("label_selector",
+			mcp.Description("Only analyze pods matching this label selector, e.g. app=web"),
+		),
+		mcp.WithString("production_namespaces",
+			mcp.Description("Comma-separated namespaces where BestEffort pods are flagged (default: namespaces named prod, production or prd, or with such a dash-separated part)"),
+		),
+		mcp.WithString("oom_window",
+			mcp.Description("How far back OOMKilled containers are reported, e.g. 6h (default: 24h)"),
+		),
+		mcp.WithNumber("max_workloads",
+			mcp.Description("Maximum number of workloads in the report, those with issues first (default: 100, max: 500)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Rightsizing",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,343 @@
+package rightsizing
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const (
+	// defaultOOMWindow is how far back OOMKilled containers are reported
+	defaultOOMWindow = 24 * time.Hour
+	// defaultMaxWorkloads caps the workloads in the report
+	defaultMaxWorkloads = 100
+	// maxWorkloadsLimit is the largest accepted max_workloads
+	maxWorkloadsLimit = 500
+)
+
+// labelSelectorPattern restricts label selectors to the characters of
+// kubectl's equality- and set-based syntax without spaces
+var labelSelectorPattern = regexp.MustCompile(`^[A-Za-z0-9._/=!,-]+$`)
+
+// Options are the parameters of rightsizing
+type Options struct {
+	// Namespace limits the report to one namespace (default: all allowed namespaces)
+	Namespace     string
+	LabelSelector string
+	// ProductionNamespaces are the namespaces where BestEffort pods are
+	// flagged; when empty, namespaces named prod, production, prd or with
+	// one of those as a dash- or underscore-separated part are used
+	ProductionNamespaces []string
+	OOMWindow            time.Duration
+	MaxWorkloads         int
+	// Now is the reference time for the OOM window (default: the current time)
+	Now time.Time
+}
+
+// Report is the result of rightsizing
+type Report struct {
+	// Namespaces are the scanned namespaces; "*" means all namespaces
+	Namespaces []string   `json:"namespaces"`
+	Summary    Summary    `json:"summary"`
+	Workloads  []Workload `json:"workloads"`
+	// Truncated is set when workloads were left out by max_workloads
+	Truncated bool     `json:"truncated,omitempty"`
+	Notes     []string `json:"notes,omitempty"`
+}
+
+// Summary totals the report
+type Summary struct {
+	Workloads        int `json:"workloads"`
+	Containers       int `json:"containers"`
+	OverProvisioned  int `json:"overProvisioned"`
+	UnderProvisioned int `json:"underProvisioned"`
+	MissingLimits    int `json:"missingLimits"`
+	BestEffort       int `json:"bestEffortInProduction"`
+	OOMKilled        int `json:"oomKilled"`
+	// CPU and memory compare the requests of the containers with metrics to
+	// their usage and to the suggested requests
+	CPU    Totals `json:"cpu"`
+	Memory Totals `json:"memory"`
+}
+
+// Totals compares requested, used and suggested amounts of a resource
+type Totals struct {
+	Requested string `json:"requested"`
+	Used      string `json:"used"`
+	Suggested string `json:"suggested"`
+}
+
+// Workload is a Deployment, StatefulSet, DaemonSet, Job or bare pod with
+// the sizing of its containers
+type Workload struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Pods      int    `json:"pods"`
+	QOSClass  string `json:"qosClass"`
+	// Production is set when the namespace is a production namespace
+	Production bool        `json:"production,omitempty"`
+	Containers []Container `json:"containers"`
+	Issues     []Issue     `json:"issues,omitempty"`
+}
+
+// Container is the sizing of one container across the workload's pods
+type Container struct {
+	Name     string    `json:"name"`
+	Requests Resources `json:"requests"`
+	Limits   Resources `json:"limits"`
+	// Usage is the highest usage among the pods, AverageUsage the mean
+	Usage        *Resources `json:"usage,omitempty"`
+	AverageUsage *Resources `json:"averageUsage,omitempty"`
+	Suggested    *Suggested `json:"suggested,omitempty"`
+}
+
+// Resources are CPU and memory quantities; empty means unset
+type Resources struct {
+	CPU    string `json:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty"`
+}
+
+// Suggested are the suggested requests and limits of a container
+type Suggested struct {
+	Requests Resources `json:"requests"`
+	Limits   Resources `json:"limits"`
+}
+
+// Issue types
+const (
+	IssueOverProvisioned  = "over-provisioned"
+	IssueUnderProvisioned = "under-provisioned"
+	IssueNearLimit        = "near-limit"
+	IssueMissingLimits    = "missing-limits"
+	IssueBestEffort       = "best-effort-in-production"
+	IssueOOMKilled        = "oom-killed"
+)
+
+// Issue is a sizing problem of a workload or one of its containers
+type Issue struct {
+	Type      string `json:"type"`
+	Container string `json:"container,omitempty"`
+	Message   string `json:"message"`
+}
+
+// usageKey identifies a container of a pod in the metrics
+type usageKey struct {
+	namespace, pod, container string
+}
+
+// usage is the CPU (millicores) and memory (bytes) usage of a container
+type usage struct {
+	milliCPU, bytes int64
+}
+
+// Analyze lists the pods of the allowed namespaces and their container
+// usage from metrics-server, groups them by workload and compares
+// requests and limits with the usage
+func Analyze(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts Options) (*Report, error) {
+	if opts.LabelSelector != "" && !labelSelectorPattern.MatchString(opts.LabelSelector) {
+		return nil, fmt.Errorf("invalid label_selector: %s", opts.LabelSelector)
+	}
+	if opts.OOMWindow == 0 {
+		opts.OOMWindow = defaultOOMWindow
+	}
+	switch {
+	case opts.MaxWorkloads == 0:
+		opts.MaxWorkloads = defaultMaxWorkloads
+	case opts.MaxWorkloads < 0 || opts.MaxWorkloads > maxWorkloadsLimit:
+		return nil, fmt.Errorf("max_workloads must be between 1 and %d", maxWorkloadsLimit)
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	namespaces, err := k8s.ScanNamespaces(ctx, runner, secConfig, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Namespaces: namespaces, Workloads: []Workload{}}
+
+	var pods []k8s.Pod
+	usages := make(map[usageKey]usage)
+	metricsAvailable := true
+	for _, namespace := range namespaces {
+		scope := "-n " + namespace
+		if namespace == k8s.AllNamespaces {
+			scope = "-A"
+		}
+		selector := ""
+		if opts.LabelSelector != "" {
+			selector = " -l " + opts.LabelSelector
+		}
+
+		var list k8s.List[k8s.Pod]
+		if err := k8s.GetJSON(ctx, runner, "get pods "+scope+selector, &list); err != nil {
+			if len(namespaces) == 1 {
+				return nil, err
+			}
+			report.Notes = append(report.Notes, fmt.Sprintf("Pods in %s: %v", namespace, err))
+			continue
+		}
+		pods = append(pods, list.Items...)
+
+		if !metricsAvailable {
+			continue
+		}
+		output, err := runner.Run(ctx, "top pods "+scope+selector+" --containers --no-headers")
+		if err != nil {
+			metricsAvailable = false
+			report.Notes = append(report.Notes, fmt.Sprintf("Usage is unavailable (kubectl top: %v); only limits, QoS and OOM kills are checked", err))
+			continue
+		}
+		parseUsage(output, namespace, usages)
+	}
+	if metricsAvailable {
+		report.Notes = append(report.Notes, "Usage is a single metrics-server sample; confirm suggestions against usage over a representative period before applying them")
+	}
+
+	production := opts.ProductionNamespaces
+	isProduction := func(namespace string) bool {
+		if len(production) == 0 {
+			return looksLikeProduction(namespace)
+		}
+		for _, p := range production {
+			if p == namespace {
+				return true
+			}
+		}
+		return false
+	}
+
+	workloads := groupByWorkload(pods)
+	for _, group := range workloads {
+		report.Workloads = append(report.Workloads, sizeWorkload(group, usages, isProduction(group.namespace), opts))
+	}
+	summarize(report)
+
+	sort.SliceStable(report.Workloads, func(i, j int) bool {
+		a, b := report.Workloads[i], report.Workloads[j]
+		if len(a.Issues) != len(b.Issues) {
+			return len(a.Issues) > len(b.Issues)
+		}
+		return a.Namespace+"/"+a.Kind+"/"+a.Name < b.Namespace+"/"+b.Kind+"/"+b.Name
+	})
+	if len(report.Workloads) > opts.MaxWorkloads {
+		report.Workloads = report.Workloads[:opts.MaxWorkloads]
+		report.Truncated = true
+	}
+	return report, nil
+}
+
+// parseUsage reads "kubectl top pods --containers --no-headers" output,
+// which has a leading namespace column with -A
+func parseUsage(output, namespace string, usages map[usageKey]usage) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if namespace != k8s.AllNamespaces {
+			fields = append([]string{namespace}, fields...)
+		}
+		if len(fields) != 5 {
+			continue
+		}
+		usages[usageKey{fields[0], fields[1], fields[2]}] = usage{
+			milliCPU: k8s.MilliCPU(fields[3]),
+			bytes:    k8s.Bytes(fields[4]),
+		}
+	}
+}
+
+// looksLikeProduction reports whether a namespace name marks it as production
+func looksLikeProduction(namespace string) bool {
+	for _, part := range strings.FieldsFunc(namespace, func(r rune) bool { return r == '-' || r == '_' }) {
+		switch part {
+		case "prod", "production", "prd":
+			return true
+		}
+	}
+	return false
+}
+
+// workloadPods are the pods of one workload
+type workloadPods struct {
+	namespace, kind, name string
+	pods                  []k8s.Pod
+}
+
+// groupByWorkload groups the pods that have not terminated by their
+// top-level controller. ReplicaSet pods are attributed to their Deployment
+// through the pod-template-hash suffix.
+func groupByWorkload(pods []k8s.Pod) []*workloadPods {
+	groups := make(map[string]*workloadPods)
+	var keys []string
+	for _, pod := range pods {
+		if pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+			continue
+		}
+		kind, name := "Pod", pod.Metadata.Name
+		if owner := k8s.ControllerOf(pod.Metadata); owner != nil {
+			kind, name = owner.Kind, owner.Name
+			if hash := pod.Metadata.Labels["pod-template-hash"]; owner.Kind == "ReplicaSet" && strings.HasSuffix(owner.Name, "-"+hash) {
+				kind, name = "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+			}
+		}
+		key := pod.Metadata.Namespace + "/" + kind + "/" + name
+		group, ok := groups[key]
+		if !ok {
+			group = &workloadPods{namespace: pod.Metadata.Namespace, kind: kind, name: name}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.pods = append(group.pods, pod)
+	}
+	sort.Strings(keys)
+	result := make([]*workloadPods, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, groups[key])
+	}
+	return result
+}
+
+// summarize counts the issues and totals the requests, usage and
+// suggestions of the containers with metrics
+func summarize(report *Report) {
+	var cpu, memory [3]int64
+	summary := &report.Summary
+	for _, w := range report.Workloads {
+		summary.Workloads++
+		summary.Containers += len(w.Containers)
+		for _, issue := range w.Issues {
+			switch issue.Type {
+			case IssueOverProvisioned:
+				summary.OverProvisioned++
+			case IssueUnderProvisioned:
+				summary.UnderProvisioned++
+			case IssueMissingLimits:
+				summary.MissingLimits++
+			case IssueBestEffort:
+				summary.BestEffort++
+			case IssueOOMKilled:
+				summary.OOMKilled++
+			}
+		}
+		for _, c := range w.Containers {
+			if c.AverageUsage == nil || c.Suggested == nil {
+				continue
+			}
+			pods := int64(w.Pods)
+			cpu[0] += k8s.MilliCPU(c.Requests.CPU) * pods
+			cpu[1] += k8s.MilliCPU(c.AverageUsage.CPU) * pods
+			cpu[2] += k8s.MilliCPU(c.Suggested.Requests.CPU) * pods
+			memory[0] += k8s.Bytes(c.Requests.Memory) * pods
+			memory[1] += k8s.Bytes(c.AverageUsage.Memory) * pods
+			memory[2] += k8s.Bytes(c.Suggested.Requests.Memory) * pods
+		}
+	}
+	summary.CPU = Totals{Requested: k8s.FormatMilliCPU(cpu[0]), Used: k8s.FormatMilliCPU(cpu[1]), Suggested: k8s.FormatMilliCPU(cpu[2])}
+	summary.Memory = Totals{Requested: k8s.FormatBytes(memory[0]), Used: k8s.FormatBytes(memory[1]), Suggested: k8s.FormatBytes(memory[2])}
+}
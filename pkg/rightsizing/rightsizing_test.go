@@ -0,0 +1,183 @@
+package rightsizing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+const prodPods = `{"items": [
+  {"metadata": {"name": "web-7d9f-a", "namespace": "payments-prod", "labels": {"pod-template-hash": "7d9f"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "web-7d9f", "controller": true}]},
+   "spec": {"containers": [{"name": "web", "resources": {"requests": {"cpu": "1", "memory": "1Gi"}, "limits": {"memory": "1Gi"}}}]},
+   "status": {"phase": "Running"}},
+  {"metadata": {"name": "web-7d9f-b", "namespace": "payments-prod", "labels": {"pod-template-hash": "7d9f"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "web-7d9f", "controller": true}]},
+   "spec": {"containers": [{"name": "web", "resources": {"requests": {"cpu": "1", "memory": "1Gi"}, "limits": {"memory": "1Gi"}}}]},
+   "status": {"phase": "Running"}},
+  {"metadata": {"name": "db-0", "namespace": "payments-prod", "ownerReferences": [{"kind": "StatefulSet", "name": "db", "controller": true}]},
+   "spec": {"containers": [{"name": "postgres", "resources": {"requests": {"cpu": "200m", "memory": "512Mi"}, "limits": {"cpu": "500m", "memory": "600Mi"}}}]},
+   "status": {"phase": "Running", "containerStatuses": [{"name": "postgres", "restartCount": 3,
+     "state": {"running": {}}, "lastState": {"terminated": {"reason": "OOMKilled", "exitCode": 137, "finishedAt": "2026-03-01T09:30:00Z"}}}]}},
+  {"metadata": {"name": "debug", "namespace": "payments-prod"},
+   "spec": {"containers": [{"name": "shell"}]},
+   "status": {"phase": "Running"}},
+  {"metadata": {"name": "migrate-1-x", "namespace": "payments-prod", "ownerReferences": [{"kind": "Job", "name": "migrate-1", "controller": true}]},
+   "spec": {"containers": [{"name": "migrate"}]},
+   "status": {"phase": "Succeeded"}}
+]}`
+
+const prodUsage = `web-7d9f-a   web        50m    200Mi
+web-7d9f-b   web        100m   250Mi
+db-0         postgres   480m   580Mi
+debug        shell      1m     2Mi
+`
+
+func findWorkload(report *Report, kind, name string) *Workload {
+	for i := range report.Workloads {
+		if report.Workloads[i].Kind == kind && report.Workloads[i].Name == name {
+			return &report.Workloads[i]
+		}
+	}
+	return nil
+}
+
+func issueTypes(w *Workload) map[string]string {
+	types := make(map[string]string)
+	for _, issue := range w.Issues {
+		types[issue.Type] = issue.Message
+	}
+	return types
+}
+
+func TestAnalyze(t *testing.T) {
+	runner := &k8stest.Runner{Outputs: map[string]string{
+		"get pods -n payments-prod -o json":                   prodPods,
+		"top pods -n payments-prod --containers --no-headers": prodUsage,
+	}}
+
+	report, err := Analyze(context.Background(), runner, nil, Options{Namespace: "payments-prod", Now: now})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Workloads) != 3 {
+		t.Fatalf("Expected 3 workloads (the completed Job is skipped), got %+v", report.Workloads)
+	}
+
+	web := findWorkload(report, "Deployment", "web")
+	if web == nil || web.Pods != 2 || web.QOSClass != "Burstable" {
+		t.Fatalf("Expected Deployment web with 2 Burstable pods, got %+v", web)
+	}
+	c := web.Containers[0]
+	if c.Usage.CPU != "100m" || c.Usage.Memory != "250Mi" || c.AverageUsage.CPU != "75m" {
+		t.Errorf("Expected peak 100m/250Mi and average 75m CPU, got %+v %+v", c.Usage, c.AverageUsage)
+	}
+	if c.Suggested.Requests.CPU != "120m" || c.Suggested.Requests.Memory != "300Mi" || c.Suggested.Limits.Memory != "375Mi" {
+		t.Errorf("Expected suggested 120m/300Mi and 375Mi limit, got %+v", c.Suggested)
+	}
+	issues := issueTypes(web)
+	if !strings.Contains(issues[IssueOverProvisioned], "cpu usage 100m is 10% of the request 1") || !strings.Contains(issues[IssueOverProvisioned], "memory") {
+		t.Errorf("Expected web to be over-provisioned on cpu and memory, got %v", issues)
+	}
+	if issues[IssueMissingLimits] != "No cpu limit" {
+		t.Errorf("Expected a missing cpu limit, got %v", issues)
+	}
+
+	db := findWorkload(report, "StatefulSet", "db")
+	issues = issueTypes(db)
+	if !strings.Contains(issues[IssueUnderProvisioned], "cpu usage 480m exceeds the request 200m") {
+		t.Errorf("Expected db to be under-provisioned on cpu, got %v", issues)
+	}
+	if !strings.Contains(issues[IssueNearLimit], "cpu usage 480m is 96% of the limit 500m, throttled") ||
+		!strings.Contains(issues[IssueNearLimit], "at risk of being OOMKilled") {
+		t.Errorf("Expected db to be near its cpu and memory limits, got %v", issues)
+	}
+	if !strings.Contains(issues[IssueOOMKilled], "OOMKilled in 1 pod(s) within 24h") {
+		t.Errorf("Expected db to be OOMKilled, got %v", issues)
+	}
+
+	debug := findWorkload(report, "Pod", "debug")
+	issues = issueTypes(debug)
+	if debug.QOSClass != "BestEffort" || issues[IssueBestEffort] == "" {
+		t.Errorf("Expected a BestEffort issue in a production namespace, got %+v", debug)
+	}
+	if len(issues) != 1 {
+		t.Errorf("Expected BestEffort pods not to be reported for missing limits or requests too, got %v", issues)
+	}
+
+	s := report.Summary
+	if s.Workloads != 3 || s.Containers != 3 || s.OverProvisioned != 1 || s.UnderProvisioned != 1 || s.MissingLimits != 1 || s.BestEffort != 1 || s.OOMKilled != 1 {
+		t.Errorf("Unexpected summary counts: %+v", s)
+	}
+	if s.CPU.Requested != "2200m" || s.CPU.Used != "631m" {
+		t.Errorf("Expected 2200m requested and 631m used CPU, got %+v", s.CPU)
+	}
+}
+
+func TestAnalyzeWithoutMetrics(t *testing.T) {
+	runner := &k8stest.Runner{
+		Outputs: map[string]string{
+			"get pods -A -l app=api -o json": `{"items": [
+				{"metadata": {"name": "api-0", "namespace": "apps"}, "spec": {"containers": [{"name": "api"}]}, "status": {"phase": "Running",
+				 "containerStatuses": [{"name": "api", "state": {"terminated": {"reason": "OOMKilled", "finishedAt": "2026-02-27T12:00:00Z"}}}]}}]}`,
+		},
+		Errors: map[string]error{
+			"top pods -A -l app=api --containers --no-headers": fmt.Errorf("error: Metrics API not available"),
+		},
+	}
+
+	report, err := Analyze(context.Background(), runner, nil, Options{LabelSelector: "app=api", ProductionNamespaces: []string{"apps"}, Now: now})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "Metrics API not available") {
+		t.Errorf("Expected a note about missing metrics, got %v", report.Notes)
+	}
+	api := findWorkload(report, "Pod", "api-0")
+	if api == nil || api.Containers[0].Usage != nil || api.Containers[0].Suggested != nil {
+		t.Fatalf("Expected api-0 without usage or suggestions, got %+v", api)
+	}
+	issues := issueTypes(api)
+	if issues[IssueBestEffort] == "" {
+		t.Errorf("Expected apps to be treated as a production namespace, got %v", issues)
+	}
+	if _, ok := issues[IssueOOMKilled]; ok {
+		t.Errorf("Expected the OOM kill outside the window to be ignored, got %v", issues)
+	}
+
+	if _, err := Analyze(context.Background(), runner, nil, Options{LabelSelector: "app=api; rm -rf /"}); err == nil {
+		t.Error("Expected an error for an invalid label selector")
+	}
+}
+
+func TestQOSClass(t *testing.T) {
+	tests := []struct {
+		name       string
+		containers []k8s.Container
+		expected   string
+	}{
+		{"no resources", []k8s.Container{{Name: "a"}}, "BestEffort"},
+		{"limits only", []k8s.Container{{Name: "a", Resources: k8s.ResourceRequirements{
+			Limits: map[string]string{"cpu": "1", "memory": "1Gi"}}}}, "Guaranteed"},
+		{"equal requests and limits", []k8s.Container{{Name: "a", Resources: k8s.ResourceRequirements{
+			Requests: map[string]string{"cpu": "1000m", "memory": "1024Mi"}, Limits: map[string]string{"cpu": "1", "memory": "1Gi"}}}}, "Guaranteed"},
+		{"requests below limits", []k8s.Container{{Name: "a", Resources: k8s.ResourceRequirements{
+			Requests: map[string]string{"cpu": "500m", "memory": "1Gi"}, Limits: map[string]string{"cpu": "1", "memory": "1Gi"}}}}, "Burstable"},
+		{"one container without limits", []k8s.Container{
+			{Name: "a", Resources: k8s.ResourceRequirements{Limits: map[string]string{"cpu": "1", "memory": "1Gi"}}},
+			{Name: "b"}}, "Burstable"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := qosClass(k8s.PodSpec{Containers: tc.containers}); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package rightsizing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+)
+
+const (
+	// requestHeadroom is applied to the peak usage for suggested requests
+	requestHeadroom = 1.2
+	// memoryLimitHeadroom is applied to the peak usage for suggested memory limits
+	memoryLimitHeadroom = 1.5
+	// overProvisionedPercent is the usage, in percent of the request, below
+	// which a container is over-provisioned
+	overProvisionedPercent = 30
+	// nearLimitPercent is the usage, in percent of the limit, at which a
+	// container is throttled or at risk of being OOMKilled
+	nearLimitPercent = 90
+	// minMilliCPU and minBytes are the smallest suggested requests, and the
+	// smallest requests checked for over-provisioning are four times them
+	minMilliCPU = 10
+	minBytes    = 32 << 20
+	// cpuStep and memoryStep round suggestions up
+	cpuStep    = 5
+	memoryStep = 1 << 20
+)
+
+// sizeWorkload compares the requests and limits of a workload's containers
+// with their usage and suggests new values
+func sizeWorkload(group *workloadPods, usages map[usageKey]usage, production bool, opts Options) Workload {
+	template := group.pods[0]
+	w := Workload{
+		Namespace:  group.namespace,
+		Kind:       group.kind,
+		Name:       group.name,
+		Pods:       len(group.pods),
+		QOSClass:   qosClass(template.Spec),
+		Production: production,
+		Containers: []Container{},
+	}
+	if production && w.QOSClass == "BestEffort" {
+		w.Issues = append(w.Issues, Issue{Type: IssueBestEffort,
+			Message: "Pods have no requests or limits in a production namespace and are the first to be evicted under node pressure"})
+	}
+
+	for _, spec := range template.Spec.Containers {
+		c := Container{
+			Name:     spec.Name,
+			Requests: Resources{CPU: spec.Resources.Requests["cpu"], Memory: spec.Resources.Requests["memory"]},
+			Limits:   Resources{CPU: spec.Resources.Limits["cpu"], Memory: spec.Resources.Limits["memory"]},
+		}
+		add := func(issueType, message string) {
+			w.Issues = append(w.Issues, Issue{Type: issueType, Container: spec.Name, Message: message})
+		}
+
+		var missing []string
+		if c.Limits.CPU == "" {
+			missing = append(missing, "cpu")
+		}
+		if c.Limits.Memory == "" {
+			missing = append(missing, "memory")
+		}
+		if len(missing) > 0 && w.QOSClass != "BestEffort" {
+			add(IssueMissingLimits, "No "+strings.Join(missing, " or ")+" limit")
+		}
+
+		if killed, last := oomKills(group.pods, spec.Name, opts.Now.Add(-opts.OOMWindow)); killed > 0 {
+			add(IssueOOMKilled, fmt.Sprintf("OOMKilled in %d pod(s) within %s (last at %s), memory limit %s",
+				killed, formatWindow(opts.OOMWindow), last, firstNonEmpty(c.Limits.Memory, "unset")))
+		}
+
+		peak, average, ok := containerUsage(group.pods, spec.Name, usages)
+		if ok {
+			c.Usage = &Resources{CPU: k8s.FormatMilliCPU(peak.milliCPU), Memory: k8s.FormatBytes(peak.bytes)}
+			c.AverageUsage = &Resources{CPU: k8s.FormatMilliCPU(average.milliCPU), Memory: k8s.FormatBytes(average.bytes)}
+			c.Suggested = suggest(c, peak)
+			var over, under, near []string
+			checkResource("cpu", k8s.MilliCPU(c.Requests.CPU), k8s.MilliCPU(c.Limits.CPU), peak.milliCPU, 4*minMilliCPU, k8s.FormatMilliCPU, &over, &under, &near)
+			checkResource("memory", k8s.Bytes(c.Requests.Memory), k8s.Bytes(c.Limits.Memory), peak.bytes, 4*minBytes, k8s.FormatBytes, &over, &under, &near)
+			if len(over) > 0 {
+				add(IssueOverProvisioned, strings.Join(over, "; "))
+			}
+			// BestEffort pods are already reported for having no requests
+			if len(under) > 0 && w.QOSClass != "BestEffort" {
+				add(IssueUnderProvisioned, strings.Join(under, "; "))
+			}
+			if len(near) > 0 {
+				add(IssueNearLimit, strings.Join(near, "; "))
+			}
+		}
+		w.Containers = append(w.Containers, c)
+	}
+	return w
+}
+
+// checkResource compares the peak usage of one resource with its request
+// and limit and appends the problems to over, under and near
+func checkResource(resource string, request, limit, peak, minChecked int64, format func(int64) string, over, under, near *[]string) {
+	switch {
+	case request == 0:
+		*under = append(*under, fmt.Sprintf("no %s request while using %s", resource, format(peak)))
+	case peak > request:
+		*under = append(*under, fmt.Sprintf("%s usage %s exceeds the request %s", resource, format(peak), format(request)))
+	case request >= minChecked && peak*100 < request*overProvisionedPercent:
+		*over = append(*over, fmt.Sprintf("%s usage %s is %d%% of the request %s", resource, format(peak), peak*100/request, format(request)))
+	}
+	if limit > 0 && peak*100 >= limit*nearLimitPercent {
+		consequence := "throttled"
+		if resource == "memory" {
+			consequence = "at risk of being OOMKilled"
+		}
+		*near = append(*near, fmt.Sprintf("%s usage %s is %d%% of the limit %s, %s", resource, format(peak), peak*100/limit, format(limit), consequence))
+	}
+}
+
+// suggest derives requests from the peak usage with headroom, and a memory
+// limit above it. The CPU limit is kept as it is: CPU over the limit is
+// throttled rather than killed, and whether to set one is a policy choice.
+func suggest(c Container, peak usage) *Suggested {
+	cpu := roundUp(int64(float64(peak.milliCPU)*requestHeadroom), cpuStep)
+	if cpu < minMilliCPU {
+		cpu = minMilliCPU
+	}
+	memory := roundUp(int64(float64(peak.bytes)*requestHeadroom), memoryStep)
+	if memory < minBytes {
+		memory = minBytes
+	}
+	memoryLimit := roundUp(int64(float64(peak.bytes)*memoryLimitHeadroom), memoryStep)
+	if memoryLimit < memory {
+		memoryLimit = memory
+	}
+	return &Suggested{
+		Requests: Resources{CPU: k8s.FormatMilliCPU(cpu), Memory: k8s.FormatBytes(memory)},
+		Limits:   Resources{CPU: c.Limits.CPU, Memory: k8s.FormatBytes(memoryLimit)},
+	}
+}
+
+// roundUp rounds value up to a multiple of step
+func roundUp(value, step int64) int64 {
+	return (value + step - 1) / step * step
+}
+
+// containerUsage returns the peak and average usage of a container across
+// the pods that have metrics
+func containerUsage(pods []k8s.Pod, container string, usages map[usageKey]usage) (peak, average usage, ok bool) {
+	var total usage
+	var count int64
+	for _, pod := range pods {
+		u, found := usages[usageKey{pod.Metadata.Namespace, pod.Metadata.Name, container}]
+		if !found {
+			continue
+		}
+		count++
+		total.milliCPU += u.milliCPU
+		total.bytes += u.bytes
+		if u.milliCPU > peak.milliCPU {
+			peak.milliCPU = u.milliCPU
+		}
+		if u.bytes > peak.bytes {
+			peak.bytes = u.bytes
+		}
+	}
+	if count == 0 {
+		return usage{}, usage{}, false
+	}
+	return peak, usage{total.milliCPU / count, total.bytes / count}, true
+}
+
+// oomKills counts the pods whose container was OOMKilled since the cutoff
+// and returns the time of the last kill
+func oomKills(pods []k8s.Pod, container string, since time.Time) (int, string) {
+	count, last := 0, ""
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != container {
+				continue
+			}
+			for _, state := range []k8s.ContainerState{status.State, status.LastState} {
+				terminated := state.Terminated
+				if terminated == nil || terminated.Reason != "OOMKilled" {
+					continue
+				}
+				finished, err := time.Parse(time.RFC3339, terminated.FinishedAt)
+				if err != nil || finished.Before(since) {
+					continue
+				}
+				count++
+				if terminated.FinishedAt > last {
+					last = terminated.FinishedAt
+				}
+				break
+			}
+		}
+	}
+	return count, last
+}
+
+// qosClass derives the QoS class of a pod from its containers' resources
+func qosClass(spec k8s.PodSpec) string {
+	containers := append(append([]k8s.Container{}, spec.InitContainers...), spec.Containers...)
+	guaranteed, empty := true, true
+	for _, c := range containers {
+		for resource, parse := range map[string]func(string) int64{"cpu": k8s.MilliCPU, "memory": k8s.Bytes} {
+			request, limit := c.Resources.Requests[resource], c.Resources.Limits[resource]
+			if request != "" || limit != "" {
+				empty = false
+			}
+			// a missing request defaults to the limit
+			if limit == "" || request != "" && parse(request) != parse(limit) {
+				guaranteed = false
+			}
+		}
+	}
+	switch {
+	case empty:
+		return "BestEffort"
+	case guaranteed:
+		return "Guaranteed"
+	default:
+		return "Burstable"
+	}
+}
+
+// formatWindow renders a window such as 24h or 30m
+func formatWindow(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
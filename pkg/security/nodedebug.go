@@ -0,0 +1,51 @@
+package security
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// KubeletLogImage is the image of the node debugging pod that reads the
+	// kubelet journal
+	KubeletLogImage = "busybox:1.36"
+	// MaxKubeletLogLines caps the kubelet journal lines a capture may read
+	MaxKubeletLogLines = 2000
+)
+
+// kubeletLogCommand matches the only "kubectl debug" command allowed: the
+// kubelet journal capture built by KubeletLogCommand. The node and namespace
+// are DNS-1123 names, so the command tokenizes the same way everywhere.
+var kubeletLogCommand = regexp.MustCompile(`^debug node/([a-z0-9]([-a-z0-9.]*[a-z0-9])?) -n ([a-z0-9]([-a-z0-9]*[a-z0-9])?) --image=` +
+	regexp.QuoteMeta(KubeletLogImage) + ` --profile=sysadmin --attach=true -- chroot /host journalctl -u kubelet --no-pager -n ([1-9][0-9]{0,3})$`)
+
+// KubeletLogCommand returns the kubectl command (without the "kubectl"
+// prefix) that reads the last lines of a node's kubelet journal from a
+// privileged debugging pod
+func KubeletLogCommand(node, namespace string, lines int) string {
+	return fmt.Sprintf("debug node/%s -n %s --image=%s --profile=sysadmin --attach=true -- chroot /host journalctl -u kubelet --no-pager -n %d",
+		node, namespace, KubeletLogImage, lines)
+}
+
+// ValidateKubeletLogCommand validates a kubelet journal capture. "kubectl
+// debug" is not an operation ValidateCommand admits, since an arbitrary
+// node debugging pod is a root shell on the node; this entry point admits
+// exactly the command built by KubeletLogCommand, at the admin access level
+// and in an allowed namespace.
+func (v *Validator) ValidateKubeletLogCommand(command string) error {
+	if v.secConfig.AccessLevel != AccessLevelAdmin {
+		return &ValidationError{Message: "Error: Capturing kubelet logs requires the admin access level"}
+	}
+	match := kubeletLogCommand.FindStringSubmatch(command)
+	if match == nil {
+		return &ValidationError{Message: "Error: Only the kubelet log capture command may use kubectl debug"}
+	}
+	if lines, _ := strconv.Atoi(match[5]); lines > MaxKubeletLogLines {
+		return &ValidationError{Message: fmt.Sprintf("Error: Kubelet log capture is limited to %d lines", MaxKubeletLogLines)}
+	}
+	if namespace := match[3]; v.secConfig.HasNamespaceRestrictions() && !v.secConfig.IsNamespaceAllowed(namespace) {
+		return &ValidationError{Message: fmt.Sprintf("Error: Access to namespace '%s' is denied by security configuration", namespace)}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+)
+
+// builtinCommandTypes are the command types whose rules are defined in this
+// package; a CommandPolicy cannot replace them
+var builtinCommandTypes = map[string]bool{
+	CommandTypeKubectl:  true,
+	CommandTypeHelm:     true,
+	CommandTypeCilium:   true,
+	CommandTypeHubble:   true,
+	CommandTypeFlux:     true,
+	CommandTypeArgoCD:   true,
+	CommandTypeIstioctl: true,
+}
+
+// FluxCommandPolicy defines the flux operations allowed at each access
+// level. Exporting sources with credentials is never allowed.
+var FluxCommandPolicy = &CommandPolicy{
+	ReadOperations: []string{
+		"get", "diff", "logs", "tree", "trace", "events", "stats", "check",
+		"version", "completion", "help",
+	},
+	ReadWriteOperations: []string{"reconcile", "suspend", "resume"},
+	AdminOperations:     []string{"create", "delete"},
+	BlockedGlobalFlags:  append(append([]string{}, KubectlBlockedGlobalFlags...), "--cluster", "--user", "--with-credentials"),
+	NamespaceFlags:      []string{"-n", "--namespace"},
+	AllNamespacesFlags:  []string{"-A", "--all-namespaces"},
+	NamespaceExemptOperations: []string{
+		"check", "version", "completion", "help",
+	},
+}
+
+// ArgoCDCommandPolicy defines the argocd operations allowed at each access
+// level. Applications are selected with -N/--app-namespace or named as
+// <app-namespace>/<app>; flags that point the CLI at another Argo CD server
+// or inject credentials are blocked.
+var ArgoCDCommandPolicy = &CommandPolicy{
+	ReadOperations: []string{
+		"app list", "app get", "app diff", "app history", "app logs",
+		"app manifests", "app resources", "app wait", "proj list", "proj get",
+		"version", "completion", "help",
+	},
+	ReadWriteOperations: []string{"app sync", "app rollback", "app terminate-op"},
+	AdminOperations:     []string{"app create", "app delete"},
+	BlockedGlobalFlags: []string{
+		"--server", "--auth-token", "--config", "--header", "--insecure",
+		"--plaintext", "--client-crt", "--client-crt-key", "--server-crt",
+		"--kube-context", "--kubeconfig",
+	},
+	NamespaceFlags:            []string{"-N", "--app-namespace"},
+	NamespaceExemptOperations: []string{"version", "completion", "help"},
+	QualifiedNames:            true,
+	ValueFlags:                []string{"--local", "--local-repo-root", "--file", "-f", "--revision"},
+}
+
+// IstioctlCommandPolicy defines the istioctl operations allowed at each
+// access level. Only diagnostics are exposed; installing, upgrading and
+// injecting sidecars are left to dedicated tooling. The kubeconfig and
+// context flags are blocked as they are for kubectl, including istioctl's
+// -c shorthand for --kubeconfig.
+var IstioctlCommandPolicy = &CommandPolicy{
+	ReadOperations: []string{
+		"analyze", "proxy-status", "ps", "proxy-config", "pc",
+		"x describe", "experimental describe", "version", "completion", "help",
+	},
+	BlockedGlobalFlags: append(append([]string{}, KubectlBlockedGlobalFlags...), "-c"),
+	NamespaceFlags:     []string{"-n", "--namespace"},
+	AllNamespacesFlags: []string{"-A", "--all-namespaces"},
+	NamespaceExemptOperations: []string{
+		"version", "completion", "help",
+	},
+}
+
+// builtinCommandPolicies are the rules of the built-in command types that
+// are validated through a CommandPolicy
+var builtinCommandPolicies = map[string]*CommandPolicy{
+	CommandTypeFlux:     FluxCommandPolicy,
+	CommandTypeArgoCD:   ArgoCDCommandPolicy,
+	CommandTypeIstioctl: IstioctlCommandPolicy,
+}
+
+// CommandPolicy holds the validation rules of a command type that is
+// registered at runtime, such as a CLI declared by a plugin descriptor.
+// Operations may span several words (e.g. "app list"); a command's
+// operation is the longest listed operation its leading arguments match.
+type CommandPolicy struct {
+	// ReadOperations are allowed at every access level
+	ReadOperations []string
+	// ReadWriteOperations are allowed at readwrite and admin
+	ReadWriteOperations []string
+	// AdminOperations are allowed at admin only
+	AdminOperations []string
+	// BlockedGlobalFlags are rejected at every access level
+	BlockedGlobalFlags []string
+	// NamespaceFlags are the flags whose value is a namespace (e.g. -n, --namespace)
+	NamespaceFlags []string
+	// AllNamespacesFlags select every namespace (e.g. -A, --all-namespaces)
+	AllNamespacesFlags []string
+	// NamespaceExemptOperations may run without a namespace flag when
+	// --allow-namespaces is configured
+	NamespaceExemptOperations []string
+	// QualifiedNames reports that arguments after the operation may name an
+	// object as <namespace>/<name>; the namespace is checked like the value
+	// of a namespace flag
+	QualifiedNames bool
+	// ValueFlags are flags other than namespace flags whose value is the
+	// next token, such as paths, so that it is not taken for an argument
+	ValueFlags []string
+}
+
+// IsBuiltinCommandType reports whether a command type is validated by rules
+// defined in this package
+func IsBuiltinCommandType(commandType string) bool {
+	return builtinCommandTypes[commandType]
+}
+
+// SetCommandPolicy registers the validation rules of a command type. The
+// built-in command types cannot be redefined.
+func (s *SecurityConfig) SetCommandPolicy(commandType string, policy *CommandPolicy) error {
+	if builtinCommandTypes[commandType] {
+		return fmt.Errorf("command type '%s' is built in and cannot be redefined", commandType)
+	}
+	if s.commandPolicies == nil {
+		s.commandPolicies = map[string]*CommandPolicy{}
+	}
+	s.commandPolicies[commandType] = policy
+	return nil
+}
+
+// CommandPolicy returns the rules of a command type validated through a
+// CommandPolicy, or nil for kubectl, helm, cilium, hubble and unknown
+// command types
+func (s *SecurityConfig) CommandPolicy(commandType string) *CommandPolicy {
+	if policy, ok := builtinCommandPolicies[commandType]; ok {
+		return policy
+	}
+	return s.commandPolicies[commandType]
+}
+
+// operation returns the operation of a tokenized command: the longest
+// listed operation matching its leading positional arguments, or the first
+// positional argument when none matches. Values of namespace flags are
+// skipped so "-n team-a app list" still yields "app list".
+func (p *CommandPolicy) operation(tokens []string, commandType string) string {
+	args := p.positionalArgs(tokens, commandType)
+	if len(args) == 0 {
+		return ""
+	}
+
+	match := ""
+	for _, list := range [][]string{p.ReadOperations, p.ReadWriteOperations, p.AdminOperations, p.NamespaceExemptOperations} {
+		for _, op := range list {
+			words := strings.Fields(op)
+			normalized := strings.Join(words, " ")
+			if len(words) == 0 || len(words) > len(args) || len(normalized) <= len(match) {
+				continue
+			}
+			if strings.Join(args[:len(words)], " ") == normalized {
+				match = normalized
+			}
+		}
+	}
+	if match == "" {
+		return args[0]
+	}
+	return match
+}
+
+// positionalArgs returns the positional arguments of a tokenized command,
+// without a leading command name and the values of namespace and value flags
+func (p *CommandPolicy) positionalArgs(tokens []string, commandType string) []string {
+	var args []string
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if strings.HasPrefix(t, "-") {
+			if p.isNamespaceFlag(t) || p.isValueFlag(t) {
+				i++
+			}
+			continue
+		}
+		if len(args) == 0 && t == commandType {
+			continue
+		}
+		args = append(args, t)
+	}
+	return args
+}
+
+// isNamespaceFlag reports whether a token is a namespace flag that takes its
+// value from the next token
+func (p *CommandPolicy) isNamespaceFlag(token string) bool {
+	for _, flag := range p.NamespaceFlags {
+		if token == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// isValueFlag reports whether a token is one of the ValueFlags
+func (p *CommandPolicy) isValueFlag(token string) bool {
+	for _, flag := range p.ValueFlags {
+		if token == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// namespaces returns the namespace values of a tokenized command and
+// whether it selects all namespaces. "--flag value", "--flag=value" and the
+// compact short form "-nvalue" are recognized.
+func (p *CommandPolicy) namespaces(tokens []string) (namespaces []string, allNamespaces bool) {
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		name, value, hasValue := strings.Cut(t, "=")
+		for _, flag := range p.AllNamespacesFlags {
+			if name == flag && (!hasValue || (value != "false" && value != "0")) {
+				allNamespaces = true
+			}
+		}
+		for _, flag := range p.NamespaceFlags {
+			if len(flag) == 2 && flag[0] == '-' && flag[1] != '-' && len(t) > 2 && strings.HasPrefix(t, flag) && t[2] != '=' {
+				namespaces = append(namespaces, t[2:])
+				continue
+			}
+			if name != flag {
+				continue
+			}
+			if hasValue {
+				namespaces = append(namespaces, value)
+			} else if i+1 < len(tokens) {
+				namespaces = append(namespaces, tokens[i+1])
+				i++
+			}
+		}
+	}
+	return namespaces, allNamespaces
+}
+
+// qualifiedNamespaces returns the namespaces of the <namespace>/<name>
+// arguments that follow a command's operation. The value of a flag that is
+// not listed in NamespaceFlags or ValueFlags cannot be told apart from an
+// argument, so a slash in it also counts and the command fails closed.
+func (p *CommandPolicy) qualifiedNamespaces(tokens []string, commandType string) []string {
+	args := p.positionalArgs(tokens, commandType)
+	operationWords := len(strings.Fields(p.operation(tokens, commandType)))
+	if operationWords > len(args) {
+		return nil
+	}
+	var namespaces []string
+	for _, arg := range args[operationWords:] {
+		if namespace, _, ok := strings.Cut(arg, "/"); ok {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// validateNamespaceScope applies --allow-namespaces to a command of a
+// registered command type. Flags after "--" are ignored, but arguments after
+// it are still checked as qualified names. A repeated namespace flag must
+// always name the same namespace. Without a namespace flag only exempt operations may run;
+// a CLI without namespace flags can therefore only run exempt operations
+// when restrictions are configured.
+func (p *CommandPolicy) validateNamespaceScope(allTokens []string, commandType string, secConfig *SecurityConfig) error {
+	tokens := splitArgsAtDoubleDash(allTokens)
+	namespaces, allNamespaces := p.namespaces(tokens)
+	if p.QualifiedNames {
+		namespaces = append(namespaces, p.qualifiedNamespaces(allTokens, commandType)...)
+	}
+	for _, namespace := range namespaces {
+		if namespace != namespaces[0] {
+			return &ValidationError{Message: "Error: Command names multiple namespaces which is not allowed"}
+		}
+	}
+	if !secConfig.HasNamespaceRestrictions() {
+		return nil
+	}
+	if allNamespaces {
+		return &ValidationError{Message: "Error: Access to all namespaces is restricted by security configuration"}
+	}
+	if len(namespaces) > 0 {
+		if !secConfig.IsNamespaceAllowed(namespaces[0]) {
+			return &ValidationError{
+				Message: "Error: Access to namespace '" + namespaces[0] + "' is denied by security configuration",
+			}
+		}
+		return nil
+	}
+
+	operation := p.operation(tokens, commandType)
+	for _, exempt := range p.NamespaceExemptOperations {
+		if operation == exempt {
+			return nil
+		}
+	}
+	if len(p.NamespaceFlags) == 0 {
+		return &ValidationError{
+			Message: "Error: Operation '" + operation + "' cannot be restricted to a namespace and is not allowed when --allow-namespaces is configured",
+		}
+	}
+	return &ValidationError{
+		Message: "Error: Command does not specify a namespace; " + strings.Join(p.NamespaceFlags, "/") + " is required when --allow-namespaces is configured",
+	}
+}
@@ -22,17 +22,49 @@ type SecurityConfig struct {
 	allowedNamespaces []string
 	// allowedNamespacesRe is a list of compiled regex patterns for namespace matching
 	allowedNamespacesRe []*regexp.Regexp
+	// redactKeys are lowercase substrings of value keys whose values are masked
+	redactKeys []string
+	// commandPolicies are the rules of command types registered at runtime
+	commandPolicies map[string]*CommandPolicy
 }
 
+// DefaultRedactKeys are the key patterns masked in helm values by default
+var DefaultRedactKeys = []string{"password", "token", "secret", "key"}
+
 // NewSecurityConfig creates a new SecurityConfig instance
 func NewSecurityConfig() *SecurityConfig {
 	return &SecurityConfig{
 		AccessLevel:         AccessLevelReadOnly,
 		allowedNamespaces:   []string{},
 		allowedNamespacesRe: []*regexp.Regexp{},
+		redactKeys:          DefaultRedactKeys,
 	}
 }
 
+// SetRedactKeys sets the comma-separated key patterns whose values are
+// masked. Patterns match case-insensitively anywhere in a key; an empty
+// list disables masking.
+func (s *SecurityConfig) SetRedactKeys(keys string) {
+	s.redactKeys = []string{}
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "" {
+			s.redactKeys = append(s.redactKeys, key)
+		}
+	}
+}
+
+// IsRedactedKey reports whether values under the key should be masked
+func (s *SecurityConfig) IsRedactedKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range s.redactKeys {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
 // SetAllowedNamespaces sets the list of allowed namespaces
 func (s *SecurityConfig) SetAllowedNamespaces(namespaces string) {
 	s.allowedNamespaces = []string{}
@@ -74,10 +106,15 @@ func (s *SecurityConfig) SetAllowedNamespaces(namespaces string) {
 	}
 }
 
+// HasNamespaceRestrictions reports whether an allowed namespace list is configured
+func (s *SecurityConfig) HasNamespaceRestrictions() bool {
+	return len(s.allowedNamespaces) > 0 || len(s.allowedNamespacesRe) > 0
+}
+
 // IsNamespaceAllowed checks if a namespace is allowed to be accessed
 func (s *SecurityConfig) IsNamespaceAllowed(namespace string) bool {
 	// If no restrictions are defined, allow all namespaces
-	if len(s.allowedNamespaces) == 0 && len(s.allowedNamespacesRe) == 0 {
+	if !s.HasNamespaceRestrictions() {
 		return true
 	}
 
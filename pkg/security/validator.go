@@ -1,6 +1,7 @@
 package security
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/google/shlex"
@@ -8,10 +9,13 @@ import (
 
 // Command type constants
 const (
-	CommandTypeKubectl = "kubectl"
-	CommandTypeHelm    = "helm"
-	CommandTypeCilium  = "cilium"
-	CommandTypeHubble  = "hubble"
+	CommandTypeKubectl  = "kubectl"
+	CommandTypeHelm     = "helm"
+	CommandTypeCilium   = "cilium"
+	CommandTypeHubble   = "hubble"
+	CommandTypeFlux     = "flux"
+	CommandTypeArgoCD   = "argocd"
+	CommandTypeIstioctl = "istioctl"
 )
 
 var (
@@ -69,7 +73,18 @@ var (
 	// HelmReadOperations defines helm operations that don't modify state
 	HelmReadOperations = []string{
 		"get", "history", "list", "show", "status", "search", "repo",
-		"env", "version", "verify", "completion", "help",
+		"env", "version", "verify", "completion", "help", "template",
+	}
+
+	// HelmReadWriteOperations defines helm operations that change releases
+	HelmReadWriteOperations = []string{
+		"install", "upgrade", "rollback", "uninstall", "test",
+	}
+
+	// helmReleaseSecretReadOperations are kubectl operations that print the
+	// objects they target; they may not target Helm release secrets
+	helmReleaseSecretReadOperations = map[string]bool{
+		"get": true, "describe": true, "edit": true,
 	}
 
 	// CiliumReadOperations defines cilium operations that don't modify state
@@ -102,6 +117,9 @@ var (
 		"options":       true,
 		"plugin":        true,
 		"explain":       true,
+		// kustomize only renders a local directory; it never talks to the
+		// API server
+		"kustomize": true,
 	}
 
 	// kubectlClusterScopedResources is the set of well-known cluster-scoped
@@ -144,6 +162,12 @@ var (
 		"ingressclass": true, "ingressclasses": true,
 		"flowschema": true, "flowschemas": true,
 		"prioritylevelconfiguration": true, "prioritylevelconfigurations": true,
+
+		// CNI policy CRDs that are cluster-scoped (Calico, Cilium)
+		"globalnetworkpolicy": true, "globalnetworkpolicies": true,
+		"globalnetworkpolicies.crd.projectcalico.org": true,
+		"ciliumclusterwidenetworkpolicy":              true, "ciliumclusterwidenetworkpolicies": true, "ccnp": true,
+		"ciliumclusterwidenetworkpolicies.cilium.io": true,
 	}
 
 	// helmNamespaceExemptOperations mirror kubectlNamespaceExemptOperations
@@ -159,6 +183,51 @@ var (
 		"verify":     true,
 		"show":       true,
 	}
+
+	// ciliumNamespaceExemptOperations and hubbleNamespaceExemptOperations
+	// are operations that report on the CNI itself (agent status, versions,
+	// nodes, local config) rather than on workloads, so they may run without
+	// a namespace filter when --allow-namespaces is configured. Everything
+	// else (observe, endpoint, policy, identity, monitor, ...) can expose
+	// traffic or endpoints of any namespace and needs a filter.
+	ciliumNamespaceExemptOperations = map[string]bool{
+		"status":     true,
+		"version":    true,
+		"config":     true,
+		"context":    true,
+		"completion": true,
+		"help":       true,
+	}
+	hubbleNamespaceExemptOperations = map[string]bool{
+		"status":     true,
+		"version":    true,
+		"config":     true,
+		"list":       true,
+		"completion": true,
+		"help":       true,
+	}
+
+	// hubbleNamespaceFlags are hubble flags whose value is a namespace filter
+	hubbleNamespaceFlags = map[string]bool{
+		"-n": true, "--namespace": true, "--from-namespace": true, "--to-namespace": true,
+	}
+
+	// ciliumNamespaceFlags are cilium flags whose value is a namespace
+	// filter. Unlike hubble, cilium's -n/--namespace names the namespace
+	// Cilium is installed in and does not filter the workloads shown, so it
+	// is listed in ciliumInstallNamespaceFlags instead.
+	ciliumNamespaceFlags = map[string]bool{
+		"--from-namespace": true, "--to-namespace": true,
+	}
+	ciliumInstallNamespaceFlags = map[string]bool{
+		"-n": true, "--namespace": true,
+	}
+
+	// ciliumPodFlags are cilium / hubble flags whose value is "[namespace/]pod".
+	// Hubble resolves a pod without a namespace prefix in "default".
+	ciliumPodFlags = map[string]bool{
+		"--pod": true, "--from-pod": true, "--to-pod": true,
+	}
 )
 
 // Validator handles validation of commands against security configuration
@@ -194,6 +263,9 @@ func (v *Validator) getReadOperationsList(commandType string) []string {
 	case CommandTypeHubble:
 		return HubbleReadOperations
 	default:
+		if policy := v.secConfig.CommandPolicy(commandType); policy != nil {
+			return policy.ReadOperations
+		}
 		return []string{}
 	}
 }
@@ -204,9 +276,7 @@ func (v *Validator) getReadWriteOperationsList(commandType string) []string {
 	case CommandTypeKubectl:
 		return KubectlReadWriteOperations
 	case CommandTypeHelm:
-		// For now, assume helm write operations are same as read operations
-		// This can be expanded when helm write operations are defined
-		return []string{}
+		return HelmReadWriteOperations
 	case CommandTypeCilium:
 		// For now, assume cilium write operations are same as read operations
 		// This can be expanded when cilium write operations are defined
@@ -216,6 +286,9 @@ func (v *Validator) getReadWriteOperationsList(commandType string) []string {
 		// This can be expanded when hubble write operations are defined
 		return []string{}
 	default:
+		if policy := v.secConfig.CommandPolicy(commandType); policy != nil {
+			return policy.ReadWriteOperations
+		}
 		return []string{}
 	}
 }
@@ -238,6 +311,9 @@ func (v *Validator) getAdminOperationsList(commandType string) []string {
 		// This can be expanded when hubble admin operations are defined
 		return []string{}
 	default:
+		if policy := v.secConfig.CommandPolicy(commandType); policy != nil {
+			return policy.AdminOperations
+		}
 		return []string{}
 	}
 }
@@ -249,6 +325,11 @@ func (v *Validator) ValidateCommand(command, commandType string) error {
 		return err
 	}
 
+	// kubectl debug is only admitted through ValidateKubeletLogCommand
+	if commandType == CommandTypeKubectl && v.extractOperationFromCommand(command, commandType) == "debug" {
+		return &ValidationError{Message: "Error: kubectl debug is not allowed; debugging pods can give root access to nodes and containers"}
+	}
+
 	// Check access level restrictions
 	if err := v.validateAccessLevel(command, commandType); err != nil {
 		return err
@@ -259,9 +340,45 @@ func (v *Validator) ValidateCommand(command, commandType string) error {
 		return err
 	}
 
+	// Check reads of Helm release secrets
+	if err := v.validateHelmReleaseSecrets(command, commandType); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// ExtractOperation returns the operation verb of a command, e.g. "get" for
+// "kubectl get pods -n default"
+func (v *Validator) ExtractOperation(command, commandType string) string {
+	return v.extractOperationFromCommand(command, commandType)
+}
+
+// IsReadOperation reports whether a command only reads cluster state, i.e.
+// whether its operation would be admitted at the readonly access level.
+// Namespace scope and blocked flags are not considered.
+func (v *Validator) IsReadOperation(command, commandType string) bool {
+	operation := v.extractOperationFromCommand(command, commandType)
+	if operation == "config" && v.isConfigWriteOperation(command) {
+		return false
+	}
+	if operation == "auth" && v.isAuthWriteOperation(command, commandType) {
+		return false
+	}
+	return v.isOperationInList(operation, v.getReadOperationsList(commandType))
+}
+
+// ExtractNamespace returns the namespace a command is explicitly scoped to.
+// It returns an empty string when the command has no namespace flag, spans
+// all namespaces, or carries conflicting namespace flags.
+func ExtractNamespace(command string) string {
+	namespace := extractNamespaceFromTokens(splitArgsAtDoubleDash(TokenizeCommand(command)))
+	if namespace == namespaceTokenAmbiguous || namespace == namespaceTokenAllNamespaces {
+		return ""
+	}
+	return namespace
+}
+
 // validateGlobalFlags rejects commands that contain flags which can redirect API traffic
 // or inject credentials, regardless of access level.
 //
@@ -282,30 +399,45 @@ func (v *Validator) validateGlobalFlags(command, commandType string) error {
 	case CommandTypeHelm:
 		blockedFlags = HelmBlockedGlobalFlags
 	default:
-		return nil
+		policy := v.secConfig.CommandPolicy(commandType)
+		if policy == nil {
+			return nil
+		}
+		blockedFlags = policy.BlockedGlobalFlags
 	}
 
 	blocked := make(map[string]struct{}, len(blockedFlags))
 	for _, f := range blockedFlags {
-		blocked[strings.ToLower(f)] = struct{}{}
+		if strings.HasPrefix(f, "--") {
+			blocked[strings.ToLower(f)] = struct{}{}
+		} else {
+			// Shorthands are case sensitive (-c and -C differ)
+			blocked[f] = struct{}{}
+		}
 	}
 
-	tokens := tokenizeCommand(command)
+	tokens := TokenizeCommand(command)
 	for _, t := range tokens {
 		// Inspect only flag-shaped tokens. Positional args like resource
 		// names cannot turn into a flag once shlex has split them out.
-		if !strings.HasPrefix(t, "--") {
+		if !strings.HasPrefix(t, "-") || len(t) < 2 {
 			continue
 		}
-		// Canonical name = everything before the first '=' (kubectl/helm
-		// long flags use the `--flag=value` form). Bare booleans like
-		// `--insecure-skip-tls-verify` have no '=', so the whole token is
-		// the canonical name.
-		name := t
-		if i := strings.Index(t, "="); i >= 0 {
-			name = t[:i]
+		var name string
+		if strings.HasPrefix(t, "--") {
+			// Canonical name = everything before the first '=' (kubectl/helm
+			// long flags use the `--flag=value` form). Bare booleans like
+			// `--insecure-skip-tls-verify` have no '=', so the whole token is
+			// the canonical name.
+			name = t
+			if i := strings.Index(t, "="); i >= 0 {
+				name = t[:i]
+			}
+			name = strings.ToLower(name)
+		} else {
+			// A shorthand may carry its value in the same token (-c/path)
+			name = t[:2]
 		}
-		name = strings.ToLower(name)
 		if _, bad := blocked[name]; bad {
 			return &ValidationError{Message: "Error: Global flag '" + name + "' is not allowed; it can redirect API traffic or inject credentials"}
 		}
@@ -339,8 +471,9 @@ func (v *Validator) validateAccessLevel(command, commandType string) error {
 			return &ValidationError{Message: "Error: Cannot execute write or admin operations in read-only mode"}
 		}
 	case AccessLevelReadWrite:
-		// Special handling for config operations - allow write config operations in readwrite mode
-		if operation == "config" {
+		// Special handling for config operations - allow write config operations in readwrite mode.
+		// Command types with a CommandPolicy list their config operations explicitly.
+		if operation == "config" && v.secConfig.CommandPolicy(commandType) == nil {
 			return nil // All config operations are allowed in readwrite mode
 		}
 		if !v.isOperationInList(operation, readOperations) && !v.isOperationInList(operation, readWriteOperations) {
@@ -352,7 +485,7 @@ func (v *Validator) validateAccessLevel(command, commandType string) error {
 		}
 	case AccessLevelAdmin:
 		// Admin level allows all operations (read, write, and admin), including all config operations
-		if operation == "config" {
+		if operation == "config" && v.secConfig.CommandPolicy(commandType) == nil {
 			return nil // All config operations are allowed in admin mode
 		}
 		if !v.isOperationInList(operation, readOperations) &&
@@ -373,13 +506,13 @@ const (
 	namespaceTokenAllNamespaces = "*"
 )
 
-// tokenizeCommand splits a command string the same way the executor does
+// TokenizeCommand splits a command string the same way the executor does
 // (shlex), so the validator's view of `-n value`, quoting and the `--`
 // separator matches what kubectl will actually receive. Falls back to
 // strings.Fields when shlex rejects the input (unterminated quotes etc.)
 // so validation still runs -- the executor will reject the broken input
 // downstream.
-func tokenizeCommand(command string) []string {
+func TokenizeCommand(command string) []string {
 	if tokens, err := shlex.Split(command); err == nil {
 		return tokens
 	}
@@ -402,7 +535,15 @@ func splitArgsAtDoubleDash(tokens []string) []string {
 
 // validateNamespaceScope validates if a command's namespace scope is allowed by security settings
 func (v *Validator) validateNamespaceScope(command, commandType string) error {
-	tokens := splitArgsAtDoubleDash(tokenizeCommand(command))
+	allTokens := TokenizeCommand(command)
+	tokens := splitArgsAtDoubleDash(allTokens)
+
+	if commandType == CommandTypeCilium || commandType == CommandTypeHubble {
+		return v.validateCiliumNamespaceScope(tokens, commandType)
+	}
+	if policy := v.secConfig.CommandPolicy(commandType); policy != nil {
+		return policy.validateNamespaceScope(allTokens, commandType, v.secConfig)
+	}
 
 	namespace := extractNamespaceFromTokens(tokens)
 
@@ -411,7 +552,7 @@ func (v *Validator) validateNamespaceScope(command, commandType string) error {
 		return &ValidationError{Message: "Error: Command contains multiple namespace flags which is not allowed"}
 	}
 
-	hasRestrictions := len(v.secConfig.allowedNamespaces) > 0 || len(v.secConfig.allowedNamespacesRe) > 0
+	hasRestrictions := v.secConfig.HasNamespaceRestrictions()
 
 	// If command applies to all namespaces, and there are namespace restrictions
 	if namespace == namespaceTokenAllNamespaces && hasRestrictions {
@@ -467,12 +608,112 @@ func (v *Validator) isCommandNamespaceExempt(tokens []string, commandType string
 		return kubectlOnlyTargetsClusterScopedResources(tokens, operation)
 	case CommandTypeHelm:
 		return helmNamespaceExemptOperations[operation]
+	case CommandTypeCilium:
+		return ciliumNamespaceExemptOperations[operation]
+	case CommandTypeHubble:
+		return hubbleNamespaceExemptOperations[operation]
 	default:
-		// cilium / hubble are not gated by --allow-namespaces in this validator.
 		return true
 	}
 }
 
+// validateCiliumNamespaceScope applies --allow-namespaces to cilium and
+// hubble commands. Their namespace selectors (--from-namespace,
+// --to-namespace, --pod, --from-pod, --to-pod, and for hubble --namespace)
+// may repeat, because hubble ORs filters of the same kind, so every value
+// must be allowed. Without any namespace selector only exempt operations may
+// run, and negated filters (--not) are rejected because they select
+// everything except a namespace.
+func (v *Validator) validateCiliumNamespaceScope(tokens []string, commandType string) error {
+	if !v.secConfig.HasNamespaceRestrictions() {
+		return nil
+	}
+
+	namespaces, allNamespaces, negated := extractCiliumNamespaces(tokens, commandType)
+	if allNamespaces {
+		return &ValidationError{Message: "Error: Access to all namespaces is restricted by security configuration"}
+	}
+	if negated {
+		return &ValidationError{Message: "Error: Negated filters (--not) are not allowed when --allow-namespaces is configured"}
+	}
+	for _, namespace := range namespaces {
+		if !v.secConfig.IsNamespaceAllowed(namespace) {
+			return &ValidationError{
+				Message: "Error: Access to namespace '" + namespace + "' is denied by security configuration",
+			}
+		}
+	}
+	if len(namespaces) == 0 && !v.isCommandNamespaceExempt(tokens, commandType) {
+		if commandType == CommandTypeCilium {
+			return &ValidationError{
+				Message: "Error: Command does not specify a namespace filter; --from-namespace, --to-namespace or --pod is required when --allow-namespaces is configured " +
+					"(cilium's -n/--namespace selects the Cilium installation, not the workloads shown)",
+			}
+		}
+		return &ValidationError{
+			Message: "Error: Command does not specify a namespace filter; --namespace, --from-namespace, --to-namespace or --pod is required when --allow-namespaces is configured",
+		}
+	}
+	return nil
+}
+
+// extractCiliumNamespaces returns the namespaces selected by the namespace
+// and pod flags of a cilium / hubble command, whether all namespaces were
+// requested, and whether any filter is negated with --not.
+func extractCiliumNamespaces(tokens []string, commandType string) (namespaces []string, allNamespaces, negated bool) {
+	namespaceFlags := hubbleNamespaceFlags
+	if commandType == CommandTypeCilium {
+		namespaceFlags = ciliumNamespaceFlags
+	}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t == "-A" || t == "--all-namespaces":
+			allNamespaces = true
+			continue
+		case strings.HasPrefix(t, "--all-namespaces="):
+			v := strings.TrimPrefix(t, "--all-namespaces=")
+			allNamespaces = allNamespaces || (v != "false" && v != "0")
+			continue
+		case t == "--not" || strings.HasPrefix(t, "--not="):
+			negated = true
+			continue
+		}
+
+		flag, value, hasValue := strings.Cut(t, "=")
+		if commandType == CommandTypeCilium && ciliumInstallNamespaceFlags[flag] {
+			// Skip the installation namespace so it is not read as a filter
+			if !hasValue {
+				i++
+			}
+			continue
+		}
+		if !namespaceFlags[flag] && !ciliumPodFlags[flag] {
+			// Compact short form `-nVALUE`
+			if namespaceFlags["-n"] && len(t) > 2 && strings.HasPrefix(t, "-n") && !strings.HasPrefix(t, "--") {
+				namespaces = append(namespaces, t[2:])
+			}
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(tokens) {
+				continue
+			}
+			i++
+			value = tokens[i]
+		}
+		if ciliumPodFlags[flag] {
+			if ns, _, ok := strings.Cut(value, "/"); ok {
+				value = ns
+			} else {
+				value = "default"
+			}
+		}
+		namespaces = append(namespaces, value)
+	}
+	return namespaces, allNamespaces, negated
+}
+
 // kubectlOnlyTargetsClusterScopedResources returns true iff the command
 // references one or more resource types AND every referenced resource type
 // is known cluster-scoped. Examples:
@@ -530,6 +771,13 @@ func kubectlOnlyTargetsClusterScopedResources(tokens []string, operation string)
 	return true
 }
 
+// IsClusterScopedResource reports whether a resource type or kind is one of
+// the well-known cluster-scoped resources. Unknown types are reported as
+// namespaced.
+func IsClusterScopedResource(resource string) bool {
+	return kubectlClusterScopedResources[strings.ToLower(resource)]
+}
+
 // collectResourceArgs returns the positional arguments that follow the
 // operation verb, stopping at flags and at the first arg that is purely a
 // name (no type). For "kubectl get nodes node1 -o yaml" it returns
@@ -537,6 +785,7 @@ func kubectlOnlyTargetsClusterScopedResources(tokens []string, operation string)
 // Flag values (`-o yaml`, `-l app=x`, `--field-selector=...`) are skipped.
 func collectResourceArgs(tokens []string, operation string) []string {
 	flagsTakingValues := map[string]bool{
+		"-n": true, "--namespace": true,
 		"-o": true, "--output": true,
 		"-l": true, "--selector": true,
 		"--field-selector":      true,
@@ -588,6 +837,138 @@ func splitResourceTypes(arg string) []string {
 	return out
 }
 
+// Markers of Helm release secrets: their name prefix, the "owner" label and
+// the secret type helm stores releases as
+const (
+	helmReleaseSecretPrefix = "sh.helm.release.v1."
+	helmReleaseSecretType   = "helm.sh/release.v1"
+)
+
+// helmOwnerSelector matches a label selector (with spaces removed) that
+// selects helm's owner label, e.g. owner=helm or owner in (helm,x)
+var helmOwnerSelector = regexp.MustCompile(`(^|,)owner(==?helm(,|$)|in\([^)]*\bhelm\b)`)
+
+// validateHelmReleaseSecrets rejects kubectl reads of the secrets helm
+// stores releases in (sh.helm.release.v1.<release>.v<revision>). They hold
+// the full chart values, including passwords, gzipped and base64-encoded,
+// which would bypass the masking of helm output. Reads by name, reads of
+// secrets selected by helm's owner label or secret type, and list reads of
+// secrets that print their data (-o yaml, json, jsonpath, templates or
+// custom columns) without a field selector excluding the release secret
+// type are rejected. Helm's other labels (name, status, version) are too
+// generic to block, so the field selector is what makes a list read safe.
+func (v *Validator) validateHelmReleaseSecrets(command, commandType string) error {
+	if commandType != CommandTypeKubectl {
+		return nil
+	}
+	tokens := splitArgsAtDoubleDash(TokenizeCommand(command))
+	operation := extractOperationFromTokens(tokens, commandType)
+	if !helmReleaseSecretReadOperations[operation] {
+		return nil
+	}
+
+	err := &ValidationError{Message: "Error: Reading Helm release secrets (" + helmReleaseSecretPrefix + "*) is not allowed; use the helm tools, which mask sensitive values"}
+	secrets := false
+	args := collectResourceArgs(tokens, operation)
+	for _, arg := range args {
+		if strings.Contains(strings.ToLower(arg), helmReleaseSecretPrefix) {
+			return err
+		}
+		for _, rt := range splitResourceTypes(arg) {
+			rt = strings.ToLower(rt)
+			if rt == "secret" || rt == "secrets" || strings.HasPrefix(rt, "secret.") || strings.HasPrefix(rt, "secrets.") {
+				secrets = true
+			}
+		}
+	}
+	if !secrets {
+		return nil
+	}
+
+	var selectors, fieldSelectors []string
+	output := ""
+	for i := 0; i < len(tokens); i++ {
+		name, value, ok := flagValue(tokens, i)
+		if !ok {
+			continue
+		}
+		switch name {
+		case "-l", "--selector":
+			selectors = append(selectors, value)
+		case "--field-selector":
+			fieldSelectors = append(fieldSelectors, value)
+		case "-o", "--output":
+			output = value
+		}
+	}
+
+	for _, selector := range selectors {
+		if helmOwnerSelector.MatchString(strings.ReplaceAll(strings.ToLower(selector), " ", "")) {
+			return err
+		}
+	}
+	excludesReleases := false
+	for _, selector := range fieldSelectors {
+		for _, requirement := range strings.Split(strings.ReplaceAll(strings.ToLower(selector), " ", ""), ",") {
+			if secretType, ok := strings.CutPrefix(requirement, "type!="); ok {
+				excludesReleases = excludesReleases || secretType == helmReleaseSecretType
+				continue
+			}
+			secretType, ok := strings.CutPrefix(requirement, "type==")
+			if !ok {
+				secretType, ok = strings.CutPrefix(requirement, "type=")
+			}
+			if !ok {
+				continue
+			}
+			if secretType == helmReleaseSecretType {
+				return err
+			}
+			// an equality on any other type excludes release secrets too
+			excludesReleases = true
+		}
+	}
+
+	format, _, _ := strings.Cut(strings.ToLower(output), "=")
+	listRead := len(args) == 1 && !strings.Contains(args[0], "/")
+	if operation == "get" && listRead && !excludesReleases && format != "" && format != "wide" && format != "name" {
+		return &ValidationError{Message: "Error: Listing secrets with -o " + format + " would print Helm release secrets (" + helmReleaseSecretPrefix + "*); add --field-selector type!=" + helmReleaseSecretType + " or use the helm tools, which mask sensitive values"}
+	}
+	return nil
+}
+
+// flagValue returns the name and value of the flag at tokens[i], accepting
+// "-l value", "-l=value", "-lvalue", "--selector value" and
+// "--selector=value". Only the short and long flags read by the validator
+// are recognized; ok is false for other tokens.
+func flagValue(tokens []string, i int) (name, value string, ok bool) {
+	shortFlags := map[string]bool{"-l": true, "-o": true}
+	longFlags := map[string]bool{"--selector": true, "--field-selector": true, "--output": true}
+	token := tokens[i]
+	if flag, v, hasValue := strings.Cut(token, "="); strings.HasPrefix(token, "--") {
+		if !longFlags[flag] {
+			return "", "", false
+		}
+		if !hasValue {
+			if i+1 >= len(tokens) {
+				return "", "", false
+			}
+			v = tokens[i+1]
+		}
+		return flag, v, true
+	}
+	if len(token) < 2 || !shortFlags[token[:2]] {
+		return "", "", false
+	}
+	if token == token[:2] {
+		if i+1 >= len(tokens) {
+			return "", "", false
+		}
+		return token, tokens[i+1], true
+	}
+	return token[:2], strings.TrimPrefix(token[2:], "="), true
+}
+
 // isOperationInList checks if an operation is in the given list
 func (v *Validator) isOperationInList(operation string, allowedOperations []string) bool {
 	for _, allowed := range allowedOperations {
@@ -598,9 +979,14 @@ func (v *Validator) isOperationInList(operation string, allowedOperations []stri
 	return false
 }
 
-// extractOperationFromCommand extracts the operation from a command
+// extractOperationFromCommand extracts the operation from a command. For
+// command types with a CommandPolicy, the operation may span several words.
 func (v *Validator) extractOperationFromCommand(command, commandType string) string {
-	return extractOperationFromTokens(splitArgsAtDoubleDash(tokenizeCommand(command)), commandType)
+	tokens := splitArgsAtDoubleDash(TokenizeCommand(command))
+	if policy := v.secConfig.CommandPolicy(commandType); policy != nil {
+		return policy.operation(tokens, commandType)
+	}
+	return extractOperationFromTokens(tokens, commandType)
 }
 
 // extractOperationFromTokens returns the first positional token after the
@@ -631,7 +1017,7 @@ func (v *Validator) isAuthWriteOperation(command, commandType string) bool {
 	if commandType != CommandTypeKubectl {
 		return false
 	}
-	tokens := splitArgsAtDoubleDash(tokenizeCommand(command))
+	tokens := splitArgsAtDoubleDash(TokenizeCommand(command))
 	// Find the "auth" verb position (after skipping the optional "kubectl"
 	// prefix and any global flags), then look at the next positional token.
 	seenAuth := false
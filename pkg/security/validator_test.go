@@ -33,6 +33,10 @@ func TestValidatorAccessLevels(t *testing.T) {
 		{"Admin - create deployment", AccessLevelAdmin, "kubectl create deployment nginx --image=nginx", false, ""},
 		{"Admin - cordon node", AccessLevelAdmin, "kubectl cordon node1", false, ""},
 		{"Admin - drain node", AccessLevelAdmin, "kubectl drain node1", false, ""},
+		{"Admin - debug node", AccessLevelAdmin, "kubectl debug node/node1 -n default --image=busybox:1.36", true, "kubectl debug is not allowed"},
+		{"Admin - debug node shell", AccessLevelAdmin, "kubectl debug node/node1 -n default -it --profile=sysadmin --image=busybox:1.36", true, "kubectl debug is not allowed"},
+		{"ReadWrite - debug node", AccessLevelReadWrite, "kubectl debug node/node1 -n default --image=busybox:1.36", true, "kubectl debug is not allowed"},
+		{"ReadOnly - debug node", AccessLevelReadOnly, "kubectl debug node/node1 -n default --image=busybox:1.36", true, "kubectl debug is not allowed"},
 
 		// proxy access level tests
 		{"ReadOnly - proxy blocked", AccessLevelReadOnly, "kubectl proxy --port=8001", true, "read-only mode"},
@@ -217,6 +221,128 @@ func TestReadOperationsValidation(t *testing.T) {
 	}
 }
 
+func TestHelmAccessLevels(t *testing.T) {
+	tests := []struct {
+		accessLevel AccessLevel
+		command     string
+		shouldErr   bool
+	}{
+		{AccessLevelReadOnly, "helm get values web -n default", false},
+		{AccessLevelReadOnly, "helm template web ./chart -n default --is-upgrade", false},
+		{AccessLevelReadOnly, "helm upgrade --install web ./chart -n default", true},
+		{AccessLevelReadOnly, "helm rollback web 2 -n default", true},
+		{AccessLevelReadWrite, "helm upgrade --install web ./chart -n default", false},
+		{AccessLevelReadWrite, "helm install web ./chart -n default", false},
+		{AccessLevelReadWrite, "helm rollback web 2 -n default", false},
+		{AccessLevelReadWrite, "helm uninstall web -n default", false},
+		{AccessLevelReadWrite, "helm plugin install https://example.com/plugin", true},
+		{AccessLevelAdmin, "helm uninstall web -n default", false},
+	}
+
+	for _, tc := range tests {
+		secConfig := NewSecurityConfig()
+		secConfig.AccessLevel = tc.accessLevel
+		err := NewValidator(secConfig).ValidateCommand(tc.command, CommandTypeHelm)
+		if tc.shouldErr && err == nil {
+			t.Errorf("Expected error for command %q with access level %s", tc.command, tc.accessLevel)
+		} else if !tc.shouldErr && err != nil {
+			t.Errorf("Unexpected error for command %q with access level %s: %v", tc.command, tc.accessLevel, err)
+		}
+	}
+}
+
+func TestValidateKubeletLogCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessLevel AccessLevel
+		command     string
+		shouldErr   bool
+	}{
+		{"capture", AccessLevelAdmin, KubeletLogCommand("node-1", "ops", 200), false},
+		{"not admin", AccessLevelReadWrite, KubeletLogCommand("node-1", "ops", 200), true},
+		{"disallowed namespace", AccessLevelAdmin, KubeletLogCommand("node-1", "kube-system", 200), true},
+		{"too many lines", AccessLevelAdmin, KubeletLogCommand("node-1", "ops", 5000), true},
+		{"injected flag", AccessLevelAdmin, KubeletLogCommand("node-1 -it", "ops", 200), true},
+		{"interactive shell", AccessLevelAdmin, "debug node/node-1 -n ops -it --image=busybox:1.36 --profile=sysadmin", true},
+		{"other image", AccessLevelAdmin, "debug node/node-1 -n ops --image=alpine --profile=sysadmin --attach=true -- chroot /host journalctl -u kubelet --no-pager -n 200", true},
+		{"other command", AccessLevelAdmin, "debug node/node-1 -n ops --image=busybox:1.36 --profile=sysadmin --attach=true -- chroot /host sh -c id", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secConfig := NewSecurityConfig()
+			secConfig.AccessLevel = tt.accessLevel
+			secConfig.SetAllowedNamespaces("ops")
+			err := NewValidator(secConfig).ValidateKubeletLogCommand(tt.command)
+			if tt.shouldErr && err == nil {
+				t.Errorf("Expected %q to be rejected", tt.command)
+			} else if !tt.shouldErr && err != nil {
+				t.Errorf("Expected %q to be allowed, got %v", tt.command, err)
+			}
+		})
+	}
+}
+
+func TestHelmReleaseSecretsBlocked(t *testing.T) {
+	tests := []struct {
+		command   string
+		shouldErr bool
+	}{
+		{"kubectl get secret sh.helm.release.v1.web.v3 -n team-a -o yaml", true},
+		{"kubectl get secrets/sh.helm.release.v1.web.v3 -n team-a", true},
+		{"kubectl describe secret SH.HELM.RELEASE.V1.web.v1 -n team-a", true},
+		{"kubectl get secrets -n team-a -l owner=helm -o yaml", true},
+		{"kubectl get secrets -n team-a --selector=name=web,owner==helm", true},
+		{"kubectl get secrets -n team-a -l 'owner in (helm, other)'", true},
+		{"kubectl get secrets -n team-a --field-selector type=helm.sh/release.v1", true},
+		{"kubectl get secrets -n team-a -o yaml", true},
+		{"kubectl get all,secrets -n team-a -o yaml", true},
+		{"kubectl get secrets -A -o jsonpath='{.items[*].data}'", true},
+		{"kubectl get secrets -n team-a -ojson", true},
+		{"kubectl get secrets -n team-a -l name=myrel,status=deployed -o yaml", true},
+		{"kubectl get secrets -n team-a -lowner=helm", true},
+		{"kubectl get secrets -n team-a -l=owner=helm", true},
+		{"kubectl get secrets -n team-a --field-selector type==helm.sh/release.v1", true},
+		{"kubectl get secrets -n team-a -o yaml --field-selector type!=helm.sh/release.v1", false},
+		{"kubectl get secrets -n team-a -o yaml --field-selector=type=kubernetes.io/tls", false},
+		{"kubectl get secrets -n team-a -o wide", false},
+		{"kubectl get secret web-auth -n team-a -o yaml", false},
+		{"kubectl get secrets -n team-a", false},
+		{"kubectl get secret web-auth -n team-a", false},
+		{"kubectl get secrets -n team-a -l owner!=helm", false},
+		{"kubectl get pods -n team-a -l owner=helm", false},
+		{"kubectl logs web -n team-a -- sh.helm.release.v1.web.v3", false},
+	}
+
+	validator := NewValidator(NewSecurityConfig())
+	for _, tc := range tests {
+		err := validator.ValidateCommand(tc.command, CommandTypeKubectl)
+		if tc.shouldErr && (err == nil || !strings.Contains(err.Error(), "Helm release secrets")) {
+			t.Errorf("Expected %q to be rejected, got %v", tc.command, err)
+		} else if !tc.shouldErr && err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", tc.command, err)
+		}
+	}
+}
+
+func TestRedactKeys(t *testing.T) {
+	secConfig := NewSecurityConfig()
+	for key, expected := range map[string]bool{"password": true, "dbPassword": true, "apiKey": true, "auth-token": true, "existingSecret": true, "replicaCount": false} {
+		if got := secConfig.IsRedactedKey(key); got != expected {
+			t.Errorf("IsRedactedKey(%q) = %v, expected %v", key, got, expected)
+		}
+	}
+
+	secConfig.SetRedactKeys(" Credential ,")
+	if !secConfig.IsRedactedKey("awsCredentials") || secConfig.IsRedactedKey("password") {
+		t.Errorf("Expected only the configured pattern to match")
+	}
+	secConfig.SetRedactKeys("")
+	if secConfig.IsRedactedKey("password") {
+		t.Errorf("Expected an empty pattern list to disable masking")
+	}
+}
+
 func TestValidateCommand(t *testing.T) {
 	// Comprehensive test with multiple security configurations
 	testCases := []struct {
@@ -441,6 +567,7 @@ func TestNamespaceNoFlagBypass(t *testing.T) {
 		{"version no ns", "kubectl version", CommandTypeKubectl, false},
 		{"cluster-info no ns", "kubectl cluster-info", CommandTypeKubectl, false},
 		{"api-resources no ns", "kubectl api-resources", CommandTypeKubectl, false},
+		{"kustomize local dir no ns", "kubectl kustomize /tmp/kustomize-1/src", CommandTypeKubectl, false},
 		{"api-versions no ns", "kubectl api-versions", CommandTypeKubectl, false},
 		{"config get-contexts no ns", "kubectl config get-contexts", CommandTypeKubectl, false},
 		{"explain pods no ns", "kubectl explain pods", CommandTypeKubectl, false},
@@ -606,6 +733,8 @@ func TestClusterScopedResourcesAllowedWithoutNamespace(t *testing.T) {
 		"kubectl get crd",
 		"kubectl get csr",
 		"kubectl get priorityclasses",
+		"kubectl get globalnetworkpolicies.crd.projectcalico.org",
+		"kubectl get ccnp",
 		"kubectl top nodes",
 		"kubectl describe node node1",
 		"kubectl get nodes,pv",         // multi-type, all cluster-scoped
@@ -629,6 +758,7 @@ func TestClusterScopedResourcesAllowedWithoutNamespace(t *testing.T) {
 		"kubectl logs mypod",              // logs always targets a pod (namespaced)
 		"kubectl label node node1 env=x",  // mutation verbs are not in the exempt verb set
 		"kubectl delete node node1",       // mutation verbs are not in the exempt verb set
+		"kubectl debug node/node1",        // the debugging pod is created in a namespace
 	}
 	for _, c := range blocked {
 		if err := validator.ValidateCommand(c, CommandTypeKubectl); err == nil {
@@ -637,6 +767,71 @@ func TestClusterScopedResourcesAllowedWithoutNamespace(t *testing.T) {
 	}
 }
 
+// TestCiliumHubbleNamespaceScope verifies that the namespace selectors of
+// cilium and hubble commands are checked against --allow-namespaces and that
+// unfiltered observation is rejected when restrictions are active.
+func TestCiliumHubbleNamespaceScope(t *testing.T) {
+	secConfig := NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("team-a,team-b")
+	validator := NewValidator(secConfig)
+
+	tests := []struct {
+		command     string
+		commandType string
+		allowed     bool
+	}{
+		{"hubble observe --namespace team-a", CommandTypeHubble, true},
+		{"hubble observe --from-namespace team-a --to-namespace team-b", CommandTypeHubble, true},
+		{"hubble observe --namespace=team-a --namespace team-b", CommandTypeHubble, true},
+		{"hubble observe --from-pod team-a/web-0", CommandTypeHubble, true},
+		{"hubble observe --pod team-b/", CommandTypeHubble, true},
+		{"hubble observe -o json --last 100 --to-pod team-a/api --verdict DROPPED", CommandTypeHubble, true},
+		{"hubble observe", CommandTypeHubble, false},
+		{"hubble observe --verdict DROPPED --last 1000", CommandTypeHubble, false},
+		{"hubble observe --from-namespace team-a --to-namespace kube-system", CommandTypeHubble, false},
+		{"hubble observe --to-pod web-0", CommandTypeHubble, false}, // resolves to "default"
+		{"hubble observe --pod kube-system/coredns-1", CommandTypeHubble, false},
+		{"hubble observe --not --namespace team-a", CommandTypeHubble, false},
+		{"hubble observe --namespace team-a --all-namespaces", CommandTypeHubble, false},
+		{"hubble status", CommandTypeHubble, true},
+		{"hubble list nodes", CommandTypeHubble, true},
+		{"cilium status", CommandTypeCilium, true},
+		{"cilium version", CommandTypeCilium, true},
+		{"cilium endpoint list", CommandTypeCilium, false},
+		{"cilium observe --from-namespace team-a", CommandTypeCilium, true},
+		{"cilium observe -n kube-system --from-pod team-a/web-0 --to-namespace team-b", CommandTypeCilium, true},
+		{"cilium observe --to-namespace kube-system", CommandTypeCilium, false},
+		// cilium -n selects the Cilium installation, not the workloads shown
+		{"cilium status -n kube-system", CommandTypeCilium, true},
+		{"cilium monitor -n team-a", CommandTypeCilium, false},
+		{"cilium observe --namespace=team-a", CommandTypeCilium, false},
+		{"cilium endpoint list -nteam-a", CommandTypeCilium, false},
+		{"cilium observe -n --from-namespace team-a", CommandTypeCilium, false},
+	}
+
+	for _, tc := range tests {
+		err := validator.ValidateCommand(tc.command, tc.commandType)
+		if tc.allowed && err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", tc.command, err)
+		}
+		if !tc.allowed && err == nil {
+			t.Errorf("Expected %q to be rejected", tc.command)
+		}
+	}
+
+	// Without restrictions every selector is accepted
+	unrestricted := NewValidator(NewSecurityConfig())
+	for _, command := range []string{"hubble observe", "hubble observe --namespace a --namespace b", "cilium endpoint list"} {
+		commandType := CommandTypeHubble
+		if strings.HasPrefix(command, "cilium") {
+			commandType = CommandTypeCilium
+		}
+		if err := unrestricted.ValidateCommand(command, commandType); err != nil {
+			t.Errorf("Expected %q to be allowed without restrictions, got %v", command, err)
+		}
+	}
+}
+
 // TestAllNamespacesPlusExplicitNamespaceIsAmbiguous — when a command
 // contains both --all-namespaces and -n X, the intent is ambiguous and
 // should be rejected rather than silently honoring one form.
@@ -657,3 +852,223 @@ func TestAllNamespacesPlusExplicitNamespaceIsAmbiguous(t *testing.T) {
 		}
 	}
 }
+
+func TestIsReadOperation(t *testing.T) {
+	v := NewValidator(NewSecurityConfig())
+
+	tests := []struct {
+		command     string
+		commandType string
+		expected    bool
+	}{
+		{"get pods -n default", CommandTypeKubectl, true},
+		{"kubectl describe deployment web", CommandTypeKubectl, true},
+		{"api-resources", CommandTypeKubectl, true},
+		{"config current-context", CommandTypeKubectl, true},
+		{"config use-context prod", CommandTypeKubectl, false},
+		{"auth can-i get pods", CommandTypeKubectl, true},
+		{"auth reconcile -f rbac.yaml", CommandTypeKubectl, false},
+		{"delete pod web", CommandTypeKubectl, false},
+		{"helm list -n default", CommandTypeHelm, true},
+		{"helm install web ./chart", CommandTypeHelm, false},
+	}
+
+	for _, tt := range tests {
+		if got := v.IsReadOperation(tt.command, tt.commandType); got != tt.expected {
+			t.Errorf("IsReadOperation(%q) = %v, expected %v", tt.command, got, tt.expected)
+		}
+	}
+}
+
+func TestExtractNamespace(t *testing.T) {
+	tests := map[string]string{
+		"get pods -n team-a":              "team-a",
+		"get pods --namespace=team-b":     "team-b",
+		"get pods -A":                     "",
+		"get pods":                        "",
+		"get pods -n a -n b":              "",
+		"exec web -n app -- grep -n x y":  "app",
+		"get pods --all-namespaces -n ns": "",
+	}
+
+	for command, expected := range tests {
+		if got := ExtractNamespace(command); got != expected {
+			t.Errorf("ExtractNamespace(%q) = %q, expected %q", command, got, expected)
+		}
+	}
+}
+
+func TestCommandPolicy(t *testing.T) {
+	policy := &CommandPolicy{
+		ReadOperations:            []string{"version", "app list", "app get"},
+		ReadWriteOperations:       []string{"app sync"},
+		AdminOperations:           []string{"app delete"},
+		BlockedGlobalFlags:        []string{"--server", "--auth-token"},
+		NamespaceFlags:            []string{"-n", "--app-namespace"},
+		AllNamespacesFlags:        []string{"-A"},
+		NamespaceExemptOperations: []string{"version"},
+	}
+
+	tests := []struct {
+		name              string
+		command           string
+		accessLevel       AccessLevel
+		allowedNamespaces string
+		shouldError       bool
+	}{
+		{"read operation", "deployctl app list -n team-a", AccessLevelReadOnly, "", false},
+		{"multi-word operation after namespace flag", "deployctl -n team-a app get web", AccessLevelReadOnly, "", false},
+		{"write operation at readonly", "deployctl app sync web -n team-a", AccessLevelReadOnly, "", true},
+		{"write operation at readwrite", "deployctl app sync web -n team-a", AccessLevelReadWrite, "", false},
+		{"admin operation at readwrite", "deployctl app delete web -n team-a", AccessLevelReadWrite, "", true},
+		{"admin operation at admin", "deployctl app delete web -n team-a", AccessLevelAdmin, "", false},
+		{"unlisted operation at admin", "deployctl login cd.example.com", AccessLevelAdmin, "", true},
+		{"config is not implicitly allowed", "deployctl config set foo", AccessLevelAdmin, "", true},
+		{"blocked flag", "deployctl app list --server=evil.example.com", AccessLevelAdmin, "", true},
+		{"allowed namespace", "deployctl app list --app-namespace team-a", AccessLevelReadOnly, "team-a", false},
+		{"denied namespace", "deployctl app list -n team-b", AccessLevelReadOnly, "team-a", true},
+		{"compact denied namespace", "deployctl app list -n team-a -nteam-b", AccessLevelReadOnly, "team-a", true},
+		{"conflicting namespaces", "deployctl app list -n team-a --app-namespace=team-b", AccessLevelReadOnly, "", true},
+		{"all namespaces", "deployctl app list -A", AccessLevelReadOnly, "team-a", true},
+		{"no namespace", "deployctl app list", AccessLevelReadOnly, "team-a", true},
+		{"exempt operation without namespace", "deployctl version", AccessLevelReadOnly, "team-a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secConfig := NewSecurityConfig()
+			secConfig.AccessLevel = tt.accessLevel
+			if tt.allowedNamespaces != "" {
+				secConfig.SetAllowedNamespaces(tt.allowedNamespaces)
+			}
+			if err := secConfig.SetCommandPolicy("deployctl", policy); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			err := NewValidator(secConfig).ValidateCommand(tt.command, "deployctl")
+			if tt.shouldError && err == nil {
+				t.Errorf("Expected error for command '%s', got nil", tt.command)
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("Expected no error for command '%s', got %v", tt.command, err)
+			}
+		})
+	}
+
+	if err := NewSecurityConfig().SetCommandPolicy(CommandTypeKubectl, policy); err == nil {
+		t.Errorf("Expected built-in command types not to be redefined")
+	}
+	secConfig := NewSecurityConfig()
+	_ = secConfig.SetCommandPolicy("deployctl", policy)
+	if op := NewValidator(secConfig).ExtractOperation("deployctl app sync web", "deployctl"); op != "app sync" {
+		t.Errorf("Expected operation 'app sync', got '%s'", op)
+	}
+}
+
+func TestFluxArgoCDAccessLevels(t *testing.T) {
+	tests := []struct {
+		name              string
+		command           string
+		commandType       string
+		accessLevel       AccessLevel
+		allowedNamespaces string
+		shouldError       bool
+	}{
+		{"flux get at readonly", "flux get kustomizations -A", CommandTypeFlux, AccessLevelReadOnly, "", false},
+		{"flux logs at readonly", "flux logs --kind=Kustomization --name=apps -n flux-system", CommandTypeFlux, AccessLevelReadOnly, "", false},
+		{"flux reconcile at readonly", "flux reconcile kustomization apps -n flux-system", CommandTypeFlux, AccessLevelReadOnly, "", true},
+		{"flux reconcile at readwrite", "flux reconcile kustomization apps -n flux-system", CommandTypeFlux, AccessLevelReadWrite, "", false},
+		{"flux suspend at readwrite", "flux suspend helmrelease redis -n team-a", CommandTypeFlux, AccessLevelReadWrite, "", false},
+		{"flux delete at readwrite", "flux delete kustomization apps -n flux-system", CommandTypeFlux, AccessLevelReadWrite, "", true},
+		{"flux bootstrap at admin", "flux bootstrap github --owner=me", CommandTypeFlux, AccessLevelAdmin, "", true},
+		{"flux export with credentials", "flux export source git --all --with-credentials", CommandTypeFlux, AccessLevelAdmin, "", true},
+		{"flux kubeconfig flag", "flux get all --kubeconfig=/tmp/other", CommandTypeFlux, AccessLevelAdmin, "", true},
+		{"flux allowed namespace", "flux get helmreleases -n team-a", CommandTypeFlux, AccessLevelReadOnly, "team-a", false},
+		{"flux all namespaces restricted", "flux get helmreleases -A", CommandTypeFlux, AccessLevelReadOnly, "team-a", true},
+		{"flux check without namespace", "flux check", CommandTypeFlux, AccessLevelReadOnly, "team-a", false},
+		{"argocd app get at readonly", "argocd app get web", CommandTypeArgoCD, AccessLevelReadOnly, "", false},
+		{"argocd app sync at readonly", "argocd app sync web", CommandTypeArgoCD, AccessLevelReadOnly, "", true},
+		{"argocd app sync at readwrite", "argocd app sync web", CommandTypeArgoCD, AccessLevelReadWrite, "", false},
+		{"argocd app delete at readwrite", "argocd app delete web", CommandTypeArgoCD, AccessLevelReadWrite, "", true},
+		{"argocd app delete at admin", "argocd app delete web", CommandTypeArgoCD, AccessLevelAdmin, "", false},
+		{"argocd account at admin", "argocd account generate-token", CommandTypeArgoCD, AccessLevelAdmin, "", true},
+		{"argocd server flag", "argocd app list --server evil.example.com", CommandTypeArgoCD, AccessLevelReadOnly, "", true},
+		{"argocd allowed app namespace", "argocd app list -N team-a", CommandTypeArgoCD, AccessLevelReadOnly, "team-a", false},
+		{"argocd denied app namespace", "argocd app get web --app-namespace=team-b", CommandTypeArgoCD, AccessLevelReadOnly, "team-a", true},
+		{"argocd without app namespace", "argocd app list", CommandTypeArgoCD, AccessLevelReadOnly, "team-a", true},
+		{"argocd allowed qualified app", "argocd app get team-a/web", CommandTypeArgoCD, AccessLevelReadOnly, "team-a", false},
+		{"argocd denied qualified app", "argocd app sync other-ns/app", CommandTypeArgoCD, AccessLevelReadWrite, "team-a", true},
+		{"argocd qualified app with allowed flag", "argocd app sync other-ns/app -N team-a", CommandTypeArgoCD, AccessLevelReadWrite, "team-a", true},
+		{"argocd qualified app matching flag", "argocd app get team-a/web -N team-a", CommandTypeArgoCD, AccessLevelReadOnly, "team-a", false},
+		{"argocd denied qualified app after flags", "argocd --grpc-web app delete other-ns/app --cascade", CommandTypeArgoCD, AccessLevelAdmin, "team-a", true},
+		{"argocd conflicting qualified app unrestricted", "argocd app sync team-b/web -N team-a", CommandTypeArgoCD, AccessLevelReadWrite, "", true},
+		{"argocd qualified app against flag", "argocd app get team-b/web -N team-a", CommandTypeArgoCD, AccessLevelReadOnly, "team-a", true},
+		{"argocd local path", "argocd app sync web -N team-a --local ./deploy/overlays/prod", CommandTypeArgoCD, AccessLevelReadWrite, "team-a", false},
+		{"argocd local path with equals", "argocd app sync web -N team-a --local=./deploy/overlays/prod", CommandTypeArgoCD, AccessLevelReadWrite, "team-a", false},
+		{"argocd revision with slash", "argocd app diff web -N team-a --revision feature/login", CommandTypeArgoCD, AccessLevelReadOnly, "team-a", false},
+		{"argocd qualified app after double dash", "argocd app manifests web -N team-a -- team-b/web", CommandTypeArgoCD, AccessLevelReadOnly, "team-a", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secConfig := NewSecurityConfig()
+			secConfig.AccessLevel = tt.accessLevel
+			if tt.allowedNamespaces != "" {
+				secConfig.SetAllowedNamespaces(tt.allowedNamespaces)
+			}
+
+			err := NewValidator(secConfig).ValidateCommand(tt.command, tt.commandType)
+			if tt.shouldError && err == nil {
+				t.Errorf("Expected error for command '%s', got nil", tt.command)
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("Expected no error for command '%s', got %v", tt.command, err)
+			}
+		})
+	}
+}
+
+func TestIstioctlAccessLevels(t *testing.T) {
+	tests := []struct {
+		name              string
+		command           string
+		accessLevel       AccessLevel
+		allowedNamespaces string
+		shouldError       bool
+	}{
+		{"analyze", "istioctl analyze -n bookinfo", AccessLevelReadOnly, "", false},
+		{"proxy-status alias", "istioctl ps -n bookinfo", AccessLevelReadOnly, "", false},
+		{"proxy-config", "istioctl proxy-config routes productpage-v1 -n bookinfo -o json", AccessLevelReadOnly, "", false},
+		{"experimental describe", "istioctl x describe pod productpage-v1 -n bookinfo", AccessLevelReadOnly, "", false},
+		{"other experimental command", "istioctl x precheck", AccessLevelAdmin, "", true},
+		{"install at admin", "istioctl install --set profile=demo", AccessLevelAdmin, "", true},
+		{"kube-inject at admin", "istioctl kube-inject -f app.yaml", AccessLevelAdmin, "", true},
+		{"context flag", "istioctl analyze --context=prod", AccessLevelReadOnly, "", true},
+		{"kubeconfig flag", "istioctl analyze --kubeconfig /tmp/other", AccessLevelReadOnly, "", true},
+		{"kubeconfig shorthand", "istioctl analyze -c /tmp/other", AccessLevelReadOnly, "", true},
+		{"compact kubeconfig shorthand", "istioctl analyze -c/tmp/other", AccessLevelReadOnly, "", true},
+		{"allowed namespace", "istioctl analyze -n bookinfo", AccessLevelReadOnly, "bookinfo", false},
+		{"denied namespace", "istioctl proxy-status -n istio-system", AccessLevelReadOnly, "bookinfo", true},
+		{"all namespaces restricted", "istioctl analyze -A", AccessLevelReadOnly, "bookinfo", true},
+		{"no namespace restricted", "istioctl proxy-status", AccessLevelReadOnly, "bookinfo", true},
+		{"version without namespace", "istioctl version", AccessLevelReadOnly, "bookinfo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secConfig := NewSecurityConfig()
+			secConfig.AccessLevel = tt.accessLevel
+			if tt.allowedNamespaces != "" {
+				secConfig.SetAllowedNamespaces(tt.allowedNamespaces)
+			}
+
+			err := NewValidator(secConfig).ValidateCommand(tt.command, CommandTypeIstioctl)
+			if tt.shouldError && err == nil {
+				t.Errorf("Expected error for command '%s', got nil", tt.command)
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("Expected no error for command '%s', got %v", tt.command, err)
+			}
+		})
+	}
+}
@@ -3,12 +3,29 @@ package server
 import (
 	"fmt"
 	"log"
+	"os/exec"
 
+	"github.com/Azure/mcp-kubernetes/pkg/argocd"
+	"github.com/Azure/mcp-kubernetes/pkg/certs"
 	"github.com/Azure/mcp-kubernetes/pkg/cilium"
 	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/diagnose"
+	"github.com/Azure/mcp-kubernetes/pkg/dns"
+	"github.com/Azure/mcp-kubernetes/pkg/flux"
+	"github.com/Azure/mcp-kubernetes/pkg/gitops"
+	"github.com/Azure/mcp-kubernetes/pkg/graph"
+	"github.com/Azure/mcp-kubernetes/pkg/health"
 	"github.com/Azure/mcp-kubernetes/pkg/helm"
 	"github.com/Azure/mcp-kubernetes/pkg/hubble"
+	"github.com/Azure/mcp-kubernetes/pkg/istio"
 	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/kustomize"
+	"github.com/Azure/mcp-kubernetes/pkg/logs"
+	"github.com/Azure/mcp-kubernetes/pkg/netpol"
+	"github.com/Azure/mcp-kubernetes/pkg/plugin"
+	"github.com/Azure/mcp-kubernetes/pkg/rbac"
+	"github.com/Azure/mcp-kubernetes/pkg/rightsizing"
+	"github.com/Azure/mcp-kubernetes/pkg/timeline"
 	"github.com/Azure/mcp-kubernetes/pkg/tools"
 	"github.com/Azure/mcp-kubernetes/pkg/version"
 	"github.com/mark3labs/mcp-go/server"
@@ -42,11 +59,32 @@ func (s *Service) Initialize() error {
 
 	// Register individual kubectl commands based on permission level
 	s.registerKubectlCommands()
+	s.mcpServer.AddTool(kustomize.RegisterKustomizeRender(s.cfg.AccessLevel), tools.CreateToolHandler(kustomize.NewExecutor(), s.cfg))
+
+	// Register diagnostic tools
+	s.mcpServer.AddTool(health.RegisterClusterHealth(), tools.CreateToolHandler(health.NewExecutor(), s.cfg))
+	s.mcpServer.AddTool(diagnose.RegisterDiagnosePod(), tools.CreateToolHandler(diagnose.NewPodExecutor(), s.cfg))
+	s.mcpServer.AddTool(diagnose.RegisterDiagnoseNode(s.cfg.AccessLevel), tools.CreateToolHandler(diagnose.NewNodeExecutor(), s.cfg))
+	s.mcpServer.AddTool(diagnose.RegisterDiagnoseStorage(), tools.CreateToolHandler(diagnose.NewStorageExecutor(), s.cfg))
+	s.mcpServer.AddTool(dns.RegisterTroubleshootDNS(s.cfg.AccessLevel), tools.CreateToolHandler(dns.NewExecutor(), s.cfg))
+	s.mcpServer.AddTool(netpol.RegisterNetpolReachability(), tools.CreateToolHandler(netpol.NewExecutor(), s.cfg))
+	s.mcpServer.AddTool(graph.RegisterResourceGraph(), tools.CreateToolHandler(graph.NewExecutor(), s.cfg))
+	s.mcpServer.AddTool(timeline.RegisterEventsTimeline(), tools.CreateToolHandler(timeline.NewExecutor(), s.cfg))
+	s.mcpServer.AddTool(logs.RegisterSearchLogs(), tools.CreateToolHandler(logs.NewExecutor(), s.cfg))
+	s.mcpServer.AddTool(rightsizing.RegisterRightsizing(), tools.CreateToolHandler(rightsizing.NewExecutor(), s.cfg))
+	s.mcpServer.AddTool(rbac.RegisterWhoCan(), tools.CreateToolHandler(rbac.NewWhoCanExecutor(), s.cfg))
+	s.mcpServer.AddTool(rbac.RegisterSubjectPermissions(), tools.CreateToolHandler(rbac.NewSubjectPermissionsExecutor(), s.cfg))
+	s.mcpServer.AddTool(certs.RegisterCertExpiry(), tools.CreateToolHandler(certs.NewExecutor(), s.cfg))
 
 	// Register additional tools
 	if s.cfg.AdditionalTools["helm"] {
 		helmTool := helm.RegisterHelm()
 		s.mcpServer.AddTool(helmTool, tools.CreateToolHandler(helm.NewExecutor(), s.cfg))
+
+		releaseExecutor := helm.NewReleaseExecutor()
+		for _, tool := range helm.RegisterHelmReleaseTools(s.cfg.AccessLevel) {
+			s.mcpServer.AddTool(tool, tools.CreateToolHandlerWithName(releaseExecutor, s.cfg, tool.Name))
+		}
 	}
 
 	if s.cfg.AdditionalTools["cilium"] {
@@ -57,6 +95,32 @@ func (s *Service) Initialize() error {
 	if s.cfg.AdditionalTools["hubble"] {
 		hubbleTool := hubble.RegisterHubble()
 		s.mcpServer.AddTool(hubbleTool, tools.CreateToolHandler(hubble.NewExecutor(), s.cfg))
+		s.mcpServer.AddTool(hubble.RegisterHubbleFlows(), tools.CreateToolHandler(hubble.NewFlowsExecutor(), s.cfg))
+	}
+
+	if s.cfg.AdditionalTools["flux"] {
+		fluxTool := flux.RegisterFlux()
+		s.mcpServer.AddTool(fluxTool, tools.CreateToolHandler(flux.NewExecutor(), s.cfg))
+	}
+
+	if s.cfg.AdditionalTools["argocd"] {
+		argocdTool := argocd.RegisterArgoCD()
+		s.mcpServer.AddTool(argocdTool, tools.CreateToolHandler(argocd.NewExecutor(), s.cfg))
+	}
+
+	if s.cfg.AdditionalTools["flux"] || s.cfg.AdditionalTools["argocd"] {
+		s.mcpServer.AddTool(gitops.RegisterGitOpsStatus(), tools.CreateToolHandler(gitops.NewExecutor(), s.cfg))
+	}
+
+	if s.cfg.AdditionalTools["istioctl"] {
+		istioctlTool := istio.RegisterIstioctl()
+		s.mcpServer.AddTool(istioctlTool, tools.CreateToolHandler(istio.NewExecutor(), s.cfg))
+		s.mcpServer.AddTool(istio.RegisterMeshAnalyze(), tools.CreateToolHandler(istio.NewAnalyzeExecutor(), s.cfg))
+	}
+
+	// Register plugin CLIs
+	if err := s.registerPlugins(); err != nil {
+		return err
 	}
 
 	return nil
@@ -103,3 +167,23 @@ func (s *Service) registerKubectlCommands() {
 		s.mcpServer.AddTool(tool, handler)
 	}
 }
+
+// registerPlugins registers a tool for each plugin descriptor. Each CLI's
+// rules are added to the security configuration so its commands run through
+// the same validator as the built-in tools.
+func (s *Service) registerPlugins() error {
+	descriptors, err := plugin.Load(s.cfg.PluginPaths)
+	if err != nil {
+		return err
+	}
+	for _, descriptor := range descriptors {
+		if _, err := exec.LookPath(descriptor.Binary); err != nil {
+			return fmt.Errorf("plugin %s is enabled but %s is not installed or not found in PATH", descriptor.Name, descriptor.Binary)
+		}
+		if err := s.cfg.SecurityConfig.SetCommandPolicy(descriptor.Name, descriptor.Policy()); err != nil {
+			return err
+		}
+		s.mcpServer.AddTool(plugin.RegisterTool(descriptor, s.cfg.AccessLevel), tools.CreateToolHandler(plugin.NewExecutor(descriptor), s.cfg))
+	}
+	return nil
+}
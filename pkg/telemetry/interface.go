@@ -20,6 +20,9 @@ type TelemetryInterface interface {
 	// TrackToolInvocation tracks a tool invocation with minimal data
 	TrackToolInvocation(ctx context.Context, toolName string, operation string, success bool)
 
+	// TrackRateLimitRejection tracks a tool invocation rejected by a rate or concurrency limit
+	TrackRateLimitRejection(ctx context.Context, toolName string, scope string)
+
 	// TrackServiceStartup tracks the MCP server startup
 	TrackServiceStartup(ctx context.Context)
 }
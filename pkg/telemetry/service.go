@@ -159,6 +159,32 @@ func (s *Service) TrackToolInvocation(ctx context.Context, toolName string, oper
 	}
 }
 
+// TrackRateLimitRejection tracks a tool invocation rejected by a rate or concurrency limit
+func (s *Service) TrackRateLimitRejection(ctx context.Context, toolName string, scope string) {
+	if !s.isInitialized {
+		return
+	}
+
+	// Send to OTLP as a span if available
+	if s.config.HasOTLP() && s.tracer != nil {
+		_, span := s.tracer.Start(ctx, "RateLimitRejection")
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("tool.name", toolName),
+			attribute.String("ratelimit.scope", scope),
+		)
+	}
+
+	// Send to Application Insights as a trace
+	if s.config.HasApplicationInsights() && s.appInsightsClient != nil {
+		event := appinsights.NewTraceTelemetry("RateLimitRejection", appinsights.Warning)
+		event.Properties["tool.name"] = toolName
+		event.Properties["ratelimit.scope"] = scope
+		s.appInsightsClient.Track(event)
+	}
+}
+
 // TrackServiceStartup tracks the MCP server startup
 func (s *Service) TrackServiceStartup(ctx context.Context) {
 	if !s.isInitialized {
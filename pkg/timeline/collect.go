@@ -0,0 +1,186 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// collector gathers the events of a target and its related objects
+type collector struct {
+	runner    k8s.Runner
+	secConfig *security.SecurityConfig
+	target    string
+	// objects are the related objects, keyed "Kind/name" or
+	// "Kind/namespace/name" for a node's pods
+	objects map[string]bool
+	events  []k8s.Event
+	notes   []string
+}
+
+// object is the metadata of an owner of pods
+type object struct {
+	Metadata k8s.ObjectMeta `json:"metadata"`
+}
+
+// namespace collects every event of a namespace
+func (c *collector) namespace(ctx context.Context, namespace string) error {
+	c.target = "Namespace/" + namespace
+	var events k8s.List[k8s.Event]
+	if err := k8s.GetJSON(ctx, c.runner, "get events -n "+namespace, &events); err != nil {
+		return err
+	}
+	c.events = events.Items
+	return nil
+}
+
+// workload collects the events of a workload, the ReplicaSets, Jobs and
+// pods it owns, the PVCs its pods mount and the nodes they run on
+func (c *collector) workload(ctx context.Context, kind, resource, namespace, name string) error {
+	c.target = fmt.Sprintf("%s/%s in namespace %s", kind, name, namespace)
+	var target object
+	if err := k8s.GetJSON(ctx, c.runner, fmt.Sprintf("get %s %s -n %s", resource, name, namespace), &target); err != nil {
+		return err
+	}
+	c.objects[kind+"/"+name] = true
+
+	// Owners of pods: the workload itself, or the ReplicaSets and Jobs it owns
+	owners := map[string]bool{kind + "/" + name: true}
+	switch kind {
+	case "Deployment", "CronJob":
+		child, childResource := "ReplicaSet", "replicasets.apps"
+		if kind == "CronJob" {
+			child, childResource = "Job", "jobs.batch"
+		}
+		var children k8s.List[object]
+		if err := k8s.GetJSON(ctx, c.runner, fmt.Sprintf("get %s -n %s", childResource, namespace), &children); err != nil {
+			c.notes = append(c.notes, fmt.Sprintf("%s: %v", childResource, err))
+		}
+		for _, item := range children.Items {
+			if owner := k8s.ControllerOf(item.Metadata); owner != nil && owner.Kind == kind && owner.Name == name {
+				owners[child+"/"+item.Metadata.Name] = true
+				c.objects[child+"/"+item.Metadata.Name] = true
+			}
+		}
+	}
+
+	var pods []k8s.Pod
+	if kind == "Pod" {
+		var pod k8s.Pod
+		if err := k8s.GetJSON(ctx, c.runner, fmt.Sprintf("get pod %s -n %s", name, namespace), &pod); err != nil {
+			return err
+		}
+		pods = append(pods, pod)
+	} else {
+		var list k8s.List[k8s.Pod]
+		if err := k8s.GetJSON(ctx, c.runner, "get pods -n "+namespace, &list); err != nil {
+			c.notes = append(c.notes, fmt.Sprintf("pods: %v", err))
+		}
+		for _, pod := range list.Items {
+			if owner := k8s.ControllerOf(pod.Metadata); owner != nil && owners[owner.Kind+"/"+owner.Name] {
+				pods = append(pods, pod)
+			}
+		}
+	}
+
+	nodes := map[string]bool{}
+	for _, pod := range pods {
+		c.objects["Pod/"+pod.Metadata.Name] = true
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				c.objects["PersistentVolumeClaim/"+v.PersistentVolumeClaim.ClaimName] = true
+			}
+		}
+		if pod.Spec.NodeName != "" {
+			nodes[pod.Spec.NodeName] = true
+		}
+	}
+
+	var events k8s.List[k8s.Event]
+	if err := k8s.GetJSON(ctx, c.runner, "get events -n "+namespace, &events); err != nil {
+		return err
+	}
+	for _, event := range events.Items {
+		if c.objects[event.InvolvedObject.Kind+"/"+event.InvolvedObject.Name] {
+			c.events = append(c.events, event)
+		}
+	}
+	for _, node := range sortedKeys(nodes) {
+		c.objects["Node/"+node] = true
+		c.nodeEvents(ctx, node)
+	}
+	return nil
+}
+
+// node collects the events of a node and of the pods scheduled on it in
+// the allowed namespaces
+func (c *collector) node(ctx context.Context, name string) error {
+	c.target = "Node/" + name
+	var node k8s.Node
+	if err := k8s.GetJSON(ctx, c.runner, "get node "+name, &node); err != nil {
+		return err
+	}
+	c.objects["Node/"+name] = true
+	c.nodeEvents(ctx, name)
+
+	namespaces, err := k8s.ScanNamespaces(ctx, c.runner, c.secConfig, "")
+	if err != nil {
+		return err
+	}
+	podsByNamespace := map[string]map[string]bool{}
+	for _, namespace := range namespaces {
+		scope := "-n " + namespace
+		if namespace == k8s.AllNamespaces {
+			scope = "-A"
+		}
+		var pods k8s.List[k8s.Pod]
+		if err := k8s.GetJSON(ctx, c.runner, fmt.Sprintf("get pods %s --field-selector spec.nodeName=%s", scope, name), &pods); err != nil {
+			c.notes = append(c.notes, fmt.Sprintf("pods in %s: %v", namespace, err))
+			continue
+		}
+		for _, pod := range pods.Items {
+			if podsByNamespace[pod.Metadata.Namespace] == nil {
+				podsByNamespace[pod.Metadata.Namespace] = map[string]bool{}
+			}
+			podsByNamespace[pod.Metadata.Namespace][pod.Metadata.Name] = true
+			c.objects["Pod/"+pod.Metadata.Namespace+"/"+pod.Metadata.Name] = true
+		}
+	}
+
+	for _, namespace := range sortedKeys(podsByNamespace) {
+		var events k8s.List[k8s.Event]
+		if err := k8s.GetJSON(ctx, c.runner, "get events -n "+namespace+" --field-selector involvedObject.kind=Pod", &events); err != nil {
+			c.notes = append(c.notes, fmt.Sprintf("events in %s: %v", namespace, err))
+			continue
+		}
+		for _, event := range events.Items {
+			if podsByNamespace[namespace][event.InvolvedObject.Name] {
+				c.events = append(c.events, event)
+			}
+		}
+	}
+	return nil
+}
+
+// nodeEvents collects the events of a node, or notes why they could not be read
+func (c *collector) nodeEvents(ctx context.Context, name string) {
+	events, err := k8s.NodeEvents(ctx, c.runner, c.secConfig, name)
+	if err != nil {
+		c.notes = append(c.notes, fmt.Sprintf("Events of node %s: %v", name, err))
+		return
+	}
+	c.events = append(c.events, events...)
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
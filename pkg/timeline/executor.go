@@ -0,0 +1,67 @@
+package timeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// Executor implements the CommandExecutor interface for events_timeline
+type Executor struct {
+	newRunner func(cfg *config.ConfigData) k8s.Runner
+}
+
+// This line ensures Executor implements the CommandExecutor and StructuredOutputExecutor interfaces
+var _ tools.CommandExecutor = (*Executor)(nil)
+var _ tools.StructuredOutputExecutor = (*Executor)(nil)
+
+// NewExecutor creates a new Executor instance
+func NewExecutor() *Executor {
+	return &Executor{
+		newRunner: func(cfg *config.ConfigData) k8s.Runner { return k8s.NewClient(cfg) },
+	}
+}
+
+// Execute builds the event timeline of a workload, namespace or node and
+// returns it as JSON
+func (e *Executor) Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	opts := Options{}
+	opts.Kind, _ = params["kind"].(string)
+	opts.Namespace, _ = params["namespace"].(string)
+	opts.Name, _ = params["name"].(string)
+	if since, ok := params["since"].(string); ok && since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil || d <= 0 {
+			return "", fmt.Errorf("invalid since '%s': expected a positive duration such as 30m or 2h", since)
+		}
+		opts.Since = d
+	}
+	if maxEvents, ok := params["max_events"].(float64); ok {
+		opts.MaxEvents = int(maxEvents)
+	}
+
+	if err := errors.Join(k8s.ValidateNamespace("namespace", opts.Namespace), k8s.ValidateName("name", opts.Name)); err != nil {
+		return "", err
+	}
+
+	timeline, err := Build(ctx, e.newRunner(cfg), cfg.SecurityConfig, opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(timeline)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal timeline: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReturnsStructuredOutput reports that events_timeline returns JSON
+func (e *Executor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return true
+}
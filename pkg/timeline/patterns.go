@@ -0,0 +1,139 @@
+package timeline
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pattern types
+const (
+	PatternBackOffLoop     = "back-off-loop"
+	PatternImagePull       = "image-pull-failure"
+	PatternSchedulingBurst = "failed-scheduling-burst"
+	PatternEvictionWave    = "eviction-wave"
+)
+
+const (
+	// schedulingBurstMinCount is the number of FailedScheduling events of a
+	// single pod that makes a burst
+	schedulingBurstMinCount = 3
+	// evictionWaveMinPods is the number of evicted pods that makes a wave
+	evictionWaveMinPods = 2
+)
+
+// imagePattern extracts the image of a pull failure message
+var imagePattern = regexp.MustCompile(`image "([^"]+)"`)
+
+// Pattern is a recurring problem found across timeline entries
+type Pattern struct {
+	Type      string   `json:"type"`
+	Severity  string   `json:"severity"`
+	Summary   string   `json:"summary"`
+	Objects   []string `json:"objects"`
+	Count     int      `json:"count"`
+	FirstSeen string   `json:"firstSeen,omitempty"`
+	LastSeen  string   `json:"lastSeen,omitempty"`
+}
+
+// patternGroup accumulates the entries of one pattern
+type patternGroup struct {
+	objects     map[string]bool
+	count       int
+	first, last time.Time
+	latest      Entry
+	details     map[string]bool
+}
+
+// add records an entry in the group
+func (g *patternGroup) add(e Entry) {
+	if g.objects == nil {
+		g.objects, g.details = map[string]bool{}, map[string]bool{}
+	}
+	g.objects[qualifiedObject(e)] = true
+	g.count += e.Count
+	if !e.first.IsZero() && (g.first.IsZero() || e.first.Before(g.first)) {
+		g.first = e.first
+	}
+	if g.latest.Reason == "" || !e.last.Before(g.last) {
+		g.last, g.latest = e.last, e
+	}
+}
+
+// pattern renders the group
+func (g *patternGroup) pattern(patternType, severity, summary string) Pattern {
+	return Pattern{
+		Type:      patternType,
+		Severity:  severity,
+		Summary:   summary,
+		Objects:   sortedKeys(g.objects),
+		Count:     g.count,
+		FirstSeen: formatTime(g.first),
+		LastSeen:  formatTime(g.last),
+	}
+}
+
+// detectPatterns flags container back-off loops, image pull failures,
+// bursts of FailedScheduling events and waves of pod evictions
+func detectPatterns(entries []Entry) []Pattern {
+	var backOff, imagePull, scheduling, eviction patternGroup
+	pressure := map[string]bool{}
+	for _, e := range entries {
+		switch {
+		case e.Reason == "CrashLoopBackOff" || (e.Reason == "BackOff" && strings.Contains(e.Message, "restarting failed container")):
+			backOff.add(e)
+		case e.Reason == "ErrImagePull" || e.Reason == "ImagePullBackOff" ||
+			(e.Reason == "Failed" && strings.Contains(e.Message, "Failed to pull image")) ||
+			(e.Reason == "BackOff" && strings.Contains(e.Message, "Back-off pulling image")):
+			imagePull.add(e)
+			if m := imagePattern.FindStringSubmatch(e.Message); m != nil {
+				imagePull.details[m[1]] = true
+			}
+		case e.Reason == "FailedScheduling":
+			scheduling.add(e)
+		case e.Reason == "Evicted" && strings.HasPrefix(e.Object, "Pod/"):
+			eviction.add(e)
+		case e.Reason == "EvictionThresholdMet" || e.Reason == "NodeHasDiskPressure" || e.Reason == "NodeHasMemoryPressure":
+			pressure[e.Reason+" on "+e.Object] = true
+		}
+	}
+
+	patterns := []Pattern{}
+	if backOff.count > 0 {
+		patterns = append(patterns, backOff.pattern(PatternBackOffLoop, SeverityCritical,
+			fmt.Sprintf("Containers are restarting in a back-off loop (%d back-off events on %d object(s))", backOff.count, len(backOff.objects))))
+	}
+	if imagePull.count > 0 {
+		summary := fmt.Sprintf("Image pulls are failing (%d events on %d object(s))", imagePull.count, len(imagePull.objects))
+		if images := sortedKeys(imagePull.details); len(images) > 0 {
+			summary += ": " + strings.Join(images, ", ")
+		}
+		patterns = append(patterns, imagePull.pattern(PatternImagePull, SeverityCritical, summary))
+	}
+	if scheduling.count >= schedulingBurstMinCount || len(scheduling.objects) >= 2 {
+		patterns = append(patterns, scheduling.pattern(PatternSchedulingBurst, SeverityCritical,
+			fmt.Sprintf("Pods cannot be scheduled (%d FailedScheduling events on %d pod(s)); latest: %s", scheduling.count, len(scheduling.objects), scheduling.latest.Message)))
+	}
+	if len(eviction.objects) >= evictionWaveMinPods {
+		summary := fmt.Sprintf("%d pods were evicted", len(eviction.objects))
+		if len(pressure) > 0 {
+			summary += " after node pressure: " + strings.Join(sortedKeys(pressure), ", ")
+		}
+		patterns = append(patterns, eviction.pattern(PatternEvictionWave, SeverityCritical, summary))
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].LastSeen > patterns[j].LastSeen
+	})
+	return patterns
+}
+
+// qualifiedObject names an entry's object with its namespace, so that
+// pods of different namespaces are told apart
+func qualifiedObject(e Entry) string {
+	if e.Namespace == "" {
+		return e.Object
+	}
+	return e.Namespace + "/" + e.Object
+}
@@ -0,0 +1,46 @@
+package timeline
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RegisterEventsTimeline registers the events_timeline tool
+func RegisterEventsTimeline() mcp.Tool {
+	return mcp.NewTool("events_timeline",
+		mcp.WithDescription(`Return a single, deduplicated timeline of the events of a workload, namespace or node, instead of correlating kubectl get events output by hand.
+
+- Workload (Deployment, StatefulSet, DaemonSet, ReplicaSet, Job, CronJob, Pod): events of the workload, its ReplicaSets or Jobs, its pods, their PVCs and the nodes they run on
+- Namespace: every event of the namespace
+- Node: events of the node and of the pods scheduled on it
+
+Repeated events are merged using their count or series, ordered by first occurrence and classified as critical, warning or info.
+Patterns are flagged: back-off loops, image pull failures, FailedScheduling bursts and eviction waves.
+Read-only. Subject to the same access level and namespace restrictions as call_kubectl.`),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Kind of the target: a workload kind (e.g. Deployment, sts, pod), Namespace or Node"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the target"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the workload (required for workloads)"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only include events last seen within this duration, e.g. 30m or 2h (default: all retained events)"),
+		),
+		mcp.WithNumber("max_events",
+			mcp.Description("Maximum number of timeline entries; the most recent are kept (default: 200, max: 1000)"),
+		),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Events Timeline",
+			ReadOnlyHint: boolPtr(true),
+		}),
+	)
+}
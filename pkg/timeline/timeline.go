@@ -0,0 +1,317 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+const (
+	// defaultMaxEvents is the number of timeline entries returned when max_events is not set
+	defaultMaxEvents = 200
+	// maxMaxEvents caps max_events
+	maxMaxEvents = 1000
+)
+
+// Event severities
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// criticalReasons are Warning event reasons that mean a workload is not
+// running as intended
+var criticalReasons = map[string]bool{
+	"BackOff": true, "CrashLoopBackOff": true, "Failed": true, "ErrImagePull": true,
+	"ImagePullBackOff": true, "Evicted": true, "OOMKilling": true, "SystemOOM": true,
+	"FailedMount": true, "FailedAttachVolume": true, "FailedCreatePodSandBox": true,
+	"NodeNotReady": true, "EvictionThresholdMet": true, "FailedScheduling": true,
+}
+
+// workloadKinds map the accepted kind names to the kind and resource of
+// each supported workload
+var workloadKinds = map[string]struct{ Kind, Resource string }{
+	"deployment":  {"Deployment", "deployments.apps"},
+	"deploy":      {"Deployment", "deployments.apps"},
+	"statefulset": {"StatefulSet", "statefulsets.apps"},
+	"sts":         {"StatefulSet", "statefulsets.apps"},
+	"daemonset":   {"DaemonSet", "daemonsets.apps"},
+	"ds":          {"DaemonSet", "daemonsets.apps"},
+	"replicaset":  {"ReplicaSet", "replicasets.apps"},
+	"rs":          {"ReplicaSet", "replicasets.apps"},
+	"job":         {"Job", "jobs.batch"},
+	"cronjob":     {"CronJob", "cronjobs.batch"},
+	"cj":          {"CronJob", "cronjobs.batch"},
+	"pod":         {"Pod", "pods"},
+	"po":          {"Pod", "pods"},
+}
+
+// Options are the parameters of events_timeline
+type Options struct {
+	// Kind is a workload kind, Namespace or Node
+	Kind      string
+	Namespace string
+	Name      string
+	// Since drops events last seen before this window (0 keeps all)
+	Since     time.Duration
+	MaxEvents int
+	// Now is the reference time for Since (default: the current time)
+	Now time.Time
+}
+
+// Timeline is the result of events_timeline
+type Timeline struct {
+	Target string `json:"target"`
+	// Objects are the related objects whose events are included; empty
+	// for a namespace, where every event is included
+	Objects  []string  `json:"objects,omitempty"`
+	Critical int       `json:"critical"`
+	Warning  int       `json:"warning"`
+	Info     int       `json:"info"`
+	Patterns []Pattern `json:"patterns"`
+	Entries  []Entry   `json:"entries"`
+	// Truncated is set when older entries were dropped to fit max_events
+	Truncated bool     `json:"truncated,omitempty"`
+	Notes     []string `json:"notes,omitempty"`
+}
+
+// Entry is an event, with repeated occurrences merged
+type Entry struct {
+	FirstSeen string `json:"firstSeen,omitempty"`
+	LastSeen  string `json:"lastSeen,omitempty"`
+	Severity  string `json:"severity"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Object    string `json:"object"`
+	Namespace string `json:"namespace,omitempty"`
+	Message   string `json:"message"`
+	Count     int    `json:"count"`
+	Source    string `json:"source,omitempty"`
+
+	first, last time.Time
+}
+
+// Build gathers the events of an object and its related objects and merges
+// them into one timeline
+func Build(ctx context.Context, runner k8s.Runner, secConfig *security.SecurityConfig, opts Options) (*Timeline, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	switch {
+	case opts.MaxEvents == 0:
+		opts.MaxEvents = defaultMaxEvents
+	case opts.MaxEvents < 0 || opts.MaxEvents > maxMaxEvents:
+		return nil, fmt.Errorf("max_events must be between 1 and %d", maxMaxEvents)
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	c := &collector{runner: runner, secConfig: secConfig, objects: map[string]bool{}}
+	var err error
+	switch kind := strings.ToLower(opts.Kind); kind {
+	case "namespace", "ns":
+		err = c.namespace(ctx, opts.Name)
+	case "node", "no":
+		err = c.node(ctx, opts.Name)
+	default:
+		workload, ok := workloadKinds[kind]
+		if !ok {
+			return nil, fmt.Errorf("unsupported kind '%s': expected a workload kind (Deployment, StatefulSet, DaemonSet, ReplicaSet, Job, CronJob, Pod), Namespace or Node", opts.Kind)
+		}
+		if opts.Namespace == "" {
+			return nil, fmt.Errorf("namespace is required for a %s", workload.Kind)
+		}
+		err = c.workload(ctx, workload.Kind, workload.Resource, opts.Namespace, opts.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := merge(c.events)
+	if opts.Since > 0 {
+		cutoff := opts.Now.Add(-opts.Since)
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.last.IsZero() || !e.last.Before(cutoff) {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+
+	t := &Timeline{Target: c.target, Patterns: detectPatterns(entries), Entries: []Entry{}, Notes: c.notes}
+	for object := range c.objects {
+		t.Objects = append(t.Objects, object)
+	}
+	sort.Strings(t.Objects)
+	for _, e := range entries {
+		switch e.Severity {
+		case SeverityCritical:
+			t.Critical++
+		case SeverityWarning:
+			t.Warning++
+		default:
+			t.Info++
+		}
+	}
+	t.Entries, t.Truncated = keepRecent(entries, opts.MaxEvents)
+	return t, nil
+}
+
+// merge deduplicates events that repeat the same reason and message for
+// the same object, summing their counts, and sorts them by first occurrence
+func merge(events []k8s.Event) []Entry {
+	byKey := map[string]*Entry{}
+	var keys []string
+	for _, event := range events {
+		object := event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name
+		namespace := firstNonEmpty(event.InvolvedObject.Namespace, event.Metadata.Namespace)
+		if event.InvolvedObject.Kind == "Node" {
+			namespace = ""
+		}
+		key := strings.Join([]string{namespace, object, event.Type, event.Reason, event.Message}, "|")
+		first, last := eventTimes(event)
+		count := event.Count
+		if event.Series != nil && event.Series.Count > count {
+			count = event.Series.Count
+		}
+		if count < 1 {
+			count = 1
+		}
+
+		entry, ok := byKey[key]
+		if !ok {
+			entry = &Entry{
+				Severity:  severity(event),
+				Type:      event.Type,
+				Reason:    event.Reason,
+				Object:    object,
+				Namespace: namespace,
+				Message:   strings.TrimSpace(event.Message),
+				Source:    firstNonEmpty(event.Source.Component, event.ReportingComponent),
+				first:     first,
+				last:      last,
+			}
+			byKey[key] = entry
+			keys = append(keys, key)
+		}
+		entry.Count += count
+		if entry.Source == "" {
+			entry.Source = firstNonEmpty(event.Source.Component, event.ReportingComponent)
+		}
+		if !first.IsZero() && (entry.first.IsZero() || first.Before(entry.first)) {
+			entry.first = first
+		}
+		if last.After(entry.last) {
+			entry.last = last
+		}
+	}
+
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		e := byKey[key]
+		e.FirstSeen, e.LastSeen = formatTime(e.first), formatTime(e.last)
+		entries = append(entries, *e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].first.Equal(entries[j].first) {
+			return entries[i].first.Before(entries[j].first)
+		}
+		return entries[i].last.Before(entries[j].last)
+	})
+	return entries
+}
+
+// keepRecent drops the entries last seen longest ago until max remain,
+// keeping the timeline order
+func keepRecent(entries []Entry, max int) ([]Entry, bool) {
+	if len(entries) <= max {
+		return entries, false
+	}
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return entries[order[i]].last.After(entries[order[j]].last)
+	})
+	keep := map[int]bool{}
+	for _, i := range order[:max] {
+		keep[i] = true
+	}
+	kept := make([]Entry, 0, max)
+	for i, e := range entries {
+		if keep[i] {
+			kept = append(kept, e)
+		}
+	}
+	return kept, true
+}
+
+// severity classifies an event
+func severity(event k8s.Event) string {
+	if event.Type != "Warning" {
+		return SeverityInfo
+	}
+	if criticalReasons[event.Reason] {
+		return SeverityCritical
+	}
+	return SeverityWarning
+}
+
+// eventTimes returns when an event was first and last seen. Core events
+// carry first/last timestamps; events.k8s.io events carry an event time
+// and a series.
+func eventTimes(event k8s.Event) (first, last time.Time) {
+	first = parseTime(firstNonEmpty(event.FirstTimestamp, event.EventTime))
+	last = parseTime(event.LastTimestamp)
+	if event.Series != nil {
+		if observed := parseTime(event.Series.LastObservedTime); observed.After(last) {
+			last = observed
+		}
+	}
+	if eventTime := parseTime(event.EventTime); eventTime.After(last) {
+		last = eventTime
+	}
+	if last.IsZero() {
+		last = first
+	}
+	if first.IsZero() {
+		first = last
+	}
+	return first, last
+}
+
+// parseTime parses an RFC 3339 timestamp; invalid values yield the zero time
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// formatTime renders a time as RFC 3339, or "" for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package timeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/k8s/k8stest"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func shopRunner() *k8stest.Runner {
+	return &k8stest.Runner{Outputs: map[string]string{
+		"get deployments.apps web -n shop -o json": `{"metadata": {"name": "web", "namespace": "shop"}}`,
+		"get replicasets.apps -n shop -o json": `{"items": [
+			{"metadata": {"name": "web-7d9f", "ownerReferences": [{"kind": "Deployment", "name": "web", "controller": true}]}},
+			{"metadata": {"name": "api-5c4b", "ownerReferences": [{"kind": "Deployment", "name": "api", "controller": true}]}}]}`,
+		"get pods -n shop -o json": `{"items": [
+			{"metadata": {"name": "web-7d9f-a", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "web-7d9f", "controller": true}]},
+			 "spec": {"nodeName": "node-1", "containers": [{"name": "web"}], "volumes": [{"name": "data", "persistentVolumeClaim": {"claimName": "web-data"}}]}},
+			{"metadata": {"name": "web-7d9f-b", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "web-7d9f", "controller": true}]},
+			 "spec": {"containers": [{"name": "web"}]}},
+			{"metadata": {"name": "api-5c4b-a", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "api-5c4b", "controller": true}]},
+			 "spec": {"nodeName": "node-1", "containers": [{"name": "api"}]}}]}`,
+		"get events -n shop -o json": `{"items": [
+			{"metadata": {"namespace": "shop"}, "involvedObject": {"kind": "Deployment", "name": "web", "namespace": "shop"}, "type": "Normal", "reason": "ScalingReplicaSet",
+			 "message": "Scaled up replica set web-7d9f to 2", "count": 1, "firstTimestamp": "2026-03-01T10:00:00Z", "lastTimestamp": "2026-03-01T10:00:00Z", "source": {"component": "deployment-controller"}},
+			{"metadata": {"namespace": "shop"}, "involvedObject": {"kind": "Pod", "name": "web-7d9f-a", "namespace": "shop"}, "type": "Warning", "reason": "BackOff",
+			 "message": "Back-off restarting failed container web in pod web-7d9f-a", "count": 12, "firstTimestamp": "2026-03-01T10:05:00Z", "lastTimestamp": "2026-03-01T11:50:00Z"},
+			{"metadata": {"namespace": "shop"}, "involvedObject": {"kind": "Pod", "name": "web-7d9f-a", "namespace": "shop"}, "type": "Warning", "reason": "BackOff",
+			 "message": "Back-off restarting failed container web in pod web-7d9f-a", "series": {"count": 5, "lastObservedTime": "2026-03-01T11:58:00Z"}, "eventTime": "2026-03-01T11:52:00Z", "reportingComponent": "kubelet"},
+			{"metadata": {"namespace": "shop"}, "involvedObject": {"kind": "Pod", "name": "web-7d9f-b", "namespace": "shop"}, "type": "Warning", "reason": "FailedScheduling",
+			 "message": "0/3 nodes are available: 3 Insufficient cpu.", "count": 4, "firstTimestamp": "2026-03-01T10:01:00Z", "lastTimestamp": "2026-03-01T11:00:00Z"},
+			{"metadata": {"namespace": "shop"}, "involvedObject": {"kind": "PersistentVolumeClaim", "name": "web-data", "namespace": "shop"}, "type": "Normal", "reason": "ProvisioningSucceeded",
+			 "message": "Successfully provisioned volume pvc-1", "firstTimestamp": "2026-03-01T09:59:00Z", "lastTimestamp": "2026-03-01T09:59:00Z"},
+			{"metadata": {"namespace": "shop"}, "involvedObject": {"kind": "Pod", "name": "api-5c4b-a", "namespace": "shop"}, "type": "Warning", "reason": "Unhealthy",
+			 "message": "Readiness probe failed", "count": 3, "firstTimestamp": "2026-03-01T10:30:00Z", "lastTimestamp": "2026-03-01T10:40:00Z"}]}`,
+		"get events -A --field-selector involvedObject.kind=Node,involvedObject.name=node-1 -o json": `{"items": [
+			{"metadata": {"namespace": "default"}, "involvedObject": {"kind": "Node", "name": "node-1"}, "type": "Warning", "reason": "NodeHasMemoryPressure",
+			 "message": "Node node-1 status is now: NodeHasMemoryPressure", "firstTimestamp": "2026-03-01T10:02:00Z", "lastTimestamp": "2026-03-01T10:02:00Z"}]}`,
+	}}
+}
+
+func TestBuildWorkload(t *testing.T) {
+	runner := shopRunner()
+	tl, err := Build(context.Background(), runner, security.NewSecurityConfig(), Options{Kind: "deploy", Namespace: "shop", Name: "web", Now: now})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedObjects := "Deployment/web,Node/node-1,PersistentVolumeClaim/web-data,Pod/web-7d9f-a,Pod/web-7d9f-b,ReplicaSet/web-7d9f"
+	if got := strings.Join(tl.Objects, ","); got != expectedObjects {
+		t.Errorf("Expected objects %s, got %s", expectedObjects, got)
+	}
+
+	var reasons []string
+	for _, e := range tl.Entries {
+		reasons = append(reasons, e.Reason)
+	}
+	expectedReasons := "ProvisioningSucceeded,ScalingReplicaSet,FailedScheduling,NodeHasMemoryPressure,BackOff"
+	if got := strings.Join(reasons, ","); got != expectedReasons {
+		t.Errorf("Expected entries %s, got %s", expectedReasons, got)
+	}
+
+	backOff := tl.Entries[4]
+	if backOff.Count != 17 || backOff.FirstSeen != "2026-03-01T10:05:00Z" || backOff.LastSeen != "2026-03-01T11:58:00Z" {
+		t.Errorf("Expected the back-off events merged into 17 occurrences from 10:05 to 11:58, got %+v", backOff)
+	}
+	if backOff.Severity != SeverityCritical || backOff.Source != "kubelet" {
+		t.Errorf("Expected a critical back-off entry reported by the kubelet, got %+v", backOff)
+	}
+	if tl.Entries[3].Namespace != "" || tl.Entries[3].Severity != SeverityWarning {
+		t.Errorf("Expected a cluster-scoped warning node entry, got %+v", tl.Entries[3])
+	}
+	if tl.Critical != 2 || tl.Warning != 1 || tl.Info != 2 {
+		t.Errorf("Expected 2 critical, 1 warning and 2 info entries, got %d, %d and %d", tl.Critical, tl.Warning, tl.Info)
+	}
+
+	patterns := map[string]Pattern{}
+	for _, p := range tl.Patterns {
+		patterns[p.Type] = p
+	}
+	if p, ok := patterns[PatternBackOffLoop]; !ok || p.Count != 17 {
+		t.Errorf("Expected a back-off loop of 17 events, got %+v", tl.Patterns)
+	}
+	if p, ok := patterns[PatternSchedulingBurst]; !ok || !strings.Contains(p.Summary, "Insufficient cpu") {
+		t.Errorf("Expected a FailedScheduling burst with the latest message, got %+v", tl.Patterns)
+	}
+}
+
+func TestBuildSinceAndMaxEvents(t *testing.T) {
+	tl, err := Build(context.Background(), shopRunner(), nil, Options{Kind: "Deployment", Namespace: "shop", Name: "web", Since: 30 * time.Minute, Now: now})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tl.Entries) != 1 || tl.Entries[0].Reason != "BackOff" {
+		t.Errorf("Expected only the back-off entry within the last 30 minutes, got %+v", tl.Entries)
+	}
+
+	tl, err = Build(context.Background(), shopRunner(), nil, Options{Kind: "Deployment", Namespace: "shop", Name: "web", MaxEvents: 2, Now: now})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !tl.Truncated || len(tl.Entries) != 2 || tl.Entries[0].Reason != "FailedScheduling" || tl.Entries[1].Reason != "BackOff" {
+		t.Errorf("Expected the 2 most recent entries in timeline order, got %+v", tl.Entries)
+	}
+}
+
+func TestBuildNodeWithNamespaceRestrictions(t *testing.T) {
+	runner := &k8stest.Runner{Outputs: map[string]string{
+		"get node node-1 -o json": `{"metadata": {"name": "node-1"}}`,
+		"get namespaces -o json":  `{"items": [{"metadata": {"name": "shop"}}, {"metadata": {"name": "default"}}]}`,
+		"get pods -n shop --field-selector spec.nodeName=node-1 -o json": `{"items": [
+			{"metadata": {"name": "web-7d9f-a", "namespace": "shop"}}]}`,
+		"get events -n shop --field-selector involvedObject.kind=Pod -o json": `{"items": [
+			{"involvedObject": {"kind": "Pod", "name": "web-7d9f-a", "namespace": "shop"}, "type": "Warning", "reason": "Evicted",
+			 "message": "The node was low on resource: memory.", "firstTimestamp": "2026-03-01T11:00:00Z"},
+			{"involvedObject": {"kind": "Pod", "name": "other", "namespace": "shop"}, "type": "Normal", "reason": "Pulled",
+			 "message": "Container image pulled", "firstTimestamp": "2026-03-01T11:00:00Z"}]}`,
+	}}
+	secConfig := security.NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("shop")
+
+	tl, err := Build(context.Background(), runner, secConfig, Options{Kind: "node", Name: "node-1", Now: now})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tl.Entries) != 1 || tl.Entries[0].Object != "Pod/web-7d9f-a" {
+		t.Errorf("Expected only the event of the pod on the node, got %+v", tl.Entries)
+	}
+	if len(tl.Notes) != 1 || !strings.Contains(tl.Notes[0], "default") {
+		t.Errorf("Expected a note that node events are not allowed, got %v", tl.Notes)
+	}
+	for _, command := range runner.Commands() {
+		if strings.Contains(command, " -A") || strings.Contains(command, "-n default") {
+			t.Errorf("Expected no command outside the allowed namespaces, got %s", command)
+		}
+	}
+}
+
+func TestDetectPatterns(t *testing.T) {
+	entries := []Entry{
+		{Reason: "Failed", Object: "Pod/a", Namespace: "shop", Count: 2, Message: `Failed to pull image "registry.example.com/web:v2": not found`},
+		{Reason: "BackOff", Object: "Pod/a", Namespace: "shop", Count: 5, Message: `Back-off pulling image "registry.example.com/web:v2"`},
+		{Reason: "FailedScheduling", Object: "Pod/b", Namespace: "shop", Count: 1, Message: "0/3 nodes are available"},
+		{Reason: "Evicted", Object: "Pod/c", Namespace: "shop", Count: 1},
+		{Reason: "Evicted", Object: "Pod/d", Namespace: "shop", Count: 1},
+		{Reason: "EvictionThresholdMet", Object: "Node/node-1", Count: 1},
+	}
+	patterns := detectPatterns(entries)
+	types := map[string]Pattern{}
+	for _, p := range patterns {
+		types[p.Type] = p
+	}
+	if p, ok := types[PatternImagePull]; !ok || p.Count != 7 || !strings.Contains(p.Summary, "registry.example.com/web:v2") {
+		t.Errorf("Expected an image pull failure naming the image, got %+v", patterns)
+	}
+	if _, ok := types[PatternBackOffLoop]; ok {
+		t.Errorf("Expected image pull back-off not to be a back-off loop, got %+v", patterns)
+	}
+	if _, ok := types[PatternSchedulingBurst]; ok {
+		t.Errorf("Expected a single FailedScheduling event not to be a burst, got %+v", patterns)
+	}
+	if p, ok := types[PatternEvictionWave]; !ok || len(p.Objects) != 2 || !strings.Contains(p.Summary, "EvictionThresholdMet on Node/node-1") {
+		t.Errorf("Expected an eviction wave of 2 pods after node pressure, got %+v", patterns)
+	}
+
+	if patterns := detectPatterns(nil); patterns == nil || len(patterns) != 0 {
+		t.Errorf("Expected an empty pattern list, got %v", patterns)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"missing name", Options{Kind: "pod", Namespace: "shop"}, "name is required"},
+		{"unsupported kind", Options{Kind: "service", Namespace: "shop", Name: "web"}, "unsupported kind"},
+		{"missing namespace", Options{Kind: "pod", Name: "web"}, "namespace is required"},
+		{"max events", Options{Kind: "ns", Name: "shop", MaxEvents: 5000}, "max_events"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Build(context.Background(), &k8stest.Runner{}, nil, tc.opts)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected error containing '%s', got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/cache"
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// executeWithCache runs the executor behind the response cache. Read-only
+// commands are served from the cache when a fresh entry exists, and mutating
+// commands invalidate the entries they could have affected. The returned
+// meta carries cache-hit information for cacheable calls and is nil otherwise.
+func executeWithCache(ctx context.Context, executor CommandExecutor, cfg *config.ConfigData, args map[string]interface{}) (string, *mcp.Meta, error) {
+	responseCache := cfg.ResponseCache
+	describer, ok := executor.(CommandDescriber)
+	if responseCache == nil || !ok {
+		result, err := executor.Execute(ctx, args, cfg)
+		return result, nil, err
+	}
+
+	commandType, command, ok := describer.DescribeCommand(args)
+	if !ok {
+		result, err := executor.Execute(ctx, args, cfg)
+		return result, nil, err
+	}
+
+	validator := security.NewValidator(cfg.SecurityConfig)
+	operation := validator.ExtractOperation(command, commandType)
+	namespace := security.ExtractNamespace(command)
+	cluster := responseCache.Cluster()
+
+	if !validator.IsReadOperation(command, commandType) {
+		result, err := executor.Execute(ctx, args, cfg)
+		InvalidateAfterCommand(cfg, commandType, command, err)
+		return result, nil, err
+	}
+
+	ttl := responseCache.TTLFor(operation)
+	key, cacheable := cache.NewKey(cluster, commandType, command)
+	if ttl <= 0 || !cacheable {
+		result, err := executor.Execute(ctx, args, cfg)
+		return result, nil, err
+	}
+	if formatter, ok := executor.(OutputFormatDescriber); ok {
+		key.Format = formatter.DescribeOutputFormat(args)
+	}
+
+	if entry, hit := responseCache.Get(key); hit {
+		return entry.Output, cacheMeta(true, entry.Age(time.Now()).Seconds(), ttl.Seconds()), nil
+	}
+
+	result, err := executor.Execute(ctx, args, cfg)
+	if err == nil {
+		responseCache.Set(key, namespace, result, ttl)
+	}
+	return result, cacheMeta(false, 0, ttl.Seconds()), err
+}
+
+// InvalidateAfterCommand drops the response cache entries that a mutating
+// command could have affected; read-only commands leave the cache untouched.
+// Tools that run commands outside the handler's cache lookup, such as the
+// composite tools' kubectl client, call it after every command they run.
+func InvalidateAfterCommand(cfg *config.ConfigData, commandType, command string, err error) {
+	responseCache := cfg.ResponseCache
+	if responseCache == nil {
+		return
+	}
+	// A validation error means nothing ran; any other outcome may have
+	// changed cluster state, even if the command reported a failure.
+	var validationErr *security.ValidationError
+	if errors.As(err, &validationErr) {
+		return
+	}
+
+	validator := security.NewValidator(cfg.SecurityConfig)
+	if validator.IsReadOperation(command, commandType) {
+		return
+	}
+	responseCache.Invalidate(responseCache.Cluster(), security.ExtractNamespace(command))
+	if validator.ExtractOperation(command, commandType) == "config" {
+		responseCache.ResetCluster()
+	}
+}
+
+// cacheMeta builds the _meta payload describing a cache lookup
+func cacheMeta(hit bool, ageSeconds, ttlSeconds float64) *mcp.Meta {
+	return mcp.NewMetaFromMap(map[string]any{
+		"cache": map[string]any{
+			"hit":        hit,
+			"ageSeconds": ageSeconds,
+			"ttlSeconds": ttlSeconds,
+		},
+	})
+}
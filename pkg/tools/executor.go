@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 )
 
@@ -10,3 +11,31 @@ import (
 type CommandExecutor interface {
 	Execute(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error)
 }
+
+// CommandDescriber is implemented by executors whose tool calls translate to a
+// single CLI command. It lets the handler classify and key calls (e.g. for
+// response caching) without executing them.
+type CommandDescriber interface {
+	// DescribeCommand returns the command type and the command line the
+	// executor would validate for the given params. ok is false when the
+	// params do not describe a single command.
+	DescribeCommand(params map[string]interface{}) (commandType string, command string, ok bool)
+}
+
+// OutputFormatDescriber is implemented by CommandDescribers that render a
+// command's output in a format chosen by a parameter, e.g. call_kubectl's
+// output_format. Calls that run the same command but differ in format must
+// not share a response cache entry, since the cache stores rendered output.
+type OutputFormatDescriber interface {
+	// DescribeOutputFormat returns the format the call's output is rendered in
+	DescribeOutputFormat(params map[string]interface{}) string
+}
+
+// StructuredOutputExecutor is implemented by executors that can return a JSON
+// object as their text result. The handler then also returns the object as
+// structuredContent so clients can consume it without re-parsing.
+type StructuredOutputExecutor interface {
+	// ReturnsStructuredOutput reports whether the call's text result is a
+	// JSON object
+	ReturnsStructuredOutput(params map[string]interface{}) bool
+}
@@ -2,10 +2,15 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 
 	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/ratelimit"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 // CreateToolHandler creates an adapter that converts CommandExecutor to the format expected by MCP server
@@ -21,7 +26,13 @@ func CreateToolHandler(executor CommandExecutor, cfg *config.ConfigData) func(ct
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := executor.Execute(ctx, args, cfg)
+		release, rejection := acquireRateLimit(ctx, cfg, req.Params.Name)
+		if rejection != nil {
+			return rejection, nil
+		}
+		defer release()
+
+		result, meta, err := executeWithCache(ctx, executor, cfg, args)
 		if cfg.TelemetryService != nil {
 			operation, _ := args["operation"].(string)
 			cfg.TelemetryService.TrackToolInvocation(ctx, req.Params.Name, operation, err == nil)
@@ -31,7 +42,9 @@ func CreateToolHandler(executor CommandExecutor, cfg *config.ConfigData) func(ct
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(result), nil
+		toolResult := newToolResult(executor, args, result)
+		toolResult.Meta = meta
+		return toolResult, nil
 	}
 }
 
@@ -48,10 +61,16 @@ func CreateToolHandlerWithName(executor CommandExecutor, cfg *config.ConfigData,
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
+		release, rejection := acquireRateLimit(ctx, cfg, toolName)
+		if rejection != nil {
+			return rejection, nil
+		}
+		defer release()
+
 		// Inject the tool name into the arguments
 		args["_tool_name"] = toolName
 
-		result, err := executor.Execute(ctx, args, cfg)
+		result, meta, err := executeWithCache(ctx, executor, cfg, args)
 		if cfg.TelemetryService != nil {
 			operation, _ := args["operation"].(string)
 			cfg.TelemetryService.TrackToolInvocation(ctx, toolName, operation, err == nil)
@@ -61,6 +80,65 @@ func CreateToolHandlerWithName(executor CommandExecutor, cfg *config.ConfigData,
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(result), nil
+		toolResult := newToolResult(executor, args, result)
+		toolResult.Meta = meta
+		return toolResult, nil
+	}
+}
+
+// newToolResult builds the result for a successful call. When the executor
+// reports a JSON object result, the object is also set as structuredContent;
+// results that do not parse as an object (e.g. kubectl error text) are
+// returned as plain text.
+func newToolResult(executor CommandExecutor, args map[string]interface{}, result string) *mcp.CallToolResult {
+	structured, ok := executor.(StructuredOutputExecutor)
+	if !ok || !structured.ReturnsStructuredOutput(args) {
+		return mcp.NewToolResultText(result)
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(result), &obj); err != nil {
+		return mcp.NewToolResultText(result)
+	}
+	return mcp.NewToolResultStructured(obj, result)
+}
+
+// acquireRateLimit admits the call through the configured rate limiter.
+// It returns a release function on success, or a tool error result carrying
+// a retry-after hint when the call is rejected.
+func acquireRateLimit(ctx context.Context, cfg *config.ConfigData, toolName string) (func(), *mcp.CallToolResult) {
+	if cfg.RateLimiter == nil {
+		return func() {}, nil
+	}
+
+	release, err := cfg.RateLimiter.Acquire(clientIDFromContext(ctx), toolName)
+	if err == nil {
+		return release, nil
+	}
+
+	var rejection *ratelimit.RejectionError
+	if !errors.As(err, &rejection) {
+		return nil, mcp.NewToolResultError(err.Error())
+	}
+
+	if cfg.TelemetryService != nil {
+		cfg.TelemetryService.TrackRateLimitRejection(ctx, toolName, rejection.Scope)
+	}
+
+	result := mcp.NewToolResultError(rejection.Error())
+	result.Meta = mcp.NewMetaFromMap(map[string]any{
+		"rateLimitScope":    rejection.Scope,
+		"retryAfterSeconds": int(math.Ceil(rejection.RetryAfter.Seconds())),
+	})
+	return nil, result
+}
+
+// clientIDFromContext returns the MCP session ID of the calling client, or
+// an empty string for transports without sessions (all such calls share a
+// single per-client bucket).
+func clientIDFromContext(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
 	}
+	return ""
 }
@@ -3,9 +3,14 @@ package tools
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/Azure/mcp-kubernetes/pkg/cache"
 	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/ratelimit"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
 	"github.com/mark3labs/mcp-go/mcp"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -26,6 +31,7 @@ func (m *mockExecutor) Execute(ctx context.Context, args map[string]interface{},
 // Mock TelemetryService for testing
 type mockTelemetryService struct {
 	invocations []invocation
+	rejections  []string
 }
 
 type invocation struct {
@@ -42,6 +48,10 @@ func (m *mockTelemetryService) TrackToolInvocation(ctx context.Context, toolName
 	})
 }
 
+func (m *mockTelemetryService) TrackRateLimitRejection(ctx context.Context, toolName string, scope string) {
+	m.rejections = append(m.rejections, toolName+":"+scope)
+}
+
 // Implement other methods to satisfy interface
 func (m *mockTelemetryService) Initialize(ctx context.Context) error    { return nil }
 func (m *mockTelemetryService) Shutdown(ctx context.Context) error      { return nil }
@@ -328,3 +338,274 @@ func TestCreateToolHandlerWithNameError(t *testing.T) {
 		t.Error("Expected success to be false")
 	}
 }
+
+func TestCreateToolHandlerRateLimited(t *testing.T) {
+	executor := &mockExecutor{
+		shouldError: false,
+		result:      "success result",
+	}
+
+	mockTelemetry := &mockTelemetryService{}
+	rlConfig := ratelimit.NewConfig()
+	rlConfig.PerTool["call_kubectl"] = ratelimit.Limit{Rate: 1, Burst: 1}
+	cfg := &config.ConfigData{
+		TelemetryService: mockTelemetry,
+		RateLimiter:      ratelimit.NewLimiter(rlConfig),
+	}
+
+	handler := CreateToolHandlerWithName(executor, cfg, "call_kubectl")
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "call_kubectl",
+			Arguments: map[string]interface{}{
+				"command": "kubectl get pods -n default",
+			},
+		},
+	}
+
+	ctx := context.Background()
+	result, err := handler(ctx, req)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected first call to succeed, got err=%v result=%+v", err, result)
+	}
+
+	result, err = handler(ctx, req)
+	if err != nil {
+		t.Fatalf("Expected no error from handler, got %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected second call to be rejected by the rate limiter")
+	}
+	if result.Meta == nil || result.Meta.AdditionalFields["retryAfterSeconds"] != 1 {
+		t.Errorf("Expected retryAfterSeconds=1 in result meta, got %+v", result.Meta)
+	}
+
+	if len(mockTelemetry.rejections) != 1 || mockTelemetry.rejections[0] != "call_kubectl:tool" {
+		t.Errorf("Expected one tool-scoped rejection to be tracked, got %v", mockTelemetry.rejections)
+	}
+	// Rejected calls never reach the executor, so only the first call is tracked as an invocation
+	if len(mockTelemetry.invocations) != 1 {
+		t.Errorf("Expected 1 telemetry invocation, got %d", len(mockTelemetry.invocations))
+	}
+}
+
+// Mock executor that describes its calls as kubectl commands
+type mockDescribingExecutor struct {
+	calls int
+}
+
+func (m *mockDescribingExecutor) Execute(ctx context.Context, args map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	m.calls++
+	return fmt.Sprintf("result %d", m.calls), nil
+}
+
+func (m *mockDescribingExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	command, ok := params["command"].(string)
+	return security.CommandTypeKubectl, command, ok
+}
+
+func TestCreateToolHandlerResponseCache(t *testing.T) {
+	executor := &mockDescribingExecutor{}
+	cacheConfig, err := cache.ParseTTLs("1m")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cfg := &config.ConfigData{
+		SecurityConfig: security.NewSecurityConfig(),
+		ResponseCache:  cache.New(cacheConfig, cache.NewIdentityResolver(func() string { return "test" })),
+	}
+	cfg.SecurityConfig.AccessLevel = security.AccessLevelReadWrite
+
+	handler := CreateToolHandler(executor, cfg)
+	call := func(command string) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "call_kubectl",
+				Arguments: map[string]interface{}{"command": command},
+			},
+		}
+		result, err := handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return result
+	}
+	cacheHit := func(result *mcp.CallToolResult) bool {
+		if result.Meta == nil {
+			t.Fatal("Expected cache metadata on a cacheable result")
+		}
+		return result.Meta.AdditionalFields["cache"].(map[string]any)["hit"].(bool)
+	}
+
+	if first := call("get pods -n default"); cacheHit(first) {
+		t.Error("Expected first read to miss the cache")
+	}
+	second := call("get pods  -n default")
+	if !cacheHit(second) {
+		t.Error("Expected repeated read to hit the cache")
+	}
+	if executor.calls != 1 {
+		t.Errorf("Expected executor to run once, ran %d times", executor.calls)
+	}
+
+	if mutation := call("delete pod web -n default"); mutation.Meta != nil {
+		t.Error("Expected no cache metadata on a mutating command")
+	}
+	if third := call("get pods -n default"); cacheHit(third) {
+		t.Error("Expected mutation to invalidate the cached read")
+	}
+	if executor.calls != 3 {
+		t.Errorf("Expected executor to run 3 times, ran %d times", executor.calls)
+	}
+}
+
+func TestInvalidateAfterCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		err         error
+		invalidated bool
+	}{
+		{name: "read", command: "get pods -n default"},
+		{name: "write", command: "apply -f /tmp/out/default.yaml -n default", invalidated: true},
+		{name: "failed write", command: "delete pod web -n default", err: errors.New("exit status 1"), invalidated: true},
+		{name: "rejected write", command: "delete pod web -n default", err: &security.ValidationError{Message: "rejected"}},
+		{name: "write in other namespace", command: "delete pod web -n other"},
+		{name: "temporary pod", command: "run dns-check-0a1b2c3d -n default --image=busybox:1.36 --restart=Never --rm -i --quiet --command -- nslookup kubernetes.default", invalidated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheConfig, err := cache.ParseTTLs("1m")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			cfg := &config.ConfigData{
+				SecurityConfig: security.NewSecurityConfig(),
+				ResponseCache:  cache.New(cacheConfig, cache.NewIdentityResolver(func() string { return "test" })),
+			}
+			key, _ := cache.NewKey(cfg.ResponseCache.Cluster(), security.CommandTypeKubectl, "get pods -n default")
+			cfg.ResponseCache.Set(key, "default", "pods", time.Minute)
+
+			InvalidateAfterCommand(cfg, security.CommandTypeKubectl, tt.command, tt.err)
+			if _, hit := cfg.ResponseCache.Get(key); hit == tt.invalidated {
+				t.Errorf("Expected invalidated %v after %q, got %v", tt.invalidated, tt.command, !hit)
+			}
+		})
+	}
+}
+
+// mockFormattingExecutor renders one command in several output formats,
+// like call_kubectl's output_format
+type mockFormattingExecutor struct {
+	calls int
+}
+
+func (m *mockFormattingExecutor) Execute(ctx context.Context, args map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	m.calls++
+	return fmt.Sprintf("%s result %d", args["output_format"], m.calls), nil
+}
+
+func (m *mockFormattingExecutor) DescribeCommand(params map[string]interface{}) (string, string, bool) {
+	command, ok := params["command"].(string)
+	return security.CommandTypeKubectl, command + " -o json", ok
+}
+
+func (m *mockFormattingExecutor) DescribeOutputFormat(params map[string]interface{}) string {
+	format, _ := params["output_format"].(string)
+	return format
+}
+
+func TestCreateToolHandlerResponseCacheOutputFormat(t *testing.T) {
+	executor := &mockFormattingExecutor{}
+	cacheConfig, err := cache.ParseTTLs("1m")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cfg := &config.ConfigData{
+		SecurityConfig: security.NewSecurityConfig(),
+		ResponseCache:  cache.New(cacheConfig, cache.NewIdentityResolver(func() string { return "test" })),
+	}
+
+	handler := CreateToolHandler(executor, cfg)
+	call := func(format string) string {
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "call_kubectl",
+				Arguments: map[string]interface{}{"command": "get pods -n default", "output_format": format},
+			},
+		}
+		result, err := handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	for i, tt := range []struct {
+		format string
+		want   string
+	}{
+		{"json", "json result 1"},
+		{"summary", "summary result 2"},
+		{"json", "json result 1"},
+		{"summary", "summary result 2"},
+	} {
+		if got := call(tt.format); got != tt.want {
+			t.Errorf("Call %d: expected %q for output_format %s, got %q", i+1, tt.want, tt.format, got)
+		}
+	}
+	if executor.calls != 2 {
+		t.Errorf("Expected executor to run once per format, ran %d times", executor.calls)
+	}
+}
+
+type mockStructuredExecutor struct {
+	output string
+}
+
+func (m *mockStructuredExecutor) Execute(ctx context.Context, args map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	return m.output, nil
+}
+
+func (m *mockStructuredExecutor) ReturnsStructuredOutput(params map[string]interface{}) bool {
+	return params["output_format"] == "json"
+}
+
+func TestCreateToolHandlerStructuredOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		outputFormat   string
+		wantStructured bool
+	}{
+		{name: "json object", output: `{"kind":"PodList","items":[]}`, outputFormat: "json", wantStructured: true},
+		{name: "error text", output: "Error from server (NotFound)", outputFormat: "json", wantStructured: false},
+		{name: "text format", output: `{"kind":"PodList"}`, outputFormat: "text", wantStructured: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.ConfigData{}
+			handler := CreateToolHandler(&mockStructuredExecutor{output: tt.output}, cfg)
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "call_kubectl",
+					Arguments: map[string]interface{}{"command": "get pods", "output_format": tt.outputFormat},
+				},
+			}
+
+			result, err := handler(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := result.StructuredContent != nil; got != tt.wantStructured {
+				t.Errorf("Expected structured content %v, got %v", tt.wantStructured, got)
+			}
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok || textContent.Text != tt.output {
+				t.Errorf("Expected text content %q, got %v", tt.output, result.Content[0])
+			}
+		})
+	}
+}